@@ -0,0 +1,51 @@
+// Command mock-oidc is a minimal OpenID Connect issuer for local development.
+// It signs ID tokens for a fixed set of users and their groups so CloudDeck's
+// single sign-on can be exercised without an external identity provider.
+//
+// Point the backend at it with:
+//
+//	OIDC_ISSUER_URL=http://localhost:9000
+//	OIDC_CLIENT_ID=clouddeck
+//	OIDC_CLIENT_SECRET=clouddeck-secret
+//	OIDC_REDIRECT_URL=http://localhost:8080/api/auth/oidc/callback
+//	OIDC_ROLE_MAPPING=clouddeck-admins=admin,clouddeck-developers=developer
+package main
+
+import (
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/oidctest"
+)
+
+func main() {
+	users, err := oidctest.ParseUsers(getEnv("MOCK_OIDC_USERS", "alice=clouddeck-admins,bob=clouddeck-developers,carol=clouddeck-viewers"))
+	if err != nil {
+		log.Fatalf("Invalid MOCK_OIDC_USERS: %v", err)
+	}
+
+	issuerURL := getEnv("MOCK_OIDC_ISSUER", "http://localhost:9000")
+	issuer, err := oidctest.NewIssuer(
+		issuerURL,
+		getEnv("MOCK_OIDC_CLIENT_ID", "clouddeck"),
+		getEnv("MOCK_OIDC_CLIENT_SECRET", "clouddeck-secret"),
+		users,
+	)
+	if err != nil {
+		log.Fatalf("Failed to create mock OIDC issuer: %v", err)
+	}
+
+	addr := getEnv("MOCK_OIDC_ADDR", ":9000")
+	log.Printf("🔑 Mock OIDC issuer %s listening on %s", issuerURL, addr)
+	if err := http.ListenAndServe(addr, issuer.Handler()); err != nil {
+		log.Fatalf("Failed to start mock OIDC issuer: %v", err)
+	}
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -1,8 +1,14 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -11,17 +17,24 @@ import (
 	"github.com/SoumyaRaikwar/clouddeck-backend/internal/database"
 	"github.com/SoumyaRaikwar/clouddeck-backend/internal/handlers"
 	"github.com/SoumyaRaikwar/clouddeck-backend/internal/middleware"
-	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models" 
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
 	"github.com/SoumyaRaikwar/clouddeck-backend/internal/repositories"
 	"github.com/SoumyaRaikwar/clouddeck-backend/internal/services"
 )
 
+// shutdownTimeout bounds how long in-flight requests may take to finish on shutdown
+const shutdownTimeout = 10 * time.Second
+
 func main() {
 	// Load environment variables
 	if err := godotenv.Load(); err != nil {
 		log.Println("No .env file found, using system environment variables")
 	}
 
+	// Background work stops when the server is asked to shut down
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// Initialize databases
 	if err := database.InitPostgres(); err != nil {
 		log.Fatalf("❌ Failed to connect to PostgreSQL: %v", err)
@@ -33,27 +46,120 @@ func main() {
 	} else {
 		defer database.CloseMongoDB()
 	}
-database.DB.AutoMigrate(&models.GitOpsApp{})
+	database.DB.AutoMigrate(&models.GitOpsApp{})
 
-	// Initialize Gin router
-	router := gin.Default()
+	// Initialize Gin router (gin's default logger would print raw query strings)
+	router := gin.New()
 
 	// Middleware
+	router.Use(gin.Recovery())
 	router.Use(middleware.Logger())
+	allowedOrigins := []string{"http://localhost:3000", "http://localhost:3001"}
 	router.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"http://localhost:3000", "http://localhost:3001"},
+		AllowOrigins:     allowedOrigins,
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
 		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		ExposeHeaders:    []string{"Content-Length", handlers.ContinueHeader, handlers.RemainingItemCountHeader},
 		AllowCredentials: true,
 	}))
 
 	// Health check
 	router.GET("/health", handlers.HealthCheck)
 
-	// API routes
-	api := router.Group("/api")
+	// Authentication (public)
+	userRepo := repositories.NewUserRepository()
+	orgRepo := repositories.NewOrganizationRepository()
+	authService := services.NewAuthService(userRepo, orgRepo)
+	if err := authService.EnsureBootstrapAdmin(); err != nil {
+		log.Printf("⚠️  %v", err)
+	}
+	authHandler := handlers.NewAuthHandler(authService)
+
+	// The server's own Docker daemon and Kubernetes cluster belong to the
+	// default organization
+	defaultOrg, err := orgRepo.FindBySlug(models.DefaultOrganizationSlug)
+	if err != nil {
+		log.Fatalf("❌ Default organization not found: %v", err)
+	}
+
+	authRoutes := router.Group("/api/auth")
+	{
+		authRoutes.POST("/login", authHandler.Login)
+		authRoutes.POST("/refresh", authHandler.Refresh)
+	}
+
+	// Single sign-on through an OIDC provider (optional)
+	oidcConfig, err := services.OIDCConfigFromEnv()
+	if err != nil {
+		log.Printf("⚠️  OIDC login disabled: %v", err)
+	}
+	if oidcConfig != nil {
+		oidcHandler := handlers.NewOIDCHandler(services.NewOIDCService(oidcConfig, userRepo, orgRepo, authService))
+		authRoutes.GET("/oidc/login", oidcHandler.Login)
+		authRoutes.GET("/oidc/callback", oidcHandler.Callback)
+		log.Printf("✅ OIDC login enabled for issuer %s", oidcConfig.IssuerURL)
+	}
+
+	// Credential vault for third-party tokens
+	vault, err := services.NewVaultFromEnv()
+	if err != nil {
+		log.Printf("⚠️  Credential vault disabled: %v", err)
+	}
+	credentialService := services.NewCredentialService(repositories.NewCredentialRepository(), vault)
+	credentialHandler := handlers.NewCredentialHandler(credentialService)
+
+	// Audit log of mutating calls and cluster actions
+	auditRepo := repositories.NewAuditRepository()
+	if err := auditRepo.EnsureIndexes(); err != nil {
+		log.Printf("⚠️  Audit log indexes not created: %v", err)
+	}
+	auditService := services.NewAuditService(auditRepo)
+	auditHandler := handlers.NewAuditHandler(auditService)
+
+	// API keys for machine clients
+	apiKeyService := services.NewAPIKeyService(repositories.NewAPIKeyRepository(), userRepo)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService)
+
+	// Role requirements for route groups
+	viewer := middleware.RequireRole(models.RoleViewer)
+	developer := middleware.RequireRole(models.RoleDeveloper)
+	operator := middleware.RequireRole(models.RoleOperator)
+	admin := middleware.RequireRole(models.RoleAdmin)
+
+	// Interactive exec sessions over WebSockets, for containers and pods
+	terminals := handlers.NewTerminalBridge(allowedOrigins)
+
+	// API routes (authenticated)
+	api := router.Group("/api", middleware.AuthRequired(authService, apiKeyService), middleware.Audit(auditService))
 	{
+		api.GET("/auth/me", authHandler.Me)
+		api.GET("/audit", admin, auditHandler.GetAuditLogs)
+
+		// Organizations (tenants)
+		orgService := services.NewOrganizationService(orgRepo, authService)
+		orgHandler := handlers.NewOrganizationHandler(orgService)
+
+		organizations := api.Group("/organizations")
+		{
+			organizations.GET("/current", orgHandler.GetCurrentOrganization)
+			organizations.GET("", admin, orgHandler.GetOrganizations)
+			organizations.POST("", admin, orgHandler.CreateOrganization)
+		}
+
+		users := api.Group("/users", admin)
+		{
+			users.GET("", authHandler.GetUsers)
+			users.POST("", authHandler.CreateUser)
+			users.PUT("/:id/role", authHandler.UpdateUserRole)
+		}
+
+		apiKeys := api.Group("/api-keys")
+		{
+			apiKeys.GET("", apiKeyHandler.GetAPIKeys)
+			apiKeys.POST("", apiKeyHandler.CreateAPIKey)
+			apiKeys.DELETE("/:id", apiKeyHandler.RevokeAPIKey)
+		}
+
 		// Items (original CRUD)
 		itemRepo := repositories.NewItemRepository()
 		itemService := services.NewItemService(itemRepo)
@@ -61,107 +167,158 @@ database.DB.AutoMigrate(&models.GitOpsApp{})
 
 		items := api.Group("/items")
 		{
-			items.GET("", itemHandler.GetAllItems)
-			items.GET("/:id", itemHandler.GetItem)
-			items.POST("", itemHandler.CreateItem)
-			items.PUT("/:id", itemHandler.UpdateItem)
-			items.DELETE("/:id", itemHandler.DeleteItem)
+			items.GET("", viewer, itemHandler.GetAllItems)
+			items.GET("/:id", viewer, itemHandler.GetItem)
+			items.POST("", developer, itemHandler.CreateItem)
+			items.PUT("/:id", developer, itemHandler.UpdateItem)
+			items.DELETE("/:id", developer, itemHandler.DeleteItem)
 		}
 
-		// Projects (new)
+		// Projects (new) - reads and updates are checked per project in the handlers
 		projectRepo := repositories.NewProjectRepository()
+		accessService := services.NewAccessService(repositories.NewProjectMemberRepository(), userRepo, projectRepo)
 		projectService := services.NewProjectService(projectRepo)
-		projectHandler := handlers.NewProjectHandler(projectService)
+		projectHandler := handlers.NewProjectHandler(projectService, accessService)
+		projectMemberHandler := handlers.NewProjectMemberHandler(accessService)
 
 		projects := api.Group("/projects")
 		{
 			projects.GET("", projectHandler.GetAllProjects)
 			projects.GET("/:id", projectHandler.GetProject)
-			projects.POST("", projectHandler.CreateProject)
+			projects.POST("", developer, projectHandler.CreateProject)
 			projects.PUT("/:id", projectHandler.UpdateProject)
-			projects.DELETE("/:id", projectHandler.DeleteProject)
-			projects.GET("/stats", projectHandler.GetProjectStats)
+			projects.DELETE("/:id", admin, projectHandler.DeleteProject)
+			projects.GET("/stats", viewer, projectHandler.GetProjectStats)
+			projects.GET("/:id/members", projectMemberHandler.GetMembers)
+			projects.POST("/:id/members", projectMemberHandler.AddMember)
+			projects.DELETE("/:id/members/:userId", projectMemberHandler.RemoveMember)
 		}
 
 		// Tasks - No DB parameter needed
 		taskRepo := repositories.NewTaskRepository()
 		taskService := services.NewTaskService(taskRepo, projectRepo)
-		taskHandler := handlers.NewTaskHandler(taskService)
+		taskHandler := handlers.NewTaskHandler(taskService, accessService)
 
 		tasks := api.Group("/tasks")
 		{
-			tasks.GET("", taskHandler.GetAllTasks)  // Supports ?projectId=X
+			tasks.GET("", taskHandler.GetAllTasks) // Supports ?projectId=X
 			tasks.GET("/:id", taskHandler.GetTask)
 			tasks.POST("", taskHandler.CreateTask)
 			tasks.PUT("/:id", taskHandler.UpdateTask)
 			tasks.DELETE("/:id", taskHandler.DeleteTask)
-			tasks.GET("/stats", taskHandler.GetTaskStats)
+			tasks.GET("/stats", viewer, taskHandler.GetTaskStats)
+		}
+
+		// Third-party credentials of the current user
+		credentials := api.Group("/credentials")
+		{
+			credentials.GET("", credentialHandler.GetCredentials)
+			credentials.POST("", credentialHandler.CreateCredential)
+			credentials.PUT("/:id/rotate", credentialHandler.RotateCredential)
+			credentials.DELETE("/:id", credentialHandler.RevokeCredential)
 		}
 
 		// GitHub Integration
-		githubService := services.NewGitHubService(taskRepo, projectRepo)
-		githubHandler := handlers.NewGitHubHandler(githubService)
+		githubService := services.NewGitHubService(taskRepo, projectRepo, credentialService)
+		githubHandler := handlers.NewGitHubHandler(githubService, accessService)
 
 		githubRoutes := api.Group("/github")
 		{
-			githubRoutes.GET("/prs", githubHandler.GetUserPRs)
-			githubRoutes.GET("/issues", githubHandler.GetRepoIssues)
+			githubRoutes.GET("/prs", viewer, githubHandler.GetUserPRs)
+			githubRoutes.GET("/issues", viewer, githubHandler.GetRepoIssues)
 			githubRoutes.POST("/sync-prs", githubHandler.SyncPRsToTasks)
 			githubRoutes.POST("/sync-issues", githubHandler.SyncIssuesToTasks)
 		}
 
-		// Containers (optional - if you added it)
-		containerService, err := services.NewContainerService()
-		if err == nil {
-			containerHandler := handlers.NewContainerHandler(containerService)
-			
-			containers := api.Group("/containers")
-			{
-				containers.GET("", containerHandler.GetContainers)
-				containers.GET("/:id/logs", containerHandler.GetContainerLogs)
-			}
+		// Containers, through the Docker Engine API: hosts registered through the
+		// API, plus the server's own daemon at DOCKER_HOST as a host of the
+		// default organization
+		dockerHostService := services.NewDockerHostService(repositories.NewDockerHostRepository(), vault)
+		if local, err := dockerHostService.EnsureLocalHost(defaultOrg.ID); err != nil {
+			log.Printf("⚠️  Docker client not available: %v", err)
+		} else if health := dockerHostService.DockerHostHealth(defaultOrg.ID, local.ID); health.Error != "" {
+			log.Printf("⚠️  Docker engine not reachable yet: %s", health.Error)
 		}
-	}
+		dockerHostHandler := handlers.NewDockerHostHandler(dockerHostService)
+		containerHandler := handlers.NewContainerHandler(dockerHostService, terminals)
+		dockerResourceHandler := handlers.NewDockerResourceHandler(dockerHostService)
 
-	// Kubernetes Integration
-k8sService, err := services.NewKubernetesService()
-if err == nil {
-	k8sHandler := handlers.NewKubernetesHandler(k8sService)
-	
-	k8s := api.Group("/kubernetes")
-	{
-		k8s.GET("/pods", k8sHandler.GetPods)
-		k8s.GET("/deployments", k8sHandler.GetDeployments)
-		k8s.GET("/services", k8sHandler.GetServices)
-		k8s.GET("/namespaces", k8sHandler.GetNamespaces)
-		k8s.GET("/pods/:namespace/:pod/logs", k8sHandler.GetPodLogs)
-	}
-} else {
-	log.Printf("⚠️  Kubernetes client not available: %v", err)
-}
+		// Background sampler feeding the per-container metrics history
+		containerMetricsService := services.NewContainerMetricsService(
+			dockerHostService,
+			repositories.NewContainerRepository(),
+			repositories.NewContainerMetricRepository(),
+			services.ContainerMetricsConfigFromEnv(),
+		)
+		containerMetricsService.Start(ctx)
+		defer containerMetricsService.Wait()
+		containerMetricsHandler := handlers.NewContainerMetricsHandler(containerMetricsService, dockerHostService)
 
-	gitopsService := services.NewGitOpsService(database.DB)
-		gitopsHandler := handlers.NewGitOpsHandler(gitopsService)
+		dockerRoutes := dockerRouteHandlers{
+			containers: containerHandler,
+			metrics:    containerMetricsHandler,
+			resources:  dockerResourceHandler,
+			stacks:     handlers.NewStackHandler(dockerHostService),
+		}
 
-		gitops := api.Group("/gitops")
+		registerDockerRoutes(api, dockerRoutes, viewer, operator, admin)
+
+		dockerHosts := api.Group("/docker-hosts")
 		{
-			gitops.POST("/apps", gitopsHandler.CreateApp)
-			gitops.GET("/apps", gitopsHandler.GetAllApps)
-			gitops.GET("/apps/:id", gitopsHandler.GetApp)
-			gitops.POST("/apps/:id/sync", gitopsHandler.SyncApp)
-			gitops.DELETE("/apps/:id", gitopsHandler.DeleteApp)
+			dockerHosts.GET("", viewer, dockerHostHandler.GetDockerHosts)
+			dockerHosts.POST("", admin, dockerHostHandler.CreateDockerHost)
+			dockerHosts.GET("/containers", viewer, dockerHostHandler.GetFleetContainers)
+			dockerHosts.GET("/:host", viewer, dockerHostHandler.GetDockerHost)
+			dockerHosts.DELETE("/:host", admin, dockerHostHandler.DeleteDockerHost)
+
+			registerDockerRoutes(dockerHosts.Group("/:host"), dockerRoutes, viewer, operator, admin)
 		}
+	}
 
-// CI/CD Pipeline (inside api group)
-cicdService := services.NewCICDService("")
-cicdHandler := handlers.NewCICDHandler(cicdService)
+	// Kubernetes Integration: clusters registered through the API, plus the
+	// cluster the server runs in (in-cluster config or $HOME/.kube/config) as a
+	// cluster of the default organization. If that cluster cannot be loaded yet,
+	// listing clusters retries recording it.
+	clusterService := services.NewClusterService(repositories.NewClusterRepository(), vault)
+	if _, err := clusterService.EnsureLocalCluster(defaultOrg.ID); err != nil {
+		log.Printf("⚠️  Default Kubernetes cluster not available: %v", err)
+	}
+	clusterHandler := handlers.NewClusterHandler(clusterService)
+	k8sHandler := handlers.NewKubernetesHandler(clusterService, terminals)
 
-cicd := api.Group("/cicd")
-{
-	cicd.GET("/runs", cicdHandler.GetWorkflowRuns)
-	cicd.GET("/stats", cicdHandler.GetPipelineStats)
-	cicd.GET("/workflows", cicdHandler.GetWorkflows)
-}
+	clusters := api.Group("/clusters")
+	{
+		clusters.GET("", viewer, clusterHandler.GetClusters)
+		clusters.POST("", admin, clusterHandler.CreateCluster)
+		clusters.GET("/:cluster", viewer, clusterHandler.GetCluster)
+		clusters.DELETE("/:cluster", admin, clusterHandler.DeleteCluster)
+
+		registerKubernetesRoutes(clusters.Group("/:cluster/kubernetes", viewer), k8sHandler, operator, admin)
+	}
+	registerKubernetesRoutes(api.Group("/kubernetes", viewer), k8sHandler, operator, admin)
+
+	gitopsService := services.NewGitOpsService(database.DB, auditService, clusterService)
+	gitopsHandler := handlers.NewGitOpsHandler(gitopsService)
+
+	gitops := api.Group("/gitops")
+	{
+		gitops.POST("/apps", operator, gitopsHandler.CreateApp)
+		gitops.GET("/apps", viewer, gitopsHandler.GetAllApps)
+		gitops.GET("/apps/:id", viewer, gitopsHandler.GetApp)
+		gitops.POST("/apps/:id/sync", operator, gitopsHandler.SyncApp)
+		gitops.DELETE("/apps/:id", operator, gitopsHandler.DeleteApp)
+	}
+
+	// CI/CD Pipeline (inside api group)
+	cicdService := services.NewCICDService(credentialService)
+	cicdHandler := handlers.NewCICDHandler(cicdService)
+
+	cicd := api.Group("/cicd", viewer)
+	{
+		cicd.GET("/runs", cicdHandler.GetWorkflowRuns)
+		cicd.GET("/stats", cicdHandler.GetPipelineStats)
+		cicd.GET("/workflows", cicdHandler.GetWorkflows)
+	}
 
 	// Start server
 	port := os.Getenv("PORT")
@@ -169,8 +326,110 @@ cicd := api.Group("/cicd")
 		port = "8080"
 	}
 
+	server := &http.Server{Addr: ":" + port, Handler: router}
+	shutdown := make(chan struct{})
+	go func() {
+		defer close(shutdown)
+		<-ctx.Done()
+		log.Println("🛑 Shutting down")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Printf("⚠️  Server shutdown: %v", err)
+		}
+	}()
+
 	log.Printf("🚀 Server starting on port %s", port)
-	if err := router.Run(":" + port); err != nil {
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		log.Fatalf("Failed to start server: %v", err)
 	}
+	<-shutdown
+}
+
+// registerKubernetesRoutes mounts the per-cluster Kubernetes endpoints; they are
+// served for registered clusters under /api/clusters/:cluster/kubernetes and,
+// under /api/kubernetes, for the server's own cluster when it belongs to the
+// caller's organization. The group requires viewer; operator guards
+// the routes that change or open a shell into workloads, and admin the node
+// operations, which affect every workload on the node.
+func registerKubernetesRoutes(k8s *gin.RouterGroup, k8sHandler *handlers.KubernetesHandler, operator, admin gin.HandlerFunc) {
+	k8s.GET("/health", k8sHandler.GetHealth)
+	k8s.GET("/pods", k8sHandler.GetPods)
+	k8s.GET("/deployments", k8sHandler.GetDeployments)
+	k8s.GET("/deployments/:namespace/:name", k8sHandler.GetDeployment)
+	k8s.GET("/services", k8sHandler.GetServices)
+	k8s.GET("/namespaces", k8sHandler.GetNamespaces)
+	k8s.GET("/nodes", k8sHandler.GetNodes)
+	k8s.GET("/nodes/:node", k8sHandler.GetNode)
+	k8s.GET("/watch", k8sHandler.WatchResources)
+	k8s.GET("/events", k8sHandler.GetEvents)
+	k8s.GET("/metrics/pods", k8sHandler.GetPodUsage)
+	k8s.GET("/metrics/pods/:namespace/:pod", k8sHandler.GetPodUsageFor)
+	k8s.GET("/metrics/nodes", k8sHandler.GetNodeUsage)
+	k8s.GET("/api-resources", k8sHandler.GetAPIResources)
+	k8s.GET("/resources/:group/:version/:resource", k8sHandler.ListDynamicResources)
+	k8s.GET("/resources/:group/:version/:resource/:name", k8sHandler.GetDynamicResource)
+	for _, kind := range services.TypedResourceKinds {
+		k8s.GET("/"+kind, k8sHandler.ListResources(kind))
+		k8s.GET("/"+kind+"/:namespace/:name", k8sHandler.GetResource(kind))
+	}
+	k8s.GET("/pods/:namespace/:pod", k8sHandler.GetPod)
+	k8s.GET("/pods/:namespace/:pod/logs", k8sHandler.GetPodLogs)
+	k8s.GET("/pods/:namespace/:pod/logs/stream", k8sHandler.StreamPodLogs)
+	k8s.GET("/pods/:namespace/:pod/exec", operator, k8sHandler.ExecPod)
+	k8s.DELETE("/pods/:namespace/:pod", operator, k8sHandler.DeletePod)
+	k8s.POST("/deployments/:namespace/:name/scale", operator, k8sHandler.ScaleDeployment)
+	k8s.POST("/deployments/:namespace/:name/restart", operator, k8sHandler.RestartDeployment)
+	k8s.POST("/nodes/:node/cordon", admin, k8sHandler.CordonNode)
+	k8s.POST("/nodes/:node/uncordon", admin, k8sHandler.UncordonNode)
+	k8s.POST("/nodes/:node/drain", admin, k8sHandler.DrainNode)
+}
+
+// dockerRouteHandlers are the handlers behind the per-host Docker endpoints
+type dockerRouteHandlers struct {
+	containers *handlers.ContainerHandler
+	metrics    *handlers.ContainerMetricsHandler
+	resources  *handlers.DockerResourceHandler
+	stacks     *handlers.StackHandler
+}
+
+// registerDockerRoutes mounts the per-host Docker endpoints; they are served for
+// registered hosts under /api/docker-hosts/:host and, under /api, for the
+// server's own daemon when it belongs to the caller's organization. Pruning
+// volumes deletes data, so it needs admin.
+func registerDockerRoutes(group *gin.RouterGroup, h dockerRouteHandlers, viewer, operator, admin gin.HandlerFunc) {
+	containers := group.Group("/containers")
+	{
+		containers.GET("", viewer, h.containers.GetContainers)
+		containers.GET("/:id/logs", viewer, h.containers.GetContainerLogs)
+		containers.GET("/:id/logs/stream", viewer, h.containers.StreamContainerLogs)
+		containers.GET("/:id/metrics", viewer, h.metrics.GetContainerMetrics)
+		containers.GET("/:id/exec", operator, h.containers.ExecContainer)
+		containers.POST("/:id/start", operator, h.containers.StartContainer)
+		containers.POST("/:id/stop", operator, h.containers.StopContainer)
+		containers.POST("/:id/restart", operator, h.containers.RestartContainer)
+		containers.POST("/:id/pause", operator, h.containers.PauseContainer)
+		containers.POST("/:id/unpause", operator, h.containers.UnpauseContainer)
+		containers.POST("/:id/kill", operator, h.containers.KillContainer)
+		containers.POST("/:id/remove", operator, h.containers.RemoveContainer)
+	}
+
+	// Images, volumes and networks
+	group.GET("/images", viewer, h.resources.GetImages)
+	group.POST("/images/prune", operator, h.resources.PruneImages)
+	group.GET("/volumes", viewer, h.resources.GetVolumes)
+	group.POST("/volumes/prune", admin, h.resources.PruneVolumes)
+	group.GET("/networks", viewer, h.resources.GetNetworks)
+	group.POST("/networks/prune", operator, h.resources.PruneNetworks)
+
+	// Docker Compose projects, grouped from their containers' labels
+	stacks := group.Group("/stacks")
+	{
+		stacks.GET("", viewer, h.stacks.GetStacks)
+		stacks.GET("/:stack", viewer, h.stacks.GetStack)
+		stacks.POST("/:stack/restart", operator, h.stacks.RestartStack)
+		stacks.POST("/:stack/recreate", operator, h.stacks.RecreateStack)
+		stacks.POST("/:stack/services/:service/restart", operator, h.stacks.RestartService)
+		stacks.POST("/:stack/services/:service/recreate", operator, h.stacks.RecreateService)
+	}
 }
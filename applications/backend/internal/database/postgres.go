@@ -60,13 +60,45 @@ func InitPostgres() error {
 		&models.Task{},
 		&models.Container{},
 		&models.GitOpsApp{}, // <-- ADD THIS
+		&models.User{},
+		&models.ProjectMember{},
+		&models.Credential{},
+		&models.Organization{},
+		&models.APIKey{},
+		&models.Cluster{},
+		&models.ContainerMetric{},
+		&models.DockerHost{},
 	); err != nil {
 		return fmt.Errorf("failed to migrate database: %w", err)
 	}
+
+	if err := ensureDefaultOrganization(); err != nil {
+		return fmt.Errorf("failed to migrate organizations: %w", err)
+	}
 	log.Println("✅ PostgreSQL connected successfully")
 	return nil
 }
 
+// ensureDefaultOrganization creates the default organization and assigns every
+// row created before multi-tenancy existed to it
+func ensureDefaultOrganization() error {
+	org := models.Organization{Name: "Default", Slug: models.DefaultOrganizationSlug}
+	if err := PostgresDB.Where("slug = ?", org.Slug).FirstOrCreate(&org).Error; err != nil {
+		return err
+	}
+
+	for _, table := range []string{"users", "projects", "gitops_apps", "credentials"} {
+		err := PostgresDB.Exec(
+			"UPDATE "+table+" SET organization_id = ? WHERE organization_id IS NULL OR organization_id = 0",
+			org.ID,
+		).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // ClosePostgres closes PostgreSQL connection
 func ClosePostgres() {
 	sqlDB, err := PostgresDB.DB()
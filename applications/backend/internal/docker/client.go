@@ -0,0 +1,446 @@
+// Package docker is a small client for the Docker Engine API. It covers the
+// endpoints CloudDeck uses and talks to the daemon over a unix socket or TCP.
+package docker
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	DefaultHost       = "unix:///var/run/docker.sock"
+	DefaultAPIVersion = "1.41"
+)
+
+// Engine is the subset of the Docker Engine API used by CloudDeck. Client
+// implements it against a real daemon; dockertest.Server provides a fake.
+type Engine interface {
+	Ping(ctx context.Context) error
+	ListContainers(ctx context.Context, all bool, labels ...string) ([]Container, error)
+	InspectContainer(ctx context.Context, id string) (*ContainerDetails, error)
+	ContainerStats(ctx context.Context, id string) (*Stats, error)
+	ContainerLogs(ctx context.Context, id string, opts LogsOptions) (io.ReadCloser, error)
+
+	StartContainer(ctx context.Context, id string) error
+	StopContainer(ctx context.Context, id string, timeout *int) error
+	RestartContainer(ctx context.Context, id string, timeout *int) error
+	PauseContainer(ctx context.Context, id string) error
+	UnpauseContainer(ctx context.Context, id string) error
+	KillContainer(ctx context.Context, id string, signal string) error
+	RemoveContainer(ctx context.Context, id string, force bool, removeVolumes bool) error
+
+	InspectContainerSpec(ctx context.Context, id string) (*ContainerSpec, error)
+	CreateContainer(ctx context.Context, opts CreateContainerOptions) (string, error)
+	RenameContainer(ctx context.Context, id string, name string) error
+	ConnectNetwork(ctx context.Context, network string, containerID string, endpoint EndpointConfig) error
+	PullImage(ctx context.Context, image string) error
+
+	CreateExec(ctx context.Context, containerID string, config ExecConfig) (string, error)
+	StartExec(ctx context.Context, execID string, tty bool) (*HijackedConn, error)
+	ResizeExec(ctx context.Context, execID string, height, width uint16) error
+	InspectExec(ctx context.Context, execID string) (*ExecInspect, error)
+
+	ListImages(ctx context.Context) ([]Image, error)
+	DiskUsage(ctx context.Context) (*DiskUsage, error)
+	ListNetworks(ctx context.Context) ([]Network, error)
+	PruneImages(ctx context.Context, all bool) (*PruneReport, error)
+	PruneVolumes(ctx context.Context) (*PruneReport, error)
+	PruneNetworks(ctx context.Context) (*PruneReport, error)
+
+	Version(ctx context.Context) (*VersionInfo, error)
+}
+
+// Client talks to a Docker daemon over HTTP
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+	version    string
+	// dial opens a raw connection to the daemon for hijacked endpoints such as exec
+	dial func(ctx context.Context) (net.Conn, error)
+	// onClose releases transport resources such as an SSH connection
+	onClose func()
+}
+
+// NewClientFromEnv connects to DOCKER_HOST (default unix:///var/run/docker.sock)
+// using DOCKER_API_VERSION (default 1.41)
+func NewClientFromEnv() (*Client, error) {
+	return NewClient(HostFromEnv(), os.Getenv("DOCKER_API_VERSION"))
+}
+
+// HostFromEnv returns the daemon address DOCKER_HOST names, or DefaultHost
+func HostFromEnv() string {
+	if host := os.Getenv("DOCKER_HOST"); host != "" {
+		return host
+	}
+	return DefaultHost
+}
+
+// NewClient connects to host, given as unix:///path/to/socket or tcp://host:port
+func NewClient(host string, version string) (*Client, error) {
+	return NewClientWithOptions(Options{Host: host, Version: version})
+}
+
+// Options describe how to reach a daemon
+type Options struct {
+	// Host is unix:///path/to/socket, tcp://host:port or ssh://user@host[:port][/socket/path]
+	Host    string
+	Version string
+	// TLS secures tcp hosts; the daemon is then reached over HTTPS
+	TLS *tls.Config
+	// SSH authenticates ssh hosts; the daemon's unix socket is forwarded over the connection
+	SSH *SSHAuth
+	// Control, when set, vets every TCP connection to a tcp or ssh host once its
+	// address is resolved, as net.Dialer.Control does, and can refuse it
+	Control func(network, address string, c syscall.RawConn) error
+}
+
+// NewClientWithOptions connects to opts.Host. Nothing is dialed until the first request.
+func NewClientWithOptions(opts Options) (*Client, error) {
+	version := opts.Version
+	if version == "" {
+		version = DefaultAPIVersion
+	}
+
+	hostURL, err := url.Parse(opts.Host)
+	if err != nil {
+		return nil, fmt.Errorf("invalid docker host %q: %w", opts.Host, err)
+	}
+
+	transport := &http.Transport{
+		MaxIdleConnsPerHost: 16,
+		IdleConnTimeout:     90 * time.Second,
+	}
+	baseURL := ""
+	var dial func(ctx context.Context) (net.Conn, error)
+	var onClose func()
+	netDialer := &net.Dialer{Control: opts.Control}
+
+	switch hostURL.Scheme {
+	case "unix":
+		socketPath := hostURL.Path
+		dial = func(ctx context.Context) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", socketPath)
+		}
+		baseURL = "http://docker"
+	case "tcp", "http", "https":
+		if hostURL.Host == "" {
+			return nil, fmt.Errorf("invalid docker host %q: missing address", opts.Host)
+		}
+		address := hostURL.Host
+		if opts.TLS == nil && hostURL.Scheme != "https" {
+			transport.DialContext = netDialer.DialContext
+			dial = func(ctx context.Context) (net.Conn, error) {
+				return netDialer.DialContext(ctx, "tcp", address)
+			}
+			baseURL = "http://" + hostURL.Host
+			break
+		}
+
+		tlsConfig := opts.TLS
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{}
+		}
+		tlsConfig = tlsConfig.Clone()
+		if tlsConfig.ServerName == "" {
+			tlsConfig.ServerName = hostURL.Hostname()
+		}
+		transport.TLSClientConfig = tlsConfig
+		transport.DialContext = netDialer.DialContext
+		dial = func(ctx context.Context) (net.Conn, error) {
+			dialer := tls.Dialer{NetDialer: netDialer, Config: tlsConfig}
+			return dialer.DialContext(ctx, "tcp", address)
+		}
+		baseURL = "https://" + hostURL.Host
+	case "ssh":
+		if opts.SSH == nil {
+			return nil, errors.New("ssh docker hosts need SSH credentials")
+		}
+		sshDialer, err := newSSHDialer(hostURL, *opts.SSH, netDialer)
+		if err != nil {
+			return nil, err
+		}
+		dial = sshDialer.dial
+		onClose = sshDialer.close
+		baseURL = "http://docker"
+	default:
+		return nil, fmt.Errorf("unsupported docker host scheme %q", hostURL.Scheme)
+	}
+
+	if baseURL == "http://docker" {
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dial(ctx)
+		}
+	}
+
+	client := NewClientWithHTTP(&http.Client{Transport: transport}, baseURL, version)
+	if dial != nil {
+		client.dial = dial
+	}
+	client.onClose = onClose
+	return client, nil
+}
+
+// Close releases idle connections; the client must not be used afterwards
+func (c *Client) Close() {
+	c.httpClient.CloseIdleConnections()
+	if c.onClose != nil {
+		c.onClose()
+	}
+}
+
+// NewClientWithHTTP uses an existing HTTP client, e.g. one pointed at a dockertest.Server
+func NewClientWithHTTP(httpClient *http.Client, baseURL string, version string) *Client {
+	if version == "" {
+		version = DefaultAPIVersion
+	}
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	return &Client{
+		httpClient: httpClient,
+		baseURL:    baseURL,
+		version:    version,
+		dial: func(ctx context.Context) (net.Conn, error) {
+			hostURL, err := url.Parse(baseURL)
+			if err != nil {
+				return nil, err
+			}
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "tcp", hostURL.Host)
+		},
+	}
+}
+
+var (
+	// ErrUnreachable wraps failures to connect to the daemon at all
+	ErrUnreachable = errors.New("docker engine unreachable")
+	// ErrNotModified is returned when a container is already in the requested
+	// state, e.g. starting a running container
+	ErrNotModified = errors.New("container is already in the requested state")
+)
+
+// APIError is a non-2xx response from the daemon
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("docker engine: %s (status %d)", e.Message, e.StatusCode)
+}
+
+// IsNotFound reports whether err is a 404 from the daemon
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// IsConflict reports whether err is a 409 from the daemon, e.g. stopping a stopped container
+func IsConflict(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusConflict
+}
+
+func (c *Client) Ping(ctx context.Context) error {
+	resp, err := c.do(ctx, http.MethodGet, "/_ping", nil, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Version reports the daemon's version and platform
+func (c *Client) Version(ctx context.Context) (*VersionInfo, error) {
+	var info VersionInfo
+	if err := c.getJSON(ctx, "/version", nil, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// ListContainers lists the running containers, or every container with all.
+// Labels, "key" or "key=value", narrow the list to containers carrying each.
+func (c *Client) ListContainers(ctx context.Context, all bool, labels ...string) ([]Container, error) {
+	query := url.Values{}
+	if all {
+		query.Set("all", "1")
+	}
+	if len(labels) > 0 {
+		filters, err := json.Marshal(map[string][]string{"label": labels})
+		if err != nil {
+			return nil, err
+		}
+		query.Set("filters", string(filters))
+	}
+
+	var containers []Container
+	if err := c.getJSON(ctx, "/containers/json", query, &containers); err != nil {
+		return nil, err
+	}
+	return containers, nil
+}
+
+func (c *Client) InspectContainer(ctx context.Context, id string) (*ContainerDetails, error) {
+	var details ContainerDetails
+	if err := c.getJSON(ctx, "/containers/"+url.PathEscape(id)+"/json", nil, &details); err != nil {
+		return nil, err
+	}
+	return &details, nil
+}
+
+// ContainerStats returns a single stats sample. The daemon waits for a second
+// sample internally so the CPU delta fields are populated.
+func (c *Client) ContainerStats(ctx context.Context, id string) (*Stats, error) {
+	query := url.Values{"stream": {"false"}}
+
+	var stats Stats
+	if err := c.getJSON(ctx, "/containers/"+url.PathEscape(id)+"/stats", query, &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// ContainerLogs returns the raw log stream. Unless the container has a TTY the
+// stream is multiplexed; use ReadLogLines to split it.
+func (c *Client) ContainerLogs(ctx context.Context, id string, opts LogsOptions) (io.ReadCloser, error) {
+	resp, err := c.do(ctx, http.MethodGet, "/containers/"+url.PathEscape(id)+"/logs", opts.query(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (c *Client) StartContainer(ctx context.Context, id string) error {
+	return c.post(ctx, "/containers/"+url.PathEscape(id)+"/start", nil)
+}
+
+func (c *Client) StopContainer(ctx context.Context, id string, timeout *int) error {
+	return c.post(ctx, "/containers/"+url.PathEscape(id)+"/stop", timeoutQuery(timeout))
+}
+
+func (c *Client) RestartContainer(ctx context.Context, id string, timeout *int) error {
+	return c.post(ctx, "/containers/"+url.PathEscape(id)+"/restart", timeoutQuery(timeout))
+}
+
+func (c *Client) PauseContainer(ctx context.Context, id string) error {
+	return c.post(ctx, "/containers/"+url.PathEscape(id)+"/pause", nil)
+}
+
+func (c *Client) UnpauseContainer(ctx context.Context, id string) error {
+	return c.post(ctx, "/containers/"+url.PathEscape(id)+"/unpause", nil)
+}
+
+// KillContainer sends signal (default SIGKILL) to the container's main process
+func (c *Client) KillContainer(ctx context.Context, id string, signal string) error {
+	query := url.Values{}
+	if signal != "" {
+		query.Set("signal", signal)
+	}
+	return c.post(ctx, "/containers/"+url.PathEscape(id)+"/kill", query)
+}
+
+func (c *Client) RemoveContainer(ctx context.Context, id string, force bool, removeVolumes bool) error {
+	query := url.Values{
+		"force": {strconv.FormatBool(force)},
+		"v":     {strconv.FormatBool(removeVolumes)},
+	}
+	resp, err := c.do(ctx, http.MethodDelete, "/containers/"+url.PathEscape(id), query, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// post sends a body-less POST, mapping 304 Not Modified to ErrNotModified
+func (c *Client) post(ctx context.Context, path string, query url.Values) error {
+	resp, err := c.do(ctx, http.MethodPost, path, query, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return ErrNotModified
+	}
+	return nil
+}
+
+func timeoutQuery(timeout *int) url.Values {
+	if timeout == nil {
+		return nil
+	}
+	return url.Values{"t": {strconv.Itoa(*timeout)}}
+}
+
+func (c *Client) getJSON(ctx context.Context, path string, query url.Values, v interface{}) error {
+	resp, err := c.do(ctx, http.MethodGet, path, query, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("docker engine: invalid response from %s: %w", path, err)
+	}
+	return nil
+}
+
+// do sends a request and turns non-2xx responses into *APIError. The caller
+// closes the body of successful responses.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body io.Reader) (*http.Response, error) {
+	target := c.baseURL + "/v" + c.version + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("%w: %v", ErrUnreachable, err)
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		defer resp.Body.Close()
+		return nil, decodeAPIError(resp)
+	}
+	return resp, nil
+}
+
+func decodeAPIError(resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+
+	var payload struct {
+		Message string `json:"message"`
+	}
+	message := strings.TrimSpace(string(data))
+	if json.Unmarshal(data, &payload) == nil && payload.Message != "" {
+		message = payload.Message
+	}
+	if message == "" {
+		message = http.StatusText(resp.StatusCode)
+	}
+	return &APIError{StatusCode: resp.StatusCode, Message: message}
+}
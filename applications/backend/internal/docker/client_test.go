@@ -0,0 +1,319 @@
+package docker_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/docker"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/docker/dockertest"
+)
+
+// checkTransport lists containers and runs an exec session over client, which
+// covers both the pooled HTTP connections and the raw dial used for hijacking
+func checkTransport(t *testing.T, client *docker.Client) {
+	t.Helper()
+	ctx := context.Background()
+
+	if err := client.Ping(ctx); err != nil {
+		t.Fatalf("Ping: %v", err)
+	}
+	containers, err := client.ListContainers(ctx, true)
+	if err != nil {
+		t.Fatalf("ListContainers: %v", err)
+	}
+	if len(containers) != 1 || containers[0].Names[0] != "/web" {
+		t.Fatalf("ListContainers = %+v, want web", containers)
+	}
+
+	stdout, _ := execEcho(t, client, false, "uptime\n")
+	if stdout != "uptime\n" {
+		t.Fatalf("exec stdout = %q, want the input echoed", stdout)
+	}
+}
+
+func newFake(t *testing.T) *dockertest.Server {
+	t.Helper()
+	fake := dockertest.NewServer()
+	t.Cleanup(fake.Close)
+	fake.AddContainer(dockertest.Container{ID: "c0ffee", Name: "web"})
+	return fake
+}
+
+func TestTCPTransport(t *testing.T) {
+	fake := newFake(t)
+
+	client, err := docker.NewClient("tcp://"+fake.Listener.Addr().String(), "")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	defer client.Close()
+	checkTransport(t, client)
+}
+
+func TestUnixTransport(t *testing.T) {
+	fake := newFake(t)
+	socket := filepath.Join(t.TempDir(), "docker.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("listen on %s: %v", socket, err)
+	}
+	server := &http.Server{Handler: fake.Config.Handler}
+	go server.Serve(listener)
+	defer server.Close()
+
+	client, err := docker.NewClient("unix://"+socket, "")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	defer client.Close()
+	checkTransport(t, client)
+}
+
+func TestTLSTransport(t *testing.T) {
+	fake := newFake(t)
+	tlsServer := httptest.NewUnstartedServer(fake.Config.Handler)
+	tlsServer.StartTLS()
+	defer tlsServer.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(tlsServer.Certificate())
+	host := "tcp://" + tlsServer.Listener.Addr().String()
+
+	client, err := docker.NewClientWithOptions(docker.Options{Host: host, TLS: &tls.Config{RootCAs: roots}})
+	if err != nil {
+		t.Fatalf("NewClientWithOptions: %v", err)
+	}
+	defer client.Close()
+	checkTransport(t, client)
+
+	tests := []struct {
+		name string
+		opts docker.Options
+	}{
+		{"untrusted certificate", docker.Options{Host: host, TLS: &tls.Config{RootCAs: x509.NewCertPool()}}},
+		{"wrong server name", docker.Options{Host: host, TLS: &tls.Config{RootCAs: roots, ServerName: "docker.internal"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := docker.NewClientWithOptions(tt.opts)
+			if err != nil {
+				t.Fatalf("NewClientWithOptions: %v", err)
+			}
+			defer client.Close()
+			if err := client.Ping(context.Background()); !errors.Is(err, docker.ErrUnreachable) {
+				t.Fatalf("Ping error = %v, want ErrUnreachable", err)
+			}
+			if _, err := client.StartExec(context.Background(), "exec1", false); err == nil {
+				t.Fatal("StartExec succeeded over an unverified connection")
+			}
+		})
+	}
+}
+
+// sshServer is an SSH server that accepts one client key and forwards
+// direct-streamlocal channels, as `ssh -L` to a unix socket does, to the fake
+// daemon's TCP address
+type sshServer struct {
+	addr    string
+	hostKey ssh.Signer
+
+	mu      sync.Mutex
+	sockets []string
+}
+
+func newSSHServer(t *testing.T, daemon string, clientKey ssh.PublicKey) *sshServer {
+	t.Helper()
+
+	_, hostPrivate, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate host key: %v", err)
+	}
+	hostKey, err := ssh.NewSignerFromKey(hostPrivate)
+	if err != nil {
+		t.Fatalf("host signer: %v", err)
+	}
+
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if !bytes.Equal(key.Marshal(), clientKey.Marshal()) {
+				return nil, errors.New("unknown public key")
+			}
+			return nil, nil
+		},
+	}
+	config.AddHostKey(hostKey)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	server := &sshServer{addr: listener.Addr().String(), hostKey: hostKey}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn, config, daemon)
+		}
+	}()
+	return server
+}
+
+func (s *sshServer) serve(conn net.Conn, config *ssh.ServerConfig, daemon string) {
+	serverConn, channels, requests, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	defer serverConn.Close()
+	go ssh.DiscardRequests(requests)
+
+	for newChannel := range channels {
+		if newChannel.ChannelType() != "direct-streamlocal@openssh.com" {
+			newChannel.Reject(ssh.UnknownChannelType, "only unix socket forwarding is supported")
+			continue
+		}
+		var target struct {
+			SocketPath string
+			Reserved0  string
+			Reserved1  uint32
+		}
+		if err := ssh.Unmarshal(newChannel.ExtraData(), &target); err != nil {
+			newChannel.Reject(ssh.ConnectionFailed, err.Error())
+			continue
+		}
+		upstream, err := net.Dial("tcp", daemon)
+		if err != nil {
+			newChannel.Reject(ssh.ConnectionFailed, err.Error())
+			continue
+		}
+		channel, channelRequests, err := newChannel.Accept()
+		if err != nil {
+			upstream.Close()
+			continue
+		}
+		go ssh.DiscardRequests(channelRequests)
+
+		s.mu.Lock()
+		s.sockets = append(s.sockets, target.SocketPath)
+		s.mu.Unlock()
+
+		go func() {
+			io.Copy(upstream, channel)
+			upstream.(*net.TCPConn).CloseWrite()
+		}()
+		go func() {
+			io.Copy(channel, upstream)
+			channel.Close()
+			upstream.Close()
+		}()
+	}
+}
+
+func (s *sshServer) forwardedSockets() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.sockets...)
+}
+
+// newSSHKey returns a private key in OpenSSH PEM form and its public key
+func newSSHKey(t *testing.T) ([]byte, ssh.PublicKey) {
+	t.Helper()
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	block, err := ssh.MarshalPrivateKey(private, "")
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	publicKey, err := ssh.NewPublicKey(public)
+	if err != nil {
+		t.Fatalf("public key: %v", err)
+	}
+	return pem.EncodeToMemory(block), publicKey
+}
+
+func TestSSHTransport(t *testing.T) {
+	fake := newFake(t)
+
+	privateKey, clientKey := newSSHKey(t)
+	server := newSSHServer(t, fake.Listener.Addr().String(), clientKey)
+	hostKey := string(ssh.MarshalAuthorizedKey(server.hostKey.PublicKey()))
+
+	client, err := docker.NewClientWithOptions(docker.Options{
+		Host: "ssh://deploy@" + server.addr + "/run/user/1000/docker.sock",
+		SSH:  &docker.SSHAuth{PrivateKey: privateKey, HostKey: hostKey},
+	})
+	if err != nil {
+		t.Fatalf("NewClientWithOptions: %v", err)
+	}
+	defer client.Close()
+	checkTransport(t, client)
+
+	sockets := server.forwardedSockets()
+	if len(sockets) == 0 {
+		t.Fatal("no connection was forwarded over SSH")
+	}
+	for _, socket := range sockets {
+		if socket != "/run/user/1000/docker.sock" {
+			t.Fatalf("forwarded socket %q, want the path from the host URL", socket)
+		}
+	}
+
+	// A server presenting another host key is refused
+	_, otherKey := newSSHKey(t)
+	pinned, err := docker.NewClientWithOptions(docker.Options{
+		Host: "ssh://deploy@" + server.addr,
+		SSH:  &docker.SSHAuth{PrivateKey: privateKey, HostKey: string(ssh.MarshalAuthorizedKey(otherKey))},
+	})
+	if err != nil {
+		t.Fatalf("NewClientWithOptions: %v", err)
+	}
+	defer pinned.Close()
+	if err := pinned.Ping(context.Background()); err == nil {
+		t.Fatal("Ping succeeded against an unpinned host key")
+	}
+}
+
+func TestNewClientWithOptionsErrors(t *testing.T) {
+	privateKey, publicKey := newSSHKey(t)
+	auth := &docker.SSHAuth{PrivateKey: privateKey, HostKey: string(ssh.MarshalAuthorizedKey(publicKey))}
+
+	tests := []struct {
+		name string
+		opts docker.Options
+	}{
+		{"unsupported scheme", docker.Options{Host: "npipe:////./pipe/docker_engine"}},
+		{"tcp without address", docker.Options{Host: "tcp://"}},
+		{"ssh without credentials", docker.Options{Host: "ssh://deploy@host"}},
+		{"ssh without user", docker.Options{Host: "ssh://host", SSH: auth}},
+		{"ssh with invalid key", docker.Options{Host: "ssh://deploy@host", SSH: &docker.SSHAuth{PrivateKey: []byte("not a key"), HostKey: auth.HostKey}}},
+		{"ssh without host key", docker.Options{Host: "ssh://deploy@host", SSH: &docker.SSHAuth{PrivateKey: privateKey}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := docker.NewClientWithOptions(tt.opts); err == nil {
+				t.Fatalf("NewClientWithOptions(%+v) succeeded", tt.opts)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ContainerSpec is how a container was created, as reported by inspect. Config
+// and HostConfig are kept as raw JSON so they can be passed back to
+// CreateContainer without losing settings CloudDeck does not model.
+type ContainerSpec struct {
+	ID         string                     `json:"Id"`
+	Name       string                     `json:"Name"`
+	Config     map[string]json.RawMessage `json:"Config"`
+	HostConfig map[string]json.RawMessage `json:"HostConfig"`
+	Mounts     []Mount                    `json:"Mounts"`
+	State      struct {
+		Status  string `json:"Status"`
+		Running bool   `json:"Running"`
+	} `json:"State"`
+	NetworkSettings struct {
+		Networks map[string]EndpointConfig `json:"Networks"`
+	} `json:"NetworkSettings"`
+}
+
+// EndpointConfig is the user-defined part of a container's attachment to a
+// network; runtime fields such as the assigned address are left out
+type EndpointConfig struct {
+	IPAMConfig json.RawMessage   `json:"IPAMConfig,omitempty"`
+	Links      []string          `json:"Links,omitempty"`
+	Aliases    []string          `json:"Aliases,omitempty"`
+	DriverOpts map[string]string `json:"DriverOpts,omitempty"`
+}
+
+// CreateContainerOptions is the body of POST /containers/create. Daemons
+// before API 1.44 attach a new container to at most one network; connect it
+// to the others with ConnectNetwork.
+type CreateContainerOptions struct {
+	Name       string
+	Config     map[string]json.RawMessage
+	HostConfig map[string]json.RawMessage
+	Networks   map[string]EndpointConfig
+}
+
+func (c *Client) InspectContainerSpec(ctx context.Context, id string) (*ContainerSpec, error) {
+	var spec ContainerSpec
+	if err := c.getJSON(ctx, "/containers/"+url.PathEscape(id)+"/json", nil, &spec); err != nil {
+		return nil, err
+	}
+	return &spec, nil
+}
+
+// CreateContainer creates a container without starting it and returns its ID
+func (c *Client) CreateContainer(ctx context.Context, opts CreateContainerOptions) (string, error) {
+	body := make(map[string]interface{}, len(opts.Config)+2)
+	for key, value := range opts.Config {
+		body[key] = value
+	}
+	body["HostConfig"] = opts.HostConfig
+	body["NetworkingConfig"] = map[string]interface{}{"EndpointsConfig": opts.Networks}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return "", err
+	}
+
+	var query url.Values
+	if opts.Name != "" {
+		query = url.Values{"name": {opts.Name}}
+	}
+	resp, err := c.do(ctx, http.MethodPost, "/containers/create", query, bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var created struct {
+		ID string `json:"Id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return "", fmt.Errorf("docker engine: invalid response from container create: %w", err)
+	}
+	return created.ID, nil
+}
+
+func (c *Client) RenameContainer(ctx context.Context, id string, name string) error {
+	return c.post(ctx, "/containers/"+url.PathEscape(id)+"/rename", url.Values{"name": {name}})
+}
+
+// ConnectNetwork attaches a container to a network
+func (c *Client) ConnectNetwork(ctx context.Context, network string, containerID string, endpoint EndpointConfig) error {
+	data, err := json.Marshal(map[string]interface{}{
+		"Container":      containerID,
+		"EndpointConfig": endpoint,
+	})
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.do(ctx, http.MethodPost, "/networks/"+url.PathEscape(network)+"/connect", nil, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// PullImage pulls image and waits for the pull to finish. An untagged
+// reference pulls :latest. Registries that need credentials are not supported.
+func (c *Client) PullImage(ctx context.Context, image string) error {
+	query := url.Values{"fromImage": {image}}
+	name := image[strings.LastIndex(image, "/")+1:]
+	if !strings.Contains(name, ":") && !strings.Contains(name, "@") {
+		query.Set("tag", "latest")
+	}
+
+	resp, err := c.do(ctx, http.MethodPost, "/images/create", query, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// The daemon streams progress messages; a failure arrives as a message
+	// rather than as an HTTP status
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var message struct {
+			Error       string `json:"error"`
+			ErrorDetail struct {
+				Message string `json:"message"`
+			} `json:"errorDetail"`
+		}
+		if err := decoder.Decode(&message); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("docker engine: invalid response from image pull: %w", err)
+		}
+		if message.ErrorDetail.Message != "" {
+			return fmt.Errorf("failed to pull %s: %s", image, message.ErrorDetail.Message)
+		}
+		if message.Error != "" {
+			return fmt.Errorf("failed to pull %s: %s", image, message.Error)
+		}
+	}
+}
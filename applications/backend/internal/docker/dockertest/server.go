@@ -0,0 +1,876 @@
+// Package dockertest provides an in-memory fake of the Docker Engine API for
+// exercising code that depends on docker.Engine without a daemon.
+package dockertest
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/docker"
+)
+
+// Container is a fake container. Stats is returned as-is by the stats endpoint.
+// Health ("healthy", "unhealthy" or "starting") and ExitCode are reported in
+// the list's Status text the way the daemon does.
+type Container struct {
+	ID       string
+	Name     string
+	Image    string
+	State    string
+	Health   string
+	ExitCode int
+	Tty      bool
+	Labels   map[string]string
+	Created  time.Time
+	Stats    docker.Stats
+	Logs     []LogEntry
+	// ImageID, Volumes (volume names) and Networks (network names) tie the
+	// container to the fake's images, volumes and networks
+	ImageID  string
+	Volumes  []string
+	Networks []string
+}
+
+// Image is a fake image; an image without tags is dangling
+type Image struct {
+	ID      string
+	Tags    []string
+	Size    int64
+	Created time.Time
+}
+
+// Volume is a fake local volume
+type Volume struct {
+	Name   string
+	Driver string
+	Size   int64
+}
+
+// Network is a fake network. The bridge, host and none networks are never pruned.
+type Network struct {
+	ID     string
+	Name   string
+	Driver string
+}
+
+// LogEntry is one line of fake container output
+type LogEntry struct {
+	Stream string
+	Line   string
+}
+
+// Server is a fake Docker daemon served over HTTP
+type Server struct {
+	*httptest.Server
+
+	mu              sync.Mutex
+	containers      map[string]*Container
+	nextContainerID int
+	execs           map[string]*Exec
+	nextExecID      int
+	images          map[string]*Image
+	volumes         map[string]*Volume
+	networks        map[string]*Network
+	pulls           []string
+}
+
+// Exec is a fake exec instance. Started sessions echo stdin back to stdout
+// and exit with code 0 once stdin is closed.
+type Exec struct {
+	ID          string
+	ContainerID string
+	Config      docker.ExecConfig
+	Running     bool
+	ExitCode    int
+	Height      int
+	Width       int
+}
+
+var versionPrefix = regexp.MustCompile(`^/v[0-9.]+/`)
+
+// NewServer starts a fake daemon; call Close when done
+func NewServer() *Server {
+	s := &Server{
+		containers: make(map[string]*Container),
+		execs:      make(map[string]*Exec),
+		images:     make(map[string]*Image),
+		volumes:    make(map[string]*Volume),
+		networks:   make(map[string]*Network),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /_ping", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("OK"))
+	})
+	mux.HandleFunc("GET /version", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, docker.VersionInfo{Version: "24.0.0-fake", APIVersion: docker.DefaultAPIVersion, Os: "linux", Arch: "amd64"})
+	})
+	mux.HandleFunc("GET /containers/json", s.listContainers)
+	mux.HandleFunc("GET /containers/{id}/json", s.inspectContainer)
+	mux.HandleFunc("GET /containers/{id}/stats", s.containerStats)
+	mux.HandleFunc("GET /containers/{id}/logs", s.containerLogs)
+	mux.HandleFunc("POST /containers/{id}/start", s.transition("running", "running", ""))
+	mux.HandleFunc("POST /containers/{id}/stop", s.transition("exited", "exited", ""))
+	mux.HandleFunc("POST /containers/{id}/restart", s.transition("running", "", ""))
+	mux.HandleFunc("POST /containers/{id}/pause", s.transition("paused", "", "running"))
+	mux.HandleFunc("POST /containers/{id}/unpause", s.transition("running", "", "paused"))
+	mux.HandleFunc("POST /containers/{id}/kill", s.transition("exited", "", "running"))
+	mux.HandleFunc("DELETE /containers/{id}", s.removeContainer)
+	mux.HandleFunc("POST /containers/create", s.createContainer)
+	mux.HandleFunc("POST /containers/{id}/rename", s.renameContainer)
+	mux.HandleFunc("POST /networks/{id}/connect", s.connectNetwork)
+	mux.HandleFunc("POST /images/create", s.pullImage)
+	mux.HandleFunc("POST /containers/{id}/exec", s.createExec)
+	mux.HandleFunc("POST /exec/{id}/start", s.startExec)
+	mux.HandleFunc("POST /exec/{id}/resize", s.resizeExec)
+	mux.HandleFunc("GET /exec/{id}/json", s.inspectExec)
+	mux.HandleFunc("GET /images/json", s.listImages)
+	mux.HandleFunc("POST /images/prune", s.pruneImages)
+	mux.HandleFunc("GET /system/df", s.diskUsage)
+	mux.HandleFunc("POST /volumes/prune", s.pruneVolumes)
+	mux.HandleFunc("GET /networks", s.listNetworks)
+	mux.HandleFunc("POST /networks/prune", s.pruneNetworks)
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Accept both versioned (/v1.41/...) and unversioned paths
+		r.URL.Path = versionPrefix.ReplaceAllString(r.URL.Path, "/")
+		mux.ServeHTTP(w, r)
+	}))
+	return s
+}
+
+// Client returns an Engine API client connected to the fake daemon
+func (s *Server) Client() *docker.Client {
+	return docker.NewClientWithHTTP(s.Server.Client(), s.URL, "")
+}
+
+// AddContainer adds or replaces a container
+func (s *Server) AddContainer(c Container) {
+	if c.State == "" {
+		c.State = "running"
+	}
+	if c.Created.IsZero() {
+		c.Created = time.Now()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.containers[c.ID] = &c
+}
+
+// RemoveContainer deletes a container
+func (s *Server) RemoveContainer(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.containers, id)
+}
+
+// AddImage adds or replaces an image
+func (s *Server) AddImage(image Image) {
+	if image.Created.IsZero() {
+		image.Created = time.Now()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.images[image.ID] = &image
+}
+
+// AddVolume adds or replaces a volume
+func (s *Server) AddVolume(volume Volume) {
+	if volume.Driver == "" {
+		volume.Driver = "local"
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.volumes[volume.Name] = &volume
+}
+
+// AddNetwork adds or replaces a network
+func (s *Server) AddNetwork(network Network) {
+	if network.Driver == "" {
+		network.Driver = "bridge"
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.networks[network.ID] = &network
+}
+
+// Exec returns a copy of an exec instance, or nil
+func (s *Server) Exec(id string) *Exec {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	exec, ok := s.execs[id]
+	if !ok {
+		return nil
+	}
+	copied := *exec
+	return &copied
+}
+
+// find resolves a full ID, unique ID prefix or name; the caller holds s.mu
+func (s *Server) find(ref string) *Container {
+	if c, ok := s.containers[ref]; ok {
+		return c
+	}
+
+	var match *Container
+	for _, c := range s.containers {
+		if c.Name == strings.TrimPrefix(ref, "/") {
+			return c
+		}
+		if strings.HasPrefix(c.ID, ref) {
+			if match != nil {
+				return nil
+			}
+			match = c
+		}
+	}
+	return match
+}
+
+func (s *Server) listContainers(w http.ResponseWriter, r *http.Request) {
+	all := r.URL.Query().Get("all") == "1" || r.URL.Query().Get("all") == "true"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := []docker.Container{}
+	for _, c := range s.containers {
+		if !all && c.State != "running" {
+			continue
+		}
+		entry := docker.Container{
+			ID:      c.ID,
+			Names:   []string{"/" + c.Name},
+			Image:   c.Image,
+			ImageID: c.ImageID,
+			Created: c.Created.Unix(),
+			State:   c.State,
+			Status:  fakeStatus(c),
+			Labels:  c.Labels,
+		}
+		for _, volume := range c.Volumes {
+			entry.Mounts = append(entry.Mounts, docker.Mount{Type: "volume", Name: volume, Destination: "/data/" + volume})
+		}
+		for _, name := range c.Networks {
+			if entry.NetworkSettings.Networks == nil {
+				entry.NetworkSettings.Networks = make(map[string]docker.EndpointSettings)
+			}
+			networkID := ""
+			if network := s.findNetwork(name); network != nil {
+				networkID = network.ID
+			}
+			entry.NetworkSettings.Networks[name] = docker.EndpointSettings{NetworkID: networkID}
+		}
+		list = append(list, entry)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Names[0] < list[j].Names[0] })
+
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (s *Server) inspectContainer(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.find(r.PathValue("id"))
+	if c == nil {
+		notFound(w, r.PathValue("id"))
+		return
+	}
+
+	var details docker.ContainerDetails
+	details.ID = c.ID
+	details.Name = "/" + c.Name
+	details.Created = c.Created.UTC().Format(time.RFC3339Nano)
+	details.Image = c.Image
+	details.State.Status = c.State
+	details.State.Running = c.State == "running"
+	details.State.Paused = c.State == "paused"
+	details.Config.Image = c.Image
+	details.Config.Tty = c.Tty
+	details.Config.Labels = c.Labels
+
+	// The parts of the full inspect response that recreating a container reads
+	response := struct {
+		docker.ContainerDetails
+		HostConfig      map[string]interface{} `json:"HostConfig"`
+		Mounts          []docker.Mount         `json:"Mounts"`
+		NetworkSettings struct {
+			Networks map[string]docker.EndpointConfig `json:"Networks"`
+		} `json:"NetworkSettings"`
+	}{ContainerDetails: details}
+
+	networkMode := "bridge"
+	if len(c.Networks) > 0 {
+		networkMode = c.Networks[0]
+	}
+	response.HostConfig = map[string]interface{}{"NetworkMode": networkMode}
+	for _, volume := range c.Volumes {
+		response.Mounts = append(response.Mounts, docker.Mount{Type: "volume", Name: volume, Destination: "/data/" + volume})
+	}
+	response.NetworkSettings.Networks = make(map[string]docker.EndpointConfig)
+	for _, name := range c.Networks {
+		response.NetworkSettings.Networks[name] = docker.EndpointConfig{Aliases: []string{c.Name}}
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// Pulls returns the images pulled so far, in order
+func (s *Server) Pulls() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.pulls...)
+}
+
+// createContainer accepts one network at creation, like daemons before API
+// 1.44. Named volumes in HostConfig.Binds become the container's Volumes.
+func (s *Server) createContainer(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Image      string            `json:"Image"`
+		Tty        bool              `json:"Tty"`
+		Labels     map[string]string `json:"Labels"`
+		HostConfig struct {
+			Binds []string `json:"Binds"`
+		} `json:"HostConfig"`
+		NetworkingConfig struct {
+			EndpointsConfig map[string]json.RawMessage `json:"EndpointsConfig"`
+		} `json:"NetworkingConfig"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+		return
+	}
+	if len(body.NetworkingConfig.EndpointsConfig) > 1 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"message": "Container cannot be connected to network endpoints"})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name := r.URL.Query().Get("name")
+	if name != "" && s.find(name) != nil {
+		writeJSON(w, http.StatusConflict, map[string]string{"message": "Conflict. The container name \"/" + name + "\" is already in use"})
+		return
+	}
+
+	s.nextContainerID++
+	c := &Container{
+		ID:      fmt.Sprintf("%064x", s.nextContainerID),
+		Name:    name,
+		Image:   body.Image,
+		State:   "created",
+		Tty:     body.Tty,
+		Labels:  body.Labels,
+		Created: time.Now(),
+	}
+	if c.Name == "" {
+		c.Name = c.ID[:12]
+	}
+	for _, bind := range body.HostConfig.Binds {
+		source, _, _ := strings.Cut(bind, ":")
+		if !strings.HasPrefix(source, "/") {
+			c.Volumes = append(c.Volumes, source)
+		}
+	}
+	for network := range body.NetworkingConfig.EndpointsConfig {
+		c.Networks = append(c.Networks, network)
+	}
+	s.containers[c.ID] = c
+
+	writeJSON(w, http.StatusCreated, map[string]string{"Id": c.ID})
+}
+
+func (s *Server) renameContainer(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.find(r.PathValue("id"))
+	if c == nil {
+		notFound(w, r.PathValue("id"))
+		return
+	}
+	name := r.URL.Query().Get("name")
+	if other := s.find(name); other != nil && other != c {
+		writeJSON(w, http.StatusConflict, map[string]string{"message": "Conflict. The container name \"/" + name + "\" is already in use"})
+		return
+	}
+
+	c.Name = name
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) connectNetwork(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Container string `json:"Container"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	network := s.findNetwork(r.PathValue("id"))
+	if network == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "network " + r.PathValue("id") + " not found"})
+		return
+	}
+	c := s.find(body.Container)
+	if c == nil {
+		notFound(w, body.Container)
+		return
+	}
+
+	c.Networks = append(c.Networks, network.Name)
+	w.WriteHeader(http.StatusOK)
+}
+
+// pullImage streams progress like the daemon; images under missing/ fail
+func (s *Server) pullImage(w http.ResponseWriter, r *http.Request) {
+	image := r.URL.Query().Get("fromImage")
+	if tag := r.URL.Query().Get("tag"); tag != "" {
+		image += ":" + tag
+	}
+
+	s.mu.Lock()
+	s.pulls = append(s.pulls, image)
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	encoder := json.NewEncoder(w)
+	encoder.Encode(map[string]string{"status": "Pulling from " + image})
+	if strings.HasPrefix(image, "missing/") {
+		message := "pull access denied for " + image
+		encoder.Encode(map[string]interface{}{"error": message, "errorDetail": map[string]string{"message": message}})
+		return
+	}
+	encoder.Encode(map[string]string{"status": "Status: Downloaded newer image for " + image})
+}
+
+// fakeStatus renders the list's Status text, e.g. "Up 5 minutes (healthy)"
+func fakeStatus(c *Container) string {
+	switch c.State {
+	case "running":
+		status := "Up 5 minutes"
+		switch c.Health {
+		case "starting":
+			status += " (health: starting)"
+		case "":
+		default:
+			status += " (" + c.Health + ")"
+		}
+		return status
+	case "exited":
+		return fmt.Sprintf("Exited (%d) 5 minutes ago", c.ExitCode)
+	}
+	return c.State
+}
+
+func (s *Server) containerStats(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.find(r.PathValue("id"))
+	if c == nil {
+		notFound(w, r.PathValue("id"))
+		return
+	}
+	writeJSON(w, http.StatusOK, c.Stats)
+}
+
+// containerLogs writes the stored lines, multiplexed unless the container has a TTY.
+// Follow is accepted but the stream ends after the stored lines.
+func (s *Server) containerLogs(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	c := s.find(r.PathValue("id"))
+	var entries []LogEntry
+	var tty bool
+	if c != nil {
+		entries = append(entries, c.Logs...)
+		tty = c.Tty
+	}
+	s.mu.Unlock()
+
+	if c == nil {
+		notFound(w, r.PathValue("id"))
+		return
+	}
+
+	query := r.URL.Query()
+	wantStdout := query.Get("stdout") == "1" || query.Get("stdout") == "true"
+	wantStderr := query.Get("stderr") == "1" || query.Get("stderr") == "true"
+
+	var selected []LogEntry
+	for _, entry := range entries {
+		if (entry.Stream == docker.StreamStderr && wantStderr) || (entry.Stream != docker.StreamStderr && wantStdout) {
+			selected = append(selected, entry)
+		}
+	}
+	if tail, err := strconv.Atoi(query.Get("tail")); err == nil && tail >= 0 && tail < len(selected) {
+		selected = selected[len(selected)-tail:]
+	}
+
+	w.WriteHeader(http.StatusOK)
+	for _, entry := range selected {
+		line := []byte(entry.Line + "\n")
+		if tty {
+			w.Write(line)
+			continue
+		}
+
+		header := make([]byte, 8)
+		header[0] = 1
+		if entry.Stream == docker.StreamStderr {
+			header[0] = 2
+		}
+		binary.BigEndian.PutUint32(header[4:], uint32(len(line)))
+		w.Write(header)
+		w.Write(line)
+	}
+}
+
+// transition moves a container to state. A container already in notModifiedFrom
+// gets 304; one not in requiredFrom (when set) gets 409, as the daemon does.
+func (s *Server) transition(state, notModifiedFrom, requiredFrom string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		c := s.find(r.PathValue("id"))
+		if c == nil {
+			notFound(w, r.PathValue("id"))
+			return
+		}
+		if notModifiedFrom != "" && c.State == notModifiedFrom {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		if requiredFrom != "" && c.State != requiredFrom {
+			writeJSON(w, http.StatusConflict, map[string]string{
+				"message": "Container " + c.ID + " is " + c.State + ", not " + requiredFrom,
+			})
+			return
+		}
+
+		c.State = state
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (s *Server) removeContainer(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.find(r.PathValue("id"))
+	if c == nil {
+		notFound(w, r.PathValue("id"))
+		return
+	}
+	force := r.URL.Query().Get("force") == "1" || r.URL.Query().Get("force") == "true"
+	if c.State == "running" && !force {
+		writeJSON(w, http.StatusConflict, map[string]string{
+			"message": "You cannot remove a running container " + c.ID + ". Stop the container before attempting removal or force remove",
+		})
+		return
+	}
+
+	delete(s.containers, c.ID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) createExec(w http.ResponseWriter, r *http.Request) {
+	var config docker.ExecConfig
+	if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.find(r.PathValue("id"))
+	if c == nil {
+		notFound(w, r.PathValue("id"))
+		return
+	}
+	if c.State != "running" {
+		writeJSON(w, http.StatusConflict, map[string]string{"message": "Container " + c.ID + " is not running"})
+		return
+	}
+
+	s.nextExecID++
+	exec := &Exec{ID: "exec" + strconv.Itoa(s.nextExecID), ContainerID: c.ID, Config: config}
+	s.execs[exec.ID] = exec
+	writeJSON(w, http.StatusCreated, map[string]string{"Id": exec.ID})
+}
+
+// startExec hijacks the connection and echoes stdin to stdout, multiplexed
+// unless the exec has a TTY
+func (s *Server) startExec(w http.ResponseWriter, r *http.Request) {
+	// Drain the body before hijacking so it is not echoed as stdin
+	var start struct {
+		Tty bool `json:"Tty"`
+	}
+	json.NewDecoder(r.Body).Decode(&start)
+	io.Copy(io.Discard, r.Body)
+
+	s.mu.Lock()
+	exec, ok := s.execs[r.PathValue("id")]
+	if ok {
+		exec.Running = true
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "No such exec instance: " + r.PathValue("id")})
+		return
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"message": "connection cannot be hijacked"})
+		return
+	}
+	conn, buf, err := hijacker.Hijack()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	buf.WriteString("HTTP/1.1 101 UPGRADED\r\nContent-Type: application/vnd.docker.raw-stream\r\nConnection: Upgrade\r\nUpgrade: tcp\r\n\r\n")
+	buf.Flush()
+
+	chunk := make([]byte, 32*1024)
+	for {
+		n, err := buf.Read(chunk)
+		if n > 0 {
+			if !start.Tty {
+				header := make([]byte, 8)
+				header[0] = 1
+				binary.BigEndian.PutUint32(header[4:], uint32(n))
+				conn.Write(header)
+			}
+			conn.Write(chunk[:n])
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	s.mu.Lock()
+	exec.Running = false
+	s.mu.Unlock()
+}
+
+func (s *Server) resizeExec(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	exec, ok := s.execs[r.PathValue("id")]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "No such exec instance: " + r.PathValue("id")})
+		return
+	}
+	exec.Height, _ = strconv.Atoi(r.URL.Query().Get("h"))
+	exec.Width, _ = strconv.Atoi(r.URL.Query().Get("w"))
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) inspectExec(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	exec, ok := s.execs[r.PathValue("id")]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "No such exec instance: " + r.PathValue("id")})
+		return
+	}
+	writeJSON(w, http.StatusOK, docker.ExecInspect{
+		ID:          exec.ID,
+		ContainerID: exec.ContainerID,
+		Running:     exec.Running,
+		ExitCode:    exec.ExitCode,
+	})
+}
+
+func (s *Server) listImages(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := []docker.Image{}
+	for _, image := range s.images {
+		tags := image.Tags
+		if len(tags) == 0 {
+			tags = []string{"<none>:<none>"}
+		}
+		list = append(list, docker.Image{
+			ID:       image.ID,
+			RepoTags: tags,
+			Created:  image.Created.Unix(),
+			Size:     image.Size,
+		})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+
+	writeJSON(w, http.StatusOK, list)
+}
+
+// pruneImages removes unused dangling images, or every unused image when
+// filters has dangling=false
+func (s *Server) pruneImages(w http.ResponseWriter, r *http.Request) {
+	var filters map[string][]string
+	json.Unmarshal([]byte(r.URL.Query().Get("filters")), &filters)
+	all := len(filters["dangling"]) > 0 && filters["dangling"][0] == "false"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	used := make(map[string]bool)
+	for _, c := range s.containers {
+		used[c.ImageID] = true
+	}
+
+	var report docker.PruneReport
+	for id, image := range s.images {
+		if used[id] || (!all && len(image.Tags) > 0) {
+			continue
+		}
+		delete(s.images, id)
+		report.ImagesDeleted = append(report.ImagesDeleted, docker.ImageDeleteItem{Deleted: id})
+		report.SpaceReclaimed += image.Size
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+func (s *Server) diskUsage(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	refs := s.volumeRefs()
+	usage := docker.DiskUsage{Volumes: []docker.Volume{}}
+	for _, volume := range s.volumes {
+		entry := docker.Volume{
+			Name:       volume.Name,
+			Driver:     volume.Driver,
+			Mountpoint: "/var/lib/docker/volumes/" + volume.Name + "/_data",
+			Scope:      "local",
+		}
+		entry.UsageData = &docker.VolumeUsage{Size: volume.Size, RefCount: refs[volume.Name]}
+		usage.Volumes = append(usage.Volumes, entry)
+	}
+	sort.Slice(usage.Volumes, func(i, j int) bool { return usage.Volumes[i].Name < usage.Volumes[j].Name })
+
+	writeJSON(w, http.StatusOK, usage)
+}
+
+func (s *Server) pruneVolumes(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	refs := s.volumeRefs()
+	report := docker.PruneReport{VolumesDeleted: []string{}}
+	for name, volume := range s.volumes {
+		if refs[name] > 0 {
+			continue
+		}
+		delete(s.volumes, name)
+		report.VolumesDeleted = append(report.VolumesDeleted, name)
+		report.SpaceReclaimed += volume.Size
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// volumeRefs counts the containers using each volume; the caller holds s.mu
+func (s *Server) volumeRefs() map[string]int64 {
+	refs := make(map[string]int64)
+	for _, c := range s.containers {
+		for _, volume := range c.Volumes {
+			refs[volume]++
+		}
+	}
+	return refs
+}
+
+func (s *Server) listNetworks(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := []docker.Network{}
+	for _, network := range s.networks {
+		list = append(list, docker.Network{
+			ID:     network.ID,
+			Name:   network.Name,
+			Driver: network.Driver,
+			Scope:  "local",
+		})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (s *Server) pruneNetworks(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	used := make(map[string]bool)
+	for _, c := range s.containers {
+		for _, name := range c.Networks {
+			if network := s.findNetwork(name); network != nil {
+				used[network.ID] = true
+			}
+		}
+	}
+
+	report := docker.PruneReport{NetworksDeleted: []string{}}
+	for id, network := range s.networks {
+		if used[id] || network.Name == "bridge" || network.Name == "host" || network.Name == "none" {
+			continue
+		}
+		delete(s.networks, id)
+		report.NetworksDeleted = append(report.NetworksDeleted, network.Name)
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// findNetwork resolves a network name or ID; the caller holds s.mu
+func (s *Server) findNetwork(ref string) *Network {
+	if network, ok := s.networks[ref]; ok {
+		return network
+	}
+	for _, network := range s.networks {
+		if network.Name == ref {
+			return network
+		}
+	}
+	return nil
+}
+
+func notFound(w http.ResponseWriter, ref string) {
+	writeJSON(w, http.StatusNotFound, map[string]string{"message": "No such container: " + ref})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
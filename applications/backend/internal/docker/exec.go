@@ -0,0 +1,163 @@
+package docker
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// ExecConfig is the body of POST /containers/{id}/exec
+type ExecConfig struct {
+	AttachStdin  bool     `json:"AttachStdin"`
+	AttachStdout bool     `json:"AttachStdout"`
+	AttachStderr bool     `json:"AttachStderr"`
+	Tty          bool     `json:"Tty"`
+	Cmd          []string `json:"Cmd"`
+	Env          []string `json:"Env,omitempty"`
+	User         string   `json:"User,omitempty"`
+	WorkingDir   string   `json:"WorkingDir,omitempty"`
+}
+
+// ExecInspect is the subset of GET /exec/{id}/json used by CloudDeck
+type ExecInspect struct {
+	ID          string `json:"ID"`
+	ContainerID string `json:"ContainerID"`
+	Running     bool   `json:"Running"`
+	ExitCode    int    `json:"ExitCode"`
+	Pid         int    `json:"Pid"`
+}
+
+// HijackedConn is the raw connection of an attached exec session. Reads go
+// through Reader, which may hold bytes buffered while reading the response
+// headers. Unless the session has a TTY the output is multiplexed; use
+// Demultiplex to split it.
+type HijackedConn struct {
+	Conn   net.Conn
+	Reader *bufio.Reader
+}
+
+func (h *HijackedConn) Read(p []byte) (int, error) {
+	return h.Reader.Read(p)
+}
+
+func (h *HijackedConn) Write(p []byte) (int, error) {
+	return h.Conn.Write(p)
+}
+
+// CloseWrite signals end of stdin to the process while output keeps flowing
+func (h *HijackedConn) CloseWrite() error {
+	if conn, ok := h.Conn.(interface{ CloseWrite() error }); ok {
+		return conn.CloseWrite()
+	}
+	return nil
+}
+
+func (h *HijackedConn) Close() error {
+	return h.Conn.Close()
+}
+
+// CreateExec sets up a command to run in a running container and returns its exec ID
+func (c *Client) CreateExec(ctx context.Context, containerID string, config ExecConfig) (string, error) {
+	body, err := json.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := c.do(ctx, http.MethodPost, "/containers/"+url.PathEscape(containerID)+"/exec", nil, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var created struct {
+		ID string `json:"Id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return "", fmt.Errorf("docker engine: invalid response from exec create: %w", err)
+	}
+	return created.ID, nil
+}
+
+// StartExec starts an exec instance and hijacks the connection so stdin and
+// output can be streamed in both directions. The caller closes the connection.
+func (c *Client) StartExec(ctx context.Context, execID string, tty bool) (*HijackedConn, error) {
+	body, err := json.Marshal(map[string]bool{"Detach": false, "Tty": tty})
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := c.dial(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnreachable, err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.baseURL+"/v"+c.version+"/exec/"+url.PathEscape(execID)+"/start", bytes.NewReader(body))
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "tcp")
+
+	// Bound the handshake by ctx; the session itself runs without a deadline
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	fail := func(err error) (*HijackedConn, error) {
+		stop()
+		conn.Close()
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, err
+	}
+
+	if err := req.Write(conn); err != nil {
+		return fail(fmt.Errorf("%w: %v", ErrUnreachable, err))
+	}
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		return fail(fmt.Errorf("docker engine: invalid response from exec start: %w", err))
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fail(decodeAPIError(resp))
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols && resp.StatusCode != http.StatusOK {
+		return fail(fmt.Errorf("docker engine: unexpected status %d from exec start", resp.StatusCode))
+	}
+
+	if !stop() {
+		conn.Close()
+		return nil, ctx.Err()
+	}
+	return &HijackedConn{Conn: conn, Reader: reader}, nil
+}
+
+// ResizeExec changes the TTY size of a running exec session
+func (c *Client) ResizeExec(ctx context.Context, execID string, height, width uint16) error {
+	query := url.Values{
+		"h": {strconv.Itoa(int(height))},
+		"w": {strconv.Itoa(int(width))},
+	}
+	resp, err := c.do(ctx, http.MethodPost, "/exec/"+url.PathEscape(execID)+"/resize", query, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (c *Client) InspectExec(ctx context.Context, execID string) (*ExecInspect, error) {
+	var inspect ExecInspect
+	if err := c.getJSON(ctx, "/exec/"+url.PathEscape(execID)+"/json", nil, &inspect); err != nil {
+		return nil, err
+	}
+	return &inspect, nil
+}
@@ -0,0 +1,142 @@
+package docker_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/docker"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/docker/dockertest"
+)
+
+// execEcho runs a session in the fake's container "web", which echoes stdin,
+// and returns what came back on stdout and stderr
+func execEcho(t *testing.T, client *docker.Client, tty bool, input string) (stdout, stderr string) {
+	t.Helper()
+	ctx := context.Background()
+
+	execID, err := client.CreateExec(ctx, "web", docker.ExecConfig{
+		AttachStdin:  true,
+		AttachStdout: true,
+		AttachStderr: true,
+		Tty:          tty,
+		Cmd:          []string{"/bin/sh"},
+	})
+	if err != nil {
+		t.Fatalf("CreateExec: %v", err)
+	}
+
+	conn, err := client.StartExec(ctx, execID, tty)
+	if err != nil {
+		t.Fatalf("StartExec: %v", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(input)); err != nil {
+		t.Fatalf("write stdin: %v", err)
+	}
+	if err := conn.CloseWrite(); err != nil {
+		t.Fatalf("close stdin: %v", err)
+	}
+
+	var out, errOut bytes.Buffer
+	if tty {
+		_, err = io.Copy(&out, conn)
+	} else {
+		err = docker.Demultiplex(conn, &out, &errOut)
+	}
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	return out.String(), errOut.String()
+}
+
+func TestExecHijack(t *testing.T) {
+	tests := []struct {
+		name string
+		tty  bool
+	}{
+		{"multiplexed", false},
+		{"tty", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := dockertest.NewServer()
+			defer fake.Close()
+			fake.AddContainer(dockertest.Container{ID: "c0ffee", Name: "web"})
+
+			stdout, stderr := execEcho(t, fake.Client(), tt.tty, "echo hello\n")
+			if stdout != "echo hello\n" || stderr != "" {
+				t.Fatalf("stdout %q stderr %q, want the input echoed on stdout", stdout, stderr)
+			}
+		})
+	}
+}
+
+func TestExecLifecycle(t *testing.T) {
+	fake := dockertest.NewServer()
+	defer fake.Close()
+	fake.AddContainer(dockertest.Container{ID: "c0ffee", Name: "web"})
+	fake.AddContainer(dockertest.Container{ID: "dead", Name: "stopped", State: "exited"})
+	client := fake.Client()
+	ctx := context.Background()
+
+	if _, err := client.CreateExec(ctx, "stopped", docker.ExecConfig{Cmd: []string{"sh"}}); !docker.IsConflict(err) {
+		t.Fatalf("exec in a stopped container: error = %v, want conflict", err)
+	}
+	if _, err := client.StartExec(ctx, "missing", false); !docker.IsNotFound(err) {
+		t.Fatalf("start of a missing exec: error = %v, want not found", err)
+	}
+
+	execID, err := client.CreateExec(ctx, "web", docker.ExecConfig{AttachStdin: true, AttachStdout: true, Tty: true, Cmd: []string{"sh"}})
+	if err != nil {
+		t.Fatalf("CreateExec: %v", err)
+	}
+	conn, err := client.StartExec(ctx, execID, true)
+	if err != nil {
+		t.Fatalf("StartExec: %v", err)
+	}
+
+	if err := client.ResizeExec(ctx, execID, 40, 120); err != nil {
+		t.Fatalf("ResizeExec: %v", err)
+	}
+	if exec := fake.Exec(execID); exec == nil || exec.Height != 40 || exec.Width != 120 {
+		t.Fatalf("exec after resize = %+v, want 40x120", exec)
+	}
+
+	inspect, err := client.InspectExec(ctx, execID)
+	if err != nil {
+		t.Fatalf("InspectExec: %v", err)
+	}
+	if !inspect.Running || inspect.ContainerID != "c0ffee" {
+		t.Fatalf("inspect of a started exec = %+v", inspect)
+	}
+
+	// The session ends once stdin is closed and the output is drained
+	conn.CloseWrite()
+	io.Copy(io.Discard, conn)
+	conn.Close()
+	if inspect, err := client.InspectExec(ctx, execID); err != nil || inspect.Running {
+		t.Fatalf("inspect of a finished exec = (%+v, %v)", inspect, err)
+	}
+}
+
+func TestStartExecCancelled(t *testing.T) {
+	fake := dockertest.NewServer()
+	defer fake.Close()
+	fake.AddContainer(dockertest.Container{ID: "c0ffee", Name: "web"})
+	client := fake.Client()
+
+	execID, err := client.CreateExec(context.Background(), "web", docker.ExecConfig{Cmd: []string{"sh"}})
+	if err != nil {
+		t.Fatalf("CreateExec: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.StartExec(ctx, execID, false); err == nil {
+		t.Fatal("StartExec succeeded with a cancelled context")
+	}
+}
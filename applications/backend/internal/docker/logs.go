@@ -0,0 +1,115 @@
+package docker
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	StreamStdout = "stdout"
+	StreamStderr = "stderr"
+
+	maxLogLine = 1 << 20
+)
+
+// ReadLogLines splits a log stream into lines and calls fn with the stream each
+// line came from. Multiplexed streams carry an 8-byte header per frame; TTY
+// containers produce a raw stream, which is reported as stdout.
+func ReadLogLines(r io.Reader, tty bool, fn func(stream, line string)) error {
+	stdout := &lineWriter{stream: StreamStdout, fn: fn}
+	stderr := &lineWriter{stream: StreamStderr, fn: fn}
+	defer stdout.flush()
+	defer stderr.flush()
+
+	if tty {
+		_, err := io.Copy(stdout, r)
+		return err
+	}
+	return Demultiplex(r, stdout, stderr)
+}
+
+// Demultiplex copies a multiplexed log or attach stream to stdout and stderr
+func Demultiplex(r io.Reader, stdout, stderr io.Writer) error {
+	header := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+
+		size := int64(binary.BigEndian.Uint32(header[4:]))
+		var w io.Writer
+		switch header[0] {
+		case 0, 1:
+			w = stdout
+		case 2:
+			w = stderr
+		default:
+			return fmt.Errorf("docker engine: unknown stream type %d in log stream", header[0])
+		}
+
+		if _, err := io.CopyN(w, r, size); err != nil {
+			return err
+		}
+	}
+}
+
+// lineWriter buffers written bytes and emits complete lines. Lines longer than
+// maxLogLine are emitted in pieces.
+type lineWriter struct {
+	stream string
+	fn     func(stream, line string)
+	buf    []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.fn(w.stream, strings.TrimSuffix(string(w.buf[:i]), "\r"))
+		w.buf = w.buf[i+1:]
+	}
+	if len(w.buf) >= maxLogLine {
+		w.flush()
+	}
+	return len(p), nil
+}
+
+func (w *lineWriter) flush() {
+	if len(w.buf) > 0 {
+		w.fn(w.stream, string(w.buf))
+		w.buf = nil
+	}
+}
+
+// ParseLogTime converts the forms `docker logs --since` accepts (RFC3339
+// timestamps, Unix timestamps and durations relative to now such as "10m")
+// into the Unix timestamp the Engine API expects
+func ParseLogTime(value string, now time.Time) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", nil
+	}
+
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return value, nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return strconv.FormatInt(t.Unix(), 10), nil
+	}
+	if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return strconv.FormatInt(now.Add(-d).Unix(), 10), nil
+	}
+	return "", fmt.Errorf("invalid time %q: use RFC3339, a Unix timestamp or a duration like 10m", value)
+}
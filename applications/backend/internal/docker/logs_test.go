@@ -0,0 +1,167 @@
+package docker_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/docker"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/docker/dockertest"
+)
+
+// frame encodes payload as one frame of a multiplexed stream
+func frame(stream byte, payload string) []byte {
+	header := make([]byte, 8)
+	header[0] = stream
+	binary.BigEndian.PutUint32(header[4:], uint32(len(payload)))
+	return append(header, payload...)
+}
+
+func TestDemultiplex(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   []byte
+		stdout  string
+		stderr  string
+		wantErr bool
+	}{
+		{name: "empty", input: nil},
+		{
+			name:   "both streams",
+			input:  bytes.Join([][]byte{frame(1, "out\n"), frame(2, "err\n"), frame(1, "more\n")}, nil),
+			stdout: "out\nmore\n",
+			stderr: "err\n",
+		},
+		{name: "stdin stream counts as stdout", input: frame(0, "typed\n"), stdout: "typed\n"},
+		{name: "empty frame", input: append(frame(1, ""), frame(2, "x")...), stderr: "x"},
+		{name: "unknown stream", input: frame(3, "?"), wantErr: true},
+		{name: "truncated header", input: frame(1, "ok")[:5], wantErr: true},
+		{name: "truncated payload", input: frame(1, "cut short")[:12], wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			err := docker.Demultiplex(bytes.NewReader(tt.input), &stdout, &stderr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Demultiplex error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if stdout.String() != tt.stdout || stderr.String() != tt.stderr {
+				t.Fatalf("stdout %q stderr %q, want %q and %q", stdout.String(), stderr.String(), tt.stdout, tt.stderr)
+			}
+		})
+	}
+}
+
+func TestReadLogLines(t *testing.T) {
+	type line struct{ stream, text string }
+	tests := []struct {
+		name  string
+		tty   bool
+		input []byte
+		want  []line
+	}{
+		{
+			name:  "lines split across frames",
+			input: bytes.Join([][]byte{frame(1, "hel"), frame(2, "oops\n"), frame(1, "lo\r\nbye")}, nil),
+			want:  []line{{docker.StreamStderr, "oops"}, {docker.StreamStdout, "hello"}, {docker.StreamStdout, "bye"}},
+		},
+		{
+			name:  "tty",
+			tty:   true,
+			input: []byte("one\ntwo\n"),
+			want:  []line{{docker.StreamStdout, "one"}, {docker.StreamStdout, "two"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []line
+			err := docker.ReadLogLines(bytes.NewReader(tt.input), tt.tty, func(stream, text string) {
+				got = append(got, line{stream, text})
+			})
+			if err != nil {
+				t.Fatalf("ReadLogLines: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("line %d = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestContainerLogs(t *testing.T) {
+	fake := dockertest.NewServer()
+	defer fake.Close()
+	fake.AddContainer(dockertest.Container{ID: "a1", Name: "api", Logs: []dockertest.LogEntry{
+		{Stream: docker.StreamStdout, Line: "ready"},
+		{Stream: docker.StreamStderr, Line: "slow query"},
+	}})
+	client := fake.Client()
+
+	tests := []struct {
+		name   string
+		opts   docker.LogsOptions
+		stdout string
+		stderr string
+	}{
+		{"both streams", docker.LogsOptions{Stdout: true, Stderr: true}, "ready\n", "slow query\n"},
+		{"stderr only", docker.LogsOptions{Stderr: true}, "", "slow query\n"},
+		{"tail", docker.LogsOptions{Stdout: true, Stderr: true, Tail: "1"}, "", "slow query\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream, err := client.ContainerLogs(context.Background(), "api", tt.opts)
+			if err != nil {
+				t.Fatalf("ContainerLogs: %v", err)
+			}
+			defer stream.Close()
+
+			var stdout, stderr strings.Builder
+			if err := docker.Demultiplex(stream, &stdout, &stderr); err != nil {
+				t.Fatalf("Demultiplex: %v", err)
+			}
+			if stdout.String() != tt.stdout || stderr.String() != tt.stderr {
+				t.Fatalf("stdout %q stderr %q, want %q and %q", stdout.String(), stderr.String(), tt.stdout, tt.stderr)
+			}
+		})
+	}
+
+	if _, err := client.ContainerLogs(context.Background(), "ghost", docker.LogsOptions{Stdout: true}); !docker.IsNotFound(err) {
+		t.Fatalf("logs of a missing container: error = %v, want not found", err)
+	}
+}
+
+func TestParseLogTime(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{value: "", want: ""},
+		{value: "1699990000", want: "1699990000"},
+		{value: "1699990000.5", want: "1699990000.5"},
+		{value: "2023-11-14T22:13:20Z", want: "1700000000"},
+		{value: "10m", want: "1699999400"},
+		{value: "-10m", wantErr: true},
+		{value: "yesterday", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := docker.ParseLogTime(tt.value, now)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseLogTime(%q) = (%q, %v), want %q", tt.value, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Image is an entry of GET /images/json
+type Image struct {
+	ID          string            `json:"Id"`
+	ParentID    string            `json:"ParentId"`
+	RepoTags    []string          `json:"RepoTags"`
+	RepoDigests []string          `json:"RepoDigests"`
+	Created     int64             `json:"Created"`
+	Size        int64             `json:"Size"`
+	Labels      map[string]string `json:"Labels"`
+}
+
+// Dangling reports whether the image has no tags, i.e. was superseded by a newer
+// build of the same tag
+func (i Image) Dangling() bool {
+	for _, tag := range i.RepoTags {
+		if tag != "<none>:<none>" {
+			return false
+		}
+	}
+	return true
+}
+
+// Volume is an entry of the Volumes list in GET /system/df. UsageData is only
+// filled in by the disk usage endpoint; Size is -1 when it is not known.
+type Volume struct {
+	Name       string            `json:"Name"`
+	Driver     string            `json:"Driver"`
+	Mountpoint string            `json:"Mountpoint"`
+	CreatedAt  string            `json:"CreatedAt"`
+	Scope      string            `json:"Scope"`
+	Labels     map[string]string `json:"Labels"`
+	UsageData  *VolumeUsage      `json:"UsageData"`
+}
+
+// VolumeUsage is a volume's size in bytes and the number of containers using it
+type VolumeUsage struct {
+	Size     int64 `json:"Size"`
+	RefCount int64 `json:"RefCount"`
+}
+
+// DiskUsage is the subset of GET /system/df used by CloudDeck
+type DiskUsage struct {
+	LayersSize int64    `json:"LayersSize"`
+	Volumes    []Volume `json:"Volumes"`
+}
+
+// Network is an entry of GET /networks
+type Network struct {
+	ID       string            `json:"Id"`
+	Name     string            `json:"Name"`
+	Created  string            `json:"Created"`
+	Driver   string            `json:"Driver"`
+	Scope    string            `json:"Scope"`
+	Internal bool              `json:"Internal"`
+	Labels   map[string]string `json:"Labels"`
+	IPAM     struct {
+		Config []struct {
+			Subnet  string `json:"Subnet"`
+			Gateway string `json:"Gateway"`
+		} `json:"Config"`
+	} `json:"IPAM"`
+}
+
+// PruneReport is the union of the image, volume and network prune responses
+type PruneReport struct {
+	ImagesDeleted   []ImageDeleteItem `json:"ImagesDeleted"`
+	VolumesDeleted  []string          `json:"VolumesDeleted"`
+	NetworksDeleted []string          `json:"NetworksDeleted"`
+	SpaceReclaimed  int64             `json:"SpaceReclaimed"`
+}
+
+// ImageDeleteItem records one tag removed or one image deleted by a prune
+type ImageDeleteItem struct {
+	Untagged string `json:"Untagged,omitempty"`
+	Deleted  string `json:"Deleted,omitempty"`
+}
+
+func (c *Client) ListImages(ctx context.Context) ([]Image, error) {
+	var images []Image
+	if err := c.getJSON(ctx, "/images/json", nil, &images); err != nil {
+		return nil, err
+	}
+	return images, nil
+}
+
+// DiskUsage reports volume sizes, which the daemon computes on request; it can
+// take a while on hosts with large volumes
+func (c *Client) DiskUsage(ctx context.Context) (*DiskUsage, error) {
+	var usage DiskUsage
+	if err := c.getJSON(ctx, "/system/df", nil, &usage); err != nil {
+		return nil, err
+	}
+	return &usage, nil
+}
+
+func (c *Client) ListNetworks(ctx context.Context) ([]Network, error) {
+	var networks []Network
+	if err := c.getJSON(ctx, "/networks", nil, &networks); err != nil {
+		return nil, err
+	}
+	return networks, nil
+}
+
+// PruneImages removes dangling images, or every image without a container when all is set
+func (c *Client) PruneImages(ctx context.Context, all bool) (*PruneReport, error) {
+	filters := map[string][]string{}
+	if all {
+		filters["dangling"] = []string{"false"}
+	}
+	return c.prune(ctx, "/images/prune", filters)
+}
+
+// PruneVolumes removes local volumes not used by any container
+func (c *Client) PruneVolumes(ctx context.Context) (*PruneReport, error) {
+	return c.prune(ctx, "/volumes/prune", nil)
+}
+
+// PruneNetworks removes user-defined networks not used by any container
+func (c *Client) PruneNetworks(ctx context.Context) (*PruneReport, error) {
+	return c.prune(ctx, "/networks/prune", nil)
+}
+
+func (c *Client) prune(ctx context.Context, path string, filters map[string][]string) (*PruneReport, error) {
+	var query url.Values
+	if len(filters) > 0 {
+		encoded, err := json.Marshal(filters)
+		if err != nil {
+			return nil, err
+		}
+		query = url.Values{"filters": {string(encoded)}}
+	}
+
+	resp, err := c.do(ctx, http.MethodPost, path, query, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var report PruneReport
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		return nil, fmt.Errorf("docker engine: invalid response from %s: %w", path, err)
+	}
+	return &report, nil
+}
@@ -0,0 +1,183 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	defaultSSHSocket  = "/var/run/docker.sock"
+	sshKeepAlive      = 30 * time.Second
+	sshHandshakeLimit = 15 * time.Second
+)
+
+// SSHAuth holds the credentials for an ssh:// host. HostKey pins the server's
+// public key in authorized_keys format ("ssh-ed25519 AAAA..."); connections to a
+// server presenting any other key are refused.
+type SSHAuth struct {
+	PrivateKey []byte
+	Passphrase []byte
+	HostKey    string
+}
+
+// ParseSSHAuth checks that the private key and host key can be used
+func ParseSSHAuth(auth SSHAuth) (ssh.Signer, ssh.PublicKey, error) {
+	var signer ssh.Signer
+	var err error
+	if len(auth.Passphrase) > 0 {
+		signer, err = ssh.ParsePrivateKeyWithPassphrase(auth.PrivateKey, auth.Passphrase)
+	} else {
+		signer, err = ssh.ParsePrivateKey(auth.PrivateKey)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid ssh private key: %v", err)
+	}
+
+	if auth.HostKey == "" {
+		return nil, nil, errors.New("ssh host key is required")
+	}
+	hostKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(auth.HostKey))
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid ssh host key: %v", err)
+	}
+	return signer, hostKey, nil
+}
+
+// sshDialer keeps one SSH connection per host and opens a forwarded stream to
+// the daemon's unix socket for every request, reconnecting when the SSH
+// connection drops
+type sshDialer struct {
+	netDialer  *net.Dialer
+	address    string
+	socketPath string
+	config     *ssh.ClientConfig
+
+	mu     sync.Mutex
+	client *ssh.Client
+	closed bool
+}
+
+func newSSHDialer(hostURL *url.URL, auth SSHAuth, netDialer *net.Dialer) (*sshDialer, error) {
+	signer, hostKey, err := ParseSSHAuth(auth)
+	if err != nil {
+		return nil, err
+	}
+
+	user := hostURL.User.Username()
+	if user == "" {
+		return nil, errors.New("ssh docker host needs a user, e.g. ssh://deploy@host")
+	}
+	address := hostURL.Host
+	if hostURL.Port() == "" {
+		address = net.JoinHostPort(hostURL.Hostname(), "22")
+	}
+	socketPath := hostURL.Path
+	if socketPath == "" || socketPath == "/" {
+		socketPath = defaultSSHSocket
+	}
+
+	dialer := &sshDialer{
+		netDialer:  netDialer,
+		address:    address,
+		socketPath: socketPath,
+		config: &ssh.ClientConfig{
+			User:            user,
+			Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+			HostKeyCallback: ssh.FixedHostKey(hostKey),
+			Timeout:         sshHandshakeLimit,
+		},
+	}
+	return dialer, nil
+}
+
+func (d *sshDialer) dial(ctx context.Context) (net.Conn, error) {
+	client, err := d.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := client.Dial("unix", d.socketPath)
+	if err == nil {
+		return conn, nil
+	}
+
+	// The connection may have gone stale; retry once on a fresh one
+	d.reset(client)
+	if client, err = d.connect(ctx); err != nil {
+		return nil, err
+	}
+	return client.Dial("unix", d.socketPath)
+}
+
+func (d *sshDialer) connect(ctx context.Context) (*ssh.Client, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.closed {
+		return nil, errors.New("docker client closed")
+	}
+	if d.client != nil {
+		return d.client, nil
+	}
+
+	conn, err := d.netDialer.DialContext(ctx, "tcp", d.address)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	} else {
+		conn.SetDeadline(time.Now().Add(sshHandshakeLimit))
+	}
+
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, d.address, d.config)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("ssh handshake with %s failed: %w", d.address, err)
+	}
+	conn.SetDeadline(time.Time{})
+
+	client := ssh.NewClient(sshConn, chans, reqs)
+	d.client = client
+	go d.keepAlive(client)
+	return client, nil
+}
+
+// keepAlive pings the server so dead connections are noticed and dropped
+func (d *sshDialer) keepAlive(client *ssh.Client) {
+	ticker := time.NewTicker(sshKeepAlive)
+	defer ticker.Stop()
+	for range ticker.C {
+		if _, _, err := client.SendRequest("keepalive@openssh.com", true, nil); err != nil {
+			d.reset(client)
+			return
+		}
+	}
+}
+
+// close drops the SSH connection for good
+func (d *sshDialer) close() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.closed = true
+	if d.client != nil {
+		d.client.Close()
+		d.client = nil
+	}
+}
+
+func (d *sshDialer) reset(client *ssh.Client) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.client == client {
+		d.client = nil
+	}
+	client.Close()
+}
@@ -0,0 +1,196 @@
+package docker
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// VersionInfo is the subset of GET /version used by CloudDeck
+type VersionInfo struct {
+	Version    string `json:"Version"`
+	APIVersion string `json:"ApiVersion"`
+	Os         string `json:"Os"`
+	Arch       string `json:"Arch"`
+}
+
+// Container is an entry of GET /containers/json
+type Container struct {
+	ID              string            `json:"Id"`
+	Names           []string          `json:"Names"`
+	Image           string            `json:"Image"`
+	ImageID         string            `json:"ImageID"`
+	Command         string            `json:"Command"`
+	Created         int64             `json:"Created"`
+	State           string            `json:"State"`
+	Status          string            `json:"Status"`
+	Labels          map[string]string `json:"Labels"`
+	Mounts          []Mount           `json:"Mounts"`
+	NetworkSettings struct {
+		Networks map[string]EndpointSettings `json:"Networks"`
+	} `json:"NetworkSettings"`
+}
+
+// Health reads the health check status from the list's Status text, e.g.
+// "Up 2 hours (healthy)": "healthy", "unhealthy", "starting", or empty for
+// containers without a health check
+func (c Container) Health() string {
+	switch {
+	case strings.HasSuffix(c.Status, "(healthy)"):
+		return "healthy"
+	case strings.HasSuffix(c.Status, "(unhealthy)"):
+		return "unhealthy"
+	case strings.HasSuffix(c.Status, "(health: starting)"):
+		return "starting"
+	}
+	return ""
+}
+
+// ExitCode reads the exit code from the list's Status text, e.g.
+// "Exited (137) 5 minutes ago"; ok is false for containers that have not exited
+func (c Container) ExitCode() (int, bool) {
+	rest, found := strings.CutPrefix(c.Status, "Exited (")
+	if !found {
+		return 0, false
+	}
+	code, _, found := strings.Cut(rest, ")")
+	if !found {
+		return 0, false
+	}
+	exitCode, err := strconv.Atoi(code)
+	return exitCode, err == nil
+}
+
+// Mount is a volume or bind mount of a container; Name is set for volumes
+type Mount struct {
+	Type        string `json:"Type"`
+	Name        string `json:"Name"`
+	Source      string `json:"Source"`
+	Destination string `json:"Destination"`
+}
+
+// EndpointSettings is a container's attachment to one network
+type EndpointSettings struct {
+	NetworkID string `json:"NetworkID"`
+	IPAddress string `json:"IPAddress"`
+}
+
+// ContainerDetails is the subset of GET /containers/{id}/json used by CloudDeck
+type ContainerDetails struct {
+	ID      string `json:"Id"`
+	Name    string `json:"Name"`
+	Created string `json:"Created"`
+	Image   string `json:"Image"`
+	State   struct {
+		Status     string `json:"Status"`
+		Running    bool   `json:"Running"`
+		Paused     bool   `json:"Paused"`
+		Restarting bool   `json:"Restarting"`
+		ExitCode   int    `json:"ExitCode"`
+		StartedAt  string `json:"StartedAt"`
+		FinishedAt string `json:"FinishedAt"`
+		Health     *struct {
+			Status string `json:"Status"`
+		} `json:"Health,omitempty"`
+	} `json:"State"`
+	Config struct {
+		Image  string            `json:"Image"`
+		Tty    bool              `json:"Tty"`
+		Labels map[string]string `json:"Labels"`
+	} `json:"Config"`
+}
+
+// Stats is a sample from GET /containers/{id}/stats
+type Stats struct {
+	Read        string      `json:"read"`
+	CPUStats    CPUStats    `json:"cpu_stats"`
+	PreCPUStats CPUStats    `json:"precpu_stats"`
+	MemoryStats MemoryStats `json:"memory_stats"`
+	Networks    map[string]struct {
+		RxBytes uint64 `json:"rx_bytes"`
+		TxBytes uint64 `json:"tx_bytes"`
+	} `json:"networks"`
+}
+
+type CPUStats struct {
+	CPUUsage struct {
+		TotalUsage  uint64   `json:"total_usage"`
+		PercpuUsage []uint64 `json:"percpu_usage"`
+	} `json:"cpu_usage"`
+	SystemUsage uint64 `json:"system_cpu_usage"`
+	OnlineCPUs  uint32 `json:"online_cpus"`
+}
+
+type MemoryStats struct {
+	Usage uint64            `json:"usage"`
+	Limit uint64            `json:"limit"`
+	Stats map[string]uint64 `json:"stats"`
+}
+
+// CPUPercent computes CPU usage the way `docker stats` does
+func (s *Stats) CPUPercent() float64 {
+	cpuDelta := float64(s.CPUStats.CPUUsage.TotalUsage) - float64(s.PreCPUStats.CPUUsage.TotalUsage)
+	systemDelta := float64(s.CPUStats.SystemUsage) - float64(s.PreCPUStats.SystemUsage)
+
+	onlineCPUs := float64(s.CPUStats.OnlineCPUs)
+	if onlineCPUs == 0 {
+		onlineCPUs = float64(len(s.CPUStats.CPUUsage.PercpuUsage))
+	}
+
+	if cpuDelta <= 0 || systemDelta <= 0 || onlineCPUs == 0 {
+		return 0
+	}
+	return cpuDelta / systemDelta * onlineCPUs * 100
+}
+
+// MemoryUsage excludes the page cache, like `docker stats`
+func (s *Stats) MemoryUsage() uint64 {
+	usage := s.MemoryStats.Usage
+	// cgroup v1 reports total_inactive_file, cgroup v2 inactive_file
+	for _, key := range []string{"total_inactive_file", "inactive_file"} {
+		if cache, ok := s.MemoryStats.Stats[key]; ok && cache < usage {
+			return usage - cache
+		}
+	}
+	return usage
+}
+
+// NetworkBytes sums received and transmitted bytes over all interfaces
+func (s *Stats) NetworkBytes() (rx, tx uint64) {
+	for _, network := range s.Networks {
+		rx += network.RxBytes
+		tx += network.TxBytes
+	}
+	return rx, tx
+}
+
+// LogsOptions are the query parameters of GET /containers/{id}/logs. Since and
+// Until are Unix timestamps; see ParseLogTime.
+type LogsOptions struct {
+	Stdout     bool
+	Stderr     bool
+	Follow     bool
+	Timestamps bool
+	Since      string
+	Until      string
+	Tail       string
+}
+
+func (o LogsOptions) query() url.Values {
+	query := url.Values{
+		"stdout":     {strconv.FormatBool(o.Stdout)},
+		"stderr":     {strconv.FormatBool(o.Stderr)},
+		"follow":     {strconv.FormatBool(o.Follow)},
+		"timestamps": {strconv.FormatBool(o.Timestamps)},
+	}
+	if o.Since != "" {
+		query.Set("since", o.Since)
+	}
+	if o.Until != "" {
+		query.Set("until", o.Until)
+	}
+	if o.Tail != "" {
+		query.Set("tail", o.Tail)
+	}
+	return query
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/middleware"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/services"
+	"github.com/SoumyaRaikwar/clouddeck-backend/pkg/utils"
+)
+
+type APIKeyHandler struct {
+	service *services.APIKeyService
+}
+
+func NewAPIKeyHandler(service *services.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		service: service,
+	}
+}
+
+// CreateAPIKey handles POST /api/api-keys
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	var req models.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	key, err := h.service.CreateAPIKey(middleware.CurrentPrincipal(c), &req)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, services.ErrForbidden) {
+			status = http.StatusForbidden
+		}
+		utils.ErrorResponse(c, status, "Failed to create API key", err.Error())
+		return
+	}
+
+	middleware.SetAuditTarget(c, "api_key", strconv.FormatUint(uint64(key.ID), 10))
+	middleware.SetAuditState(c, nil, key.APIKey)
+
+	utils.SuccessResponse(c, http.StatusCreated, "API key created successfully; store it now, it will not be shown again", key)
+}
+
+// GetAPIKeys handles GET /api/api-keys
+func (h *APIKeyHandler) GetAPIKeys(c *gin.Context) {
+	keys, err := h.service.GetAPIKeys(middleware.CurrentPrincipal(c))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch API keys", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "API keys fetched successfully", keys)
+}
+
+// RevokeAPIKey handles DELETE /api/api-keys/:id
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid API key ID", err.Error())
+		return
+	}
+
+	middleware.SetAuditTarget(c, "api_key", c.Param("id"))
+
+	key, err := h.service.RevokeAPIKey(middleware.CurrentPrincipal(c), uint(id))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Failed to revoke API key", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "API key revoked successfully", key)
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/repositories"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/services"
+	"github.com/SoumyaRaikwar/clouddeck-backend/pkg/utils"
+)
+
+type AuditHandler struct {
+	service *services.AuditService
+}
+
+func NewAuditHandler(service *services.AuditService) *AuditHandler {
+	return &AuditHandler{
+		service: service,
+	}
+}
+
+// GetAuditLogs handles GET /api/audit
+// Supports ?actor=, ?entityType=, ?entityId=, ?from=, ?to= (RFC3339) and ?limit=
+func (h *AuditHandler) GetAuditLogs(c *gin.Context) {
+	query := &models.AuditQuery{
+		OrganizationID: organizationID(c),
+		Actor:          c.Query("actor"),
+		EntityType:     c.Query("entityType"),
+		EntityID:       c.Query("entityId"),
+	}
+
+	for param, target := range map[string]**time.Time{"from": &query.From, "to": &query.To} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid "+param+" time", err.Error())
+			return
+		}
+		*target = &parsed
+	}
+
+	if limitParam := c.Query("limit"); limitParam != "" {
+		limit, err := strconv.ParseInt(limitParam, 10, 64)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid limit", err.Error())
+			return
+		}
+		query.Limit = limit
+	}
+
+	logs, err := h.service.GetAuditLogs(query)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, repositories.ErrAuditStoreUnavailable) {
+			status = http.StatusServiceUnavailable
+		}
+		utils.ErrorResponse(c, status, "Failed to fetch audit log", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Audit log fetched successfully", logs)
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/middleware"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/services"
+	"github.com/SoumyaRaikwar/clouddeck-backend/pkg/utils"
+)
+
+type AuthHandler struct {
+	service *services.AuthService
+}
+
+func NewAuthHandler(service *services.AuthService) *AuthHandler {
+	return &AuthHandler{
+		service: service,
+	}
+}
+
+// Login handles POST /api/auth/login
+func (h *AuthHandler) Login(c *gin.Context) {
+	var req models.LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	tokens, err := h.service.Login(&req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidCredentials) {
+			utils.ErrorResponse(c, http.StatusUnauthorized, "Login failed", err.Error())
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Login failed", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Logged in successfully", tokens)
+}
+
+// Refresh handles POST /api/auth/refresh
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req models.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	tokens, err := h.service.Refresh(req.RefreshToken)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Token refresh failed", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Token refreshed successfully", tokens)
+}
+
+// Me handles GET /api/auth/me
+func (h *AuthHandler) Me(c *gin.Context) {
+	principal := middleware.CurrentPrincipal(c)
+
+	user, err := h.service.GetUserByID(principal.UserID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "User not found", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "User fetched successfully", user)
+}
+
+// GetUsers handles GET /api/users
+func (h *AuthHandler) GetUsers(c *gin.Context) {
+	users, err := h.service.GetAllUsers(organizationID(c))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch users", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Users fetched successfully", users)
+}
+
+// CreateUser handles POST /api/users
+func (h *AuthHandler) CreateUser(c *gin.Context) {
+	var req models.CreateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	user, err := h.service.CreateUser(organizationID(c), &req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to create user", err.Error())
+		return
+	}
+
+	middleware.SetAuditTarget(c, "user", strconv.FormatUint(uint64(user.ID), 10))
+	middleware.SetAuditState(c, nil, user)
+
+	utils.SuccessResponse(c, http.StatusCreated, "User created successfully", user)
+}
+
+// UpdateUserRole handles PUT /api/users/:id/role
+func (h *AuthHandler) UpdateUserRole(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID", err.Error())
+		return
+	}
+
+	var req models.UpdateUserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	before, err := h.service.GetOrganizationUser(organizationID(c), uint(id))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "User not found", err.Error())
+		return
+	}
+	middleware.SetAuditTarget(c, "user", c.Param("id"))
+
+	user, err := h.service.UpdateUserRole(organizationID(c), uint(id), req.Role)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Failed to update role", err.Error())
+		return
+	}
+
+	middleware.SetAuditState(c, before, user)
+
+	utils.SuccessResponse(c, http.StatusOK, "Role updated successfully", user)
+}
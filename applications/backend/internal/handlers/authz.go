@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/middleware"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/services"
+	"github.com/SoumyaRaikwar/clouddeck-backend/pkg/utils"
+)
+
+// authorizeProject responds with 403 and returns false unless the caller holds
+// at least role on the project, either globally or through project membership.
+func authorizeProject(c *gin.Context, access *services.AccessService, projectID uint, role string) bool {
+	err := access.RequireProjectRole(middleware.CurrentPrincipal(c), projectID, role)
+	if err == nil {
+		return true
+	}
+
+	if errors.Is(err, services.ErrProjectNotFound) {
+		utils.ErrorResponse(c, http.StatusNotFound, "Project not found", err.Error())
+		return false
+	}
+
+	if errors.Is(err, services.ErrForbidden) {
+		utils.ErrorResponse(c, http.StatusForbidden, "Forbidden", "requires role "+role+" on this project")
+		return false
+	}
+
+	utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to check permissions", err.Error())
+	return false
+}
+
+// organizationID returns the organization every repository query is scoped to
+func organizationID(c *gin.Context) uint {
+	return middleware.CurrentPrincipal(c).OrganizationID
+}
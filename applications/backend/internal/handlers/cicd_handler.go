@@ -6,6 +6,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/middleware"
 	"github.com/SoumyaRaikwar/clouddeck-backend/internal/services"
 	"github.com/SoumyaRaikwar/clouddeck-backend/pkg/utils"
 )
@@ -21,24 +22,21 @@ func NewCICDHandler(service *services.CICDService) *CICDHandler {
 }
 
 func (h *CICDHandler) GetWorkflowRuns(c *gin.Context) {
-	token := c.Query("token")
+	credentialID, ok := credentialIDQuery(c)
 	owner := c.Query("owner")
 	repo := c.Query("repo")
 	limitStr := c.DefaultQuery("limit", "20")
 
-	if token == "" || owner == "" || repo == "" {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Token, owner, and repo are required", "")
+	if !ok || owner == "" || repo == "" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "credentialId, owner, and repo are required", "")
 		return
 	}
 
 	limit, _ := strconv.Atoi(limitStr)
-	
-	// Create new service with provided token
-	service := services.NewCICDService(token)
-	
-	runs, err := service.GetWorkflowRuns(owner, repo, limit)
+
+	runs, err := h.service.GetWorkflowRuns(middleware.CurrentPrincipal(c).UserID, credentialID, owner, repo, limit)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch workflow runs", err.Error())
+		utils.ErrorResponse(c, credentialErrorStatus(err), "Failed to fetch workflow runs", err.Error())
 		return
 	}
 
@@ -46,21 +44,18 @@ func (h *CICDHandler) GetWorkflowRuns(c *gin.Context) {
 }
 
 func (h *CICDHandler) GetPipelineStats(c *gin.Context) {
-	token := c.Query("token")
+	credentialID, ok := credentialIDQuery(c)
 	owner := c.Query("owner")
 	repo := c.Query("repo")
 
-	if token == "" || owner == "" || repo == "" {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Token, owner, and repo are required", "")
+	if !ok || owner == "" || repo == "" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "credentialId, owner, and repo are required", "")
 		return
 	}
 
-	// Create new service with provided token
-	service := services.NewCICDService(token)
-	
-	stats, err := service.GetPipelineStats(owner, repo)
+	stats, err := h.service.GetPipelineStats(middleware.CurrentPrincipal(c).UserID, credentialID, owner, repo)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch pipeline stats", err.Error())
+		utils.ErrorResponse(c, credentialErrorStatus(err), "Failed to fetch pipeline stats", err.Error())
 		return
 	}
 
@@ -68,21 +63,18 @@ func (h *CICDHandler) GetPipelineStats(c *gin.Context) {
 }
 
 func (h *CICDHandler) GetWorkflows(c *gin.Context) {
-	token := c.Query("token")
+	credentialID, ok := credentialIDQuery(c)
 	owner := c.Query("owner")
 	repo := c.Query("repo")
 
-	if token == "" || owner == "" || repo == "" {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Token, owner, and repo are required", "")
+	if !ok || owner == "" || repo == "" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "credentialId, owner, and repo are required", "")
 		return
 	}
 
-	// Create new service with provided token
-	service := services.NewCICDService(token)
-	
-	workflows, err := service.GetWorkflows(owner, repo)
+	workflows, err := h.service.GetWorkflows(middleware.CurrentPrincipal(c).UserID, credentialID, owner, repo)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch workflows", err.Error())
+		utils.ErrorResponse(c, credentialErrorStatus(err), "Failed to fetch workflows", err.Error())
 		return
 	}
 
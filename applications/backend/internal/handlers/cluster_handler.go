@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/middleware"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/services"
+	"github.com/SoumyaRaikwar/clouddeck-backend/pkg/utils"
+)
+
+type ClusterHandler struct {
+	service *services.ClusterService
+}
+
+func NewClusterHandler(service *services.ClusterService) *ClusterHandler {
+	return &ClusterHandler{
+		service: service,
+	}
+}
+
+// CreateCluster handles POST /api/clusters
+func (h *ClusterHandler) CreateCluster(c *gin.Context) {
+	var req models.CreateClusterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	cluster, err := h.service.CreateCluster(organizationID(c), middleware.CurrentPrincipal(c).UserID, &req)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, services.ErrVaultUnavailable) {
+			status = http.StatusServiceUnavailable
+		}
+		utils.ErrorResponse(c, status, "Failed to add cluster", err.Error())
+		return
+	}
+
+	middleware.SetAuditTarget(c, "cluster", strconv.FormatUint(uint64(cluster.ID), 10))
+	middleware.SetAuditState(c, nil, cluster)
+
+	utils.SuccessResponse(c, http.StatusCreated, "Cluster added successfully", cluster)
+}
+
+// GetClusters handles GET /api/clusters, including the health of each cluster
+func (h *ClusterHandler) GetClusters(c *gin.Context) {
+	clusters, err := h.service.GetClusterStatuses(organizationID(c))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch clusters", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Clusters fetched successfully", clusters)
+}
+
+// GetCluster handles GET /api/clusters/:cluster
+func (h *ClusterHandler) GetCluster(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("cluster"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid cluster ID", err.Error())
+		return
+	}
+
+	cluster, err := h.service.GetCluster(organizationID(c), uint(id))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Cluster not found", err.Error())
+		return
+	}
+
+	status := models.ClusterStatus{
+		Cluster: *cluster,
+		Health:  h.service.ClusterHealth(organizationID(c), cluster.ID),
+	}
+	utils.SuccessResponse(c, http.StatusOK, "Cluster fetched successfully", status)
+}
+
+// DeleteCluster handles DELETE /api/clusters/:cluster
+func (h *ClusterHandler) DeleteCluster(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("cluster"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid cluster ID", err.Error())
+		return
+	}
+
+	middleware.SetAuditTarget(c, "cluster", c.Param("cluster"))
+	if before, err := h.service.GetCluster(organizationID(c), uint(id)); err == nil {
+		middleware.SetAuditState(c, before, nil)
+	}
+
+	if err := h.service.DeleteCluster(organizationID(c), uint(id)); err != nil {
+		status := http.StatusNotFound
+		if errors.Is(err, services.ErrLocalCluster) {
+			status = http.StatusBadRequest
+		}
+		utils.ErrorResponse(c, status, "Failed to delete cluster", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Cluster deleted successfully", nil)
+}
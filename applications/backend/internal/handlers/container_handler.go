@@ -1,28 +1,41 @@
 package handlers
 
 import (
+	"errors"
+	"io"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/docker"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/middleware"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
 	"github.com/SoumyaRaikwar/clouddeck-backend/internal/services"
 	"github.com/SoumyaRaikwar/clouddeck-backend/pkg/utils"
 )
 
+// ContainerHandler serves the containers of one Docker host, picked by dockerHost
 type ContainerHandler struct {
-	service *services.ContainerService
+	hosts     *services.DockerHostService
+	terminals *TerminalBridge
 }
 
-func NewContainerHandler(service *services.ContainerService) *ContainerHandler {
+func NewContainerHandler(hosts *services.DockerHostService, terminals *TerminalBridge) *ContainerHandler {
 	return &ContainerHandler{
-		service: service,
+		hosts:     hosts,
+		terminals: terminals,
 	}
 }
 
 func (h *ContainerHandler) GetContainers(c *gin.Context) {
-	containers, err := h.service.GetAllContainers()
+	_, service, ok := dockerHost(c, h.hosts)
+	if !ok {
+		return
+	}
+
+	containers, err := service.GetAllContainers(c.Request.Context())
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch containers", err.Error())
+		utils.ErrorResponse(c, dockerErrorStatus(err), "Failed to fetch containers", err.Error())
 		return
 	}
 
@@ -30,14 +43,179 @@ func (h *ContainerHandler) GetContainers(c *gin.Context) {
 }
 
 func (h *ContainerHandler) GetContainerLogs(c *gin.Context) {
+	_, service, ok := dockerHost(c, h.hosts)
+	if !ok {
+		return
+	}
 	containerID := c.Param("id")
-	tail := c.DefaultQuery("tail", "100")
 
-	logs, err := h.service.GetContainerLogs(containerID, tail)
+	logs, err := service.GetContainerLogs(c.Request.Context(), containerID, containerLogOptions(c))
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch logs", err.Error())
+		utils.ErrorResponse(c, dockerErrorStatus(err), "Failed to fetch logs", err.Error())
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Logs fetched successfully", gin.H{"logs": logs})
+	utils.SuccessResponse(c, http.StatusOK, "Logs fetched successfully", logs)
+}
+
+// StreamContainerLogs handles GET /api/docker-hosts/:host/containers/:id/logs/stream
+// Lines are sent as server-sent events named after their stream ("stdout" or
+// "stderr"), followed by "end" or "error". Disconnecting closes the upstream stream.
+func (h *ContainerHandler) StreamContainerLogs(c *gin.Context) {
+	_, service, ok := dockerHost(c, h.hosts)
+	if !ok {
+		return
+	}
+	ctx := c.Request.Context()
+
+	lines, result, err := service.StreamContainerLogs(ctx, c.Param("id"), containerLogOptions(c))
+	if err != nil {
+		utils.ErrorResponse(c, dockerErrorStatus(err), "Failed to stream logs", err.Error())
+		return
+	}
+
+	startEventStream(c)
+	for line := range lines {
+		c.SSEvent(line.Stream, line.Text)
+		c.Writer.Flush()
+	}
+
+	if ctx.Err() != nil {
+		return
+	}
+	if err := <-result; err != nil {
+		c.SSEvent("error", err.Error())
+	} else {
+		c.SSEvent("end", "")
+	}
+	c.Writer.Flush()
+}
+
+// StartContainer handles POST /api/docker-hosts/:host/containers/:id/start
+func (h *ContainerHandler) StartContainer(c *gin.Context) {
+	h.runAction(c, services.ContainerActionStart)
+}
+
+// StopContainer handles POST /api/docker-hosts/:host/containers/:id/stop
+func (h *ContainerHandler) StopContainer(c *gin.Context) {
+	h.runAction(c, services.ContainerActionStop)
+}
+
+// RestartContainer handles POST /api/docker-hosts/:host/containers/:id/restart
+func (h *ContainerHandler) RestartContainer(c *gin.Context) {
+	h.runAction(c, services.ContainerActionRestart)
+}
+
+// PauseContainer handles POST /api/docker-hosts/:host/containers/:id/pause
+func (h *ContainerHandler) PauseContainer(c *gin.Context) {
+	h.runAction(c, services.ContainerActionPause)
+}
+
+// UnpauseContainer handles POST /api/docker-hosts/:host/containers/:id/unpause
+func (h *ContainerHandler) UnpauseContainer(c *gin.Context) {
+	h.runAction(c, services.ContainerActionUnpause)
+}
+
+// KillContainer handles POST /api/docker-hosts/:host/containers/:id/kill
+func (h *ContainerHandler) KillContainer(c *gin.Context) {
+	h.runAction(c, services.ContainerActionKill)
+}
+
+// RemoveContainer handles POST /api/docker-hosts/:host/containers/:id/remove
+func (h *ContainerHandler) RemoveContainer(c *gin.Context) {
+	h.runAction(c, services.ContainerActionRemove)
+}
+
+func (h *ContainerHandler) runAction(c *gin.Context, action string) {
+	_, service, ok := dockerHost(c, h.hosts)
+	if !ok {
+		return
+	}
+
+	var req models.ContainerActionRequest
+	// The body is optional; an empty one leaves every option at its default
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	containerID := c.Param("id")
+	middleware.SetAuditTarget(c, "container", containerID)
+	middleware.SetAuditDetail(c, "action", action)
+
+	result, err := service.RunContainerAction(c.Request.Context(), containerID, action, &req)
+	if result != nil {
+		middleware.SetAuditTarget(c, "container", result.ContainerID)
+		middleware.SetAuditDetail(c, "name", result.Name)
+		middleware.SetAuditState(c, gin.H{"state": result.StateBefore}, gin.H{"state": result.StateAfter})
+	}
+	if err != nil {
+		status := dockerErrorStatus(err)
+		switch {
+		case errors.Is(err, services.ErrConfirmationRequired):
+			status = http.StatusPreconditionRequired
+		case errors.Is(err, docker.ErrNotModified):
+			status = http.StatusConflict
+		}
+		utils.ErrorResponse(c, status, "Failed to "+action+" container", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Container "+action+" succeeded", result)
+}
+
+// ExecContainer handles GET /api/docker-hosts/:host/containers/:id/exec
+// It opens an interactive exec session and relays it over a WebSocket; see
+// terminalMessage for the frame format. The container must be running.
+func (h *ContainerHandler) ExecContainer(c *gin.Context) {
+	_, service, ok := dockerHost(c, h.hosts)
+	if !ok {
+		return
+	}
+	containerID := c.Param("id")
+	command, tty := terminalCommand(c)
+
+	middleware.SetAuditTarget(c, "container", containerID)
+	middleware.SetAuditDetail(c, "command", command)
+
+	session, err := service.OpenExec(c.Request.Context(), containerID, command, tty)
+	if err != nil {
+		status, ok := terminalSessionStatus(err)
+		if !ok {
+			status = dockerErrorStatus(err)
+		}
+		utils.ErrorResponse(c, status, "Failed to open exec session", err.Error())
+		return
+	}
+
+	h.terminals.Attach(c, session, tty)
+}
+
+// containerLogOptions reads ?tail=, ?since=, ?until= and ?timestamps=
+func containerLogOptions(c *gin.Context) services.ContainerLogOptions {
+	return services.ContainerLogOptions{
+		Tail:       c.DefaultQuery("tail", "100"),
+		Since:      c.Query("since"),
+		Until:      c.Query("until"),
+		Timestamps: c.Query("timestamps") == "true",
+	}
+}
+
+// dockerErrorStatus maps Docker Engine and request errors to HTTP statuses
+func dockerErrorStatus(err error) int {
+	var apiErr *docker.APIError
+	switch {
+	case errors.Is(err, services.ErrInvalidContainerRequest):
+		return http.StatusBadRequest
+	case errors.Is(err, docker.ErrUnreachable):
+		return http.StatusServiceUnavailable
+	case docker.IsNotFound(err):
+		return http.StatusNotFound
+	case docker.IsConflict(err):
+		return http.StatusConflict
+	case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusBadRequest:
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
 }
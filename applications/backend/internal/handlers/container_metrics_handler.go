@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/services"
+	"github.com/SoumyaRaikwar/clouddeck-backend/pkg/utils"
+)
+
+type ContainerMetricsHandler struct {
+	service *services.ContainerMetricsService
+	hosts   *services.DockerHostService
+}
+
+func NewContainerMetricsHandler(service *services.ContainerMetricsService, hosts *services.DockerHostService) *ContainerMetricsHandler {
+	return &ContainerMetricsHandler{
+		service: service,
+		hosts:   hosts,
+	}
+}
+
+// GetContainerMetrics handles GET /api/docker-hosts/:host/containers/:id/metrics
+// ?from= and ?to= accept RFC3339, Unix timestamps or durations ago such as "6h"
+// (defaults: 1h ago and now); ?step= is a duration or seconds (default: about
+// 300 points). History of removed containers stays available until retention.
+func (h *ContainerMetricsHandler) GetContainerMetrics(c *gin.Context) {
+	hostID, service, ok := dockerHost(c, h.hosts)
+	if !ok {
+		return
+	}
+	now := time.Now().UTC()
+
+	from, err := parseMetricsTime(c.DefaultQuery("from", "1h"), now)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid from", err.Error())
+		return
+	}
+	to, err := parseMetricsTime(c.Query("to"), now)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid to", err.Error())
+		return
+	}
+	step, err := parseMetricsStep(c.Query("step"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid step", err.Error())
+		return
+	}
+
+	series, err := h.service.GetMetrics(c.Request.Context(), hostID, service, c.Param("id"), from, to, step)
+	if err != nil {
+		status := dockerErrorStatus(err)
+		if errors.Is(err, services.ErrUnknownContainer) {
+			status = http.StatusNotFound
+		}
+		utils.ErrorResponse(c, status, "Failed to fetch container metrics", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Container metrics fetched successfully", series)
+}
+
+// parseMetricsTime reads an RFC3339 time, a Unix timestamp or a duration before
+// now; empty means now
+func parseMetricsTime(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return now, nil
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t.UTC(), nil
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q: use RFC3339, a Unix timestamp or a duration like 6h", value)
+}
+
+// parseMetricsStep reads a duration such as "1m" or a number of seconds; empty means automatic
+func parseMetricsStep(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= time.Second {
+		return d, nil
+	}
+	return 0, fmt.Errorf("invalid step %q: use a duration of at least 1s or a number of seconds", value)
+}
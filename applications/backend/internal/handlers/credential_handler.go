@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/middleware"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/services"
+	"github.com/SoumyaRaikwar/clouddeck-backend/pkg/utils"
+)
+
+type CredentialHandler struct {
+	service *services.CredentialService
+}
+
+func NewCredentialHandler(service *services.CredentialService) *CredentialHandler {
+	return &CredentialHandler{
+		service: service,
+	}
+}
+
+// CreateCredential handles POST /api/credentials
+func (h *CredentialHandler) CreateCredential(c *gin.Context) {
+	var req models.CreateCredentialRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	credential, err := h.service.CreateCredential(organizationID(c), middleware.CurrentPrincipal(c).UserID, &req)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, services.ErrVaultUnavailable) {
+			status = http.StatusServiceUnavailable
+		}
+		utils.ErrorResponse(c, status, "Failed to save credential", err.Error())
+		return
+	}
+
+	middleware.SetAuditTarget(c, "credential", strconv.FormatUint(uint64(credential.ID), 10))
+	middleware.SetAuditState(c, nil, credential)
+
+	utils.SuccessResponse(c, http.StatusCreated, "Credential saved successfully", credential)
+}
+
+// GetCredentials handles GET /api/credentials
+func (h *CredentialHandler) GetCredentials(c *gin.Context) {
+	credentials, err := h.service.GetCredentials(middleware.CurrentPrincipal(c).UserID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch credentials", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Credentials fetched successfully", credentials)
+}
+
+// RotateCredential handles PUT /api/credentials/:id/rotate
+func (h *CredentialHandler) RotateCredential(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid credential ID", err.Error())
+		return
+	}
+
+	var req models.RotateCredentialRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	middleware.SetAuditTarget(c, "credential", c.Param("id"))
+
+	credential, err := h.service.RotateCredential(middleware.CurrentPrincipal(c).UserID, uint(id), req.Secret)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, services.ErrVaultUnavailable) {
+			status = http.StatusServiceUnavailable
+		}
+		utils.ErrorResponse(c, status, "Failed to rotate credential", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Credential rotated successfully", credential)
+}
+
+// RevokeCredential handles DELETE /api/credentials/:id
+func (h *CredentialHandler) RevokeCredential(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid credential ID", err.Error())
+		return
+	}
+
+	middleware.SetAuditTarget(c, "credential", c.Param("id"))
+
+	if err := h.service.RevokeCredential(middleware.CurrentPrincipal(c).UserID, uint(id)); err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Failed to revoke credential", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Credential revoked successfully", nil)
+}
+
+// credentialIDQuery reads the ?credentialId= reference used in place of raw tokens
+func credentialIDQuery(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Query("credentialId"), 10, 32)
+	if err != nil || id == 0 {
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func credentialErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrVaultUnavailable):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, services.ErrCredentialUnavailable), errors.Is(err, services.ErrCredentialRevoked):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
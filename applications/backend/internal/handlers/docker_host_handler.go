@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/middleware"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/services"
+	"github.com/SoumyaRaikwar/clouddeck-backend/pkg/utils"
+)
+
+type DockerHostHandler struct {
+	service *services.DockerHostService
+}
+
+func NewDockerHostHandler(service *services.DockerHostService) *DockerHostHandler {
+	return &DockerHostHandler{
+		service: service,
+	}
+}
+
+// dockerHost resolves the Docker host a request targets among the hosts of the
+// caller's organization: the host named by the :host route parameter, or the
+// server's own daemon for the /api/containers, /api/images, ... routes, which
+// only the organization owning it can use. It returns the host's ID.
+func dockerHost(c *gin.Context, hosts *services.DockerHostService) (uint, *services.ContainerService, bool) {
+	var hostID uint
+	var service *services.ContainerService
+	var err error
+
+	if hostParam := c.Param("host"); hostParam != "" {
+		id, parseErr := strconv.ParseUint(hostParam, 10, 32)
+		if parseErr != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid Docker host ID", parseErr.Error())
+			return 0, nil, false
+		}
+		hostID = uint(id)
+		service, err = hosts.Client(organizationID(c), hostID)
+	} else {
+		hostID, service, err = hosts.LocalClient(organizationID(c))
+	}
+
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, services.ErrDockerHostNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, services.ErrDockerHostUnavailable):
+			status = http.StatusServiceUnavailable
+		}
+		utils.ErrorResponse(c, status, "Docker host unavailable", err.Error())
+		return 0, nil, false
+	}
+	middleware.SetAuditDetail(c, "docker_host", hostID)
+	return hostID, service, true
+}
+
+// CreateDockerHost handles POST /api/docker-hosts
+func (h *DockerHostHandler) CreateDockerHost(c *gin.Context) {
+	var req models.CreateDockerHostRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	host, err := h.service.CreateDockerHost(organizationID(c), middleware.CurrentPrincipal(c).UserID, &req)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, services.ErrVaultUnavailable) {
+			status = http.StatusServiceUnavailable
+		}
+		utils.ErrorResponse(c, status, "Failed to add Docker host", err.Error())
+		return
+	}
+
+	middleware.SetAuditTarget(c, "docker_host", strconv.FormatUint(uint64(host.ID), 10))
+	middleware.SetAuditState(c, nil, host)
+
+	utils.SuccessResponse(c, http.StatusCreated, "Docker host added successfully", host)
+}
+
+// GetDockerHosts handles GET /api/docker-hosts, including the health of each host
+func (h *DockerHostHandler) GetDockerHosts(c *gin.Context) {
+	hosts, err := h.service.GetDockerHostStatuses(organizationID(c))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch Docker hosts", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Docker hosts fetched successfully", hosts)
+}
+
+// GetDockerHost handles GET /api/docker-hosts/:host
+func (h *DockerHostHandler) GetDockerHost(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("host"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid Docker host ID", err.Error())
+		return
+	}
+
+	host, err := h.service.GetDockerHost(organizationID(c), uint(id))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Docker host not found", err.Error())
+		return
+	}
+
+	status := models.DockerHostStatus{
+		DockerHost: *host,
+		Health:     h.service.DockerHostHealth(organizationID(c), host.ID),
+	}
+	utils.SuccessResponse(c, http.StatusOK, "Docker host fetched successfully", status)
+}
+
+// DeleteDockerHost handles DELETE /api/docker-hosts/:host
+func (h *DockerHostHandler) DeleteDockerHost(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("host"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid Docker host ID", err.Error())
+		return
+	}
+
+	middleware.SetAuditTarget(c, "docker_host", c.Param("host"))
+	if before, err := h.service.GetDockerHost(organizationID(c), uint(id)); err == nil {
+		middleware.SetAuditState(c, before, nil)
+	}
+
+	if err := h.service.DeleteDockerHost(organizationID(c), uint(id)); err != nil {
+		status := http.StatusNotFound
+		if errors.Is(err, services.ErrLocalDockerHost) {
+			status = http.StatusBadRequest
+		}
+		utils.ErrorResponse(c, status, "Failed to delete Docker host", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Docker host deleted successfully", nil)
+}
+
+// GetFleetContainers handles GET /api/docker-hosts/containers
+// It lists the containers of every host of the organization, each tagged with
+// its host. Hosts that cannot be reached are listed in hosts
+// with their error instead of failing the request.
+func (h *DockerHostHandler) GetFleetContainers(c *gin.Context) {
+	fleet, err := h.service.FleetContainers(c.Request.Context(), organizationID(c))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch containers", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Containers fetched successfully", fleet)
+}
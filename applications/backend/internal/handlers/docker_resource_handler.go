@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/middleware"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/services"
+	"github.com/SoumyaRaikwar/clouddeck-backend/pkg/utils"
+)
+
+// DockerResourceHandler serves the images, volumes and networks of a Docker
+// host, picked by dockerHost
+type DockerResourceHandler struct {
+	hosts *services.DockerHostService
+}
+
+func NewDockerResourceHandler(hosts *services.DockerHostService) *DockerResourceHandler {
+	return &DockerResourceHandler{
+		hosts: hosts,
+	}
+}
+
+// GetImages handles GET /api/docker-hosts/:host/images
+func (h *DockerResourceHandler) GetImages(c *gin.Context) {
+	_, service, ok := dockerHost(c, h.hosts)
+	if !ok {
+		return
+	}
+
+	images, err := service.GetImages(c.Request.Context())
+	if err != nil {
+		utils.ErrorResponse(c, dockerErrorStatus(err), "Failed to fetch images", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Images fetched successfully", images)
+}
+
+// GetVolumes handles GET /api/docker-hosts/:host/volumes
+func (h *DockerResourceHandler) GetVolumes(c *gin.Context) {
+	_, service, ok := dockerHost(c, h.hosts)
+	if !ok {
+		return
+	}
+
+	volumes, err := service.GetVolumes(c.Request.Context())
+	if err != nil {
+		utils.ErrorResponse(c, dockerErrorStatus(err), "Failed to fetch volumes", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Volumes fetched successfully", volumes)
+}
+
+// GetNetworks handles GET /api/docker-hosts/:host/networks
+func (h *DockerResourceHandler) GetNetworks(c *gin.Context) {
+	_, service, ok := dockerHost(c, h.hosts)
+	if !ok {
+		return
+	}
+
+	networks, err := service.GetNetworks(c.Request.Context())
+	if err != nil {
+		utils.ErrorResponse(c, dockerErrorStatus(err), "Failed to fetch networks", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Networks fetched successfully", networks)
+}
+
+// PruneImages handles POST /api/docker-hosts/:host/images/prune
+// Without a body only dangling images are removed; {"all": true} removes every
+// image no container uses.
+func (h *DockerResourceHandler) PruneImages(c *gin.Context) {
+	_, service, ok := dockerHost(c, h.hosts)
+	if !ok {
+		return
+	}
+
+	var req models.PruneImagesRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	middleware.SetAuditTarget(c, "image", "prune")
+	middleware.SetAuditDetail(c, "all", req.All)
+
+	result, err := service.PruneImages(c.Request.Context(), req.All)
+	if err != nil {
+		utils.ErrorResponse(c, dockerErrorStatus(err), "Failed to prune images", err.Error())
+		return
+	}
+
+	recordPrune(c, result)
+	utils.SuccessResponse(c, http.StatusOK, "Images pruned successfully", result)
+}
+
+// PruneVolumes handles POST /api/docker-hosts/:host/volumes/prune
+// It removes every local volume no container mounts, including their data.
+func (h *DockerResourceHandler) PruneVolumes(c *gin.Context) {
+	_, service, ok := dockerHost(c, h.hosts)
+	if !ok {
+		return
+	}
+
+	middleware.SetAuditTarget(c, "volume", "prune")
+
+	result, err := service.PruneVolumes(c.Request.Context())
+	if err != nil {
+		utils.ErrorResponse(c, dockerErrorStatus(err), "Failed to prune volumes", err.Error())
+		return
+	}
+
+	recordPrune(c, result)
+	utils.SuccessResponse(c, http.StatusOK, "Volumes pruned successfully", result)
+}
+
+// PruneNetworks handles POST /api/docker-hosts/:host/networks/prune
+func (h *DockerResourceHandler) PruneNetworks(c *gin.Context) {
+	_, service, ok := dockerHost(c, h.hosts)
+	if !ok {
+		return
+	}
+
+	middleware.SetAuditTarget(c, "network", "prune")
+
+	result, err := service.PruneNetworks(c.Request.Context())
+	if err != nil {
+		utils.ErrorResponse(c, dockerErrorStatus(err), "Failed to prune networks", err.Error())
+		return
+	}
+
+	recordPrune(c, result)
+	utils.SuccessResponse(c, http.StatusOK, "Networks pruned successfully", result)
+}
+
+func recordPrune(c *gin.Context, result *models.PruneResult) {
+	middleware.SetAuditDetail(c, "deleted", result.Deleted)
+	middleware.SetAuditDetail(c, "space_reclaimed", result.SpaceReclaimed)
+}
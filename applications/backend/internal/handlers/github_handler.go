@@ -5,45 +5,49 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/middleware"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
 	"github.com/SoumyaRaikwar/clouddeck-backend/internal/services"
 	"github.com/SoumyaRaikwar/clouddeck-backend/pkg/utils"
 )
 
 type GitHubHandler struct {
 	service *services.GitHubService
+	access  *services.AccessService
 }
 
-func NewGitHubHandler(service *services.GitHubService) *GitHubHandler {
+func NewGitHubHandler(service *services.GitHubService, access *services.AccessService) *GitHubHandler {
 	return &GitHubHandler{
 		service: service,
+		access:  access,
 	}
 }
 
 type SyncPRsRequest struct {
-	Token     string `json:"token" binding:"required"`
-	Username  string `json:"username" binding:"required"`
-	ProjectID uint   `json:"project_id" binding:"required"`
+	CredentialID uint   `json:"credential_id" binding:"required"`
+	Username     string `json:"username" binding:"required"`
+	ProjectID    uint   `json:"project_id" binding:"required"`
 }
 
 type SyncIssuesRequest struct {
-	Token     string `json:"token" binding:"required"`
-	Owner     string `json:"owner" binding:"required"`
-	Repo      string `json:"repo" binding:"required"`
-	ProjectID uint   `json:"project_id" binding:"required"`
+	CredentialID uint   `json:"credential_id" binding:"required"`
+	Owner        string `json:"owner" binding:"required"`
+	Repo         string `json:"repo" binding:"required"`
+	ProjectID    uint   `json:"project_id" binding:"required"`
 }
 
 func (h *GitHubHandler) GetUserPRs(c *gin.Context) {
-	token := c.Query("token")
+	credentialID, ok := credentialIDQuery(c)
 	username := c.Query("username")
 
-	if token == "" || username == "" {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Token and username are required", "")
+	if !ok || username == "" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "credentialId and username are required", "")
 		return
 	}
 
-	prs, err := h.service.GetUserPRs(token, username)
+	prs, err := h.service.GetUserPRs(middleware.CurrentPrincipal(c).UserID, credentialID, username)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch PRs", err.Error())
+		utils.ErrorResponse(c, credentialErrorStatus(err), "Failed to fetch PRs", err.Error())
 		return
 	}
 
@@ -51,18 +55,18 @@ func (h *GitHubHandler) GetUserPRs(c *gin.Context) {
 }
 
 func (h *GitHubHandler) GetRepoIssues(c *gin.Context) {
-	token := c.Query("token")
+	credentialID, ok := credentialIDQuery(c)
 	owner := c.Query("owner")
 	repo := c.Query("repo")
 
-	if token == "" || owner == "" || repo == "" {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Token, owner, and repo are required", "")
+	if !ok || owner == "" || repo == "" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "credentialId, owner, and repo are required", "")
 		return
 	}
 
-	issues, err := h.service.GetRepoIssues(token, owner, repo)
+	issues, err := h.service.GetRepoIssues(middleware.CurrentPrincipal(c).UserID, credentialID, owner, repo)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch issues", err.Error())
+		utils.ErrorResponse(c, credentialErrorStatus(err), "Failed to fetch issues", err.Error())
 		return
 	}
 
@@ -76,8 +80,12 @@ func (h *GitHubHandler) SyncPRsToTasks(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.SyncPRsToTasks(req.Token, req.Username, req.ProjectID); err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to sync PRs", err.Error())
+	if !authorizeProject(c, h.access, req.ProjectID, models.RoleDeveloper) {
+		return
+	}
+
+	if err := h.service.SyncPRsToTasks(organizationID(c), middleware.CurrentPrincipal(c).UserID, req.CredentialID, req.Username, req.ProjectID); err != nil {
+		utils.ErrorResponse(c, credentialErrorStatus(err), "Failed to sync PRs", err.Error())
 		return
 	}
 
@@ -91,8 +99,12 @@ func (h *GitHubHandler) SyncIssuesToTasks(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.SyncIssuesToTasks(req.Token, req.Owner, req.Repo, req.ProjectID); err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to sync issues", err.Error())
+	if !authorizeProject(c, h.access, req.ProjectID, models.RoleDeveloper) {
+		return
+	}
+
+	if err := h.service.SyncIssuesToTasks(organizationID(c), middleware.CurrentPrincipal(c).UserID, req.CredentialID, req.Owner, req.Repo, req.ProjectID); err != nil {
+		utils.ErrorResponse(c, credentialErrorStatus(err), "Failed to sync issues", err.Error())
 		return
 	}
 
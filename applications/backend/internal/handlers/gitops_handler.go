@@ -1,11 +1,13 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/middleware"
 	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
 	"github.com/SoumyaRaikwar/clouddeck-backend/internal/services"
 	"github.com/SoumyaRaikwar/clouddeck-backend/pkg/utils"
@@ -26,16 +28,23 @@ func (h *GitOpsHandler) CreateApp(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.CreateApp(&app); err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to create GitOps app", err.Error())
+	if err := h.service.CreateApp(organizationID(c), &app); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrInvalidGitOpsApp) || errors.Is(err, services.ErrClusterNotFound) {
+			status = http.StatusBadRequest
+		}
+		utils.ErrorResponse(c, status, "Failed to create GitOps app", err.Error())
 		return
 	}
 
+	middleware.SetAuditTarget(c, "gitops_app", strconv.FormatUint(uint64(app.ID), 10))
+	middleware.SetAuditState(c, nil, app)
+
 	utils.SuccessResponse(c, http.StatusCreated, "GitOps app created successfully", app)
 }
 
 func (h *GitOpsHandler) GetAllApps(c *gin.Context) {
-	apps, err := h.service.GetAllApps()
+	apps, err := h.service.GetAllApps(organizationID(c))
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch apps", err.Error())
 		return
@@ -46,7 +55,7 @@ func (h *GitOpsHandler) GetAllApps(c *gin.Context) {
 
 func (h *GitOpsHandler) GetApp(c *gin.Context) {
 	id, _ := strconv.Atoi(c.Param("id"))
-	app, err := h.service.GetAppByID(uint(id))
+	app, err := h.service.GetAppByID(organizationID(c), uint(id))
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusNotFound, "App not found", err.Error())
 		return
@@ -57,8 +66,9 @@ func (h *GitOpsHandler) GetApp(c *gin.Context) {
 
 func (h *GitOpsHandler) SyncApp(c *gin.Context) {
 	id, _ := strconv.Atoi(c.Param("id"))
+	middleware.SetAuditTarget(c, "gitops_app", c.Param("id"))
 
-	if err := h.service.SyncApp(uint(id)); err != nil {
+	if err := h.service.SyncApp(organizationID(c), uint(id), middleware.CurrentPrincipal(c)); err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Sync failed", err.Error())
 		return
 	}
@@ -68,8 +78,12 @@ func (h *GitOpsHandler) SyncApp(c *gin.Context) {
 
 func (h *GitOpsHandler) DeleteApp(c *gin.Context) {
 	id, _ := strconv.Atoi(c.Param("id"))
+	middleware.SetAuditTarget(c, "gitops_app", c.Param("id"))
+	if before, err := h.service.GetAppByID(organizationID(c), uint(id)); err == nil {
+		middleware.SetAuditState(c, before, nil)
+	}
 
-	if err := h.service.DeleteApp(uint(id)); err != nil {
+	if err := h.service.DeleteApp(organizationID(c), uint(id)); err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Delete failed", err.Error())
 		return
 	}
@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/middleware"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/services"
+	"github.com/SoumyaRaikwar/clouddeck-backend/pkg/utils"
+)
+
+// The handlers below change cluster state. Each accepts ?dryRun=true, which
+// has the API server validate the change without persisting it; dry runs are
+// audited like real changes, with the dry_run detail set.
+
+// ScaleDeployment handles POST /api/clusters/:cluster/kubernetes/deployments/:namespace/:name/scale
+func (h *KubernetesHandler) ScaleDeployment(c *gin.Context) {
+	client, ok := h.cluster(c)
+	if !ok {
+		return
+	}
+
+	var req models.ScaleDeploymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	namespace, name := c.Param("namespace"), c.Param("name")
+	dryRun := kubernetesDryRun(c, "deployment", namespace+"/"+name)
+
+	result, err := client.ScaleDeployment(c.Request.Context(), namespace, name, *req.Replicas, dryRun)
+	if err != nil {
+		utils.ErrorResponse(c, kubernetesErrorStatus(err), "Failed to scale deployment", err.Error())
+		return
+	}
+
+	middleware.SetAuditState(c, result.Before, result.After)
+	utils.SuccessResponse(c, http.StatusOK, "Deployment scaled successfully", result)
+}
+
+// RestartDeployment handles POST /api/clusters/:cluster/kubernetes/deployments/:namespace/:name/restart
+func (h *KubernetesHandler) RestartDeployment(c *gin.Context) {
+	client, ok := h.cluster(c)
+	if !ok {
+		return
+	}
+
+	namespace, name := c.Param("namespace"), c.Param("name")
+	dryRun := kubernetesDryRun(c, "deployment", namespace+"/"+name)
+
+	result, err := client.RestartDeployment(c.Request.Context(), namespace, name, dryRun)
+	if err != nil {
+		utils.ErrorResponse(c, kubernetesErrorStatus(err), "Failed to restart deployment", err.Error())
+		return
+	}
+
+	middleware.SetAuditState(c, result.Before, result.After)
+	utils.SuccessResponse(c, http.StatusOK, "Deployment restarted successfully", result)
+}
+
+// DeletePod handles DELETE /api/clusters/:cluster/kubernetes/pods/:namespace/:pod
+// The body is optional and may override the grace period.
+func (h *KubernetesHandler) DeletePod(c *gin.Context) {
+	client, ok := h.cluster(c)
+	if !ok {
+		return
+	}
+
+	var req models.DeletePodRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	namespace, name := c.Param("namespace"), c.Param("pod")
+	dryRun := kubernetesDryRun(c, "pod", namespace+"/"+name)
+
+	result, err := client.DeletePod(c.Request.Context(), namespace, name, req.GracePeriodSeconds, dryRun)
+	if err != nil {
+		utils.ErrorResponse(c, kubernetesErrorStatus(err), "Failed to delete pod", err.Error())
+		return
+	}
+
+	middleware.SetAuditState(c, result.Before, nil)
+	utils.SuccessResponse(c, http.StatusOK, "Pod deleted successfully", result)
+}
+
+// CordonNode handles POST /api/clusters/:cluster/kubernetes/nodes/:node/cordon
+func (h *KubernetesHandler) CordonNode(c *gin.Context) {
+	h.setNodeSchedulable(c, false)
+}
+
+// UncordonNode handles POST /api/clusters/:cluster/kubernetes/nodes/:node/uncordon
+func (h *KubernetesHandler) UncordonNode(c *gin.Context) {
+	h.setNodeSchedulable(c, true)
+}
+
+func (h *KubernetesHandler) setNodeSchedulable(c *gin.Context, schedulable bool) {
+	client, ok := h.cluster(c)
+	if !ok {
+		return
+	}
+
+	action := "cordon"
+	if schedulable {
+		action = "uncordon"
+	}
+
+	name := c.Param("node")
+	dryRun := kubernetesDryRun(c, "node", name)
+
+	result, err := client.CordonNode(c.Request.Context(), name, !schedulable, dryRun)
+	if err != nil {
+		utils.ErrorResponse(c, kubernetesErrorStatus(err), "Failed to "+action+" node", err.Error())
+		return
+	}
+
+	middleware.SetAuditState(c, result.Before, result.After)
+	utils.SuccessResponse(c, http.StatusOK, "Node "+action+"ed successfully", result)
+}
+
+// DrainNode handles POST /api/clusters/:cluster/kubernetes/nodes/:node/drain
+// The node is cordoned and its pods evicted; see services.KubernetesService.DrainNode.
+// The response lists every pod and is 200 even when some evictions were refused.
+func (h *KubernetesHandler) DrainNode(c *gin.Context) {
+	client, ok := h.cluster(c)
+	if !ok {
+		return
+	}
+
+	var req models.DrainNodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	name := c.Param("node")
+	dryRun := kubernetesDryRun(c, "node", name)
+
+	result, err := client.DrainNode(c.Request.Context(), name, &req, dryRun)
+	if err != nil {
+		utils.ErrorResponse(c, kubernetesErrorStatus(err), "Failed to drain node", err.Error())
+		return
+	}
+
+	middleware.SetAuditDetail(c, "evicted", result.Evicted)
+	if len(result.Failed) > 0 {
+		middleware.SetAuditDetail(c, "failed", result.Failed)
+	}
+	utils.SuccessResponse(c, http.StatusOK, "Node drained successfully", result)
+}
+
+// kubernetesDryRun sets the audit target of a change and reads ?dryRun=
+func kubernetesDryRun(c *gin.Context, targetType, targetID string) bool {
+	dryRun := c.Query("dryRun") == "true"
+	middleware.SetAuditTarget(c, targetType, targetID)
+	if dryRun {
+		middleware.SetAuditDetail(c, "dry_run", true)
+	}
+	return dryRun
+}
+
+// kubernetesErrorStatus maps an API server error to the status to return
+func kubernetesErrorStatus(err error) int {
+	switch {
+	case apierrors.IsNotFound(err), errors.Is(err, services.ErrUnknownResourceKind), errors.Is(err, services.ErrUnknownResource):
+		return http.StatusNotFound
+	case apierrors.IsForbidden(err):
+		return http.StatusForbidden
+	case apierrors.IsConflict(err), apierrors.IsAlreadyExists(err):
+		return http.StatusConflict
+	case apierrors.IsResourceExpired(err), apierrors.IsGone(err):
+		// An expired continue token; the client must list from the start
+		return http.StatusGone
+	case apierrors.IsInvalid(err):
+		return http.StatusUnprocessableEntity
+	case apierrors.IsBadRequest(err):
+		return http.StatusBadRequest
+	case apierrors.IsTooManyRequests(err):
+		return http.StatusTooManyRequests
+	case apierrors.IsMethodNotSupported(err):
+		return http.StatusMethodNotAllowed
+	}
+	return http.StatusInternalServerError
+}
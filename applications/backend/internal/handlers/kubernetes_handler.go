@@ -1,63 +1,202 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/middleware"
 	"github.com/SoumyaRaikwar/clouddeck-backend/internal/services"
 	"github.com/SoumyaRaikwar/clouddeck-backend/pkg/utils"
 )
 
 type KubernetesHandler struct {
-	service *services.KubernetesService
+	clusters  *services.ClusterService
+	terminals *TerminalBridge
 }
 
-func NewKubernetesHandler(service *services.KubernetesService) *KubernetesHandler {
+func NewKubernetesHandler(clusters *services.ClusterService, terminals *TerminalBridge) *KubernetesHandler {
 	return &KubernetesHandler{
-		service: service,
+		clusters:  clusters,
+		terminals: terminals,
 	}
 }
 
+// clusterID returns the cluster a request targets: the one named by the
+// :cluster route parameter, or the server's own cluster for the /api/kubernetes
+// routes, which only the organization owning it can use
+func (h *KubernetesHandler) clusterID(c *gin.Context) (uint, bool) {
+	if param := c.Param("cluster"); param != "" {
+		id, err := strconv.ParseUint(param, 10, 32)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid cluster ID", err.Error())
+			return 0, false
+		}
+		return uint(id), true
+	}
+
+	id, err := h.clusters.LocalClusterID(organizationID(c))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Cluster not found", err.Error())
+		return 0, false
+	}
+	return id, true
+}
+
+// cluster resolves the cluster a request targets among the clusters of the
+// caller's organization
+func (h *KubernetesHandler) cluster(c *gin.Context) (*services.KubernetesService, bool) {
+	id, ok := h.clusterID(c)
+	if !ok {
+		return nil, false
+	}
+
+	client, err := h.clusters.Client(organizationID(c), id)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, services.ErrClusterNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, services.ErrClusterUnavailable):
+			status = http.StatusServiceUnavailable
+		}
+		utils.ErrorResponse(c, status, "Kubernetes cluster unavailable", err.Error())
+		return nil, false
+	}
+	return client, true
+}
+
+// GetHealth handles GET /api/clusters/:cluster/kubernetes/health
+func (h *KubernetesHandler) GetHealth(c *gin.Context) {
+	id, ok := h.clusterID(c)
+	if !ok {
+		return
+	}
+	if _, err := h.clusters.GetCluster(organizationID(c), id); err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Cluster not found", err.Error())
+		return
+	}
+	health := h.clusters.ClusterHealth(organizationID(c), id)
+
+	utils.SuccessResponse(c, http.StatusOK, "Cluster health fetched successfully", health)
+}
+
+// GetPods handles GET /api/clusters/:cluster/kubernetes/pods
+// See kubernetesListOptions for the selector, paging and sort parameters.
 func (h *KubernetesHandler) GetPods(c *gin.Context) {
+	client, ok := h.cluster(c)
+	if !ok {
+		return
+	}
+
+	opts, err := kubernetesListOptions(c)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid list options", err.Error())
+		return
+	}
 	namespace := c.DefaultQuery("namespace", "all")
-	
-	pods, err := h.service.GetPods(namespace)
+
+	pods, meta, err := client.GetPods(namespace, opts)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch pods", err.Error())
+		utils.ErrorResponse(c, kubernetesErrorStatus(err), "Failed to fetch pods", err.Error())
 		return
 	}
 
+	setListHeaders(c, meta)
 	utils.SuccessResponse(c, http.StatusOK, "Pods fetched successfully", pods)
 }
 
+// GetPod handles GET /api/clusters/:cluster/kubernetes/pods/:namespace/:pod
+func (h *KubernetesHandler) GetPod(c *gin.Context) {
+	client, ok := h.cluster(c)
+	if !ok {
+		return
+	}
+
+	pod, err := client.GetPod(c.Request.Context(), c.Param("namespace"), c.Param("pod"))
+	if err != nil {
+		utils.ErrorResponse(c, kubernetesErrorStatus(err), "Failed to fetch pod", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Pod fetched successfully", pod)
+}
+
+// GetDeployments handles GET /api/clusters/:cluster/kubernetes/deployments
 func (h *KubernetesHandler) GetDeployments(c *gin.Context) {
+	client, ok := h.cluster(c)
+	if !ok {
+		return
+	}
+
+	opts, err := kubernetesListOptions(c)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid list options", err.Error())
+		return
+	}
 	namespace := c.DefaultQuery("namespace", "all")
-	
-	deployments, err := h.service.GetDeployments(namespace)
+
+	deployments, meta, err := client.GetDeployments(namespace, opts)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch deployments", err.Error())
+		utils.ErrorResponse(c, kubernetesErrorStatus(err), "Failed to fetch deployments", err.Error())
 		return
 	}
 
+	setListHeaders(c, meta)
 	utils.SuccessResponse(c, http.StatusOK, "Deployments fetched successfully", deployments)
 }
 
+// GetDeployment handles GET /api/clusters/:cluster/kubernetes/deployments/:namespace/:name
+func (h *KubernetesHandler) GetDeployment(c *gin.Context) {
+	client, ok := h.cluster(c)
+	if !ok {
+		return
+	}
+
+	deployment, err := client.GetDeployment(c.Request.Context(), c.Param("namespace"), c.Param("name"))
+	if err != nil {
+		utils.ErrorResponse(c, kubernetesErrorStatus(err), "Failed to fetch deployment", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Deployment fetched successfully", deployment)
+}
+
+// GetServices handles GET /api/clusters/:cluster/kubernetes/services
 func (h *KubernetesHandler) GetServices(c *gin.Context) {
+	client, ok := h.cluster(c)
+	if !ok {
+		return
+	}
+
+	opts, err := kubernetesListOptions(c)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid list options", err.Error())
+		return
+	}
 	namespace := c.DefaultQuery("namespace", "all")
-	
-	services, err := h.service.GetServices(namespace)
+
+	services, meta, err := client.GetServices(namespace, opts)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch services", err.Error())
+		utils.ErrorResponse(c, kubernetesErrorStatus(err), "Failed to fetch services", err.Error())
 		return
 	}
 
+	setListHeaders(c, meta)
 	utils.SuccessResponse(c, http.StatusOK, "Services fetched successfully", services)
 }
 
 func (h *KubernetesHandler) GetNamespaces(c *gin.Context) {
-	namespaces, err := h.service.GetNamespaces()
+	client, ok := h.cluster(c)
+	if !ok {
+		return
+	}
+
+	namespaces, err := client.GetNamespaces()
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch namespaces", err.Error())
 		return
@@ -66,14 +205,51 @@ func (h *KubernetesHandler) GetNamespaces(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, "Namespaces fetched successfully", namespaces)
 }
 
+// GetNodes handles GET /api/clusters/:cluster/kubernetes/nodes
+func (h *KubernetesHandler) GetNodes(c *gin.Context) {
+	client, ok := h.cluster(c)
+	if !ok {
+		return
+	}
+
+	nodes, err := client.ListNodes(c.Request.Context())
+	if err != nil {
+		utils.ErrorResponse(c, kubernetesErrorStatus(err), "Failed to fetch nodes", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Nodes fetched successfully", nodes)
+}
+
+// GetNode handles GET /api/clusters/:cluster/kubernetes/nodes/:node
+func (h *KubernetesHandler) GetNode(c *gin.Context) {
+	client, ok := h.cluster(c)
+	if !ok {
+		return
+	}
+
+	node, err := client.GetNode(c.Request.Context(), c.Param("node"))
+	if err != nil {
+		utils.ErrorResponse(c, kubernetesErrorStatus(err), "Failed to fetch node", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Node fetched successfully", node)
+}
+
 func (h *KubernetesHandler) GetPodLogs(c *gin.Context) {
-	namespace := c.Param("namespace")
-	podName := c.Param("pod")
-	lines := c.DefaultQuery("lines", "100")
-	
-	linesInt, _ := strconv.ParseInt(lines, 10, 64)
-	
-	logs, err := h.service.GetPodLogs(namespace, podName, linesInt)
+	client, ok := h.cluster(c)
+	if !ok {
+		return
+	}
+
+	opts, err := podLogOptions(c)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid log options", err.Error())
+		return
+	}
+
+	logs, err := client.GetPodLogs(c.Request.Context(), c.Param("namespace"), c.Param("pod"), opts)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch pod logs", err.Error())
 		return
@@ -81,3 +257,273 @@ func (h *KubernetesHandler) GetPodLogs(c *gin.Context) {
 
 	utils.SuccessResponse(c, http.StatusOK, "Logs fetched successfully", gin.H{"logs": logs})
 }
+
+// StreamPodLogs handles GET /api/clusters/:cluster/kubernetes/pods/:namespace/:pod/logs/stream
+// It follows the logs as server-sent events: one "log" event per line, then "end"
+// when the container stops or an "error" event. Closing the connection cancels
+// the upstream stream.
+func (h *KubernetesHandler) StreamPodLogs(c *gin.Context) {
+	client, ok := h.cluster(c)
+	if !ok {
+		return
+	}
+
+	opts, err := podLogOptions(c)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid log options", err.Error())
+		return
+	}
+
+	stream, err := client.StreamPodLogs(c.Request.Context(), c.Param("namespace"), c.Param("pod"), opts)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if apierrors.IsNotFound(err) {
+			status = http.StatusNotFound
+		} else if apierrors.IsBadRequest(err) {
+			status = http.StatusBadRequest
+		}
+		utils.ErrorResponse(c, status, "Failed to stream pod logs", err.Error())
+		return
+	}
+	defer stream.Close()
+
+	streamLines(c, stream)
+}
+
+// ExecPod handles GET /api/clusters/:cluster/kubernetes/pods/:namespace/:pod/exec
+// It opens an exec session in ?container= (default: the pod's default container)
+// and relays it over a WebSocket; see terminalMessage for the frame format.
+func (h *KubernetesHandler) ExecPod(c *gin.Context) {
+	client, ok := h.cluster(c)
+	if !ok {
+		return
+	}
+
+	namespace, podName := c.Param("namespace"), c.Param("pod")
+	command, tty := terminalCommand(c)
+
+	middleware.SetAuditTarget(c, "pod", namespace+"/"+podName)
+	middleware.SetAuditDetail(c, "command", command)
+	if container := c.Query("container"); container != "" {
+		middleware.SetAuditDetail(c, "container", container)
+	}
+
+	session, err := client.OpenExec(c.Request.Context(), namespace, podName, c.Query("container"), command, tty)
+	if err != nil {
+		status, ok := terminalSessionStatus(err)
+		if !ok {
+			status = http.StatusInternalServerError
+			if apierrors.IsNotFound(err) {
+				status = http.StatusNotFound
+			} else if apierrors.IsForbidden(err) {
+				status = http.StatusForbidden
+			}
+		}
+		utils.ErrorResponse(c, status, "Failed to open exec session", err.Error())
+		return
+	}
+
+	h.terminals.Attach(c, session, tty)
+}
+
+// GetEvents handles GET /api/clusters/:cluster/kubernetes/events
+// Filters: ?namespace=, ?kind= and ?name= or ?uid= of the involved object,
+// ?type= (Normal or Warning), ?sinceSeconds= or ?sinceTime= (RFC3339) and ?limit=.
+func (h *KubernetesHandler) GetEvents(c *gin.Context) {
+	client, ok := h.cluster(c)
+	if !ok {
+		return
+	}
+
+	filter, err := eventFilter(c)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid event filter", err.Error())
+		return
+	}
+
+	events, err := client.ListEvents(c.Request.Context(), filter)
+	if err != nil {
+		utils.ErrorResponse(c, kubernetesErrorStatus(err), "Failed to fetch events", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Events fetched successfully", events)
+}
+
+func eventFilter(c *gin.Context) (services.EventFilter, error) {
+	filter := services.EventFilter{
+		Namespace: c.DefaultQuery("namespace", "all"),
+		Kind:      c.Query("kind"),
+		Name:      c.Query("name"),
+		UID:       c.Query("uid"),
+		Type:      c.Query("type"),
+	}
+
+	if filter.Type != "" && filter.Type != "Normal" && filter.Type != "Warning" {
+		return filter, errors.New("type must be Normal or Warning")
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		parsed, err := strconv.Atoi(limit)
+		if err != nil || parsed <= 0 {
+			return filter, errors.New("limit must be a positive integer")
+		}
+		filter.Limit = parsed
+	}
+
+	if sinceSeconds := c.Query("sinceSeconds"); sinceSeconds != "" {
+		seconds, err := strconv.ParseInt(sinceSeconds, 10, 64)
+		if err != nil || seconds <= 0 {
+			return filter, errors.New("sinceSeconds must be a positive integer")
+		}
+		since := time.Now().Add(-time.Duration(seconds) * time.Second)
+		filter.Since = &since
+	}
+
+	if sinceTime := c.Query("sinceTime"); sinceTime != "" {
+		if filter.Since != nil {
+			return filter, errors.New("sinceSeconds and sinceTime are mutually exclusive")
+		}
+		parsed, err := time.Parse(time.RFC3339, sinceTime)
+		if err != nil {
+			return filter, errors.New("sinceTime must be an RFC3339 timestamp")
+		}
+		filter.Since = &parsed
+	}
+
+	return filter, nil
+}
+
+// Headers on a paged list response. The body stays the plain list, so clients
+// that do not page keep working.
+const (
+	ContinueHeader           = "X-Continue"
+	RemainingItemCountHeader = "X-Remaining-Item-Count"
+)
+
+// kubernetesListOptions reads the list parameters of the pod, deployment and
+// service lists: ?labelSelector=, ?fieldSelector=, ?limit=, ?continue= with
+// the ContinueHeader of the previous page, and ?sort=, e.g. -created_at
+func kubernetesListOptions(c *gin.Context) (services.ListOptions, error) {
+	opts := services.ListOptions{
+		LabelSelector: c.Query("labelSelector"),
+		FieldSelector: c.Query("fieldSelector"),
+		Continue:      c.Query("continue"),
+		Sort:          c.Query("sort"),
+	}
+	if limit := c.Query("limit"); limit != "" {
+		parsed, err := strconv.ParseInt(limit, 10, 64)
+		if err != nil || parsed <= 0 {
+			return opts, errors.New("limit must be a positive integer")
+		}
+		opts.Limit = parsed
+	}
+	return opts, nil
+}
+
+func setListHeaders(c *gin.Context, meta services.ListMeta) {
+	if meta.Continue != "" {
+		c.Header(ContinueHeader, meta.Continue)
+	}
+	if meta.RemainingItemCount != nil {
+		c.Header(RemainingItemCountHeader, strconv.FormatInt(*meta.RemainingItemCount, 10))
+	}
+}
+
+// watchPingInterval is how often WatchResources sends a keep-alive event
+const watchPingInterval = 30 * time.Second
+
+// WatchResources handles GET /api/clusters/:cluster/kubernetes/watch
+// It streams pod, deployment, service and namespace changes as server-sent
+// events, limited to ?namespace= if given. The first event, "snapshot", holds
+// the current lists; each change follows as an "added", "updated" or "deleted"
+// event carrying a services.KubernetesWatchEvent. A "resync" event means the
+// client fell behind and the stream ends; reconnect to get a fresh snapshot.
+func (h *KubernetesHandler) WatchResources(c *gin.Context) {
+	client, ok := h.cluster(c)
+	if !ok {
+		return
+	}
+
+	namespace := c.DefaultQuery("namespace", "all")
+	events, cancel, err := client.Watch(namespace)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrWatchUnavailable) {
+			status = http.StatusServiceUnavailable
+		}
+		utils.ErrorResponse(c, status, "Failed to watch cluster", err.Error())
+		return
+	}
+	defer cancel()
+
+	// Lists are taken after the watch starts, so no change is missed; a change
+	// may show up both in the snapshot and as an event
+	snapshot, err := client.Snapshot(namespace)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to watch cluster", err.Error())
+		return
+	}
+
+	startEventStream(c)
+	c.SSEvent("snapshot", snapshot)
+	c.Writer.Flush()
+
+	ticker := time.NewTicker(watchPingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-ticker.C:
+			c.SSEvent("ping", "")
+		case event, ok := <-events:
+			if !ok {
+				c.SSEvent("resync", "")
+				c.Writer.Flush()
+				return
+			}
+			c.SSEvent(event.Type, event)
+		}
+		c.Writer.Flush()
+	}
+}
+
+// podLogOptions reads ?container=, ?previous=, ?lines=, ?sinceSeconds=,
+// ?sinceTime= (RFC3339) and ?timestamps=
+func podLogOptions(c *gin.Context) (services.PodLogOptions, error) {
+	opts := services.PodLogOptions{
+		Container:  c.Query("container"),
+		Previous:   c.Query("previous") == "true",
+		Timestamps: c.Query("timestamps") == "true",
+	}
+
+	lines, err := strconv.ParseInt(c.DefaultQuery("lines", "100"), 10, 64)
+	if err != nil {
+		return opts, errors.New("lines must be an integer")
+	}
+	if lines > 0 {
+		opts.TailLines = &lines
+	}
+
+	if sinceSeconds := c.Query("sinceSeconds"); sinceSeconds != "" {
+		seconds, err := strconv.ParseInt(sinceSeconds, 10, 64)
+		if err != nil || seconds <= 0 {
+			return opts, errors.New("sinceSeconds must be a positive integer")
+		}
+		opts.SinceSeconds = &seconds
+	}
+
+	if sinceTime := c.Query("sinceTime"); sinceTime != "" {
+		if opts.SinceSeconds != nil {
+			return opts, errors.New("sinceSeconds and sinceTime are mutually exclusive")
+		}
+		parsed, err := time.Parse(time.RFC3339, sinceTime)
+		if err != nil {
+			return opts, errors.New("sinceTime must be an RFC3339 timestamp")
+		}
+		opts.SinceTime = &parsed
+	}
+
+	return opts, nil
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/services"
+	"github.com/SoumyaRaikwar/clouddeck-backend/pkg/utils"
+)
+
+// GetPodUsage handles GET /api/clusters/:cluster/kubernetes/metrics/pods
+// It reports current CPU and memory usage per pod and container against
+// requests and limits, filtered by ?namespace= and optionally by ?flag=, e.g.
+// cpu_near_limit. Without metrics-server the response has available false.
+func (h *KubernetesHandler) GetPodUsage(c *gin.Context) {
+	client, ok := h.cluster(c)
+	if !ok {
+		return
+	}
+
+	usages, err := client.GetPodUsage(c.Request.Context(), c.DefaultQuery("namespace", "all"))
+	if err == nil {
+		if flag := c.Query("flag"); flag != "" {
+			usages = slices.DeleteFunc(usages, func(usage services.PodUsage) bool {
+				return !slices.Contains(usage.Flags, flag)
+			})
+		}
+	}
+	usageResponse(c, usages, err)
+}
+
+// GetPodUsageFor handles GET /api/clusters/:cluster/kubernetes/metrics/pods/:namespace/:pod
+func (h *KubernetesHandler) GetPodUsageFor(c *gin.Context) {
+	client, ok := h.cluster(c)
+	if !ok {
+		return
+	}
+
+	usage, err := client.GetPodUsageFor(c.Request.Context(), c.Param("namespace"), c.Param("pod"))
+	usageResponse(c, usage, err)
+}
+
+// GetNodeUsage handles GET /api/clusters/:cluster/kubernetes/metrics/nodes
+func (h *KubernetesHandler) GetNodeUsage(c *gin.Context) {
+	client, ok := h.cluster(c)
+	if !ok {
+		return
+	}
+
+	usages, err := client.GetNodeUsage(c.Request.Context())
+	usageResponse(c, usages, err)
+}
+
+// usageResponse wraps usage as {"available": true, "usage": ...}. A cluster
+// without metrics-server is not an error for the client; it gets available
+// false and the reason instead.
+func usageResponse(c *gin.Context, usage interface{}, err error) {
+	if errors.Is(err, services.ErrMetricsUnavailable) {
+		utils.SuccessResponse(c, http.StatusOK, "Metrics unavailable", gin.H{
+			"available": false,
+			"reason":    err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		utils.ErrorResponse(c, kubernetesErrorStatus(err), "Failed to fetch resource usage", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Resource usage fetched successfully", gin.H{
+		"available": true,
+		"usage":     usage,
+	})
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/pkg/utils"
+)
+
+// coreGroup stands for the legacy core API group, whose name is empty, in
+// /resources/:group/:version/:resource paths
+const coreGroup = "core"
+
+// ListResources returns the handler for
+// GET /api/clusters/:cluster/kubernetes/<kind>, one of
+// services.TypedResourceKinds, filtered by ?namespace= like GetPods
+func (h *KubernetesHandler) ListResources(kind string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		client, ok := h.cluster(c)
+		if !ok {
+			return
+		}
+
+		items, err := client.ListResources(c.Request.Context(), kind, c.DefaultQuery("namespace", "all"))
+		if err != nil {
+			utils.ErrorResponse(c, kubernetesErrorStatus(err), "Failed to fetch "+kind, err.Error())
+			return
+		}
+
+		utils.SuccessResponse(c, http.StatusOK, "Resources fetched successfully", items)
+	}
+}
+
+// GetResource returns the handler for
+// GET /api/clusters/:cluster/kubernetes/<kind>/:namespace/:name
+func (h *KubernetesHandler) GetResource(kind string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		client, ok := h.cluster(c)
+		if !ok {
+			return
+		}
+
+		detail, err := client.GetResource(c.Request.Context(), kind, c.Param("namespace"), c.Param("name"))
+		if err != nil {
+			utils.ErrorResponse(c, kubernetesErrorStatus(err), "Failed to fetch resource", err.Error())
+			return
+		}
+
+		utils.SuccessResponse(c, http.StatusOK, "Resource fetched successfully", detail)
+	}
+}
+
+// GetAPIResources handles GET /api/clusters/:cluster/kubernetes/api-resources
+func (h *KubernetesHandler) GetAPIResources(c *gin.Context) {
+	client, ok := h.cluster(c)
+	if !ok {
+		return
+	}
+
+	resources, err := client.GetAPIResources()
+	if err != nil {
+		utils.ErrorResponse(c, kubernetesErrorStatus(err), "Failed to discover resources", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "API resources fetched successfully", resources)
+}
+
+// ListDynamicResources handles GET /api/clusters/:cluster/kubernetes/resources/:group/:version/:resource
+// for any resource type the cluster serves, including custom resources; use
+// "core" as the group of core resources. ?namespace= filters namespaced types.
+// Secret values are removed.
+func (h *KubernetesHandler) ListDynamicResources(c *gin.Context) {
+	client, ok := h.cluster(c)
+	if !ok {
+		return
+	}
+
+	items, err := client.ListDynamic(c.Request.Context(), resourceGVR(c), c.DefaultQuery("namespace", "all"))
+	if err != nil {
+		utils.ErrorResponse(c, kubernetesErrorStatus(err), "Failed to fetch resources", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Resources fetched successfully", items)
+}
+
+// GetDynamicResource handles GET /api/clusters/:cluster/kubernetes/resources/:group/:version/:resource/:name
+// ?namespace= is required for namespaced types.
+func (h *KubernetesHandler) GetDynamicResource(c *gin.Context) {
+	client, ok := h.cluster(c)
+	if !ok {
+		return
+	}
+
+	obj, err := client.GetDynamic(c.Request.Context(), resourceGVR(c), c.Query("namespace"), c.Param("name"))
+	if err != nil {
+		utils.ErrorResponse(c, kubernetesErrorStatus(err), "Failed to fetch resource", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Resource fetched successfully", obj)
+}
+
+func resourceGVR(c *gin.Context) schema.GroupVersionResource {
+	group := c.Param("group")
+	if group == coreGroup {
+		group = ""
+	}
+	return schema.GroupVersionResource{Group: group, Version: c.Param("version"), Resource: c.Param("resource")}
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/oauth2"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/services"
+	"github.com/SoumyaRaikwar/clouddeck-backend/pkg/utils"
+)
+
+const (
+	oidcCookieName   = "clouddeck_oidc"
+	oidcCookiePath   = "/api/auth/oidc"
+	oidcCookieMaxAge = 600
+)
+
+type OIDCHandler struct {
+	service *services.OIDCService
+	// postLoginRedirect, when set, receives the tokens in the URL fragment
+	// instead of the callback answering with JSON
+	postLoginRedirect string
+}
+
+func NewOIDCHandler(service *services.OIDCService) *OIDCHandler {
+	return &OIDCHandler{
+		service:           service,
+		postLoginRedirect: os.Getenv("OIDC_POST_LOGIN_REDIRECT"),
+	}
+}
+
+// Login handles GET /api/auth/oidc/login
+func (h *OIDCHandler) Login(c *gin.Context) {
+	state, err := randomToken()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "OIDC login failed", err.Error())
+		return
+	}
+	nonce, err := randomToken()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "OIDC login failed", err.Error())
+		return
+	}
+	codeVerifier := oauth2.GenerateVerifier()
+
+	authURL, err := h.service.AuthCodeURL(c.Request.Context(), state, nonce, codeVerifier)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadGateway, "OIDC login failed", err.Error())
+		return
+	}
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcCookieName, strings.Join([]string{state, nonce, codeVerifier}, "."), oidcCookieMaxAge, oidcCookiePath, "", c.Request.TLS != nil, true)
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// Callback handles GET /api/auth/oidc/callback
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	if providerError := c.Query("error"); providerError != "" {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "OIDC login failed", providerError+": "+c.Query("error_description"))
+		return
+	}
+
+	cookie, err := c.Cookie(oidcCookieName)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "OIDC login failed", "login session missing or expired")
+		return
+	}
+	c.SetCookie(oidcCookieName, "", -1, oidcCookiePath, "", c.Request.TLS != nil, true)
+
+	parts := strings.Split(cookie, ".")
+	if len(parts) != 3 || subtle.ConstantTimeCompare([]byte(parts[0]), []byte(c.Query("state"))) != 1 {
+		utils.ErrorResponse(c, http.StatusBadRequest, "OIDC login failed", "state mismatch")
+		return
+	}
+
+	code := c.Query("code")
+	if code == "" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "OIDC login failed", "missing authorization code")
+		return
+	}
+
+	tokens, err := h.service.Exchange(c.Request.Context(), code, parts[1], parts[2])
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "OIDC login failed", err.Error())
+		return
+	}
+
+	if h.postLoginRedirect != "" {
+		fragment := url.Values{
+			"accessToken":  {tokens.AccessToken},
+			"refreshToken": {tokens.RefreshToken},
+			"tokenType":    {tokens.TokenType},
+		}
+		c.Redirect(http.StatusFound, h.postLoginRedirect+"#"+fragment.Encode())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Logged in successfully", tokens)
+}
+
+func randomToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,326 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/database"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/oidctest"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/repositories"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/services"
+)
+
+const (
+	oidcClientID     = "clouddeck"
+	oidcClientSecret = "clouddeck-secret"
+	oidcRedirectURL  = "http://clouddeck.test/api/auth/oidc/callback"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// oidcFlow runs the login and callback endpoints against a mock issuer
+type oidcFlow struct {
+	router *gin.Engine
+	issuer *oidctest.Server
+	db     *gorm.DB
+}
+
+func newOIDCFlow(t *testing.T, linkByEmail bool) *oidcFlow {
+	t.Helper()
+	t.Setenv("JWT_SECRET", strings.Repeat("s", 32))
+	t.Setenv("OIDC_POST_LOGIN_REDIRECT", "")
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "clouddeck.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Organization{}, &models.User{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	previous := database.PostgresDB
+	database.PostgresDB = db
+	t.Cleanup(func() { database.PostgresDB = previous })
+	if err := db.Create(&models.Organization{Name: "Default", Slug: models.DefaultOrganizationSlug}).Error; err != nil {
+		t.Fatalf("create organization: %v", err)
+	}
+
+	issuer := oidctest.NewServer(oidcClientID, oidcClientSecret, map[string][]string{
+		"alice": {"clouddeck-admins"},
+		"bob":   {"clouddeck-developers"},
+	})
+	t.Cleanup(issuer.Close)
+
+	userRepo := repositories.NewUserRepository()
+	orgRepo := repositories.NewOrganizationRepository()
+	service := services.NewOIDCService(&services.OIDCConfig{
+		IssuerURL:    issuer.URL,
+		ClientID:     oidcClientID,
+		ClientSecret: oidcClientSecret,
+		RedirectURL:  oidcRedirectURL,
+		GroupsClaim:  "groups",
+		RoleMappings: map[string]string{"clouddeck-admins": models.RoleAdmin, "clouddeck-developers": models.RoleDeveloper},
+		DefaultRole:  models.RoleNone,
+		DefaultOrg:   models.DefaultOrganizationSlug,
+		LinkByEmail:  linkByEmail,
+	}, userRepo, orgRepo, services.NewAuthService(userRepo, orgRepo))
+
+	handler := NewOIDCHandler(service)
+	router := gin.New()
+	router.GET("/api/auth/oidc/login", handler.Login)
+	router.GET("/api/auth/oidc/callback", handler.Callback)
+	return &oidcFlow{router: router, issuer: issuer, db: db}
+}
+
+// authorize starts a login and signs username in at the issuer. It returns the
+// login cookie and the callback URL the issuer redirected the browser to.
+func (f *oidcFlow) authorize(t *testing.T, username string) (*http.Cookie, *url.URL) {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	f.router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/auth/oidc/login", nil))
+	if recorder.Code != http.StatusFound {
+		t.Fatalf("login: status %d: %s", recorder.Code, recorder.Body)
+	}
+	cookies := recorder.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != oidcCookieName || !cookies[0].HttpOnly {
+		t.Fatalf("login cookies = %+v, want one HttpOnly %s", cookies, oidcCookieName)
+	}
+
+	authURL, err := url.Parse(recorder.Header().Get("Location"))
+	if err != nil || !strings.HasPrefix(authURL.String(), f.issuer.URL+"/authorize") {
+		t.Fatalf("login redirected to %q, want the issuer", recorder.Header().Get("Location"))
+	}
+	query := authURL.Query()
+	if query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" || query.Get("nonce") == "" {
+		t.Fatalf("authorization request %v lacks PKCE or a nonce", query)
+	}
+	query.Set("login_hint", username)
+	authURL.RawQuery = query.Encode()
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(authURL.String())
+	if err != nil {
+		t.Fatalf("authorize: %v", err)
+	}
+	resp.Body.Close()
+	callback, err := resp.Location()
+	if err != nil {
+		t.Fatalf("authorize: status %d without redirect: %v", resp.StatusCode, err)
+	}
+	return cookies[0], callback
+}
+
+func (f *oidcFlow) callback(cookie *http.Cookie, callback *url.URL) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, callback.RequestURI(), nil)
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	recorder := httptest.NewRecorder()
+	f.router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+// login runs the whole flow for username and returns the signed-in user
+func (f *oidcFlow) login(t *testing.T, username string) *models.User {
+	t.Helper()
+	cookie, callback := f.authorize(t, username)
+	recorder := f.callback(cookie, callback)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("callback: status %d: %s", recorder.Code, recorder.Body)
+	}
+
+	var body struct {
+		Data models.TokenPair `json:"data"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode callback response: %v", err)
+	}
+	if body.Data.AccessToken == "" || body.Data.RefreshToken == "" || body.Data.User == nil {
+		t.Fatalf("callback response %s lacks tokens or the user", recorder.Body)
+	}
+	return body.Data.User
+}
+
+// withCookiePart replaces part i (state, nonce, code verifier) of the login cookie
+func withCookiePart(cookie *http.Cookie, i int, value string) *http.Cookie {
+	parts := strings.Split(cookie.Value, ".")
+	parts[i] = value
+	changed := *cookie
+	changed.Value = strings.Join(parts, ".")
+	return &changed
+}
+
+func TestOIDCLogin(t *testing.T) {
+	flow := newOIDCFlow(t, false)
+
+	user := flow.login(t, "alice")
+	if user.Username != "alice" || user.Email != "alice@example.com" || user.Role != models.RoleAdmin || user.AuthProvider != models.AuthProviderOIDC {
+		t.Fatalf("provisioned user = %+v, want alice as an OIDC admin", user)
+	}
+
+	// Later logins find the same user and keep the role in sync with the groups
+	again := flow.login(t, "alice")
+	if again.ID != user.ID {
+		t.Fatalf("second login signed in user %d, want %d", again.ID, user.ID)
+	}
+	developer := flow.login(t, "bob")
+	if developer.Role != models.RoleDeveloper {
+		t.Fatalf("bob's role = %q, want developer", developer.Role)
+	}
+
+	var count int64
+	flow.db.Model(&models.User{}).Count(&count)
+	if count != 2 {
+		t.Fatalf("%d users after three logins, want 2", count)
+	}
+}
+
+func TestOIDCCallbackRejects(t *testing.T) {
+	flow := newOIDCFlow(t, false)
+
+	tests := []struct {
+		name   string
+		tamper func(cookie *http.Cookie, callback *url.URL) (*http.Cookie, *url.URL)
+		status int
+		reason string
+	}{
+		{
+			name: "missing login cookie",
+			tamper: func(_ *http.Cookie, callback *url.URL) (*http.Cookie, *url.URL) {
+				return nil, callback
+			},
+			status: http.StatusBadRequest,
+			reason: "login session missing",
+		},
+		{
+			name: "state mismatch",
+			tamper: func(cookie *http.Cookie, callback *url.URL) (*http.Cookie, *url.URL) {
+				return withCookiePart(cookie, 0, "forged-state"), callback
+			},
+			status: http.StatusBadRequest,
+			reason: "state mismatch",
+		},
+		{
+			name: "nonce mismatch",
+			tamper: func(cookie *http.Cookie, callback *url.URL) (*http.Cookie, *url.URL) {
+				return withCookiePart(cookie, 1, "forged-nonce"), callback
+			},
+			status: http.StatusUnauthorized,
+			reason: "nonce mismatch",
+		},
+		{
+			name: "wrong code verifier",
+			tamper: func(cookie *http.Cookie, callback *url.URL) (*http.Cookie, *url.URL) {
+				return withCookiePart(cookie, 2, "forged-verifier-forged-verifier-forged-verifier"), callback
+			},
+			status: http.StatusUnauthorized,
+			reason: "code_verifier does not match",
+		},
+		{
+			name: "provider error",
+			tamper: func(cookie *http.Cookie, _ *url.URL) (*http.Cookie, *url.URL) {
+				return cookie, &url.URL{Path: "/api/auth/oidc/callback", RawQuery: "error=access_denied"}
+			},
+			status: http.StatusUnauthorized,
+			reason: "access_denied",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cookie, callback := flow.authorize(t, "alice")
+			recorder := flow.callback(tt.tamper(cookie, callback))
+			if recorder.Code != tt.status || !strings.Contains(recorder.Body.String(), tt.reason) {
+				t.Fatalf("callback: status %d: %s; want %d mentioning %q", recorder.Code, recorder.Body, tt.status, tt.reason)
+			}
+		})
+	}
+
+	var count int64
+	flow.db.Model(&models.User{}).Count(&count)
+	if count != 0 {
+		t.Fatalf("rejected logins provisioned %d users", count)
+	}
+}
+
+func TestOIDCCodeReplay(t *testing.T) {
+	flow := newOIDCFlow(t, false)
+
+	cookie, callback := flow.authorize(t, "alice")
+	if recorder := flow.callback(cookie, callback); recorder.Code != http.StatusOK {
+		t.Fatalf("callback: status %d: %s", recorder.Code, recorder.Body)
+	}
+
+	// The cookie is still at hand, e.g. because the callback URL leaked with it
+	recorder := flow.callback(cookie, callback)
+	if recorder.Code != http.StatusUnauthorized || !strings.Contains(recorder.Body.String(), "invalid_grant") {
+		t.Fatalf("replayed code: status %d: %s; want 401 invalid_grant", recorder.Code, recorder.Body)
+	}
+}
+
+func TestOIDCLinkByEmail(t *testing.T) {
+	localUser := func(username, email string) *models.User {
+		return &models.User{
+			OrganizationID: 1,
+			Username:       username,
+			Email:          email,
+			PasswordHash:   "hash",
+			AuthProvider:   models.AuthProviderLocal,
+			Role:           models.RoleViewer,
+			Active:         true,
+		}
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		flow := newOIDCFlow(t, false)
+		local := localUser("asmith", "alice@example.com")
+		flow.db.Create(local)
+
+		user := flow.login(t, "alice")
+		if user.ID == local.ID {
+			t.Fatal("OIDC login took over a local account without OIDC_LINK_BY_EMAIL")
+		}
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		flow := newOIDCFlow(t, true)
+		local := localUser("asmith", "Alice@Example.com")
+		flow.db.Create(local)
+
+		user := flow.login(t, "alice")
+		if user.ID != local.ID || user.Username != "asmith" || user.AuthProvider != models.AuthProviderOIDC || user.Role != models.RoleAdmin {
+			t.Fatalf("signed in %+v, want local user %d linked and synced to admin", user, local.ID)
+		}
+		if again := flow.login(t, "alice"); again.ID != local.ID {
+			t.Fatalf("second login signed in user %d, want linked user %d", again.ID, local.ID)
+		}
+	})
+
+	t.Run("ambiguous email", func(t *testing.T) {
+		flow := newOIDCFlow(t, true)
+		first, second := localUser("asmith", "alice@example.com"), localUser("alice-admin", "alice@example.com")
+		flow.db.Create(first)
+		flow.db.Create(second)
+
+		user := flow.login(t, "alice")
+		if user.ID == first.ID || user.ID == second.ID {
+			t.Fatalf("linked user %d although two local accounts share the email", user.ID)
+		}
+	})
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/services"
+	"github.com/SoumyaRaikwar/clouddeck-backend/pkg/utils"
+)
+
+type OrganizationHandler struct {
+	service *services.OrganizationService
+}
+
+func NewOrganizationHandler(service *services.OrganizationService) *OrganizationHandler {
+	return &OrganizationHandler{
+		service: service,
+	}
+}
+
+// GetCurrentOrganization handles GET /api/organizations/current
+func (h *OrganizationHandler) GetCurrentOrganization(c *gin.Context) {
+	org, err := h.service.GetOrganizationByID(organizationID(c))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Organization not found", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Organization fetched successfully", org)
+}
+
+// GetOrganizations handles GET /api/organizations
+func (h *OrganizationHandler) GetOrganizations(c *gin.Context) {
+	if !h.requireInstanceAdmin(c) {
+		return
+	}
+
+	orgs, err := h.service.GetAllOrganizations()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch organizations", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Organizations fetched successfully", orgs)
+}
+
+// CreateOrganization handles POST /api/organizations
+func (h *OrganizationHandler) CreateOrganization(c *gin.Context) {
+	if !h.requireInstanceAdmin(c) {
+		return
+	}
+
+	var req models.CreateOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	org, err := h.service.CreateOrganization(&req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to create organization", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Organization created successfully", org)
+}
+
+// requireInstanceAdmin only lets admins of the default organization manage tenants
+func (h *OrganizationHandler) requireInstanceAdmin(c *gin.Context) bool {
+	if !h.service.IsDefaultOrganization(organizationID(c)) {
+		utils.ErrorResponse(c, http.StatusForbidden, "Forbidden", "only admins of the default organization can manage organizations")
+		return false
+	}
+	return true
+}
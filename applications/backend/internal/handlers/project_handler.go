@@ -6,6 +6,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/middleware"
 	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
 	"github.com/SoumyaRaikwar/clouddeck-backend/internal/services"
 	"github.com/SoumyaRaikwar/clouddeck-backend/pkg/utils"
@@ -13,11 +14,13 @@ import (
 
 type ProjectHandler struct {
 	service *services.ProjectService
+	access  *services.AccessService
 }
 
-func NewProjectHandler(service *services.ProjectService) *ProjectHandler {
+func NewProjectHandler(service *services.ProjectService, access *services.AccessService) *ProjectHandler {
 	return &ProjectHandler{
 		service: service,
+		access:  access,
 	}
 }
 
@@ -28,17 +31,31 @@ func (h *ProjectHandler) CreateProject(c *gin.Context) {
 		return
 	}
 
-	project, err := h.service.CreateProject(&req)
+	project, err := h.service.CreateProject(organizationID(c), &req)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to create project", err.Error())
 		return
 	}
 
+	middleware.SetAuditTarget(c, "project", strconv.FormatUint(uint64(project.ID), 10))
+	middleware.SetAuditState(c, nil, project)
+
 	utils.SuccessResponse(c, http.StatusCreated, "Project created successfully", project)
 }
 
 func (h *ProjectHandler) GetAllProjects(c *gin.Context) {
-	projects, err := h.service.GetAllProjects()
+	ids, all, err := h.access.AccessibleProjectIDs(middleware.CurrentPrincipal(c), models.RoleViewer)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to check permissions", err.Error())
+		return
+	}
+
+	var projects []models.Project
+	if all {
+		projects, err = h.service.GetAllProjects(organizationID(c))
+	} else {
+		projects, err = h.service.GetProjectsByIDs(organizationID(c), ids)
+	}
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch projects", err.Error())
 		return
@@ -55,7 +72,11 @@ func (h *ProjectHandler) GetProject(c *gin.Context) {
 		return
 	}
 
-	project, err := h.service.GetProjectByID(uint(id))
+	if !authorizeProject(c, h.access, uint(id), models.RoleViewer) {
+		return
+	}
+
+	project, err := h.service.GetProjectByID(organizationID(c), uint(id))
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusNotFound, "Project not found", err.Error())
 		return
@@ -72,18 +93,27 @@ func (h *ProjectHandler) UpdateProject(c *gin.Context) {
 		return
 	}
 
+	if !authorizeProject(c, h.access, uint(id), models.RoleDeveloper) {
+		return
+	}
+
 	var req models.ProjectUpdateInput
 	if err := c.ShouldBindJSON(&req); err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request body", err.Error())
 		return
 	}
 
-	project, err := h.service.UpdateProject(uint(id), &req)
+	middleware.SetAuditTarget(c, "project", idParam)
+	before, _ := h.service.GetProjectByID(organizationID(c), uint(id))
+
+	project, err := h.service.UpdateProject(organizationID(c), uint(id), &req)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to update project", err.Error())
 		return
 	}
 
+	middleware.SetAuditState(c, before, project)
+
 	utils.SuccessResponse(c, http.StatusOK, "Project updated successfully", project)
 }
 
@@ -95,7 +125,11 @@ func (h *ProjectHandler) DeleteProject(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.DeleteProject(uint(id)); err != nil {
+	middleware.SetAuditTarget(c, "project", idParam)
+	before, _ := h.service.GetProjectByID(organizationID(c), uint(id))
+	middleware.SetAuditState(c, before, nil)
+
+	if err := h.service.DeleteProject(organizationID(c), uint(id)); err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to delete project", err.Error())
 		return
 	}
@@ -104,7 +138,7 @@ func (h *ProjectHandler) DeleteProject(c *gin.Context) {
 }
 
 func (h *ProjectHandler) GetProjectStats(c *gin.Context) {
-	stats, err := h.service.GetStats()
+	stats, err := h.service.GetStats(organizationID(c))
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch stats", err.Error())
 		return
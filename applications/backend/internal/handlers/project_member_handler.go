@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/middleware"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/services"
+	"github.com/SoumyaRaikwar/clouddeck-backend/pkg/utils"
+)
+
+type ProjectMemberHandler struct {
+	access *services.AccessService
+}
+
+func NewProjectMemberHandler(access *services.AccessService) *ProjectMemberHandler {
+	return &ProjectMemberHandler{
+		access: access,
+	}
+}
+
+// GetMembers handles GET /api/projects/:id/members
+func (h *ProjectMemberHandler) GetMembers(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid project ID", err.Error())
+		return
+	}
+
+	if !authorizeProject(c, h.access, uint(id), models.RoleViewer) {
+		return
+	}
+
+	members, err := h.access.GetProjectMembers(uint(id))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch members", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Members fetched successfully", members)
+}
+
+// AddMember handles POST /api/projects/:id/members
+func (h *ProjectMemberHandler) AddMember(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid project ID", err.Error())
+		return
+	}
+
+	if !authorizeProject(c, h.access, uint(id), models.RoleAdmin) {
+		return
+	}
+
+	var req models.AddProjectMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	member, err := h.access.AddProjectMember(organizationID(c), uint(id), &req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to add member", err.Error())
+		return
+	}
+
+	middleware.SetAuditTarget(c, "project_member", c.Param("id")+"/"+strconv.FormatUint(uint64(member.UserID), 10))
+	middleware.SetAuditState(c, nil, member)
+
+	utils.SuccessResponse(c, http.StatusCreated, "Member added successfully", member)
+}
+
+// RemoveMember handles DELETE /api/projects/:id/members/:userId
+func (h *ProjectMemberHandler) RemoveMember(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid project ID", err.Error())
+		return
+	}
+
+	userID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID", err.Error())
+		return
+	}
+
+	if !authorizeProject(c, h.access, uint(id), models.RoleAdmin) {
+		return
+	}
+
+	middleware.SetAuditTarget(c, "project_member", c.Param("id")+"/"+c.Param("userId"))
+
+	if err := h.access.RemoveProjectMember(uint(id), uint(userID)); err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Failed to remove member", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Member removed successfully", nil)
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/middleware"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/services"
+	"github.com/SoumyaRaikwar/clouddeck-backend/pkg/utils"
+)
+
+// StackHandler serves the Docker Compose stacks of a Docker host, picked by dockerHost
+type StackHandler struct {
+	hosts *services.DockerHostService
+}
+
+func NewStackHandler(hosts *services.DockerHostService) *StackHandler {
+	return &StackHandler{
+		hosts: hosts,
+	}
+}
+
+// GetStacks handles GET /api/docker-hosts/:host/stacks
+func (h *StackHandler) GetStacks(c *gin.Context) {
+	_, service, ok := dockerHost(c, h.hosts)
+	if !ok {
+		return
+	}
+
+	stacks, err := service.GetStacks(c.Request.Context())
+	if err != nil {
+		utils.ErrorResponse(c, dockerErrorStatus(err), "Failed to fetch stacks", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Stacks fetched successfully", stacks)
+}
+
+// GetStack handles GET /api/docker-hosts/:host/stacks/:stack
+func (h *StackHandler) GetStack(c *gin.Context) {
+	_, service, ok := dockerHost(c, h.hosts)
+	if !ok {
+		return
+	}
+
+	stack, err := service.GetStack(c.Request.Context(), c.Param("stack"))
+	if err != nil {
+		utils.ErrorResponse(c, stackErrorStatus(err), "Failed to fetch stack", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Stack fetched successfully", stack)
+}
+
+// RestartStack handles POST /api/docker-hosts/:host/stacks/:stack/restart
+func (h *StackHandler) RestartStack(c *gin.Context) {
+	h.runAction(c, services.StackActionRestart)
+}
+
+// RecreateStack handles POST /api/docker-hosts/:host/stacks/:stack/recreate
+// Each container is replaced by a new one with the same settings, picking up
+// the current image for its tag ({"pull": true} pulls it first).
+func (h *StackHandler) RecreateStack(c *gin.Context) {
+	h.runAction(c, services.StackActionRecreate)
+}
+
+// RestartService handles POST /api/docker-hosts/:host/stacks/:stack/services/:service/restart
+func (h *StackHandler) RestartService(c *gin.Context) {
+	h.runAction(c, services.StackActionRestart)
+}
+
+// RecreateService handles POST /api/docker-hosts/:host/stacks/:stack/services/:service/recreate
+func (h *StackHandler) RecreateService(c *gin.Context) {
+	h.runAction(c, services.StackActionRecreate)
+}
+
+func (h *StackHandler) runAction(c *gin.Context, action string) {
+	_, service, ok := dockerHost(c, h.hosts)
+	if !ok {
+		return
+	}
+
+	var req models.StackActionRequest
+	// The body is optional; an empty one leaves every option at its default
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	stackName, serviceName := c.Param("stack"), c.Param("service")
+	target := stackName
+	if serviceName != "" {
+		target += "/" + serviceName
+	}
+	middleware.SetAuditTarget(c, "stack", target)
+	middleware.SetAuditDetail(c, "action", action)
+	if req.Pull {
+		middleware.SetAuditDetail(c, "pull", true)
+	}
+
+	result, err := service.RunStackAction(c.Request.Context(), stackName, serviceName, action, &req)
+	if result != nil {
+		middleware.SetAuditDetail(c, "containers", result.Containers)
+	}
+	if err != nil {
+		utils.ErrorResponse(c, stackErrorStatus(err), "Failed to "+action+" stack", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Stack "+action+" succeeded", result)
+}
+
+func stackErrorStatus(err error) int {
+	if errors.Is(err, services.ErrStackNotFound) || errors.Is(err, services.ErrStackServiceNotFound) {
+		return http.StatusNotFound
+	}
+	return dockerErrorStatus(err)
+}
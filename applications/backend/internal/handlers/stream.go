@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxStreamLine is the longest log line streamLines forwards in one event
+const maxStreamLine = 1 << 20
+
+// startEventStream writes the headers for a server-sent event response
+func startEventStream(c *gin.Context) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// Disable response buffering in nginx-style proxies
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+}
+
+// streamLines relays r line by line as "log" events until it ends or the client
+// goes away, then sends "end" (or "error" if reading failed)
+func streamLines(c *gin.Context, r io.Reader) {
+	startEventStream(c)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxStreamLine)
+	for scanner.Scan() {
+		c.SSEvent("log", scanner.Text())
+		c.Writer.Flush()
+	}
+
+	if c.Request.Context().Err() != nil {
+		return
+	}
+	if err := scanner.Err(); err != nil {
+		c.SSEvent("error", err.Error())
+	} else {
+		c.SSEvent("end", "")
+	}
+	c.Writer.Flush()
+}
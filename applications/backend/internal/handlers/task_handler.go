@@ -6,6 +6,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/middleware"
 	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
 	"github.com/SoumyaRaikwar/clouddeck-backend/internal/services"
 	"github.com/SoumyaRaikwar/clouddeck-backend/pkg/utils"
@@ -13,11 +14,13 @@ import (
 
 type TaskHandler struct {
 	service *services.TaskService
+	access  *services.AccessService
 }
 
-func NewTaskHandler(service *services.TaskService) *TaskHandler {
+func NewTaskHandler(service *services.TaskService, access *services.AccessService) *TaskHandler {
 	return &TaskHandler{
 		service: service,
+		access:  access,
 	}
 }
 
@@ -28,12 +31,19 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 		return
 	}
 
-	task, err := h.service.CreateTask(&req)
+	if !authorizeProject(c, h.access, req.ProjectID, models.RoleDeveloper) {
+		return
+	}
+
+	task, err := h.service.CreateTask(organizationID(c), &req)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to create task", err.Error())
 		return
 	}
 
+	middleware.SetAuditTarget(c, "task", strconv.FormatUint(uint64(task.ID), 10))
+	middleware.SetAuditState(c, nil, task)
+
 	utils.SuccessResponse(c, http.StatusCreated, "Task created successfully", task)
 }
 
@@ -47,7 +57,11 @@ func (h *TaskHandler) GetAllTasks(c *gin.Context) {
 			return
 		}
 
-		tasks, err := h.service.GetTasksByProjectID(uint(projectID))
+		if !authorizeProject(c, h.access, uint(projectID), models.RoleViewer) {
+			return
+		}
+
+		tasks, err := h.service.GetTasksByProjectID(organizationID(c), uint(projectID))
 		if err != nil {
 			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch tasks", err.Error())
 			return
@@ -57,7 +71,18 @@ func (h *TaskHandler) GetAllTasks(c *gin.Context) {
 		return
 	}
 
-	tasks, err := h.service.GetAllTasks()
+	ids, all, err := h.access.AccessibleProjectIDs(middleware.CurrentPrincipal(c), models.RoleViewer)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to check permissions", err.Error())
+		return
+	}
+
+	var tasks []models.Task
+	if all {
+		tasks, err = h.service.GetAllTasks(organizationID(c))
+	} else {
+		tasks, err = h.service.GetTasksByProjectIDs(organizationID(c), ids)
+	}
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch tasks", err.Error())
 		return
@@ -74,12 +99,16 @@ func (h *TaskHandler) GetTask(c *gin.Context) {
 		return
 	}
 
-	task, err := h.service.GetTaskByID(uint(id))
+	task, err := h.service.GetTaskByID(organizationID(c), uint(id))
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusNotFound, "Task not found", err.Error())
 		return
 	}
 
+	if !authorizeProject(c, h.access, task.ProjectID, models.RoleViewer) {
+		return
+	}
+
 	utils.SuccessResponse(c, http.StatusOK, "Task fetched successfully", task)
 }
 
@@ -91,18 +120,26 @@ func (h *TaskHandler) UpdateTask(c *gin.Context) {
 		return
 	}
 
+	before, ok := h.authorizeTask(c, uint(id), models.RoleDeveloper)
+	if !ok {
+		return
+	}
+	middleware.SetAuditTarget(c, "task", idParam)
+
 	var req models.UpdateTaskRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request body", err.Error())
 		return
 	}
 
-	task, err := h.service.UpdateTask(uint(id), &req)
+	task, err := h.service.UpdateTask(organizationID(c), uint(id), &req)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to update task", err.Error())
 		return
 	}
 
+	middleware.SetAuditState(c, before, task)
+
 	utils.SuccessResponse(c, http.StatusOK, "Task updated successfully", task)
 }
 
@@ -114,7 +151,14 @@ func (h *TaskHandler) DeleteTask(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.DeleteTask(uint(id)); err != nil {
+	before, ok := h.authorizeTask(c, uint(id), models.RoleDeveloper)
+	if !ok {
+		return
+	}
+	middleware.SetAuditTarget(c, "task", idParam)
+	middleware.SetAuditState(c, before, nil)
+
+	if err := h.service.DeleteTask(organizationID(c), uint(id)); err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to delete task", err.Error())
 		return
 	}
@@ -123,7 +167,7 @@ func (h *TaskHandler) DeleteTask(c *gin.Context) {
 }
 
 func (h *TaskHandler) GetTaskStats(c *gin.Context) {
-	stats, err := h.service.GetStats()
+	stats, err := h.service.GetStats(organizationID(c))
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch stats", err.Error())
 		return
@@ -131,3 +175,14 @@ func (h *TaskHandler) GetTaskStats(c *gin.Context) {
 
 	utils.SuccessResponse(c, http.StatusOK, "Stats fetched successfully", stats)
 }
+
+// authorizeTask checks the caller's role on the project that owns the task and
+// returns the task's current state
+func (h *TaskHandler) authorizeTask(c *gin.Context, id uint, role string) (*models.Task, bool) {
+	task, err := h.service.GetTaskByID(organizationID(c), id)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Task not found", err.Error())
+		return nil, false
+	}
+	return task, authorizeProject(c, h.access, task.ProjectID, role)
+}
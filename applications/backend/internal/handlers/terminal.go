@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/middleware"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/services"
+)
+
+const (
+	terminalPingInterval = 30 * time.Second
+	terminalWriteTimeout = 10 * time.Second
+	// maxTerminalMessage bounds a single client message (stdin chunk or resize)
+	maxTerminalMessage = 64 * 1024
+)
+
+// terminalMessage is the JSON frame exchanged over a terminal WebSocket.
+// The client sends {"type":"stdin","data":"..."} and {"type":"resize","cols":80,"rows":24};
+// the server sends "stdout", "stderr", then {"type":"exit","code":0} or an "error".
+type terminalMessage struct {
+	Type string `json:"type"`
+	Data string `json:"data,omitempty"`
+	Cols uint16 `json:"cols,omitempty"`
+	Rows uint16 `json:"rows,omitempty"`
+	Code *int   `json:"code,omitempty"`
+}
+
+// TerminalBridge upgrades requests to WebSockets and relays them to exec sessions
+type TerminalBridge struct {
+	upgrader websocket.Upgrader
+}
+
+// NewTerminalBridge accepts WebSocket connections from allowedOrigins, from the
+// API's own host, and from non-browser clients that send no Origin
+func NewTerminalBridge(allowedOrigins []string) *TerminalBridge {
+	allowed := make(map[string]bool, len(allowedOrigins))
+	for _, origin := range allowedOrigins {
+		allowed[strings.TrimSuffix(origin, "/")] = true
+	}
+
+	return &TerminalBridge{
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  4096,
+			WriteBufferSize: 4096,
+			CheckOrigin: func(r *http.Request) bool {
+				origin := r.Header.Get("Origin")
+				if origin == "" || allowed[origin] {
+					return true
+				}
+				originURL, err := url.Parse(origin)
+				return err == nil && strings.EqualFold(originURL.Host, r.Host)
+			},
+		},
+	}
+}
+
+// terminalCommand reads ?command= (repeatable, one argument each, default
+// /bin/sh) and ?tty= (default true)
+func terminalCommand(c *gin.Context) ([]string, bool) {
+	command := c.QueryArray("command")
+	if len(command) == 0 {
+		command = []string{"/bin/sh"}
+	}
+	return command, c.DefaultQuery("tty", "true") != "false"
+}
+
+// Attach upgrades the request and runs session until the command exits or the
+// client disconnects. Errors before this point are plain HTTP responses; after
+// it they are sent as "error" frames.
+func (b *TerminalBridge) Attach(c *gin.Context, session services.TerminalSession, tty bool) {
+	conn, err := b.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// The upgrader has already replied with an HTTP error
+		return
+	}
+	defer conn.Close()
+	conn.SetReadLimit(maxTerminalMessage)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	out := &terminalConn{conn: conn}
+	stdin, stdinWriter := io.Pipe()
+	defer stdin.Close()
+	resize := make(chan services.TerminalSize, 8)
+
+	go func() {
+		defer cancel()
+		defer close(resize)
+		defer stdinWriter.Close()
+
+		for {
+			var msg terminalMessage
+			if err := conn.ReadJSON(&msg); err != nil {
+				return
+			}
+			switch msg.Type {
+			case "stdin":
+				if _, err := stdinWriter.Write([]byte(msg.Data)); err != nil {
+					return
+				}
+			case "resize":
+				if msg.Cols == 0 || msg.Rows == 0 {
+					continue
+				}
+				select {
+				case resize <- services.TerminalSize{Width: msg.Cols, Height: msg.Rows}:
+				default:
+					// Drop bursts of resizes; the next one carries the final size
+				}
+			}
+		}
+	}()
+
+	go func() {
+		ticker := time.NewTicker(terminalPingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := out.ping(); err != nil {
+					cancel()
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	code, err := session.Run(ctx, services.TerminalStreams{
+		Stdin:  stdin,
+		Stdout: &terminalWriter{conn: out, stream: "stdout"},
+		Stderr: &terminalWriter{conn: out, stream: "stderr"},
+		Resize: resize,
+		Tty:    tty,
+	})
+	if ctx.Err() != nil {
+		middleware.SetAuditDetail(c, "outcome", "client disconnected")
+		return
+	}
+
+	if err != nil {
+		middleware.SetAuditDetail(c, "error", err.Error())
+		out.send(terminalMessage{Type: "error", Data: err.Error()})
+	} else {
+		middleware.SetAuditDetail(c, "exit_code", code)
+		out.send(terminalMessage{Type: "exit", Code: &code})
+	}
+	out.close()
+}
+
+// terminalSessionStatus maps errors from opening an exec session to HTTP statuses
+func terminalSessionStatus(err error) (int, bool) {
+	switch {
+	case errors.Is(err, services.ErrInvalidExecRequest):
+		return http.StatusBadRequest, true
+	case errors.Is(err, services.ErrExecTargetNotRunning):
+		return http.StatusConflict, true
+	}
+	return 0, false
+}
+
+// terminalConn serializes writes; gorilla/websocket allows one writer at a time
+type terminalConn struct {
+	mu   sync.Mutex
+	conn *websocket.Conn
+}
+
+func (t *terminalConn) send(msg terminalMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.conn.SetWriteDeadline(time.Now().Add(terminalWriteTimeout))
+	return t.conn.WriteMessage(websocket.TextMessage, data)
+}
+
+func (t *terminalConn) ping() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(terminalWriteTimeout))
+}
+
+func (t *terminalConn) close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	message := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+	t.conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(terminalWriteTimeout))
+}
+
+// terminalWriter sends output as frames of one stream. A multi-byte UTF-8
+// character split across writes is held back until it is complete, since JSON
+// strings cannot carry partial characters.
+type terminalWriter struct {
+	conn    *terminalConn
+	stream  string
+	pending []byte
+}
+
+func (w *terminalWriter) Write(p []byte) (int, error) {
+	data := append(w.pending, p...)
+	w.pending = nil
+
+	// Hold back an incomplete character at the end (at most 3 bytes)
+	for i := len(data) - 1; i >= 0 && i >= len(data)-3; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				w.pending = append([]byte(nil), data[i:]...)
+				data = data[:i]
+			}
+			break
+		}
+	}
+
+	if len(data) > 0 {
+		if err := w.conn.send(terminalMessage{Type: w.stream, Data: string(data)}); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
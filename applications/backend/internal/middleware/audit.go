@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/services"
+	"github.com/SoumyaRaikwar/clouddeck-backend/pkg/utils"
+)
+
+const auditEntryKey = "auditEntry"
+
+// Audit records every mutating request (POST, PUT, PATCH, DELETE) and every
+// WebSocket session with its actor, route, target entity and outcome. Handlers
+// enrich the entry through SetAuditTarget and SetAuditState. It must run after
+// AuthRequired.
+func Audit(auditService *services.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		default:
+			if !websocket.IsWebSocketUpgrade(c.Request) {
+				c.Next()
+				return
+			}
+		}
+
+		startTime := time.Now()
+		entry := &models.AuditLog{
+			Timestamp: startTime.UTC(),
+			Method:    c.Request.Method,
+			Route:     c.FullPath(),
+			Path:      c.Request.URL.Path,
+			Action:    c.Request.Method + " " + c.FullPath(),
+			ClientIP:  c.ClientIP(),
+		}
+		if principal := CurrentPrincipal(c); principal != nil {
+			entry.OrganizationID = principal.OrganizationID
+			entry.ActorID = principal.UserID
+			entry.ActorName = principal.Username
+			entry.APIKeyID = principal.APIKeyID
+		}
+		c.Set(auditEntryKey, entry)
+
+		c.Next()
+
+		if entry.EntityType == "" {
+			entry.EntityType = entityTypeFromRoute(c.FullPath())
+		}
+		if entry.EntityID == "" {
+			entry.EntityID = c.Param("id")
+		}
+
+		entry.StatusCode = c.Writer.Status()
+		entry.DurationMs = time.Since(startTime).Milliseconds()
+		entry.Outcome = models.AuditOutcomeSuccess
+		if entry.StatusCode >= http.StatusBadRequest {
+			entry.Outcome = models.AuditOutcomeFailure
+			if errMessage, ok := c.Get(utils.ErrorContextKey); ok {
+				entry.Error, _ = errMessage.(string)
+			}
+			// A failed request changed nothing, whatever state the handler attached
+			entry.After = nil
+		}
+
+		auditService.Record(entry)
+	}
+}
+
+// SetAuditTarget names the entity a mutating request acted on
+func SetAuditTarget(c *gin.Context, entityType string, entityID string) {
+	if entry := auditEntry(c); entry != nil {
+		entry.EntityType = entityType
+		entry.EntityID = entityID
+	}
+}
+
+// SetAuditState attaches the entity state before and after the change; either may be nil
+func SetAuditState(c *gin.Context, before interface{}, after interface{}) {
+	if entry := auditEntry(c); entry != nil {
+		entry.Before = services.AuditSnapshot(before)
+		entry.After = services.AuditSnapshot(after)
+	}
+}
+
+// SetAuditDetail adds free-form context such as a requested replica count
+func SetAuditDetail(c *gin.Context, key string, value interface{}) {
+	if entry := auditEntry(c); entry != nil {
+		if entry.Details == nil {
+			entry.Details = make(map[string]interface{})
+		}
+		entry.Details[key] = value
+	}
+}
+
+func auditEntry(c *gin.Context) *models.AuditLog {
+	value, ok := c.Get(auditEntryKey)
+	if !ok {
+		return nil
+	}
+	entry, _ := value.(*models.AuditLog)
+	return entry
+}
+
+// entityTypeFromRoute derives a fallback entity type from the first path
+// segment after /api, e.g. /api/tasks/:id -> tasks
+func entityTypeFromRoute(route string) string {
+	parts := strings.Split(strings.TrimPrefix(route, "/api/"), "/")
+	if len(parts) == 0 {
+		return ""
+	}
+	return parts[0]
+}
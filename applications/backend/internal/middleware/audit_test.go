@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/repositories"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/services"
+	"github.com/SoumyaRaikwar/clouddeck-backend/pkg/utils"
+)
+
+// auditRequest sends method path through Audit as principal and returns the
+// entry the middleware recorded, or nil when it recorded none
+func auditRequest(t *testing.T, principal *models.Principal, method, route, path string, handler func(*gin.Context)) *models.AuditLog {
+	t.Helper()
+
+	// Without MongoDB the entry is dropped after Record, but it is complete by then
+	audit := services.NewAuditService(repositories.NewAuditRepository())
+
+	var entry *models.AuditLog
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(principalKey, principal)
+		c.Next()
+	}, Audit(audit))
+	router.Handle(method, route, func(c *gin.Context) {
+		entry = auditEntry(c)
+		handler(c)
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, path, nil))
+	return entry
+}
+
+func TestAuditRecordsActorAndState(t *testing.T) {
+	principal := &models.Principal{UserID: 7, Username: "ada", Role: models.RoleAdmin, OrganizationID: 3, APIKeyID: 9}
+	before := &models.User{ID: 12, Username: "bob", Role: models.RoleViewer, PasswordHash: "$2a$10$hash", ExternalID: "sub-1"}
+	after := *before
+	after.Role = models.RoleOperator
+
+	entry := auditRequest(t, principal, http.MethodPut, "/api/users/:id/role", "/api/users/12/role", func(c *gin.Context) {
+		SetAuditTarget(c, "user", c.Param("id"))
+		SetAuditState(c, before, &after)
+		utils.SuccessResponse(c, http.StatusOK, "Role updated successfully", after)
+	})
+	if entry == nil {
+		t.Fatal("no audit entry for a PUT")
+	}
+
+	if entry.ActorID != 7 || entry.ActorName != "ada" || entry.APIKeyID != 9 || entry.OrganizationID != 3 {
+		t.Fatalf("entry actor = %d %q (key %d) in organization %d, want ada (7, key 9) in 3",
+			entry.ActorID, entry.ActorName, entry.APIKeyID, entry.OrganizationID)
+	}
+	if entry.Action != "PUT /api/users/:id/role" || entry.EntityType != "user" || entry.EntityID != "12" {
+		t.Fatalf("entry = %s on %s/%s, want PUT /api/users/:id/role on user/12", entry.Action, entry.EntityType, entry.EntityID)
+	}
+	if entry.Outcome != models.AuditOutcomeSuccess || entry.StatusCode != http.StatusOK {
+		t.Fatalf("entry outcome = %s (%d), want success", entry.Outcome, entry.StatusCode)
+	}
+
+	for _, snapshot := range []map[string]interface{}{entry.Before, entry.After} {
+		for _, field := range []string{"passwordHash", "PasswordHash", "externalId", "ExternalID"} {
+			if _, ok := snapshot[field]; ok {
+				t.Fatalf("snapshot %v contains the redacted field %s", snapshot, field)
+			}
+		}
+	}
+	if len(entry.Changes) != 1 || entry.Changes[0].Field != "role" ||
+		entry.Changes[0].Before != models.RoleViewer || entry.Changes[0].After != models.RoleOperator {
+		t.Fatalf("entry changes = %+v, want only role viewer -> operator", entry.Changes)
+	}
+}
+
+func TestAuditFailedRequestHasNoAfterState(t *testing.T) {
+	principal := &models.Principal{UserID: 7, Username: "ada", Role: models.RoleAdmin, OrganizationID: 3}
+	before := &models.Project{ID: 4, Name: "api"}
+
+	entry := auditRequest(t, principal, http.MethodDelete, "/api/projects/:id", "/api/projects/4", func(c *gin.Context) {
+		SetAuditState(c, before, &models.Project{ID: 4, Name: "renamed"})
+		utils.ErrorResponse(c, http.StatusConflict, "Failed to delete project", "project has tasks")
+	})
+	if entry == nil {
+		t.Fatal("no audit entry for a DELETE")
+	}
+
+	if entry.Outcome != models.AuditOutcomeFailure || entry.StatusCode != http.StatusConflict {
+		t.Fatalf("entry outcome = %s (%d), want failure", entry.Outcome, entry.StatusCode)
+	}
+	if entry.Error != "Failed to delete project: project has tasks" {
+		t.Fatalf("entry error = %q", entry.Error)
+	}
+	if entry.After != nil || entry.Changes != nil {
+		t.Fatalf("failed request recorded after state %v and changes %v", entry.After, entry.Changes)
+	}
+	if entry.Before["name"] != "api" {
+		t.Fatalf("entry before = %v, want the project as it was", entry.Before)
+	}
+	if entry.EntityType != "projects" || entry.EntityID != "4" {
+		t.Fatalf("entry target = %s/%s, want the route's projects/4", entry.EntityType, entry.EntityID)
+	}
+}
+
+func TestAuditSkipsReads(t *testing.T) {
+	entry := auditRequest(t, &models.Principal{UserID: 7}, http.MethodGet, "/api/projects", "/api/projects", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	if entry != nil {
+		t.Fatalf("GET recorded an audit entry: %+v", entry)
+	}
+}
@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/services"
+	"github.com/SoumyaRaikwar/clouddeck-backend/pkg/utils"
+)
+
+const principalKey = "principal"
+
+// AuthRequired rejects requests that do not carry a valid bearer access token or
+// API key. API keys are additionally limited to their scopes, matched against the
+// first path segment after /api: GET and HEAD need read access, anything else
+// (and WebSocket upgrades, which open interactive sessions) write.
+func AuthRequired(authService *services.AuthService, apiKeyService *services.APIKeyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := bearerToken(c)
+		if token == "" {
+			utils.ErrorResponse(c, http.StatusUnauthorized, "Authentication required", "missing bearer token")
+			c.Abort()
+			return
+		}
+
+		var principal *models.Principal
+		var err error
+		if strings.HasPrefix(token, models.APIKeyPrefix) {
+			principal, err = apiKeyService.Authenticate(token)
+		} else {
+			principal, err = authService.ValidateAccessToken(token)
+		}
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusUnauthorized, "Authentication required", err.Error())
+			c.Abort()
+			return
+		}
+
+		if principal.APIKeyID != 0 {
+			resource, write := requiredScope(c)
+			if !models.ScopeAllows(principal.Scopes, resource, write) {
+				access := "read"
+				if write {
+					access = "write"
+				}
+				utils.ErrorResponse(c, http.StatusForbidden, "Forbidden", "api key lacks scope "+resource+":"+access)
+				c.Abort()
+				return
+			}
+		}
+
+		c.Set(principalKey, principal)
+		c.Next()
+	}
+}
+
+// RequireRole rejects callers whose global role is below role.
+// It must run after AuthRequired.
+func RequireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := CurrentPrincipal(c)
+		if principal == nil || !models.RoleAtLeast(principal.Role, role) {
+			utils.ErrorResponse(c, http.StatusForbidden, "Forbidden", "requires role "+role)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// CurrentPrincipal returns the caller attached by AuthRequired, or nil
+func CurrentPrincipal(c *gin.Context) *models.Principal {
+	value, ok := c.Get(principalKey)
+	if !ok {
+		return nil
+	}
+	principal, _ := value.(*models.Principal)
+	return principal
+}
+
+// scopeMounts are the route groups that mount another resource's routes under
+// one of their items, e.g. /api/clusters/:cluster/kubernetes/pods. Those routes
+// need the scope of the mounted resource, here kubernetes.
+var scopeMounts = map[string]bool{
+	"clusters":     true,
+	"docker-hosts": true,
+}
+
+// requiredScope returns the scope resource for the matched route and whether the
+// request needs write access
+func requiredScope(c *gin.Context) (string, bool) {
+	segments := strings.Split(strings.TrimPrefix(c.FullPath(), "/api/"), "/")
+	resource := segments[0]
+	if scopeMounts[resource] && len(segments) > 2 && strings.HasPrefix(segments[1], ":") {
+		resource = segments[2]
+	}
+	write := c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead
+	return resource, write || websocket.IsWebSocketUpgrade(c.Request)
+}
+
+// bearerToken reads the Authorization header. Browsers cannot set headers on
+// WebSocket handshakes, so those may pass the token as ?access_token= instead.
+func bearerToken(c *gin.Context) string {
+	header := c.GetHeader("Authorization")
+	if len(header) > 7 && strings.EqualFold(header[:7], "Bearer ") {
+		return strings.TrimSpace(header[7:])
+	}
+	if websocket.IsWebSocketUpgrade(c.Request) {
+		return c.Query("access_token")
+	}
+	return ""
+}
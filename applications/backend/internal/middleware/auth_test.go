@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func TestRequiredScope(t *testing.T) {
+	tests := []struct {
+		name      string
+		method    string
+		route     string
+		path      string
+		websocket bool
+		resource  string
+		write     bool
+	}{
+		{"list projects", http.MethodGet, "/api/projects", "/api/projects", false, "projects", false},
+		{"head project", http.MethodHead, "/api/projects/:id", "/api/projects/1", false, "projects", false},
+		{"create project", http.MethodPost, "/api/projects", "/api/projects", false, "projects", true},
+		{"delete task", http.MethodDelete, "/api/tasks/:id", "/api/tasks/1", false, "tasks", true},
+		{"list clusters", http.MethodGet, "/api/clusters", "/api/clusters", false, "clusters", false},
+		{"delete cluster", http.MethodDelete, "/api/clusters/:cluster", "/api/clusters/3", false, "clusters", true},
+		{"cluster health", http.MethodGet, "/api/clusters/:cluster/kubernetes/health", "/api/clusters/3/kubernetes/health", false, "kubernetes", false},
+		{"cluster pods", http.MethodGet, "/api/clusters/:cluster/kubernetes/pods", "/api/clusters/3/kubernetes/pods", false, "kubernetes", false},
+		{"scale deployment", http.MethodPost, "/api/clusters/:cluster/kubernetes/deployments/:namespace/:name/scale", "/api/clusters/3/kubernetes/deployments/default/web/scale", false, "kubernetes", true},
+		{"pod terminal", http.MethodGet, "/api/clusters/:cluster/kubernetes/pods/:namespace/:pod/exec", "/api/clusters/3/kubernetes/pods/default/web/exec", true, "kubernetes", true},
+		{"local cluster pods", http.MethodGet, "/api/kubernetes/pods", "/api/kubernetes/pods", false, "kubernetes", false},
+		{"local cluster drain", http.MethodPost, "/api/kubernetes/nodes/:node/drain", "/api/kubernetes/nodes/worker-1/drain", false, "kubernetes", true},
+		{"fleet containers", http.MethodGet, "/api/docker-hosts/containers", "/api/docker-hosts/containers", false, "docker-hosts", false},
+		{"get docker host", http.MethodGet, "/api/docker-hosts/:host", "/api/docker-hosts/2", false, "docker-hosts", false},
+		{"host containers", http.MethodGet, "/api/docker-hosts/:host/containers", "/api/docker-hosts/2/containers", false, "containers", false},
+		{"container action", http.MethodPost, "/api/docker-hosts/:host/containers/:id/stop", "/api/docker-hosts/2/containers/abc/stop", false, "containers", true},
+		{"container terminal", http.MethodGet, "/api/docker-hosts/:host/containers/:id/exec", "/api/docker-hosts/2/containers/abc/exec", true, "containers", true},
+		{"local host containers", http.MethodGet, "/api/containers", "/api/containers", false, "containers", false},
+		{"local host image prune", http.MethodPost, "/api/images/prune", "/api/images/prune", false, "images", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resource string
+			var write bool
+			router := gin.New()
+			router.Handle(tt.method, tt.route, func(c *gin.Context) {
+				resource, write = requiredScope(c)
+			})
+
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.websocket {
+				req.Header.Set("Connection", "Upgrade")
+				req.Header.Set("Upgrade", "websocket")
+			}
+			router.ServeHTTP(httptest.NewRecorder(), req)
+
+			if resource != tt.resource || write != tt.write {
+				t.Fatalf("requiredScope = (%q, %v), want (%q, %v)", resource, write, tt.resource, tt.write)
+			}
+		})
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	tests := []struct {
+		name      string
+		principal *models.Principal
+		required  string
+		status    int
+	}{
+		{"no principal", nil, models.RoleViewer, http.StatusForbidden},
+		{"none below viewer", &models.Principal{Role: models.RoleNone}, models.RoleViewer, http.StatusForbidden},
+		{"viewer is viewer", &models.Principal{Role: models.RoleViewer}, models.RoleViewer, http.StatusOK},
+		{"viewer below operator", &models.Principal{Role: models.RoleViewer}, models.RoleOperator, http.StatusForbidden},
+		{"developer below operator", &models.Principal{Role: models.RoleDeveloper}, models.RoleOperator, http.StatusForbidden},
+		{"operator is operator", &models.Principal{Role: models.RoleOperator}, models.RoleOperator, http.StatusOK},
+		{"admin above operator", &models.Principal{Role: models.RoleAdmin}, models.RoleOperator, http.StatusOK},
+		{"operator below admin", &models.Principal{Role: models.RoleOperator}, models.RoleAdmin, http.StatusForbidden},
+		{"unknown role", &models.Principal{Role: "root"}, models.RoleViewer, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reached := false
+			router := gin.New()
+			router.GET("/api/users", func(c *gin.Context) {
+				if tt.principal != nil {
+					c.Set(principalKey, tt.principal)
+				}
+			}, RequireRole(tt.required), func(c *gin.Context) {
+				reached = true
+				c.Status(http.StatusOK)
+			})
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/users", nil))
+
+			if recorder.Code != tt.status {
+				t.Fatalf("status = %d, want %d", recorder.Code, tt.status)
+			}
+			if reached != (tt.status == http.StatusOK) {
+				t.Fatalf("handler reached = %v with status %d", reached, recorder.Code)
+			}
+		})
+	}
+}
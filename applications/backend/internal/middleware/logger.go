@@ -2,6 +2,8 @@ package middleware
 
 import (
 	"log"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -22,10 +24,38 @@ func Logger() gin.HandlerFunc {
 		log.Printf(
 			"[%s] %s %s | Status: %d | Latency: %v",
 			c.Request.Method,
-			c.Request.RequestURI,
+			redactURI(c.Request.RequestURI),
 			c.ClientIP(),
 			c.Writer.Status(),
 			latency,
 		)
 	}
-}
\ No newline at end of file
+}
+
+// sensitiveParams are query parameters whose values must never reach the logs
+var sensitiveParams = []string{"token", "access_token", "secret", "password", "code", "state"}
+
+// redactURI masks sensitive query parameter values in a request URI
+func redactURI(uri string) string {
+	path, rawQuery, found := strings.Cut(uri, "?")
+	if !found {
+		return uri
+	}
+
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return path + "?[unparseable query]"
+	}
+
+	redacted := false
+	for _, key := range sensitiveParams {
+		if _, ok := query[key]; ok {
+			query.Set(key, "REDACTED")
+			redacted = true
+		}
+	}
+	if !redacted {
+		return uri
+	}
+	return path + "?" + query.Encode()
+}
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// APIKeyPrefix marks bearer tokens that are API keys rather than JWTs
+	APIKeyPrefix = "cdk_"
+
+	// APIKeyScopeAll grants every scope the key's role allows
+	APIKeyScopeAll = "*"
+)
+
+// APIKey authenticates machine clients such as CI bots. Only a SHA-256 hash of
+// the key is stored; the plaintext is returned once, when the key is created.
+// Keys with a UserID act as that user; organization keys (UserID nil) act with
+// their own Role.
+type APIKey struct {
+	ID             uint           `gorm:"primarykey" json:"id"`
+	OrganizationID uint           `gorm:"not null;index" json:"organizationId"`
+	UserID         *uint          `gorm:"index" json:"userId,omitempty"`
+	CreatedByID    uint           `gorm:"not null" json:"createdById"`
+	Name           string         `gorm:"type:varchar(100);not null" json:"name"`
+	Prefix         string         `gorm:"type:varchar(16);not null" json:"prefix"`
+	KeyHash        string         `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	Role           string         `gorm:"type:varchar(20)" json:"role,omitempty"`
+	Scopes         []string       `gorm:"serializer:json;type:text;not null" json:"scopes"`
+	ExpiresAt      *time.Time     `json:"expiresAt,omitempty"`
+	LastUsedAt     *time.Time     `json:"lastUsedAt,omitempty"`
+	RevokedAt      *time.Time     `json:"revokedAt,omitempty"`
+	CreatedAt      time.Time      `json:"createdAt"`
+	UpdatedAt      time.Time      `json:"updatedAt"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// CreateAPIKeyRequest creates a personal key unless Organization is set, in which
+// case the key belongs to the caller's organization and acts with Role.
+// Scopes take the form "<resource>:read", "<resource>:write" or "*", where resource
+// is the first path segment after /api (e.g. "tasks:write", "gitops:write"), or
+// for routes mounted under a cluster or Docker host the segment after its ID
+// (e.g. "kubernetes:read" for /api/clusters/:cluster/kubernetes/pods).
+type CreateAPIKeyRequest struct {
+	Name         string     `json:"name" binding:"required,min=1,max=100"`
+	Scopes       []string   `json:"scopes" binding:"required,min=1"`
+	ExpiresAt    *time.Time `json:"expiresAt"`
+	Organization bool       `json:"organization"`
+	Role         string     `json:"role" binding:"omitempty,oneof=viewer developer operator admin"`
+}
+
+// CreatedAPIKey is returned once on creation and carries the plaintext key
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// ScopeAllows reports whether scopes permit a read or write on resource.
+// A write scope implies read on the same resource.
+func ScopeAllows(scopes []string, resource string, write bool) bool {
+	for _, scope := range scopes {
+		if scope == APIKeyScopeAll {
+			return true
+		}
+		scopeResource, access, ok := strings.Cut(scope, ":")
+		if !ok || (scopeResource != resource && scopeResource != APIKeyScopeAll) {
+			continue
+		}
+		if access == "write" || (access == "read" && !write) {
+			return true
+		}
+	}
+	return false
+}
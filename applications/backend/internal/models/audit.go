@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeFailure = "failure"
+)
+
+// AuditLog records who changed what. Entries are stored in MongoDB.
+type AuditLog struct {
+	ID             primitive.ObjectID     `bson:"_id,omitempty" json:"id"`
+	Timestamp      time.Time              `bson:"timestamp" json:"timestamp"`
+	OrganizationID uint                   `bson:"organization_id" json:"organizationId"`
+	ActorID        uint                   `bson:"actor_id" json:"actorId"`
+	ActorName      string                 `bson:"actor_name" json:"actorName"`
+	APIKeyID       uint                   `bson:"api_key_id,omitempty" json:"apiKeyId,omitempty"`
+	Action         string                 `bson:"action" json:"action"`
+	Method         string                 `bson:"method,omitempty" json:"method,omitempty"`
+	Route          string                 `bson:"route,omitempty" json:"route,omitempty"`
+	Path           string                 `bson:"path,omitempty" json:"path,omitempty"`
+	EntityType     string                 `bson:"entity_type" json:"entityType"`
+	EntityID       string                 `bson:"entity_id,omitempty" json:"entityId,omitempty"`
+	Before         map[string]interface{} `bson:"before,omitempty" json:"before,omitempty"`
+	After          map[string]interface{} `bson:"after,omitempty" json:"after,omitempty"`
+	Changes        []AuditChange          `bson:"changes,omitempty" json:"changes,omitempty"`
+	Details        map[string]interface{} `bson:"details,omitempty" json:"details,omitempty"`
+	Outcome        string                 `bson:"outcome" json:"outcome"`
+	StatusCode     int                    `bson:"status_code,omitempty" json:"statusCode,omitempty"`
+	Error          string                 `bson:"error,omitempty" json:"error,omitempty"`
+	ClientIP       string                 `bson:"client_ip,omitempty" json:"clientIp,omitempty"`
+	DurationMs     int64                  `bson:"duration_ms" json:"durationMs"`
+}
+
+// AuditChange is a single field that differs between the before and after state
+type AuditChange struct {
+	Field  string      `bson:"field" json:"field"`
+	Before interface{} `bson:"before,omitempty" json:"before,omitempty"`
+	After  interface{} `bson:"after,omitempty" json:"after,omitempty"`
+}
+
+type AuditQuery struct {
+	OrganizationID uint
+	Actor          string
+	EntityType     string
+	EntityID       string
+	From           *time.Time
+	To             *time.Time
+	Limit          int64
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	ClusterStatusHealthy     = "healthy"
+	ClusterStatusUnreachable = "unreachable"
+	ClusterStatusError       = "error"
+)
+
+// Cluster is a Kubernetes cluster connection owned by an organization. The
+// kubeconfig is stored encrypted with the credential vault and never returned;
+// deleting a cluster removes the row and its kubeconfig outright. Local marks
+// the cluster the server itself runs in or $HOME/.kube/config points at, which
+// belongs to the default organization, has no stored kubeconfig and cannot be
+// added or removed through the API.
+type Cluster struct {
+	ID             uint      `gorm:"primarykey" json:"id"`
+	OrganizationID uint      `gorm:"not null;uniqueIndex:idx_clusters_org_name" json:"organizationId"`
+	Name           string    `gorm:"type:varchar(100);not null;uniqueIndex:idx_clusters_org_name" json:"name"`
+	Context        string    `gorm:"type:varchar(255)" json:"context"`
+	Server         string    `gorm:"type:varchar(500)" json:"server"`
+	Local          bool      `gorm:"not null;default:false" json:"local"`
+	Ciphertext     []byte    `gorm:"type:bytea;not null" json:"-"`
+	Nonce          []byte    `gorm:"type:bytea;not null" json:"-"`
+	CreatedByID    uint      `json:"createdById"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+func (Cluster) TableName() string {
+	return "clusters"
+}
+
+// CreateClusterRequest registers a cluster from a kubeconfig document. Context
+// selects one of its contexts; when empty the kubeconfig's current-context is used.
+type CreateClusterRequest struct {
+	Name       string `json:"name" binding:"required,min=1,max=100"`
+	Kubeconfig string `json:"kubeconfig" binding:"required"`
+	Context    string `json:"context" binding:"omitempty,max=255"`
+}
+
+// ClusterHealth is the result of probing a cluster's API server
+type ClusterHealth struct {
+	Status    string    `json:"status"`
+	Version   string    `json:"version,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	LatencyMs int64     `json:"latencyMs"`
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+type ClusterStatus struct {
+	Cluster
+	Health ClusterHealth `json:"health"`
+}
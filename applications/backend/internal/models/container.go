@@ -8,6 +8,7 @@ import (
 
 type Container struct {
 	ID          uint           `gorm:"primarykey" json:"id"`
+	HostID      uint           `gorm:"index" json:"hostId"`
 	ContainerID string         `gorm:"type:varchar(255);uniqueIndex" json:"containerId"`
 	Name        string         `gorm:"type:varchar(255)" json:"name"`
 	Image       string         `gorm:"type:varchar(255)" json:"image"`
@@ -28,11 +29,38 @@ func (Container) TableName() string {
 }
 
 type ContainerStats struct {
-	ContainerID string  `json:"containerId"`
-	Name        string  `json:"name"`
-	Status       string  `json:"status"`
-	Image        string  `json:"image"`
-	CPUPercent  float64 `json:"cpuPercent"`
-	MemoryUsage int64   `json:"memoryUsage"`
-	MemoryLimit int64   `json:"memoryLimit"`
+	HostID      uint              `json:"hostId,omitempty"`
+	Host        string            `json:"host,omitempty"`
+	ContainerID string            `json:"containerId"`
+	Name        string            `json:"name"`
+	Status      string            `json:"status"`
+	Health      string            `json:"health,omitempty"`
+	ExitCode    *int              `json:"exitCode,omitempty"`
+	Image       string            `json:"image"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	CPUPercent  float64           `json:"cpuPercent"`
+	MemoryUsage int64             `json:"memoryUsage"`
+	MemoryLimit int64             `json:"memoryLimit"`
+	NetworkRx   int64             `json:"networkRx"`
+	NetworkTx   int64             `json:"networkTx"`
+	StatsError  string            `json:"statsError,omitempty"`
+}
+
+// ContainerActionRequest carries the optional parameters of a lifecycle action.
+// Destructive actions (kill, remove) must set Confirm to the container's name or ID.
+type ContainerActionRequest struct {
+	Confirm       string `json:"confirm"`
+	Timeout       *int   `json:"timeout" binding:"omitempty,min=0,max=600"`
+	Signal        string `json:"signal" binding:"omitempty,max=20"`
+	Force         bool   `json:"force"`
+	RemoveVolumes bool   `json:"removeVolumes"`
+}
+
+// ContainerActionResult reports the container state around a lifecycle action
+type ContainerActionResult struct {
+	ContainerID string `json:"containerId"`
+	Name        string `json:"name"`
+	Action      string `json:"action"`
+	StateBefore string `json:"stateBefore"`
+	StateAfter  string `json:"stateAfter"`
 }
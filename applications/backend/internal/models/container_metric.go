@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// ContainerMetricRaw is the Resolution of samples written by the sampler; rollups
+// store their bucket width in seconds instead
+const ContainerMetricRaw = 0
+
+// ContainerMetric is one point of a container's resource time series. Raw
+// samples are downsampled into coarser rollups as they age. Network counters are
+// cumulative bytes since the container started.
+type ContainerMetric struct {
+	ID          uint      `gorm:"primarykey" json:"-"`
+	ContainerID string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_container_metrics_series,priority:1" json:"containerId"`
+	Resolution  int       `gorm:"not null;uniqueIndex:idx_container_metrics_series,priority:2" json:"resolution"`
+	Timestamp   time.Time `gorm:"not null;uniqueIndex:idx_container_metrics_series,priority:3;index" json:"timestamp"`
+	CPUPercent  float64   `json:"cpuPercent"`
+	MemoryUsage int64     `json:"memoryUsage"`
+	MemoryLimit int64     `json:"memoryLimit"`
+	NetworkRx   int64     `json:"networkRx"`
+	NetworkTx   int64     `json:"networkTx"`
+}
+
+func (ContainerMetric) TableName() string {
+	return "container_metrics"
+}
+
+// ContainerMetricPoint is one step of a series returned to clients. Network
+// rates are bytes per second since the previous point and are omitted when the
+// counters reset (e.g. after a restart).
+type ContainerMetricPoint struct {
+	Timestamp     time.Time `json:"timestamp"`
+	CPUPercent    float64   `json:"cpuPercent"`
+	MemoryUsage   int64     `json:"memoryUsage"`
+	MemoryLimit   int64     `json:"memoryLimit"`
+	NetworkRx     int64     `json:"networkRx"`
+	NetworkTx     int64     `json:"networkTx"`
+	NetworkRxRate *float64  `json:"networkRxRate,omitempty"`
+	NetworkTxRate *float64  `json:"networkTxRate,omitempty"`
+}
+
+// ContainerMetricSeries answers GET /api/docker-hosts/:host/containers/:id/metrics.
+// Resolution is the width in seconds of the stored data the points were built
+// from.
+type ContainerMetricSeries struct {
+	ContainerID string                 `json:"containerId"`
+	Name        string                 `json:"name,omitempty"`
+	From        time.Time              `json:"from"`
+	To          time.Time              `json:"to"`
+	Step        int                    `json:"step"`
+	Resolution  int                    `json:"resolution"`
+	Points      []ContainerMetricPoint `json:"points"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Credential is a third-party secret (e.g. a GitHub token) stored encrypted at rest.
+// The plaintext is never returned by the API; services resolve it by ID.
+type Credential struct {
+	ID             uint           `gorm:"primarykey" json:"id"`
+	OrganizationID uint           `gorm:"index" json:"organizationId"`
+	UserID         uint           `gorm:"not null;index" json:"userId"`
+	Name           string         `gorm:"type:varchar(100);not null" json:"name"`
+	Provider       string         `gorm:"type:varchar(50);not null;default:'github'" json:"provider"`
+	Ciphertext     []byte         `gorm:"type:bytea;not null" json:"-"`
+	Nonce          []byte         `gorm:"type:bytea;not null" json:"-"`
+	Hint           string         `gorm:"type:varchar(8)" json:"hint"`
+	LastUsedAt     *time.Time     `json:"lastUsedAt,omitempty"`
+	RotatedAt      *time.Time     `json:"rotatedAt,omitempty"`
+	RevokedAt      *time.Time     `json:"revokedAt,omitempty"`
+	CreatedAt      time.Time      `json:"createdAt"`
+	UpdatedAt      time.Time      `json:"updatedAt"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (Credential) TableName() string {
+	return "credentials"
+}
+
+type CreateCredentialRequest struct {
+	Name     string `json:"name" binding:"required,min=1,max=100"`
+	Provider string `json:"provider" binding:"omitempty,oneof=github"`
+	Secret   string `json:"secret" binding:"required"`
+}
+
+type RotateCredentialRequest struct {
+	Secret string `json:"secret" binding:"required"`
+}
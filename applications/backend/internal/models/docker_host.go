@@ -0,0 +1,75 @@
+package models
+
+import "time"
+
+// DockerHost is a Docker daemon owned by an organization, reached over tcp
+// (optionally with TLS client certificates) or ssh. TLS and SSH private
+// material is stored encrypted with the credential vault and never returned.
+// Local marks the server's own daemon at DOCKER_HOST, which belongs to the
+// default organization and cannot be added or removed through the API.
+type DockerHost struct {
+	ID             uint      `gorm:"primarykey" json:"id"`
+	OrganizationID uint      `gorm:"not null;uniqueIndex:idx_docker_hosts_org_name" json:"organizationId"`
+	Name           string    `gorm:"type:varchar(100);not null;uniqueIndex:idx_docker_hosts_org_name" json:"name"`
+	URL            string    `gorm:"type:varchar(500);not null" json:"url"`
+	TLS            bool      `json:"tls"`
+	Local          bool      `gorm:"not null;default:false" json:"local"`
+	SSHHostKey     string    `gorm:"type:text" json:"sshHostKey,omitempty"`
+	Ciphertext     []byte    `gorm:"type:bytea" json:"-"`
+	Nonce          []byte    `gorm:"type:bytea" json:"-"`
+	CreatedByID    uint      `json:"createdById"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+func (DockerHost) TableName() string {
+	return "docker_hosts"
+}
+
+// CreateDockerHostRequest registers a daemon. URL is tcp://host:port or
+// ssh://user@host[:port][/path/to/docker.sock]. For TLS set CACert and, for
+// client authentication, ClientCert and ClientKey (PEM). SSH hosts need
+// SSHPrivateKey and the server's SSHHostKey in authorized_keys format.
+type CreateDockerHostRequest struct {
+	Name          string `json:"name" binding:"required,min=1,max=100"`
+	URL           string `json:"url" binding:"required,max=500"`
+	TLS           bool   `json:"tls"`
+	CACert        string `json:"caCert"`
+	ClientCert    string `json:"clientCert"`
+	ClientKey     string `json:"clientKey"`
+	SSHPrivateKey string `json:"sshPrivateKey"`
+	SSHPassphrase string `json:"sshPassphrase"`
+	SSHHostKey    string `json:"sshHostKey" binding:"omitempty,max=8192"`
+}
+
+// DockerHostHealth is the result of pinging a daemon
+type DockerHostHealth struct {
+	Status     string    `json:"status"`
+	Version    string    `json:"version,omitempty"`
+	APIVersion string    `json:"apiVersion,omitempty"`
+	OS         string    `json:"os,omitempty"`
+	Arch       string    `json:"arch,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	LatencyMs  int64     `json:"latencyMs"`
+	CheckedAt  time.Time `json:"checkedAt"`
+}
+
+type DockerHostStatus struct {
+	DockerHost
+	Health DockerHostHealth `json:"health"`
+}
+
+// FleetHostResult reports how listing one host went in a fleet-wide list
+type FleetHostResult struct {
+	HostID     uint   `json:"hostId"`
+	Name       string `json:"name"`
+	Containers int    `json:"containers"`
+	Error      string `json:"error,omitempty"`
+}
+
+// FleetContainerList is every container across an organization's hosts. A
+// host that cannot be reached is reported in Hosts instead of failing the list.
+type FleetContainerList struct {
+	Containers []ContainerStats  `json:"containers"`
+	Hosts      []FleetHostResult `json:"hosts"`
+}
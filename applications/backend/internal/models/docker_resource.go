@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// DockerImage is an image on a Docker host. Size includes layers shared with
+// other images, so sizes do not add up to the disk used.
+type DockerImage struct {
+	ID         string    `json:"id"`
+	Tags       []string  `json:"tags"`
+	Digests    []string  `json:"digests"`
+	Created    time.Time `json:"created"`
+	Size       int64     `json:"size"`
+	Dangling   bool      `json:"dangling"`
+	Containers []string  `json:"containers"`
+}
+
+// DockerVolume is a volume on a Docker host. Size is -1 when the driver does
+// not report it.
+type DockerVolume struct {
+	Name       string            `json:"name"`
+	Driver     string            `json:"driver"`
+	Mountpoint string            `json:"mountpoint"`
+	Scope      string            `json:"scope"`
+	Labels     map[string]string `json:"labels"`
+	CreatedAt  string            `json:"createdAt"`
+	Size       int64             `json:"size"`
+	Dangling   bool              `json:"dangling"`
+	Containers []string          `json:"containers"`
+}
+
+// DockerNetwork is a network on a Docker host. Builtin networks (bridge, host,
+// none) are never pruned.
+type DockerNetwork struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Driver     string   `json:"driver"`
+	Scope      string   `json:"scope"`
+	Internal   bool     `json:"internal"`
+	Subnets    []string `json:"subnets"`
+	Created    string   `json:"created"`
+	Builtin    bool     `json:"builtin"`
+	Unused     bool     `json:"unused"`
+	Containers []string `json:"containers"`
+}
+
+// PruneImagesRequest selects what POST /api/docker-hosts/:host/images/prune removes: dangling
+// images only, or with All every image no container uses
+type PruneImagesRequest struct {
+	All bool `json:"all"`
+}
+
+// PruneResult reports what a prune removed and the disk space it freed
+type PruneResult struct {
+	Deleted        []string `json:"deleted"`
+	SpaceReclaimed int64    `json:"spaceReclaimed"`
+}
@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// ScaleDeploymentRequest sets a deployment's replica count
+type ScaleDeploymentRequest struct {
+	Replicas *int32 `json:"replicas" binding:"required,min=0,max=1000"`
+}
+
+// DeletePodRequest optionally overrides the pod's termination grace period
+type DeletePodRequest struct {
+	GracePeriodSeconds *int64 `json:"gracePeriodSeconds" binding:"omitempty,min=0,max=3600"`
+}
+
+// DrainNodeRequest controls which pods a drain may evict, like the flags of
+// `kubectl drain`. DaemonSet pods are skipped unless IgnoreDaemonSets is false,
+// in which case their presence fails the drain.
+type DrainNodeRequest struct {
+	GracePeriodSeconds *int64 `json:"gracePeriodSeconds" binding:"omitempty,min=0,max=3600"`
+	IgnoreDaemonSets   *bool  `json:"ignoreDaemonSets"`
+	DeleteEmptyDirData bool   `json:"deleteEmptyDirData"`
+	Force              bool   `json:"force"`
+}
+
+// KubernetesActionResult reports a change to one object. With DryRun the API
+// server validated the change and After shows its outcome, but nothing was stored.
+type KubernetesActionResult struct {
+	Kind      string                 `json:"kind"`
+	Namespace string                 `json:"namespace,omitempty"`
+	Name      string                 `json:"name"`
+	Action    string                 `json:"action"`
+	DryRun    bool                   `json:"dryRun"`
+	Before    map[string]interface{} `json:"before,omitempty"`
+	After     map[string]interface{} `json:"after,omitempty"`
+	Timestamp time.Time              `json:"timestamp"`
+}
+
+// DrainResult reports a node drain. Pods whose eviction is refused, e.g. by a
+// PodDisruptionBudget, are listed in Failed; draining again retries them.
+type DrainResult struct {
+	Node    string     `json:"node"`
+	DryRun  bool       `json:"dryRun"`
+	Evicted []DrainPod `json:"evicted"`
+	Skipped []DrainPod `json:"skipped"`
+	Failed  []DrainPod `json:"failed"`
+}
+
+// DrainPod is a pod considered by a drain and why it was skipped or not evicted
+type DrainPod struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Reason    string `json:"reason,omitempty"`
+}
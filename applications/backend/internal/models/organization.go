@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultOrganizationSlug identifies the organization that pre-existing data is
+// migrated into. Its admins operate the instance and may create new organizations.
+const DefaultOrganizationSlug = "default"
+
+// Organization is a tenant that owns projects, GitOps apps, credentials and cluster connections
+type Organization struct {
+	ID        uint           `gorm:"primarykey" json:"id"`
+	Name      string         `gorm:"type:varchar(255);not null" json:"name"`
+	Slug      string         `gorm:"type:varchar(100);not null;uniqueIndex" json:"slug"`
+	CreatedAt time.Time      `json:"createdAt"`
+	UpdatedAt time.Time      `json:"updatedAt"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (Organization) TableName() string {
+	return "organizations"
+}
+
+type CreateOrganizationRequest struct {
+	Name          string `json:"name" binding:"required,min=2,max=255"`
+	Slug          string `json:"slug" binding:"required,min=2,max=100"`
+	AdminUsername string `json:"adminUsername" binding:"required,min=3,max=100"`
+	AdminPassword string `json:"adminPassword" binding:"required,min=8,max=72"`
+}
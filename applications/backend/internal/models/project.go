@@ -7,16 +7,17 @@ import (
 )
 
 type Project struct {
-	ID          uint           `gorm:"primarykey" json:"id"`
-	Name        string         `gorm:"type:varchar(255);not null;unique" json:"name"`
-	Description string         `gorm:"type:text" json:"description"`
-	RepoURL     string         `gorm:"type:varchar(500)" json:"repoUrl"`
-	Status      string         `gorm:"type:varchar(50);default:'active'" json:"status"`
-	Color       string         `gorm:"type:varchar(7);default:'#1976d2'" json:"color"`
-	Tasks       []Task         `gorm:"foreignKey:ProjectID" json:"tasks,omitempty"`
-	CreatedAt   time.Time      `json:"createdAt"`
-	UpdatedAt   time.Time      `json:"updatedAt"`
-	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+	ID             uint           `gorm:"primarykey" json:"id"`
+	OrganizationID uint           `gorm:"uniqueIndex:idx_projects_org_name" json:"organizationId"`
+	Name           string         `gorm:"type:varchar(255);not null;uniqueIndex:idx_projects_org_name" json:"name"`
+	Description    string         `gorm:"type:text" json:"description"`
+	RepoURL        string         `gorm:"type:varchar(500)" json:"repoUrl"`
+	Status         string         `gorm:"type:varchar(50);default:'active'" json:"status"`
+	Color          string         `gorm:"type:varchar(7);default:'#1976d2'" json:"color"`
+	Tasks          []Task         `gorm:"foreignKey:ProjectID" json:"tasks,omitempty"`
+	CreatedAt      time.Time      `json:"createdAt"`
+	UpdatedAt      time.Time      `json:"updatedAt"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 func (Project) TableName() string {
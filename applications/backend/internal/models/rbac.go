@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+)
+
+// Global roles, ordered from least to most privileged. RoleNone users can only
+// reach projects they have been added to as a ProjectMember.
+const (
+	RoleNone      = "none"
+	RoleViewer    = "viewer"
+	RoleDeveloper = "developer"
+	RoleOperator  = "operator"
+	RoleAdmin     = "admin"
+)
+
+var roleRanks = map[string]int{
+	RoleNone:      0,
+	RoleViewer:    1,
+	RoleDeveloper: 2,
+	RoleOperator:  3,
+	RoleAdmin:     4,
+}
+
+// RoleAtLeast reports whether role grants at least the privileges of required
+func RoleAtLeast(role, required string) bool {
+	rank, ok := roleRanks[role]
+	if !ok {
+		return false
+	}
+	return rank >= roleRanks[required]
+}
+
+// ValidRole reports whether role is one of the global roles
+func ValidRole(role string) bool {
+	_, ok := roleRanks[role]
+	return ok
+}
+
+// HigherRole returns the more privileged of two roles
+func HigherRole(a, b string) string {
+	if roleRanks[b] > roleRanks[a] {
+		return b
+	}
+	return a
+}
+
+// ProjectMember grants a user a role on a single project, independent of their global role
+type ProjectMember struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	ProjectID uint      `gorm:"not null;uniqueIndex:idx_project_member" json:"projectId"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_project_member;index" json:"userId"`
+	User      *User     `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Role      string    `gorm:"type:varchar(20);not null" json:"role"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (ProjectMember) TableName() string {
+	return "project_members"
+}
+
+type AddProjectMemberRequest struct {
+	UserID uint   `json:"userId" binding:"required"`
+	Role   string `json:"role" binding:"required,oneof=viewer developer operator admin"`
+}
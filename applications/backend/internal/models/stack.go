@@ -0,0 +1,65 @@
+package models
+
+// Stack and service health, from best to worst. A service whose containers all
+// exited with code 0 (e.g. a migration job) is "completed" and does not count
+// against its stack.
+const (
+	StackHealthHealthy   = "healthy"
+	StackHealthCompleted = "completed"
+	StackHealthStarting  = "starting"
+	StackHealthDegraded  = "degraded"
+	StackHealthUnhealthy = "unhealthy"
+	StackHealthStopped   = "stopped"
+)
+
+// Stack is a Docker Compose project, assembled from the compose labels of its
+// containers. Services are listed in dependency order.
+type Stack struct {
+	Name        string         `json:"name"`
+	WorkingDir  string         `json:"workingDir,omitempty"`
+	ConfigFiles []string       `json:"configFiles,omitempty"`
+	Health      string         `json:"health"`
+	Services    []StackService `json:"services"`
+}
+
+// StackService is one compose service and its containers (replicas)
+type StackService struct {
+	Name        string           `json:"name"`
+	Image       string           `json:"image"`
+	Health      string           `json:"health"`
+	Running     int              `json:"running"`
+	Replicas    int              `json:"replicas"`
+	DependsOn   []string         `json:"dependsOn,omitempty"`
+	CPUPercent  float64          `json:"cpuPercent"`
+	MemoryUsage int64            `json:"memoryUsage"`
+	Containers  []ContainerStats `json:"containers"`
+}
+
+// StackActionRequest carries the optional parameters of a stack or service
+// action. Timeout is how long containers get to stop; Pull fetches the
+// services' images before recreating, skipping services whose containers only
+// know their image by ID.
+type StackActionRequest struct {
+	Timeout *int `json:"timeout" binding:"omitempty,min=0,max=600"`
+	Pull    bool `json:"pull"`
+}
+
+// StackActionResult reports what a stack or service action did to each container
+type StackActionResult struct {
+	Stack      string                 `json:"stack"`
+	Service    string                 `json:"service,omitempty"`
+	Action     string                 `json:"action"`
+	Containers []StackContainerResult `json:"containers"`
+}
+
+// StackContainerResult is one container of a stack action. Recreating a
+// container replaces it, so NewContainerID is set and differs from ContainerID.
+type StackContainerResult struct {
+	Service        string `json:"service"`
+	Name           string `json:"name"`
+	ContainerID    string `json:"containerId"`
+	NewContainerID string `json:"newContainerId,omitempty"`
+	StateBefore    string `json:"stateBefore"`
+	StateAfter     string `json:"stateAfter,omitempty"`
+	Error          string `json:"error,omitempty"`
+}
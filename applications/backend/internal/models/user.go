@@ -0,0 +1,74 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	AuthProviderLocal = "local"
+	AuthProviderOIDC  = "oidc"
+)
+
+type User struct {
+	ID             uint           `gorm:"primarykey" json:"id"`
+	OrganizationID uint           `gorm:"index" json:"organizationId"`
+	Username       string         `gorm:"type:varchar(100);not null;uniqueIndex" json:"username"`
+	Email          string         `gorm:"type:varchar(255);index" json:"email"`
+	Name           string         `gorm:"type:varchar(255)" json:"name"`
+	PasswordHash   string         `gorm:"type:varchar(255)" json:"-"`
+	AuthProvider   string         `gorm:"type:varchar(20);not null;default:'local'" json:"authProvider"`
+	ExternalID     string         `gorm:"type:varchar(255);index" json:"-"`
+	Role           string         `gorm:"type:varchar(20);not null;default:'viewer'" json:"role"`
+	Active         bool           `gorm:"default:true" json:"active"`
+	LastLoginAt    *time.Time     `json:"lastLoginAt,omitempty"`
+	CreatedAt      time.Time      `json:"createdAt"`
+	UpdatedAt      time.Time      `json:"updatedAt"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (User) TableName() string {
+	return "users"
+}
+
+// Principal is the authenticated caller attached to a request by the auth middleware
+type Principal struct {
+	UserID         uint   `json:"userId"`
+	Username       string `json:"username"`
+	Role           string `json:"role"`
+	OrganizationID uint   `json:"organizationId"`
+
+	// Set when the caller authenticated with an API key
+	APIKeyID uint     `json:"apiKeyId,omitempty"`
+	Scopes   []string `json:"scopes,omitempty"`
+}
+
+type CreateUserRequest struct {
+	Username string `json:"username" binding:"required,min=3,max=100"`
+	Email    string `json:"email" binding:"omitempty,email"`
+	Name     string `json:"name" binding:"omitempty,max=255"`
+	Password string `json:"password" binding:"required,min=8,max=72"`
+	Role     string `json:"role" binding:"omitempty,oneof=none viewer developer operator admin"`
+}
+
+type UpdateUserRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=none viewer developer operator admin"`
+}
+
+type LoginRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
+}
+
+type TokenPair struct {
+	AccessToken  string    `json:"accessToken"`
+	RefreshToken string    `json:"refreshToken"`
+	TokenType    string    `json:"tokenType"`
+	ExpiresAt    time.Time `json:"expiresAt"`
+	User         *User     `json:"user,omitempty"`
+}
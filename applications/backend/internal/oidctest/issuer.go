@@ -0,0 +1,339 @@
+// Package oidctest provides a minimal OpenID Connect issuer. It signs ID tokens
+// for a fixed set of users and their groups so single sign-on can be exercised
+// without an external identity provider; cmd/mock-oidc serves it for local
+// development and tests run it in-process with NewServer.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"log"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	keyID        = "mock-oidc"
+	codeLifetime = time.Minute
+	tokenTTL     = time.Hour
+)
+
+// Issuer serves discovery, keys, /authorize and /token for its users, who are
+// mapped to their groups
+type Issuer struct {
+	url          string
+	clientID     string
+	clientSecret string
+	users        map[string][]string
+	key          *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]*authorization
+}
+
+// authorization is an issued code waiting to be redeemed at the token endpoint
+type authorization struct {
+	username      string
+	redirectURI   string
+	nonce         string
+	codeChallenge string
+	expiresAt     time.Time
+}
+
+// NewIssuer returns an issuer identified by issuerURL with a fresh signing key
+func NewIssuer(issuerURL, clientID, clientSecret string, users map[string][]string) (*Issuer, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+	return &Issuer{
+		url:          strings.TrimSuffix(issuerURL, "/"),
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		users:        users,
+		key:          key,
+		codes:        make(map[string]*authorization),
+	}, nil
+}
+
+// Handler routes the issuer's endpoints
+func (s *Issuer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", s.discovery)
+	mux.HandleFunc("/keys", s.keys)
+	mux.HandleFunc("/authorize", s.authorize)
+	mux.HandleFunc("/token", s.token)
+	return mux
+}
+
+// Server is an Issuer listening on a local port; its URL is the issuer URL
+type Server struct {
+	*httptest.Server
+	Issuer *Issuer
+}
+
+// NewServer starts an issuer for clientID; call Close when done
+func NewServer(clientID, clientSecret string, users map[string][]string) *Server {
+	server := httptest.NewUnstartedServer(nil)
+	issuer, err := NewIssuer("http://"+server.Listener.Addr().String(), clientID, clientSecret, users)
+	if err != nil {
+		server.Close()
+		panic(fmt.Sprintf("oidctest: %v", err))
+	}
+	server.Config.Handler = issuer.Handler()
+	server.Start()
+	return &Server{Server: server, Issuer: issuer}
+}
+
+func (s *Issuer) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                s.url,
+		"authorization_endpoint":                s.url + "/authorize",
+		"token_endpoint":                        s.url + "/token",
+		"jwks_uri":                              s.url + "/keys",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"scopes_supported":                      []string{"openid", "profile", "email", "groups"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (s *Issuer) keys(w http.ResponseWriter, r *http.Request) {
+	pub := s.key.PublicKey
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"alg": "RS256",
+			"use": "sig",
+			"kid": keyID,
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+var loginPage = template.Must(template.New("login").Parse(`<!DOCTYPE html>
+<html><head><title>Mock OIDC login</title></head>
+<body>
+<h1>Mock OIDC login</h1>
+<ul>
+{{range .}}<li><a href="{{.URL}}">{{.Username}}</a> ({{.Groups}})</li>
+{{end}}</ul>
+</body></html>`))
+
+// authorize signs in the user named by ?login_hint= immediately, or lists the
+// configured users to choose from
+func (s *Issuer) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("client_id") != s.clientID {
+		http.Error(w, "unknown client_id", http.StatusBadRequest)
+		return
+	}
+	if query.Get("response_type") != "code" {
+		http.Error(w, "unsupported response_type", http.StatusBadRequest)
+		return
+	}
+	redirectURI, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil || !redirectURI.IsAbs() {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+
+	username := query.Get("login_hint")
+	if _, ok := s.users[username]; !ok {
+		s.renderLogin(w, r)
+		return
+	}
+
+	code := randomString()
+	s.mu.Lock()
+	s.codes[code] = &authorization{
+		username:      username,
+		redirectURI:   redirectURI.String(),
+		nonce:         query.Get("nonce"),
+		codeChallenge: query.Get("code_challenge"),
+		expiresAt:     time.Now().Add(codeLifetime),
+	}
+	s.mu.Unlock()
+
+	params := redirectURI.Query()
+	params.Set("code", code)
+	params.Set("state", query.Get("state"))
+	redirectURI.RawQuery = params.Encode()
+	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+func (s *Issuer) renderLogin(w http.ResponseWriter, r *http.Request) {
+	type choice struct {
+		Username string
+		Groups   string
+		URL      string
+	}
+
+	var choices []choice
+	for username, groups := range s.users {
+		query := r.URL.Query()
+		query.Set("login_hint", username)
+		choices = append(choices, choice{
+			Username: username,
+			Groups:   strings.Join(groups, ", "),
+			URL:      "/authorize?" + query.Encode(),
+		})
+	}
+	sort.Slice(choices, func(i, j int) bool { return choices[i].Username < choices[j].Username })
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := loginPage.Execute(w, choices); err != nil {
+		log.Printf("Failed to render login page: %v", err)
+	}
+}
+
+func (s *Issuer) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		tokenError(w, http.StatusMethodNotAllowed, "invalid_request", "POST required")
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		tokenError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if clientID != s.clientID || subtle.ConstantTimeCompare([]byte(clientSecret), []byte(s.clientSecret)) != 1 {
+		tokenError(w, http.StatusUnauthorized, "invalid_client", "unknown client or bad secret")
+		return
+	}
+	if r.PostForm.Get("grant_type") != "authorization_code" {
+		tokenError(w, http.StatusBadRequest, "unsupported_grant_type", "only authorization_code is supported")
+		return
+	}
+
+	// A code is spent once it is redeemed with the right verifier; a wrong
+	// verifier leaves it in place because oauth2 clients that auto-detect the
+	// client authentication style retry a failed exchange with the same code
+	code := r.PostForm.Get("code")
+	s.mu.Lock()
+	auth, ok := s.codes[code]
+	if ok && time.Now().After(auth.expiresAt) {
+		delete(s.codes, code)
+		ok = false
+	}
+	if ok && auth.redirectURI == r.PostForm.Get("redirect_uri") &&
+		(auth.codeChallenge == "" || pkceChallenge(r.PostForm.Get("code_verifier")) == auth.codeChallenge) {
+		delete(s.codes, code)
+	}
+	s.mu.Unlock()
+
+	if !ok || auth.redirectURI != r.PostForm.Get("redirect_uri") {
+		tokenError(w, http.StatusBadRequest, "invalid_grant", "code is invalid, expired or was issued for another redirect_uri")
+		return
+	}
+	if auth.codeChallenge != "" && pkceChallenge(r.PostForm.Get("code_verifier")) != auth.codeChallenge {
+		tokenError(w, http.StatusBadRequest, "invalid_grant", "code_verifier does not match code_challenge")
+		return
+	}
+
+	idToken, err := s.signIDToken(auth)
+	if err != nil {
+		tokenError(w, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": randomString(),
+		"token_type":   "Bearer",
+		"expires_in":   int(tokenTTL.Seconds()),
+		"id_token":     idToken,
+	})
+}
+
+func (s *Issuer) signIDToken(auth *authorization) (string, error) {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":                s.url,
+		"sub":                "mock|" + auth.username,
+		"aud":                s.clientID,
+		"iat":                now.Unix(),
+		"exp":                now.Add(tokenTTL).Unix(),
+		"preferred_username": auth.username,
+		"name":               auth.username,
+		"email":              auth.username + "@example.com",
+		"email_verified":     true,
+		"groups":             s.users[auth.username],
+	}
+	if auth.nonce != "" {
+		claims["nonce"] = auth.nonce
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = keyID
+	return token.SignedString(s.key)
+}
+
+// ParseUsers parses "alice=group-a|group-b,bob=group-c"
+func ParseUsers(value string) (map[string][]string, error) {
+	users := make(map[string][]string)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		username, groups, _ := strings.Cut(entry, "=")
+		if username == "" {
+			return nil, fmt.Errorf("missing username in %q", entry)
+		}
+		users[username] = []string{}
+		for _, group := range strings.Split(groups, "|") {
+			if group = strings.TrimSpace(group); group != "" {
+				users[username] = append(users[username], group)
+			}
+		}
+	}
+	if len(users) == 0 {
+		return nil, fmt.Errorf("no users configured")
+	}
+	return users, nil
+}
+
+func pkceChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func randomString() string {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		log.Fatalf("Failed to read random bytes: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+func tokenError(w http.ResponseWriter, status int, code, description string) {
+	writeJSON(w, status, map[string]string{"error": code, "error_description": description})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Failed to write response: %v", err)
+	}
+}
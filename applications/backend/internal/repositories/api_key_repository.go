@@ -0,0 +1,71 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/database"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
+)
+
+type APIKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository() *APIKeyRepository {
+	return &APIKeyRepository{
+		db: database.PostgresDB,
+	}
+}
+
+func (r *APIKeyRepository) Create(key *models.APIKey) error {
+	return r.db.Create(key).Error
+}
+
+func (r *APIKeyRepository) FindByHash(hash string) (*models.APIKey, error) {
+	var key models.APIKey
+	err := r.db.Where("key_hash = ?", hash).First(&key).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("api key not found")
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+// FindVisible lists the caller's personal keys and, when includeOrganization is
+// set, the organization-wide keys of orgID
+func (r *APIKeyRepository) FindVisible(orgID, userID uint, includeOrganization bool) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	query := r.db.Where("organization_id = ?", orgID)
+	if includeOrganization {
+		query = query.Where("user_id = ? OR user_id IS NULL", userID)
+	} else {
+		query = query.Where("user_id = ?", userID)
+	}
+	err := query.Order("created_at DESC").Find(&keys).Error
+	return keys, err
+}
+
+func (r *APIKeyRepository) FindByID(orgID, id uint) (*models.APIKey, error) {
+	var key models.APIKey
+	err := r.db.Where("organization_id = ?", orgID).First(&key, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("api key not found")
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *APIKeyRepository) Update(key *models.APIKey) error {
+	return r.db.Save(key).Error
+}
+
+func (r *APIKeyRepository) TouchLastUsed(id uint, at time.Time) error {
+	return r.db.Model(&models.APIKey{}).Where("id = ?", id).UpdateColumn("last_used_at", at).Error
+}
@@ -0,0 +1,102 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/database"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
+)
+
+const auditCollection = "audit_logs"
+
+var ErrAuditStoreUnavailable = errors.New("audit store unavailable: MongoDB is not connected")
+
+type AuditRepository struct {
+	collection *mongo.Collection
+}
+
+// NewAuditRepository uses the shared MongoDB connection; when MongoDB is down
+// the repository is created without a collection and reports ErrAuditStoreUnavailable.
+func NewAuditRepository() *AuditRepository {
+	repo := &AuditRepository{}
+	if database.MongoDB != nil {
+		repo.collection = database.MongoDB.Collection(auditCollection)
+	}
+	return repo
+}
+
+func (r *AuditRepository) Available() bool {
+	return r.collection != nil
+}
+
+// EnsureIndexes creates the indexes used by the audit filters
+func (r *AuditRepository) EnsureIndexes() error {
+	if r.collection == nil {
+		return ErrAuditStoreUnavailable
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "organization_id", Value: 1}, {Key: "timestamp", Value: -1}}},
+		{Keys: bson.D{{Key: "organization_id", Value: 1}, {Key: "actor_name", Value: 1}, {Key: "timestamp", Value: -1}}},
+		{Keys: bson.D{{Key: "organization_id", Value: 1}, {Key: "entity_type", Value: 1}, {Key: "entity_id", Value: 1}, {Key: "timestamp", Value: -1}}},
+	})
+	return err
+}
+
+func (r *AuditRepository) Create(ctx context.Context, entry *models.AuditLog) error {
+	if r.collection == nil {
+		return ErrAuditStoreUnavailable
+	}
+	_, err := r.collection.InsertOne(ctx, entry)
+	return err
+}
+
+func (r *AuditRepository) Find(ctx context.Context, query *models.AuditQuery) ([]models.AuditLog, error) {
+	if r.collection == nil {
+		return nil, ErrAuditStoreUnavailable
+	}
+
+	filter := bson.M{"organization_id": query.OrganizationID}
+	if query.Actor != "" {
+		filter["actor_name"] = query.Actor
+	}
+	if query.EntityType != "" {
+		filter["entity_type"] = query.EntityType
+	}
+	if query.EntityID != "" {
+		filter["entity_id"] = query.EntityID
+	}
+	if query.From != nil || query.To != nil {
+		timeRange := bson.M{}
+		if query.From != nil {
+			timeRange["$gte"] = *query.From
+		}
+		if query.To != nil {
+			timeRange["$lte"] = *query.To
+		}
+		filter["timestamp"] = timeRange
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "timestamp", Value: -1}}).SetLimit(query.Limit)
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	logs := []models.AuditLog{}
+	if err := cursor.All(ctx, &logs); err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
@@ -0,0 +1,94 @@
+package repositories
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/database"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
+)
+
+type ClusterRepository struct {
+	db    *gorm.DB
+	orgID uint
+}
+
+func NewClusterRepository() *ClusterRepository {
+	return &ClusterRepository{
+		db: database.PostgresDB,
+	}
+}
+
+// ForOrganization returns a repository whose queries only see clusters of orgID
+// and whose Create assigns new clusters to it.
+func (r *ClusterRepository) ForOrganization(orgID uint) *ClusterRepository {
+	return &ClusterRepository{
+		db:    r.db.Where("clusters.organization_id = ?", orgID).Session(&gorm.Session{}),
+		orgID: orgID,
+	}
+}
+
+func (r *ClusterRepository) Create(cluster *models.Cluster) error {
+	if r.orgID != 0 {
+		cluster.OrganizationID = r.orgID
+	}
+	return r.db.Create(cluster).Error
+}
+
+// FindLocal returns the server's own cluster, whichever organization owns it
+func (r *ClusterRepository) FindLocal() (*models.Cluster, error) {
+	var cluster models.Cluster
+	err := r.db.Where("local = ?", true).First(&cluster).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("cluster not found")
+		}
+		return nil, err
+	}
+	return &cluster, nil
+}
+
+// CreateLocal stores the server's own cluster for the organization cluster
+// names; it has no kubeconfig
+func (r *ClusterRepository) CreateLocal(cluster *models.Cluster) error {
+	cluster.Local = true
+	cluster.Ciphertext = []byte{}
+	cluster.Nonce = []byte{}
+	return r.db.Create(cluster).Error
+}
+
+func (r *ClusterRepository) FindAll() ([]models.Cluster, error) {
+	var clusters []models.Cluster
+	err := r.db.Order("name ASC").Find(&clusters).Error
+	return clusters, err
+}
+
+func (r *ClusterRepository) FindByID(id uint) (*models.Cluster, error) {
+	var cluster models.Cluster
+	err := r.db.First(&cluster, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("cluster not found")
+		}
+		return nil, err
+	}
+	return &cluster, nil
+}
+
+func (r *ClusterRepository) ExistsByName(name string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Cluster{}).Where("name = ?", name).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *ClusterRepository) Delete(id uint) error {
+	result := r.db.Delete(&models.Cluster{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("cluster not found")
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/database"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
+)
+
+type ContainerMetricRepository struct {
+	db *gorm.DB
+}
+
+func NewContainerMetricRepository() *ContainerMetricRepository {
+	return &ContainerMetricRepository{
+		db: database.PostgresDB,
+	}
+}
+
+// CreateBatch stores points, skipping any that already exist for the same
+// container, resolution and timestamp
+func (r *ContainerMetricRepository) CreateBatch(points []models.ContainerMetric) error {
+	if len(points) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&points, 500).Error
+}
+
+// FindRange returns a container's points at resolution in [from, to), oldest first
+func (r *ContainerMetricRepository) FindRange(containerID string, resolution int, from, to time.Time) ([]models.ContainerMetric, error) {
+	var points []models.ContainerMetric
+	err := r.db.
+		Where("container_id = ? AND resolution = ? AND timestamp >= ? AND timestamp < ?", containerID, resolution, from, to).
+		Order("timestamp ASC").
+		Find(&points).Error
+	return points, err
+}
+
+// FindAllRange returns the points of every container at resolution in [from, to)
+func (r *ContainerMetricRepository) FindAllRange(resolution int, from, to time.Time) ([]models.ContainerMetric, error) {
+	var points []models.ContainerMetric
+	err := r.db.
+		Where("resolution = ? AND timestamp >= ? AND timestamp < ?", resolution, from, to).
+		Order("container_id ASC, timestamp ASC").
+		Find(&points).Error
+	return points, err
+}
+
+// Oldest returns the earliest timestamp stored at resolution at or after since,
+// or nil if there is none
+func (r *ContainerMetricRepository) Oldest(resolution int, since time.Time) (*time.Time, error) {
+	return r.boundary(r.db.Where("timestamp >= ?", since), resolution, "timestamp ASC")
+}
+
+// Newest returns the latest timestamp stored at resolution, or nil if there is none
+func (r *ContainerMetricRepository) Newest(resolution int) (*time.Time, error) {
+	return r.boundary(r.db, resolution, "timestamp DESC")
+}
+
+func (r *ContainerMetricRepository) boundary(query *gorm.DB, resolution int, order string) (*time.Time, error) {
+	var points []models.ContainerMetric
+	err := query.Select("timestamp").Where("resolution = ?", resolution).Order(order).Limit(1).Find(&points).Error
+	if err != nil || len(points) == 0 {
+		return nil, err
+	}
+	return &points[0].Timestamp, nil
+}
+
+// DeleteBefore removes points at resolution older than cutoff
+func (r *ContainerMetricRepository) DeleteBefore(resolution int, cutoff time.Time) (int64, error) {
+	result := r.db.Where("resolution = ? AND timestamp < ?", resolution, cutoff).Delete(&models.ContainerMetric{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/database"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
+)
+
+// ContainerRepository stores the last known state of every container the
+// metrics sampler has seen, so history stays addressable after removal
+type ContainerRepository struct {
+	db *gorm.DB
+}
+
+func NewContainerRepository() *ContainerRepository {
+	return &ContainerRepository{
+		db: database.PostgresDB,
+	}
+}
+
+// Upsert records the latest snapshot of each container, reviving soft-deleted rows
+func (r *ContainerRepository) Upsert(containers []models.Container) error {
+	if len(containers) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "container_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"host_id", "name", "image", "status", "state", "cpu_percent", "memory_usage",
+			"memory_limit", "network_rx", "network_tx", "updated_at", "deleted_at",
+		}),
+	}).Create(&containers).Error
+}
+
+// MarkRemoved soft-deletes every container of hostID not in activeIDs
+func (r *ContainerRepository) MarkRemoved(hostID uint, activeIDs []string) error {
+	query := r.db.Model(&models.Container{}).Where("host_id = ?", hostID)
+	if len(activeIDs) > 0 {
+		query = query.Where("container_id NOT IN ?", activeIDs)
+	}
+	return query.Update("deleted_at", time.Now().UTC()).Error
+}
+
+// FindByRef resolves a full container ID, a unique ID prefix or a name on
+// hostID, including containers that have since been removed
+func (r *ContainerRepository) FindByRef(hostID uint, ref string) (*models.Container, error) {
+	var container models.Container
+	err := r.db.Unscoped().
+		Where("host_id = ?", hostID).
+		Where("container_id = ? OR name = ?", ref, ref).
+		Order("updated_at DESC").
+		First(&container).Error
+	if err == nil {
+		return &container, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	var matches []models.Container
+	err = r.db.Unscoped().Where("host_id = ? AND container_id LIKE ?", hostID, ref+"%").Limit(2).Find(&matches).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) != 1 {
+		return nil, errors.New("container not found")
+	}
+	return &matches[0], nil
+}
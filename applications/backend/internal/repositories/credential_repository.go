@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/database"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
+)
+
+type CredentialRepository struct {
+	db *gorm.DB
+}
+
+func NewCredentialRepository() *CredentialRepository {
+	return &CredentialRepository{
+		db: database.PostgresDB,
+	}
+}
+
+func (r *CredentialRepository) Create(credential *models.Credential) error {
+	return r.db.Create(credential).Error
+}
+
+// CreateSealed inserts credential and then lets seal encrypt its secret, which is
+// bound to the ID the insert assigns; a failed seal leaves no row behind
+func (r *CredentialRepository) CreateSealed(credential *models.Credential, seal func(*models.Credential) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		credential.Ciphertext = []byte{}
+		credential.Nonce = []byte{}
+		if err := tx.Create(credential).Error; err != nil {
+			return err
+		}
+		if err := seal(credential); err != nil {
+			return err
+		}
+		return tx.Model(credential).Select("ciphertext", "nonce").Updates(credential).Error
+	})
+}
+
+func (r *CredentialRepository) FindByUserID(userID uint) ([]models.Credential, error) {
+	var credentials []models.Credential
+	err := r.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&credentials).Error
+	return credentials, err
+}
+
+func (r *CredentialRepository) FindByIDForUser(id, userID uint) (*models.Credential, error) {
+	var credential models.Credential
+	err := r.db.Where("user_id = ?", userID).First(&credential, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("credential not found")
+		}
+		return nil, err
+	}
+	return &credential, nil
+}
+
+func (r *CredentialRepository) ExistsActiveByName(userID uint, name string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Credential{}).
+		Where("user_id = ? AND name = ? AND revoked_at IS NULL", userID, name).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *CredentialRepository) Update(credential *models.Credential) error {
+	return r.db.Save(credential).Error
+}
+
+func (r *CredentialRepository) TouchLastUsed(credential *models.Credential) error {
+	return r.db.Model(credential).UpdateColumn("last_used_at", credential.LastUsedAt).Error
+}
@@ -0,0 +1,99 @@
+package repositories
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/database"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
+)
+
+type DockerHostRepository struct {
+	db    *gorm.DB
+	orgID uint
+}
+
+func NewDockerHostRepository() *DockerHostRepository {
+	return &DockerHostRepository{
+		db: database.PostgresDB,
+	}
+}
+
+// ForOrganization returns a repository whose queries only see Docker hosts of orgID
+// and whose Create assigns new hosts to it.
+func (r *DockerHostRepository) ForOrganization(orgID uint) *DockerHostRepository {
+	return &DockerHostRepository{
+		db:    r.db.Where("docker_hosts.organization_id = ?", orgID).Session(&gorm.Session{}),
+		orgID: orgID,
+	}
+}
+
+func (r *DockerHostRepository) Create(host *models.DockerHost) error {
+	if r.orgID != 0 {
+		host.OrganizationID = r.orgID
+	}
+	return r.db.Create(host).Error
+}
+
+// FindLocal returns the host of the server's own daemon, whichever organization owns it
+func (r *DockerHostRepository) FindLocal() (*models.DockerHost, error) {
+	var host models.DockerHost
+	err := r.db.Where("local = ?", true).First(&host).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("docker host not found")
+		}
+		return nil, err
+	}
+	return &host, nil
+}
+
+// CreateLocal stores the host of the server's own daemon for the organization
+// host names. Containers sampled before the daemon had a host row were recorded
+// under host 0 and move to it. Call it on the unscoped repository, whose
+// transaction carries no organization condition.
+func (r *DockerHostRepository) CreateLocal(host *models.DockerHost) error {
+	host.Local = true
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(host).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Model(&models.Container{}).Where("host_id = ?", 0).Update("host_id", host.ID).Error
+	})
+}
+
+func (r *DockerHostRepository) FindAll() ([]models.DockerHost, error) {
+	var hosts []models.DockerHost
+	err := r.db.Order("name ASC").Find(&hosts).Error
+	return hosts, err
+}
+
+func (r *DockerHostRepository) FindByID(id uint) (*models.DockerHost, error) {
+	var host models.DockerHost
+	err := r.db.First(&host, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("docker host not found")
+		}
+		return nil, err
+	}
+	return &host, nil
+}
+
+func (r *DockerHostRepository) ExistsByName(name string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.DockerHost{}).Where("name = ?", name).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *DockerHostRepository) Delete(id uint) error {
+	result := r.db.Delete(&models.DockerHost{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("docker host not found")
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/database"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
+)
+
+type OrganizationRepository struct {
+	db *gorm.DB
+}
+
+func NewOrganizationRepository() *OrganizationRepository {
+	return &OrganizationRepository{
+		db: database.PostgresDB,
+	}
+}
+
+func (r *OrganizationRepository) Create(org *models.Organization) error {
+	return r.db.Create(org).Error
+}
+
+// CreateWithAdmin inserts org and its first admin account in one transaction, so
+// a failed user insert leaves no organization without an admin behind
+func (r *OrganizationRepository) CreateWithAdmin(org *models.Organization, admin *models.User) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(org).Error; err != nil {
+			return err
+		}
+		admin.OrganizationID = org.ID
+		return tx.Create(admin).Error
+	})
+}
+
+func (r *OrganizationRepository) FindAll() ([]models.Organization, error) {
+	var orgs []models.Organization
+	err := r.db.Order("created_at ASC").Find(&orgs).Error
+	return orgs, err
+}
+
+func (r *OrganizationRepository) FindByID(id uint) (*models.Organization, error) {
+	var org models.Organization
+	err := r.db.First(&org, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("organization not found")
+		}
+		return nil, err
+	}
+	return &org, nil
+}
+
+func (r *OrganizationRepository) FindBySlug(slug string) (*models.Organization, error) {
+	var org models.Organization
+	err := r.db.Where("slug = ?", slug).First(&org).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("organization not found")
+		}
+		return nil, err
+	}
+	return &org, nil
+}
+
+func (r *OrganizationRepository) ExistsBySlug(slug string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Organization{}).Where("slug = ?", slug).Count(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,35 @@
+package repositories
+
+import (
+	"testing"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
+)
+
+func TestOrganizationRepositoryCreateWithAdmin(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewOrganizationRepository()
+
+	org := &models.Organization{Name: "Acme", Slug: "acme"}
+	admin := &models.User{Username: "ada", PasswordHash: "x", Role: models.RoleAdmin}
+	if err := repo.CreateWithAdmin(org, admin); err != nil {
+		t.Fatalf("CreateWithAdmin: %v", err)
+	}
+	if admin.OrganizationID != org.ID {
+		t.Fatalf("admin organization = %d, want %d", admin.OrganizationID, org.ID)
+	}
+
+	// A failed admin insert rolls the organization back
+	taken := &models.User{Username: "ada", PasswordHash: "x", Role: models.RoleAdmin}
+	if err := repo.CreateWithAdmin(&models.Organization{Name: "Other", Slug: "other"}, taken); err == nil {
+		t.Fatal("CreateWithAdmin succeeded with a taken username")
+	}
+	if exists, err := repo.ExistsBySlug("other"); err != nil || exists {
+		t.Fatalf("ExistsBySlug(other) = (%v, %v), want the organization rolled back", exists, err)
+	}
+	var users int64
+	db.Model(&models.User{}).Count(&users)
+	if users != 1 {
+		t.Fatalf("%d users stored, want 1", users)
+	}
+}
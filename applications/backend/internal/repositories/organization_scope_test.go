@@ -0,0 +1,216 @@
+package repositories
+
+import (
+	"path/filepath"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/database"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
+)
+
+const (
+	orgA uint = 1
+	orgB uint = 2
+)
+
+// openTestDB points database.PostgresDB at a fresh SQLite database for the test
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "clouddeck.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Organization{}, &models.User{}, &models.Project{}, &models.Task{}, &models.Cluster{}, &models.DockerHost{}, &models.Container{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	previous := database.PostgresDB
+	database.PostgresDB = db
+	t.Cleanup(func() { database.PostgresDB = previous })
+	return db
+}
+
+func TestClusterRepositoryForOrganization(t *testing.T) {
+	openTestDB(t)
+	base := NewClusterRepository()
+
+	own := &models.Cluster{Name: "prod", Ciphertext: []byte{1}, Nonce: []byte{1}}
+	if err := base.ForOrganization(orgA).Create(own); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if own.OrganizationID != orgA {
+		t.Fatalf("Create assigned organization %d, want %d", own.OrganizationID, orgA)
+	}
+	other := &models.Cluster{Name: "prod", Ciphertext: []byte{1}, Nonce: []byte{1}}
+	if err := base.ForOrganization(orgB).Create(other); err != nil {
+		t.Fatalf("create in second organization: %v", err)
+	}
+
+	scoped := base.ForOrganization(orgA)
+	clusters, err := scoped.FindAll()
+	if err != nil {
+		t.Fatalf("FindAll: %v", err)
+	}
+	if len(clusters) != 1 || clusters[0].ID != own.ID {
+		t.Fatalf("FindAll = %+v, want only cluster %d", clusters, own.ID)
+	}
+
+	tests := []struct {
+		name  string
+		id    uint
+		found bool
+	}{
+		{"own cluster", own.ID, true},
+		{"other organization's cluster", other.ID, false},
+		{"missing cluster", other.ID + 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := scoped.FindByID(tt.id)
+			if found := err == nil; found != tt.found {
+				t.Fatalf("FindByID(%d) err = %v, want found %v", tt.id, err, tt.found)
+			}
+		})
+	}
+
+	if err := scoped.Delete(other.ID); err == nil {
+		t.Fatal("Delete removed another organization's cluster")
+	}
+	if _, err := base.FindByID(other.ID); err != nil {
+		t.Fatalf("other organization's cluster is gone: %v", err)
+	}
+	if exists, _ := base.ForOrganization(orgB).ExistsByName("staging"); exists {
+		t.Fatal("ExistsByName found a cluster that was never created")
+	}
+}
+
+func TestDockerHostRepositoryForOrganization(t *testing.T) {
+	db := openTestDB(t)
+	base := NewDockerHostRepository()
+
+	for _, host := range []*models.DockerHost{
+		{OrganizationID: orgA, Name: "build", URL: "tcp://build:2376"},
+		{OrganizationID: orgB, Name: "edge", URL: "tcp://edge:2376"},
+	} {
+		if err := base.Create(host); err != nil {
+			t.Fatalf("create %s: %v", host.Name, err)
+		}
+	}
+
+	tests := []struct {
+		org   uint
+		name  string
+		exist bool
+	}{
+		{orgA, "build", true},
+		{orgA, "edge", false},
+		{orgB, "edge", true},
+		{orgB, "build", false},
+	}
+	for _, tt := range tests {
+		exists, err := base.ForOrganization(tt.org).ExistsByName(tt.name)
+		if err != nil {
+			t.Fatalf("ExistsByName: %v", err)
+		}
+		if exists != tt.exist {
+			t.Errorf("organization %d ExistsByName(%q) = %v, want %v", tt.org, tt.name, exists, tt.exist)
+		}
+	}
+
+	// The local host is created through the unscoped repository and takes over
+	// the containers recorded before it existed
+	if err := db.Create(&models.Container{ContainerID: "abc", Name: "web"}).Error; err != nil {
+		t.Fatalf("create container: %v", err)
+	}
+	local := &models.DockerHost{OrganizationID: orgA, Name: "local", URL: "unix:///var/run/docker.sock"}
+	if err := base.CreateLocal(local); err != nil {
+		t.Fatalf("CreateLocal: %v", err)
+	}
+	var container models.Container
+	if err := db.Where("container_id = ?", "abc").First(&container).Error; err != nil {
+		t.Fatalf("find container: %v", err)
+	}
+	if container.HostID != local.ID {
+		t.Fatalf("container host = %d, want local host %d", container.HostID, local.ID)
+	}
+
+	hosts, err := base.ForOrganization(orgA).FindAll()
+	if err != nil {
+		t.Fatalf("FindAll: %v", err)
+	}
+	if len(hosts) != 2 {
+		t.Fatalf("organization %d sees %d hosts, want 2", orgA, len(hosts))
+	}
+	if hosts, _ := base.ForOrganization(orgB).FindAll(); len(hosts) != 1 {
+		t.Fatalf("organization %d sees %d hosts, want 1", orgB, len(hosts))
+	}
+}
+
+func TestTaskRepositoryForOrganization(t *testing.T) {
+	openTestDB(t)
+	projects := NewProjectRepository()
+	tasks := NewTaskRepository()
+
+	ownProject := &models.Project{Name: "api"}
+	otherProject := &models.Project{Name: "api"}
+	deletedProject := &models.Project{Name: "old"}
+	if err := projects.ForOrganization(orgA).Create(ownProject); err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	if err := projects.ForOrganization(orgB).Create(otherProject); err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	if err := projects.ForOrganization(orgA).Create(deletedProject); err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+
+	ownTask := &models.Task{ProjectID: ownProject.ID, Title: "ship"}
+	otherTask := &models.Task{ProjectID: otherProject.ID, Title: "ship"}
+	orphanTask := &models.Task{ProjectID: deletedProject.ID, Title: "archive"}
+	for _, task := range []*models.Task{ownTask, otherTask, orphanTask} {
+		if err := tasks.Create(task); err != nil {
+			t.Fatalf("create task: %v", err)
+		}
+	}
+	if err := projects.ForOrganization(orgA).Delete(deletedProject.ID); err != nil {
+		t.Fatalf("delete project: %v", err)
+	}
+
+	scoped := tasks.ForOrganization(orgA)
+	all, err := scoped.FindAll()
+	if err != nil {
+		t.Fatalf("FindAll: %v", err)
+	}
+	if len(all) != 1 || all[0].ID != ownTask.ID {
+		t.Fatalf("FindAll = %+v, want only task %d", all, ownTask.ID)
+	}
+
+	tests := []struct {
+		name  string
+		id    uint
+		found bool
+	}{
+		{"own task", ownTask.ID, true},
+		{"other organization's task", otherTask.ID, false},
+		{"task of a deleted project", orphanTask.ID, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := scoped.FindByID(tt.id)
+			if found := err == nil; found != tt.found {
+				t.Fatalf("FindByID(%d) err = %v, want found %v", tt.id, err, tt.found)
+			}
+		})
+	}
+
+	if _, err := projects.ForOrganization(orgA).FindByID(otherProject.ID); err == nil {
+		t.Fatal("FindByID returned another organization's project")
+	}
+}
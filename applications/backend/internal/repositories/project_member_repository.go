@@ -0,0 +1,64 @@
+package repositories
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/database"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
+)
+
+type ProjectMemberRepository struct {
+	db *gorm.DB
+}
+
+func NewProjectMemberRepository() *ProjectMemberRepository {
+	return &ProjectMemberRepository{
+		db: database.PostgresDB,
+	}
+}
+
+// Upsert adds a member or updates the role of an existing one
+func (r *ProjectMemberRepository) Upsert(member *models.ProjectMember) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "project_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role", "updated_at"}),
+	}).Create(member).Error
+}
+
+func (r *ProjectMemberRepository) FindByProjectID(projectID uint) ([]models.ProjectMember, error) {
+	var members []models.ProjectMember
+	err := r.db.Preload("User").Where("project_id = ?", projectID).Order("created_at ASC").Find(&members).Error
+	return members, err
+}
+
+func (r *ProjectMemberRepository) FindByUserID(userID uint) ([]models.ProjectMember, error) {
+	var members []models.ProjectMember
+	err := r.db.Where("user_id = ?", userID).Find(&members).Error
+	return members, err
+}
+
+func (r *ProjectMemberRepository) FindRole(projectID, userID uint) (string, error) {
+	var member models.ProjectMember
+	err := r.db.Where("project_id = ? AND user_id = ?", projectID, userID).First(&member).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.RoleNone, nil
+		}
+		return "", err
+	}
+	return member.Role, nil
+}
+
+func (r *ProjectMemberRepository) Delete(projectID, userID uint) error {
+	result := r.db.Where("project_id = ? AND user_id = ?", projectID, userID).Delete(&models.ProjectMember{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("project member not found")
+	}
+	return nil
+}
@@ -10,7 +10,8 @@ import (
 )
 
 type ProjectRepository struct {
-	db *gorm.DB
+	db    *gorm.DB
+	orgID uint
 }
 
 func NewProjectRepository() *ProjectRepository {
@@ -19,7 +20,19 @@ func NewProjectRepository() *ProjectRepository {
 	}
 }
 
+// ForOrganization returns a repository whose queries only see projects owned by orgID
+// and whose Create assigns new projects to it.
+func (r *ProjectRepository) ForOrganization(orgID uint) *ProjectRepository {
+	return &ProjectRepository{
+		db:    r.db.Where("projects.organization_id = ?", orgID).Session(&gorm.Session{}),
+		orgID: orgID,
+	}
+}
+
 func (r *ProjectRepository) Create(project *models.Project) error {
+	if r.orgID != 0 {
+		project.OrganizationID = r.orgID
+	}
 	return r.db.Create(project).Error
 }
 
@@ -29,6 +42,15 @@ func (r *ProjectRepository) FindAll() ([]models.Project, error) {
 	return projects, err
 }
 
+func (r *ProjectRepository) FindByIDs(ids []uint) ([]models.Project, error) {
+	var projects []models.Project
+	if len(ids) == 0 {
+		return projects, nil
+	}
+	err := r.db.Preload("Tasks").Where("id IN ?", ids).Order("created_at DESC").Find(&projects).Error
+	return projects, err
+}
+
 func (r *ProjectRepository) FindByID(id uint) (*models.Project, error) {
 	var project models.Project
 	err := r.db.Preload("Tasks").First(&project, id).Error
@@ -41,6 +63,12 @@ func (r *ProjectRepository) FindByID(id uint) (*models.Project, error) {
 	return &project, nil
 }
 
+func (r *ProjectRepository) Exists(id uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Project{}).Where("id = ?", id).Count(&count).Error
+	return count > 0, err
+}
+
 func (r *ProjectRepository) Update(project *models.Project) error {
 	return r.db.Save(project).Error
 }
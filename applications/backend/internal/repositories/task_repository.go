@@ -19,6 +19,17 @@ func NewTaskRepository() *TaskRepository {
 	}
 }
 
+// ForOrganization returns a repository whose queries only see tasks belonging to
+// projects owned by orgID
+func (r *TaskRepository) ForOrganization(orgID uint) *TaskRepository {
+	return &TaskRepository{
+		db: r.db.Where(
+			"tasks.project_id IN (SELECT id FROM projects WHERE organization_id = ? AND deleted_at IS NULL)",
+			orgID,
+		).Session(&gorm.Session{}),
+	}
+}
+
 func (r *TaskRepository) Create(task *models.Task) error {
 	return r.db.Create(task).Error
 }
@@ -35,6 +46,15 @@ func (r *TaskRepository) FindByProjectID(projectID uint) ([]models.Task, error)
 	return tasks, err
 }
 
+func (r *TaskRepository) FindByProjectIDs(projectIDs []uint) ([]models.Task, error) {
+	var tasks []models.Task
+	if len(projectIDs) == 0 {
+		return tasks, nil
+	}
+	err := r.db.Preload("Project").Where("project_id IN ?", projectIDs).Order("created_at DESC").Find(&tasks).Error
+	return tasks, err
+}
+
 func (r *TaskRepository) FindByID(id uint) (*models.Task, error) {
 	var task models.Task
 	err := r.db.Preload("Project").First(&task, id).Error
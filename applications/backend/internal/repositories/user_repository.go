@@ -0,0 +1,109 @@
+package repositories
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/database"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
+)
+
+type UserRepository struct {
+	db    *gorm.DB
+	orgID uint
+}
+
+func NewUserRepository() *UserRepository {
+	return &UserRepository{
+		db: database.PostgresDB,
+	}
+}
+
+// ForOrganization returns a repository whose queries only see members of orgID
+// and whose Create assigns new users to it.
+func (r *UserRepository) ForOrganization(orgID uint) *UserRepository {
+	return &UserRepository{
+		db:    r.db.Where("users.organization_id = ?", orgID).Session(&gorm.Session{}),
+		orgID: orgID,
+	}
+}
+
+func (r *UserRepository) Create(user *models.User) error {
+	if r.orgID != 0 {
+		user.OrganizationID = r.orgID
+	}
+	return r.db.Create(user).Error
+}
+
+func (r *UserRepository) FindAll() ([]models.User, error) {
+	var users []models.User
+	err := r.db.Order("created_at DESC").Find(&users).Error
+	return users, err
+}
+
+func (r *UserRepository) FindByID(id uint) (*models.User, error) {
+	var user models.User
+	err := r.db.First(&user, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *UserRepository) FindByUsername(username string) (*models.User, error) {
+	var user models.User
+	err := r.db.Where("username = ?", username).First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// FindByExternalID finds a user provisioned by an external identity provider
+func (r *UserRepository) FindByExternalID(provider, externalID string) (*models.User, error) {
+	var user models.User
+	err := r.db.Where("auth_provider = ? AND external_id = ?", provider, externalID).First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// FindByEmail returns the users of provider with email, ignoring case
+func (r *UserRepository) FindByEmail(provider, email string) ([]models.User, error) {
+	var users []models.User
+	err := r.db.Where("auth_provider = ? AND LOWER(email) = LOWER(?)", provider, email).Find(&users).Error
+	return users, err
+}
+
+func (r *UserRepository) Update(user *models.User) error {
+	return r.db.Save(user).Error
+}
+
+func (r *UserRepository) ExistsByUsername(username string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.User{}).Where("username = ?", username).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *UserRepository) Count() (int64, error) {
+	var count int64
+	err := r.db.Model(&models.User{}).Count(&count).Error
+	return count, err
+}
+
+func (r *UserRepository) CountByRole(role string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.User{}).Where("role = ?", role).Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/repositories"
+)
+
+var (
+	ErrForbidden       = errors.New("insufficient permissions")
+	ErrProjectNotFound = errors.New("project not found")
+)
+
+// AccessService resolves what a caller may do on individual projects by
+// combining their global role with any per-project membership.
+type AccessService struct {
+	memberRepo  *repositories.ProjectMemberRepository
+	userRepo    *repositories.UserRepository
+	projectRepo *repositories.ProjectRepository
+}
+
+func NewAccessService(memberRepo *repositories.ProjectMemberRepository, userRepo *repositories.UserRepository, projectRepo *repositories.ProjectRepository) *AccessService {
+	return &AccessService{
+		memberRepo:  memberRepo,
+		userRepo:    userRepo,
+		projectRepo: projectRepo,
+	}
+}
+
+// ProjectRole returns the effective role of the caller on a project
+func (s *AccessService) ProjectRole(principal *models.Principal, projectID uint) (string, error) {
+	memberRole, err := s.memberRepo.FindRole(projectID, principal.UserID)
+	if err != nil {
+		return "", err
+	}
+	return models.HigherRole(principal.Role, memberRole), nil
+}
+
+// RequireProjectRole returns ErrProjectNotFound if the project is outside the caller's
+// organization, and ErrForbidden unless the caller holds at least role on it
+func (s *AccessService) RequireProjectRole(principal *models.Principal, projectID uint, role string) error {
+	exists, err := s.projectRepo.ForOrganization(principal.OrganizationID).Exists(projectID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrProjectNotFound
+	}
+
+	effective, err := s.ProjectRole(principal, projectID)
+	if err != nil {
+		return err
+	}
+	if !models.RoleAtLeast(effective, role) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// AccessibleProjectIDs lists the projects the caller holds at least role on.
+// all is true when the caller's global role already covers every project.
+func (s *AccessService) AccessibleProjectIDs(principal *models.Principal, role string) (ids []uint, all bool, err error) {
+	if models.RoleAtLeast(principal.Role, role) {
+		return nil, true, nil
+	}
+
+	memberships, err := s.memberRepo.FindByUserID(principal.UserID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	for _, m := range memberships {
+		if models.RoleAtLeast(m.Role, role) {
+			ids = append(ids, m.ProjectID)
+		}
+	}
+	return ids, false, nil
+}
+
+func (s *AccessService) GetProjectMembers(projectID uint) ([]models.ProjectMember, error) {
+	return s.memberRepo.FindByProjectID(projectID)
+}
+
+func (s *AccessService) AddProjectMember(orgID uint, projectID uint, req *models.AddProjectMemberRequest) (*models.ProjectMember, error) {
+	if _, err := s.userRepo.ForOrganization(orgID).FindByID(req.UserID); err != nil {
+		return nil, err
+	}
+
+	member := &models.ProjectMember{
+		ProjectID: projectID,
+		UserID:    req.UserID,
+		Role:      req.Role,
+	}
+	if err := s.memberRepo.Upsert(member); err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+func (s *AccessService) RemoveProjectMember(projectID, userID uint) error {
+	return s.memberRepo.Delete(projectID, userID)
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/repositories"
+)
+
+// apiKeyTouchInterval limits how often last-used timestamps are written
+const apiKeyTouchInterval = time.Minute
+
+var scopePattern = regexp.MustCompile(`^(\*|[a-z0-9-]+:(read|write))$`)
+
+type APIKeyService struct {
+	repo     *repositories.APIKeyRepository
+	userRepo *repositories.UserRepository
+}
+
+func NewAPIKeyService(repo *repositories.APIKeyRepository, userRepo *repositories.UserRepository) *APIKeyService {
+	return &APIKeyService{
+		repo:     repo,
+		userRepo: userRepo,
+	}
+}
+
+// CreateAPIKey issues a new key for the caller, or for their organization when
+// req.Organization is set. The plaintext key is only available in the result.
+func (s *APIKeyService) CreateAPIKey(principal *models.Principal, req *models.CreateAPIKeyRequest) (*models.CreatedAPIKey, error) {
+	if principal.APIKeyID != 0 {
+		return nil, errors.New("api keys cannot be used to create api keys")
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("name cannot be empty")
+	}
+
+	scopes := make([]string, 0, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		scope = strings.ToLower(strings.TrimSpace(scope))
+		if !scopePattern.MatchString(scope) {
+			return nil, errors.New("invalid scope " + scope + ": expected <resource>:read, <resource>:write or *")
+		}
+		scopes = append(scopes, scope)
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, errors.New("expiresAt must be in the future")
+	}
+
+	key := &models.APIKey{
+		OrganizationID: principal.OrganizationID,
+		CreatedByID:    principal.UserID,
+		Name:           name,
+		Scopes:         scopes,
+		ExpiresAt:      req.ExpiresAt,
+	}
+
+	if req.Organization {
+		if !models.RoleAtLeast(principal.Role, models.RoleAdmin) {
+			return nil, ErrForbidden
+		}
+		role := req.Role
+		if role == "" {
+			role = models.RoleViewer
+		}
+		key.Role = role
+	} else {
+		userID := principal.UserID
+		key.UserID = &userID
+	}
+
+	plaintext, err := generateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+	key.KeyHash = hashAPIKey(plaintext)
+	key.Prefix = plaintext[:12]
+
+	if err := s.repo.Create(key); err != nil {
+		return nil, err
+	}
+
+	return &models.CreatedAPIKey{APIKey: *key, Key: plaintext}, nil
+}
+
+// GetAPIKeys lists the caller's own keys; admins also see organization keys
+func (s *APIKeyService) GetAPIKeys(principal *models.Principal) ([]models.APIKey, error) {
+	includeOrganization := models.RoleAtLeast(principal.Role, models.RoleAdmin)
+	return s.repo.FindVisible(principal.OrganizationID, principal.UserID, includeOrganization)
+}
+
+// RevokeAPIKey disables a key. Owners may revoke their own keys; admins may
+// revoke any key in their organization.
+func (s *APIKeyService) RevokeAPIKey(principal *models.Principal, id uint) (*models.APIKey, error) {
+	key, err := s.repo.FindByID(principal.OrganizationID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	isOwner := key.UserID != nil && *key.UserID == principal.UserID
+	if !isOwner && !models.RoleAtLeast(principal.Role, models.RoleAdmin) {
+		return nil, errors.New("api key not found")
+	}
+
+	if key.RevokedAt != nil {
+		return key, nil
+	}
+
+	now := time.Now()
+	key.RevokedAt = &now
+	if err := s.repo.Update(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Authenticate resolves an API key presented as a bearer token to the caller it acts as
+func (s *APIKeyService) Authenticate(plaintext string) (*models.Principal, error) {
+	key, err := s.repo.FindByHash(hashAPIKey(plaintext))
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	now := time.Now()
+	if key.RevokedAt != nil || (key.ExpiresAt != nil && !key.ExpiresAt.After(now)) {
+		return nil, ErrInvalidToken
+	}
+
+	principal := &models.Principal{
+		OrganizationID: key.OrganizationID,
+		APIKeyID:       key.ID,
+		Scopes:         key.Scopes,
+	}
+
+	if key.UserID != nil {
+		user, err := s.userRepo.FindByID(*key.UserID)
+		if err != nil || !user.Active || user.OrganizationID != key.OrganizationID {
+			return nil, ErrInvalidToken
+		}
+		principal.UserID = user.ID
+		principal.Username = user.Username
+		principal.Role = user.Role
+	} else {
+		principal.Username = "apikey:" + key.Name
+		principal.Role = key.Role
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > apiKeyTouchInterval {
+		_ = s.repo.TouchLastUsed(key.ID, now)
+	}
+
+	return principal, nil
+}
+
+func generateAPIKey() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return models.APIKeyPrefix + base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func hashAPIKey(plaintext string) string {
+	sum := sha256.Sum256([]byte(plaintext))
+	return hex.EncodeToString(sum[:])
+}
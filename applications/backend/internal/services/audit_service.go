@@ -0,0 +1,112 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/repositories"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+// ignoredAuditFields change on every write and would only add noise to diffs
+var ignoredAuditFields = map[string]bool{
+	"updatedAt":  true,
+	"updated_at": true,
+}
+
+type AuditService struct {
+	repo *repositories.AuditRepository
+}
+
+func NewAuditService(repo *repositories.AuditRepository) *AuditService {
+	return &AuditService{repo: repo}
+}
+
+// Record stores an audit entry. Failures are logged rather than returned so an
+// unavailable audit store never blocks the action being audited.
+func (s *AuditService) Record(entry *models.AuditLog) {
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now().UTC()
+	}
+	if entry.Outcome != models.AuditOutcomeFailure && (entry.Before != nil || entry.After != nil) {
+		entry.Changes = diffAuditState(entry.Before, entry.After)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := s.repo.Create(ctx, entry); err != nil {
+		log.Printf("⚠️  Failed to record audit entry %s %s/%s by %s: %v",
+			entry.Action, entry.EntityType, entry.EntityID, entry.ActorName, err)
+	}
+}
+
+func (s *AuditService) GetAuditLogs(query *models.AuditQuery) ([]models.AuditLog, error) {
+	if query.Limit <= 0 {
+		query.Limit = defaultAuditLimit
+	}
+	if query.Limit > maxAuditLimit {
+		query.Limit = maxAuditLimit
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	return s.repo.Find(ctx, query)
+}
+
+// AuditSnapshot converts an entity into the generic JSON form stored in audit entries
+func AuditSnapshot(v interface{}) map[string]interface{} {
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil()) {
+		return nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+
+	var snapshot map[string]interface{}
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil
+	}
+	return snapshot
+}
+
+// diffAuditState lists the top-level fields that differ between two snapshots
+func diffAuditState(before, after map[string]interface{}) []models.AuditChange {
+	keys := make(map[string]bool)
+	for k := range before {
+		keys[k] = true
+	}
+	for k := range after {
+		keys[k] = true
+	}
+
+	fields := make([]string, 0, len(keys))
+	for k := range keys {
+		if !ignoredAuditFields[k] {
+			fields = append(fields, k)
+		}
+	}
+	sort.Strings(fields)
+
+	var changes []models.AuditChange
+	for _, field := range fields {
+		b, a := before[field], after[field]
+		if reflect.DeepEqual(b, a) {
+			continue
+		}
+		changes = append(changes, models.AuditChange{Field: field, Before: b, After: a})
+	}
+	return changes
+}
@@ -0,0 +1,355 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/repositories"
+)
+
+const (
+	tokenIssuer      = "clouddeck"
+	tokenTypeAccess  = "access"
+	tokenTypeRefresh = "refresh"
+	// minJWTSecretLength is the shortest JWT_SECRET accepted, the key size of HS256
+	minJWTSecretLength = 32
+)
+
+var (
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	ErrInvalidToken       = errors.New("invalid or expired token")
+)
+
+type AuthService struct {
+	userRepo   *repositories.UserRepository
+	orgRepo    *repositories.OrganizationRepository
+	secret     []byte
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+}
+
+// authClaims are the claims carried by CloudDeck access and refresh tokens
+type authClaims struct {
+	UserID    uint   `json:"uid"`
+	Username  string `json:"usr"`
+	Role      string `json:"role"`
+	OrgID     uint   `json:"org"`
+	TokenType string `json:"typ"`
+	jwt.RegisteredClaims
+}
+
+func NewAuthService(userRepo *repositories.UserRepository, orgRepo *repositories.OrganizationRepository) *AuthService {
+	secret := []byte(os.Getenv("JWT_SECRET"))
+	if len(secret) > 0 && len(secret) < minJWTSecretLength {
+		log.Fatalf("❌ JWT_SECRET must be at least %d bytes, got %d", minJWTSecretLength, len(secret))
+	}
+	if len(secret) == 0 {
+		// Tokens signed with a random secret stop validating on restart,
+		// which is acceptable for local development only.
+		log.Println("⚠️  JWT_SECRET not set, using a random signing key")
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			log.Fatalf("Failed to generate JWT signing key: %v", err)
+		}
+	}
+
+	return &AuthService{
+		userRepo:   userRepo,
+		orgRepo:    orgRepo,
+		secret:     secret,
+		accessTTL:  durationFromEnv("JWT_ACCESS_TTL", 15*time.Minute),
+		refreshTTL: durationFromEnv("JWT_REFRESH_TTL", 7*24*time.Hour),
+	}
+}
+
+// EnsureBootstrapAdmin creates the first user from ADMIN_USERNAME/ADMIN_PASSWORD
+// when the users table is empty, so a fresh install can be logged into. If users
+// exist but none is an admin, the ADMIN_USERNAME account is promoted instead.
+func (s *AuthService) EnsureBootstrapAdmin() error {
+	username := os.Getenv("ADMIN_USERNAME")
+	password := os.Getenv("ADMIN_PASSWORD")
+
+	count, err := s.userRepo.Count()
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return s.promoteBootstrapAdmin(username)
+	}
+
+	if username == "" || password == "" {
+		log.Println("⚠️  No users exist; set ADMIN_USERNAME and ADMIN_PASSWORD to create the first account")
+		return nil
+	}
+
+	org, err := s.orgRepo.FindBySlug(models.DefaultOrganizationSlug)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.CreateUser(org.ID, &models.CreateUserRequest{
+		Username: username,
+		Password: password,
+		Name:     "Administrator",
+		Role:     models.RoleAdmin,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create bootstrap admin: %w", err)
+	}
+
+	log.Printf("✅ Created bootstrap user %q", username)
+	return nil
+}
+
+func (s *AuthService) promoteBootstrapAdmin(username string) error {
+	admins, err := s.userRepo.CountByRole(models.RoleAdmin)
+	if err != nil || admins > 0 || username == "" {
+		return err
+	}
+
+	user, err := s.userRepo.FindByUsername(username)
+	if err != nil {
+		return nil
+	}
+
+	user.Role = models.RoleAdmin
+	if err := s.userRepo.Update(user); err != nil {
+		return fmt.Errorf("failed to promote bootstrap admin: %w", err)
+	}
+
+	log.Printf("✅ Promoted %q to admin", username)
+	return nil
+}
+
+// CreateUser adds a password-authenticated user to an organization
+func (s *AuthService) CreateUser(orgID uint, req *models.CreateUserRequest) (*models.User, error) {
+	user, err := s.newLocalUser(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.ForOrganization(orgID).Create(user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// newLocalUser validates req and builds a password account from it, without storing it
+func (s *AuthService) newLocalUser(req *models.CreateUserRequest) (*models.User, error) {
+	username := strings.TrimSpace(req.Username)
+	if username == "" {
+		return nil, errors.New("username cannot be empty")
+	}
+
+	exists, err := s.userRepo.ExistsByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, errors.New("user with this username already exists")
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	role := req.Role
+	if role == "" {
+		role = models.RoleViewer
+	}
+
+	user := &models.User{
+		Username:     username,
+		Email:        strings.TrimSpace(req.Email),
+		Name:         strings.TrimSpace(req.Name),
+		PasswordHash: string(hash),
+		AuthProvider: models.AuthProviderLocal,
+		Role:         role,
+		Active:       true,
+	}
+
+	return user, nil
+}
+
+func (s *AuthService) GetAllUsers(orgID uint) ([]models.User, error) {
+	return s.userRepo.ForOrganization(orgID).FindAll()
+}
+
+func (s *AuthService) GetUserByID(id uint) (*models.User, error) {
+	return s.userRepo.FindByID(id)
+}
+
+// GetOrganizationUser returns user id when it is a member of orgID
+func (s *AuthService) GetOrganizationUser(orgID uint, id uint) (*models.User, error) {
+	return s.userRepo.ForOrganization(orgID).FindByID(id)
+}
+
+// UpdateUserRole changes a user's global role; it applies from their next token refresh
+func (s *AuthService) UpdateUserRole(orgID uint, id uint, role string) (*models.User, error) {
+	repo := s.userRepo.ForOrganization(orgID)
+
+	user, err := repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	user.Role = role
+	if err := repo.Update(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// Login verifies a username/password pair and issues a new token pair
+func (s *AuthService) Login(req *models.LoginRequest) (*models.TokenPair, error) {
+	user, err := s.userRepo.FindByUsername(strings.TrimSpace(req.Username))
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	if !user.Active || user.PasswordHash == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	return s.CompleteLogin(user)
+}
+
+// CompleteLogin records a successful sign-in, however the user authenticated,
+// and issues their token pair
+func (s *AuthService) CompleteLogin(user *models.User) (*models.TokenPair, error) {
+	now := time.Now()
+	user.LastLoginAt = &now
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(user)
+}
+
+// Refresh exchanges a valid refresh token for a new token pair
+func (s *AuthService) Refresh(refreshToken string) (*models.TokenPair, error) {
+	claims, err := s.parseToken(refreshToken, tokenTypeRefresh)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByID(claims.UserID)
+	if err != nil || !user.Active {
+		return nil, ErrInvalidToken
+	}
+
+	return s.issueTokens(user)
+}
+
+// ValidateAccessToken parses an access token and returns the caller it identifies
+func (s *AuthService) ValidateAccessToken(accessToken string) (*models.Principal, error) {
+	claims, err := s.parseToken(accessToken, tokenTypeAccess)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.Principal{
+		UserID:         claims.UserID,
+		Username:       claims.Username,
+		Role:           claims.Role,
+		OrganizationID: claims.OrgID,
+	}, nil
+}
+
+func (s *AuthService) issueTokens(user *models.User) (*models.TokenPair, error) {
+	now := time.Now()
+	accessExpiry := now.Add(s.accessTTL)
+
+	accessToken, err := s.signToken(user, tokenTypeAccess, now, accessExpiry)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := s.signToken(user, tokenTypeRefresh, now, now.Add(s.refreshTTL))
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresAt:    accessExpiry,
+		User:         user,
+	}, nil
+}
+
+func (s *AuthService) signToken(user *models.User, tokenType string, issuedAt, expiresAt time.Time) (string, error) {
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", fmt.Errorf("failed to generate token id: %w", err)
+	}
+
+	claims := authClaims{
+		UserID:    user.ID,
+		Username:  user.Username,
+		Role:      user.Role,
+		OrgID:     user.OrganizationID,
+		TokenType: tokenType,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    tokenIssuer,
+			Subject:   fmt.Sprintf("%d", user.ID),
+			ID:        hex.EncodeToString(jti),
+			IssuedAt:  jwt.NewNumericDate(issuedAt),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signed, err := token.SignedString(s.secret)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+	return signed, nil
+}
+
+func (s *AuthService) parseToken(tokenString string, expectedType string) (*authClaims, error) {
+	claims := &authClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		return s.secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(tokenIssuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	if claims.TokenType != expectedType {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+func durationFromEnv(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("⚠️  Invalid %s %q, using %v", key, value, defaultValue)
+		return defaultValue
+	}
+	return d
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
+)
+
+func TestValidateAccessToken(t *testing.T) {
+	service := &AuthService{secret: bytes.Repeat([]byte{1}, 32), accessTTL: time.Minute, refreshTTL: time.Hour}
+	user := &models.User{ID: 7, Username: "ada", Role: models.RoleOperator, OrganizationID: 3}
+
+	tokens, err := service.issueTokens(user)
+	if err != nil {
+		t.Fatalf("issueTokens: %v", err)
+	}
+
+	now := time.Now()
+	expired, err := service.signToken(user, tokenTypeAccess, now.Add(-time.Hour), now.Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("signToken: %v", err)
+	}
+	otherSecret := &AuthService{secret: bytes.Repeat([]byte{2}, 32)}
+	forged, err := otherSecret.signToken(user, tokenTypeAccess, now, now.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("signToken: %v", err)
+	}
+	unsigned, err := jwt.NewWithClaims(jwt.SigningMethodNone, authClaims{
+		UserID:           user.ID,
+		Role:             models.RoleAdmin,
+		TokenType:        tokenTypeAccess,
+		RegisteredClaims: jwt.RegisteredClaims{Issuer: tokenIssuer, ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute))},
+	}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatalf("sign unsigned token: %v", err)
+	}
+	foreign, err := jwt.NewWithClaims(jwt.SigningMethodHS256, authClaims{
+		UserID:           user.ID,
+		TokenType:        tokenTypeAccess,
+		RegisteredClaims: jwt.RegisteredClaims{Issuer: "elsewhere", ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute))},
+	}).SignedString(service.secret)
+	if err != nil {
+		t.Fatalf("sign foreign token: %v", err)
+	}
+	noExpiry, err := jwt.NewWithClaims(jwt.SigningMethodHS256, authClaims{
+		UserID:           user.ID,
+		TokenType:        tokenTypeAccess,
+		RegisteredClaims: jwt.RegisteredClaims{Issuer: tokenIssuer},
+	}).SignedString(service.secret)
+	if err != nil {
+		t.Fatalf("sign token without expiry: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		ok    bool
+	}{
+		{"access token", tokens.AccessToken, true},
+		{"refresh token", tokens.RefreshToken, false},
+		{"expired", expired, false},
+		{"other secret", forged, false},
+		{"alg none", unsigned, false},
+		{"other issuer", foreign, false},
+		{"no expiry", noExpiry, false},
+		{"garbage", "not.a.token", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := service.ValidateAccessToken(tt.token)
+			if !tt.ok {
+				if !errors.Is(err, ErrInvalidToken) {
+					t.Fatalf("ValidateAccessToken error = %v, want ErrInvalidToken", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ValidateAccessToken: %v", err)
+			}
+			if principal.UserID != 7 || principal.Username != "ada" || principal.Role != models.RoleOperator ||
+				principal.OrganizationID != 3 || principal.APIKeyID != 0 {
+				t.Fatalf("principal = %+v, want user 7 (ada), operator in organization 3", principal)
+			}
+		})
+	}
+}
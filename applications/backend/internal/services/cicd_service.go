@@ -10,7 +10,7 @@ import (
 )
 
 type CICDService struct {
-	githubClient *github.Client
+	credentials *CredentialService
 }
 
 type WorkflowRun struct {
@@ -37,13 +37,21 @@ type PipelineStats struct {
 	AvgDuration    string  `json:"avg_duration"`
 }
 
-func NewCICDService(token string) *CICDService {
-	client := createGitHubClient(token)
+func NewCICDService(credentials *CredentialService) *CICDService {
 	return &CICDService{
-		githubClient: client,
+		credentials: credentials,
 	}
 }
 
+// clientFor resolves a vault credential and returns a GitHub client using it
+func (s *CICDService) clientFor(userID, credentialID uint) (*github.Client, error) {
+	token, err := s.credentials.ResolveSecret(userID, credentialID)
+	if err != nil {
+		return nil, err
+	}
+	return createGitHubClient(token), nil
+}
+
 // createGitHubClient creates a new GitHub client with authentication
 func createGitHubClient(token string) *github.Client {
 	if token == "" {
@@ -57,7 +65,11 @@ func createGitHubClient(token string) *github.Client {
 }
 
 // GetWorkflowRuns fetches workflow runs for a repository
-func (s *CICDService) GetWorkflowRuns(owner, repo string, limit int) ([]WorkflowRun, error) {
+func (s *CICDService) GetWorkflowRuns(userID, credentialID uint, owner, repo string, limit int) ([]WorkflowRun, error) {
+	client, err := s.clientFor(userID, credentialID)
+	if err != nil {
+		return nil, err
+	}
 	ctx := context.Background()
 
 	opts := &github.ListWorkflowRunsOptions{
@@ -66,7 +78,7 @@ func (s *CICDService) GetWorkflowRuns(owner, repo string, limit int) ([]Workflow
 		},
 	}
 
-	runs, _, err := s.githubClient.Actions.ListRepositoryWorkflowRuns(ctx, owner, repo, opts)
+	runs, _, err := client.Actions.ListRepositoryWorkflowRuns(ctx, owner, repo, opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch workflow runs: %v", err)
 	}
@@ -115,7 +127,11 @@ func (s *CICDService) GetWorkflowRuns(owner, repo string, limit int) ([]Workflow
 }
 
 // GetPipelineStats calculates statistics for workflow runs
-func (s *CICDService) GetPipelineStats(owner, repo string) (*PipelineStats, error) {
+func (s *CICDService) GetPipelineStats(userID, credentialID uint, owner, repo string) (*PipelineStats, error) {
+	client, err := s.clientFor(userID, credentialID)
+	if err != nil {
+		return nil, err
+	}
 	ctx := context.Background()
 
 	opts := &github.ListWorkflowRunsOptions{
@@ -124,7 +140,7 @@ func (s *CICDService) GetPipelineStats(owner, repo string) (*PipelineStats, erro
 		},
 	}
 
-	runs, _, err := s.githubClient.Actions.ListRepositoryWorkflowRuns(ctx, owner, repo, opts)
+	runs, _, err := client.Actions.ListRepositoryWorkflowRuns(ctx, owner, repo, opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch workflow runs: %v", err)
 	}
@@ -159,10 +175,14 @@ func (s *CICDService) GetPipelineStats(owner, repo string) (*PipelineStats, erro
 }
 
 // GetWorkflows lists all workflows in a repository
-func (s *CICDService) GetWorkflows(owner, repo string) ([]*github.Workflow, error) {
+func (s *CICDService) GetWorkflows(userID, credentialID uint, owner, repo string) ([]*github.Workflow, error) {
+	client, err := s.clientFor(userID, credentialID)
+	if err != nil {
+		return nil, err
+	}
 	ctx := context.Background()
 
-	workflows, _, err := s.githubClient.Actions.ListWorkflows(ctx, owner, repo, nil)
+	workflows, _, err := client.Actions.ListWorkflows(ctx, owner, repo, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch workflows: %v", err)
 	}
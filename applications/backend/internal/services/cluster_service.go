@@ -0,0 +1,380 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/repositories"
+)
+
+const (
+	// defaultClusterRetry is how long a failed default-cluster load is cached
+	defaultClusterRetry  = 30 * time.Second
+	clusterHealthTimeout = 5 * time.Second
+	// localClusterName names the server's own cluster
+	localClusterName = "local"
+)
+
+var (
+	ErrClusterNotFound    = errors.New("cluster not found")
+	ErrClusterUnavailable = errors.New("cluster unavailable")
+	ErrLocalCluster       = errors.New("the server's own cluster cannot be removed")
+)
+
+// ClusterService manages registered cluster connections and keeps a registry of
+// clients keyed by cluster ID, so one broken cluster never hides the others.
+// The cluster the server itself runs in is a cluster of the default
+// organization like any other; see EnsureLocalCluster.
+type ClusterService struct {
+	repo   *repositories.ClusterRepository
+	vault  *Vault
+	policy targetPolicy
+
+	mu               sync.Mutex
+	clients          map[uint]*KubernetesService
+	defaultClient    *KubernetesService
+	defaultErr       error
+	defaultCheckedAt time.Time
+	// localOrgID is the organization EnsureLocalCluster records the server's
+	// own cluster for, and localRecorded whether it has
+	localOrgID    uint
+	localRecorded bool
+}
+
+// NewClusterService creates the service; vault may be nil, in which case
+// registered clusters cannot be added or connected to.
+func NewClusterService(repo *repositories.ClusterRepository, vault *Vault) *ClusterService {
+	return &ClusterService{
+		repo:    repo,
+		vault:   vault,
+		policy:  targetPolicyFromEnv(),
+		clients: make(map[uint]*KubernetesService),
+	}
+}
+
+// CreateCluster validates and stores a kubeconfig for orgID
+func (s *ClusterService) CreateCluster(orgID uint, userID uint, req *models.CreateClusterRequest) (*models.Cluster, error) {
+	if s.vault == nil {
+		return nil, ErrVaultUnavailable
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("name cannot be empty")
+	}
+	if name == localClusterName {
+		return nil, fmt.Errorf("%q is reserved for the server's own cluster", localClusterName)
+	}
+
+	repo := s.repo.ForOrganization(orgID)
+	exists, err := repo.ExistsByName(name)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, errors.New("cluster with this name already exists")
+	}
+
+	contextName, config, err := parseKubeconfig([]byte(req.Kubeconfig), req.Context, s.policy)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, ciphertext, err := s.vault.Seal([]byte(req.Kubeconfig), clusterAAD(orgID, name))
+	if err != nil {
+		return nil, err
+	}
+
+	cluster := &models.Cluster{
+		Name:        name,
+		Context:     contextName,
+		Server:      config.Host,
+		Ciphertext:  ciphertext,
+		Nonce:       nonce,
+		CreatedByID: userID,
+	}
+	if err := repo.Create(cluster); err != nil {
+		return nil, err
+	}
+	return cluster, nil
+}
+
+func (s *ClusterService) GetClusters(orgID uint) ([]models.Cluster, error) {
+	s.retryLocalCluster()
+	return s.repo.ForOrganization(orgID).FindAll()
+}
+
+func (s *ClusterService) GetCluster(orgID uint, id uint) (*models.Cluster, error) {
+	cluster, err := s.repo.ForOrganization(orgID).FindByID(id)
+	if err != nil {
+		return nil, ErrClusterNotFound
+	}
+	return cluster, nil
+}
+
+func (s *ClusterService) DeleteCluster(orgID uint, id uint) error {
+	cluster, err := s.GetCluster(orgID, id)
+	if err != nil {
+		return err
+	}
+	if cluster.Local {
+		return ErrLocalCluster
+	}
+	if err := s.repo.ForOrganization(orgID).Delete(id); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	client := s.clients[id]
+	delete(s.clients, id)
+	s.mu.Unlock()
+
+	if client != nil {
+		client.Close()
+	}
+	return nil
+}
+
+// LocalClusterID returns the ID of the cluster the server itself runs in when
+// it is a cluster of orgID, for the /api/kubernetes routes that predate
+// registered clusters
+func (s *ClusterService) LocalClusterID(orgID uint) (uint, error) {
+	s.retryLocalCluster()
+	cluster, err := s.repo.ForOrganization(orgID).FindLocal()
+	if err != nil {
+		return 0, ErrClusterNotFound
+	}
+	return cluster.ID, nil
+}
+
+// Client returns the client for a registered cluster of orgID, connecting on first use
+func (s *ClusterService) Client(orgID uint, id uint) (*KubernetesService, error) {
+	cluster, err := s.GetCluster(orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if cluster.Local {
+		return s.local()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if client, ok := s.clients[cluster.ID]; ok {
+		return client, nil
+	}
+
+	if s.vault == nil {
+		return nil, fmt.Errorf("%w: %v", ErrClusterUnavailable, ErrVaultUnavailable)
+	}
+	kubeconfig, err := s.vault.Open(cluster.Nonce, cluster.Ciphertext, clusterAAD(cluster.OrganizationID, cluster.Name))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrClusterUnavailable, err)
+	}
+
+	_, config, err := parseKubeconfig(kubeconfig, cluster.Context, s.policy)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrClusterUnavailable, err)
+	}
+
+	client, err := newKubernetesServiceForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrClusterUnavailable, err)
+	}
+
+	s.clients[cluster.ID] = client
+	return client, nil
+}
+
+// EnsureLocalCluster records the cluster the server itself runs in as a
+// cluster of orgID, the default organization, unless it already is. Nothing is
+// recorded while that cluster's configuration cannot be loaded; listing
+// clusters retries then, at most every defaultClusterRetry, so a cluster that
+// becomes reachable after startup still shows up.
+func (s *ClusterService) EnsureLocalCluster(orgID uint) (*models.Cluster, error) {
+	s.mu.Lock()
+	s.localOrgID = orgID
+	s.mu.Unlock()
+
+	client, err := s.local()
+	if err != nil {
+		return nil, err
+	}
+	cluster, err := s.repo.FindLocal()
+	if err != nil {
+		cluster = &models.Cluster{
+			OrganizationID: orgID,
+			Name:           localClusterName,
+			Server:         client.config.Host,
+		}
+		if err := s.repo.CreateLocal(cluster); err != nil {
+			return nil, err
+		}
+	}
+
+	s.mu.Lock()
+	s.localRecorded = true
+	s.mu.Unlock()
+	return cluster, nil
+}
+
+// retryLocalCluster repeats a startup EnsureLocalCluster that recorded nothing
+func (s *ClusterService) retryLocalCluster() {
+	s.mu.Lock()
+	orgID, recorded := s.localOrgID, s.localRecorded
+	s.mu.Unlock()
+
+	if orgID != 0 && !recorded {
+		s.EnsureLocalCluster(orgID)
+	}
+}
+
+// local returns the client for the cluster the server itself runs in or
+// $HOME/.kube/config points at. Load failures are retried after
+// defaultClusterRetry.
+func (s *ClusterService) local() (*KubernetesService, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.defaultClient != nil {
+		return s.defaultClient, nil
+	}
+	if s.defaultErr != nil && time.Since(s.defaultCheckedAt) < defaultClusterRetry {
+		return nil, s.defaultErr
+	}
+
+	s.defaultCheckedAt = time.Now()
+	client, err := NewKubernetesService()
+	if err != nil {
+		s.defaultErr = fmt.Errorf("%w: %v", ErrClusterUnavailable, err)
+		return nil, s.defaultErr
+	}
+
+	s.defaultClient = client
+	s.defaultErr = nil
+	return client, nil
+}
+
+// GetClusterStatuses lists the clusters of orgID with their health, probing them concurrently
+func (s *ClusterService) GetClusterStatuses(orgID uint) ([]models.ClusterStatus, error) {
+	clusters, err := s.GetClusters(orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]models.ClusterStatus, len(clusters))
+	var wg sync.WaitGroup
+	for i := range clusters {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			statuses[i] = models.ClusterStatus{
+				Cluster: clusters[i],
+				Health:  s.ClusterHealth(orgID, clusters[i].ID),
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	return statuses, nil
+}
+
+// ClusterHealth probes one registered cluster
+func (s *ClusterService) ClusterHealth(orgID uint, id uint) models.ClusterHealth {
+	client, err := s.Client(orgID, id)
+	if err != nil {
+		return models.ClusterHealth{
+			Status:    models.ClusterStatusError,
+			Error:     err.Error(),
+			CheckedAt: time.Now().UTC(),
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), clusterHealthTimeout)
+	defer cancel()
+	return client.Health(ctx)
+}
+
+// parseKubeconfig selects a context from a kubeconfig and builds its REST config.
+// Kubeconfigs that would make the server run commands, read local files or,
+// under policy, connect into its own network are rejected, since they are
+// supplied over the API.
+func parseKubeconfig(data []byte, contextName string, policy targetPolicy) (string, *rest.Config, error) {
+	kubeconfig, err := clientcmd.Load(data)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid kubeconfig: %v", err)
+	}
+
+	if contextName == "" {
+		contextName = kubeconfig.CurrentContext
+	}
+	kubeContext, ok := kubeconfig.Contexts[contextName]
+	if contextName == "" || !ok {
+		return "", nil, fmt.Errorf("kubeconfig has no context %q", contextName)
+	}
+
+	if err := checkKubeconfigContext(kubeconfig, kubeContext, policy); err != nil {
+		return "", nil, err
+	}
+
+	config, err := clientcmd.NewNonInteractiveClientConfig(*kubeconfig, contextName, &clientcmd.ConfigOverrides{}, nil).ClientConfig()
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid kubeconfig context %q: %v", contextName, err)
+	}
+	// The API server, or the proxy in front of it, is vetted again when dialed
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Control: policy.control}
+	config.Dial = dialer.DialContext
+	return contextName, config, nil
+}
+
+func checkKubeconfigContext(kubeconfig *clientcmdapi.Config, kubeContext *clientcmdapi.Context, policy targetPolicy) error {
+	cluster, ok := kubeconfig.Clusters[kubeContext.Cluster]
+	if !ok {
+		return fmt.Errorf("kubeconfig has no cluster %q", kubeContext.Cluster)
+	}
+	if cluster.CertificateAuthority != "" {
+		return errors.New("kubeconfig must embed certificate-authority-data rather than reference a file")
+	}
+	for _, target := range []struct{ field, value string }{
+		{"server", cluster.Server},
+		{"proxy-url", cluster.ProxyURL},
+	} {
+		if target.value == "" {
+			continue
+		}
+		targetURL, err := url.Parse(target.value)
+		if err != nil {
+			return fmt.Errorf("kubeconfig cluster has an invalid %s: %v", target.field, err)
+		}
+		if err := policy.checkHost(targetURL.Hostname()); err != nil {
+			return fmt.Errorf("kubeconfig cluster %s: %w", target.field, err)
+		}
+	}
+
+	authInfo, ok := kubeconfig.AuthInfos[kubeContext.AuthInfo]
+	if !ok {
+		return fmt.Errorf("kubeconfig has no user %q", kubeContext.AuthInfo)
+	}
+	if authInfo.Exec != nil || authInfo.AuthProvider != nil {
+		return errors.New("kubeconfig users with exec or auth-provider plugins are not supported; use a token or client certificate")
+	}
+	if authInfo.ClientCertificate != "" || authInfo.ClientKey != "" || authInfo.TokenFile != "" {
+		return errors.New("kubeconfig must embed client credentials rather than reference files")
+	}
+	return nil
+}
+
+func clusterAAD(orgID uint, name string) []byte {
+	return []byte(fmt.Sprintf("clouddeck:cluster:org:%d:%s", orgID, name))
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// testKubeconfig is a kubeconfig for one cluster at server, reached through proxy when set
+func testKubeconfig(server, proxy string) string {
+	proxyLine := ""
+	if proxy != "" {
+		proxyLine = "\n    proxy-url: " + proxy
+	}
+	return fmt.Sprintf(`apiVersion: v1
+kind: Config
+current-context: prod
+clusters:
+- name: prod
+  cluster:
+    server: %s%s
+contexts:
+- name: prod
+  context:
+    cluster: prod
+    user: deploy
+users:
+- name: deploy
+  user:
+    token: secret
+`, server, proxyLine)
+}
+
+func TestParseKubeconfigTargetPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		server  string
+		proxy   string
+		allowed bool
+	}{
+		{"public server", "https://203.0.113.10:6443", "", true},
+		{"public server and proxy", "https://203.0.113.10:6443", "http://198.51.100.7:3128", true},
+		{"loopback server", "https://127.0.0.1:6443", "", false},
+		{"metadata server", "http://169.254.169.254", "", false},
+		{"private server", "https://10.0.0.1:6443", "", false},
+		{"private proxy", "https://203.0.113.10:6443", "socks5://192.168.0.10:1080", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := []byte(testKubeconfig(tt.server, tt.proxy))
+
+			_, config, err := parseKubeconfig(data, "", targetPolicy{})
+			if tt.allowed {
+				if err != nil {
+					t.Fatalf("parseKubeconfig: %v", err)
+				}
+				if config.Dial == nil {
+					t.Fatal("parseKubeconfig left connections to the cluster unchecked")
+				}
+				return
+			}
+			if !errors.Is(err, ErrTargetNotAllowed) {
+				t.Fatalf("parseKubeconfig error = %v, want ErrTargetNotAllowed", err)
+			}
+			if _, _, err := parseKubeconfig(data, "", targetPolicy{allowPrivate: true}); err != nil {
+				t.Fatalf("parseKubeconfig with private targets allowed: %v", err)
+			}
+		})
+	}
+}
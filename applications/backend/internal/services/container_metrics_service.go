@@ -0,0 +1,450 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/docker"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/repositories"
+)
+
+const (
+	// defaultMetricsPoints is the series length used when no step is requested
+	defaultMetricsPoints = 300
+	// maxMetricsPoints bounds the series length a request may ask for
+	maxMetricsPoints = 2000
+	// maxRollupBuckets bounds how much history one rollup pass aggregates, so a
+	// backlog after downtime is worked off over several passes
+	maxRollupBuckets = 288
+)
+
+// ErrUnknownContainer is returned for metrics of a container that neither the
+// daemon nor the sampler's history knows
+var ErrUnknownContainer = errors.New("container not found")
+
+// ContainerMetricsConfig controls sampling and retention. Raw samples are kept
+// for RawRetention, 5-minute rollups for RollupRetention and hourly rollups for
+// HourlyRetention.
+type ContainerMetricsConfig struct {
+	Interval        time.Duration
+	RawRetention    time.Duration
+	RollupRetention time.Duration
+	HourlyRetention time.Duration
+}
+
+// ContainerMetricsConfigFromEnv reads CONTAINER_METRICS_INTERVAL (default 15s,
+// 0 disables sampling) and CONTAINER_METRICS_RETENTION_RAW, _5M and _1H
+// (defaults 24h, 168h and 2160h)
+func ContainerMetricsConfigFromEnv() ContainerMetricsConfig {
+	return ContainerMetricsConfig{
+		Interval:        durationFromEnv("CONTAINER_METRICS_INTERVAL", 15*time.Second),
+		RawRetention:    durationFromEnv("CONTAINER_METRICS_RETENTION_RAW", 24*time.Hour),
+		RollupRetention: durationFromEnv("CONTAINER_METRICS_RETENTION_5M", 7*24*time.Hour),
+		HourlyRetention: durationFromEnv("CONTAINER_METRICS_RETENTION_1H", 90*24*time.Hour),
+	}
+}
+
+// metricTier is one resolution of stored metrics; width is zero for raw samples
+type metricTier struct {
+	width     time.Duration
+	retention time.Duration
+}
+
+func (t metricTier) resolution() int {
+	return int(t.width / time.Second)
+}
+
+// ContainerMetricsService samples container stats of every Docker host into
+// a time series and answers range queries over it
+type ContainerMetricsService struct {
+	hosts     *DockerHostService
+	inventory *repositories.ContainerRepository
+	metrics   *repositories.ContainerMetricRepository
+	config    ContainerMetricsConfig
+	tiers     []metricTier
+	running   sync.WaitGroup
+}
+
+func NewContainerMetricsService(hosts *DockerHostService, inventory *repositories.ContainerRepository, metrics *repositories.ContainerMetricRepository, config ContainerMetricsConfig) *ContainerMetricsService {
+	return &ContainerMetricsService{
+		hosts:     hosts,
+		inventory: inventory,
+		metrics:   metrics,
+		config:    config,
+		tiers: []metricTier{
+			{width: 0, retention: config.RawRetention},
+			{width: 5 * time.Minute, retention: config.RollupRetention},
+			{width: time.Hour, retention: config.HourlyRetention},
+		},
+	}
+}
+
+// Start samples every Interval and rolls up and prunes old data every five
+// minutes until ctx is cancelled. It returns immediately; Wait blocks until the
+// collector has stopped.
+func (s *ContainerMetricsService) Start(ctx context.Context) {
+	if s.config.Interval <= 0 {
+		log.Println("⚠️  Container metrics sampling disabled")
+		return
+	}
+
+	s.running.Add(1)
+	go func() {
+		defer s.running.Done()
+		sampleTicker := time.NewTicker(s.config.Interval)
+		defer sampleTicker.Stop()
+		rollupTicker := time.NewTicker(s.tiers[1].width)
+		defer rollupTicker.Stop()
+
+		s.sampleOnce(ctx)
+		for {
+			select {
+			case <-sampleTicker.C:
+				s.sampleOnce(ctx)
+			case <-rollupTicker.C:
+				if err := s.Rollup(time.Now().UTC()); err != nil {
+					log.Printf("⚠️  Container metrics rollup failed: %v", err)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Wait blocks until the collector started by Start has stopped
+func (s *ContainerMetricsService) Wait() {
+	s.running.Wait()
+}
+
+func (s *ContainerMetricsService) sampleOnce(ctx context.Context) {
+	sampleCtx, cancel := context.WithTimeout(ctx, s.config.Interval)
+	defer cancel()
+	if err := s.Sample(sampleCtx); err != nil && ctx.Err() == nil {
+		log.Printf("⚠️  Container metrics sample failed: %v", err)
+	}
+}
+
+// Sample records one stats sample for every running container of every host
+// and refreshes the container inventory. Hosts are sampled concurrently; one
+// failing host does not keep the others from being recorded.
+func (s *ContainerMetricsService) Sample(ctx context.Context) error {
+	targets, err := s.hosts.targets(nil)
+	if err != nil {
+		return err
+	}
+
+	errs := make([]error, len(targets))
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		if target.err != nil {
+			errs[i] = fmt.Errorf("%s: %w", target.name, target.err)
+			continue
+		}
+		wg.Add(1)
+		go func(i int, target dockerTarget) {
+			defer wg.Done()
+			if err := s.sampleHost(ctx, target.id, target.service); err != nil {
+				errs[i] = fmt.Errorf("%s: %w", target.name, err)
+			}
+		}(i, target)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+func (s *ContainerMetricsService) sampleHost(ctx context.Context, hostID uint, service *ContainerService) error {
+	stats, err := service.GetAllContainers(ctx)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+
+	inventory := make([]models.Container, 0, len(stats))
+	activeIDs := make([]string, 0, len(stats))
+	var points []models.ContainerMetric
+	for _, stat := range stats {
+		activeIDs = append(activeIDs, stat.ContainerID)
+		inventory = append(inventory, models.Container{
+			HostID:      hostID,
+			ContainerID: stat.ContainerID,
+			Name:        stat.Name,
+			Image:       stat.Image,
+			Status:      stat.Status,
+			State:       stat.Status,
+			CPUPercent:  stat.CPUPercent,
+			MemoryUsage: stat.MemoryUsage,
+			MemoryLimit: stat.MemoryLimit,
+			NetworkRx:   stat.NetworkRx,
+			NetworkTx:   stat.NetworkTx,
+		})
+
+		if stat.Status != "running" || stat.StatsError != "" {
+			continue
+		}
+		points = append(points, models.ContainerMetric{
+			ContainerID: stat.ContainerID,
+			Resolution:  models.ContainerMetricRaw,
+			Timestamp:   now,
+			CPUPercent:  stat.CPUPercent,
+			MemoryUsage: stat.MemoryUsage,
+			MemoryLimit: stat.MemoryLimit,
+			NetworkRx:   stat.NetworkRx,
+			NetworkTx:   stat.NetworkTx,
+		})
+	}
+
+	if err := s.inventory.Upsert(inventory); err != nil {
+		return fmt.Errorf("failed to update container inventory: %w", err)
+	}
+	if err := s.inventory.MarkRemoved(hostID, activeIDs); err != nil {
+		return fmt.Errorf("failed to update container inventory: %w", err)
+	}
+	if err := s.metrics.CreateBatch(points); err != nil {
+		return fmt.Errorf("failed to store container metrics: %w", err)
+	}
+	return nil
+}
+
+// Rollup aggregates each tier's completed buckets into the next coarser tier,
+// then deletes points that have outlived their tier's retention
+func (s *ContainerMetricsService) Rollup(now time.Time) error {
+	for i := 1; i < len(s.tiers); i++ {
+		if err := s.rollupTier(s.tiers[i-1], s.tiers[i], now); err != nil {
+			return err
+		}
+	}
+
+	for _, tier := range s.tiers {
+		if tier.retention <= 0 {
+			continue
+		}
+		if _, err := s.metrics.DeleteBefore(tier.resolution(), now.Add(-tier.retention)); err != nil {
+			return fmt.Errorf("failed to prune container metrics: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *ContainerMetricsService) rollupTier(source, target metricTier, now time.Time) error {
+	// Resume after the newest bucket already rolled up, or from the oldest source point
+	start, err := s.metrics.Newest(target.resolution())
+	if err != nil {
+		return err
+	}
+	if start != nil {
+		next := start.Add(target.width)
+		start = &next
+	} else {
+		if start, err = s.metrics.Oldest(source.resolution(), time.Time{}); err != nil || start == nil {
+			return err
+		}
+		truncated := start.Truncate(target.width)
+		start = &truncated
+	}
+
+	// Only completed buckets, and at most maxRollupBuckets of them per pass
+	windowEnd := func(start time.Time) time.Time {
+		end := now.Truncate(target.width)
+		if limit := start.Add(maxRollupBuckets * target.width); end.After(limit) {
+			end = limit
+		}
+		return end
+	}
+
+	var points []models.ContainerMetric
+	for {
+		end := windowEnd(*start)
+		if !start.Before(end) {
+			return nil
+		}
+		if points, err = s.metrics.FindAllRange(source.resolution(), *start, end); err != nil {
+			return err
+		}
+		if len(points) > 0 {
+			break
+		}
+
+		// Nothing was sampled in the window, e.g. while the server was down, so
+		// no rollup would move Newest past it; skip ahead to the bucket of the
+		// next source point
+		next, err := s.metrics.Oldest(source.resolution(), end)
+		if err != nil || next == nil {
+			return err
+		}
+		truncated := next.Truncate(target.width)
+		start = &truncated
+	}
+
+	var rollups []models.ContainerMetric
+	for len(points) > 0 {
+		n := 1
+		for n < len(points) && points[n].ContainerID == points[0].ContainerID {
+			n++
+		}
+		for _, bucket := range aggregateMetrics(points[:n], func(t time.Time) time.Time { return t.Truncate(target.width) }) {
+			bucket.Resolution = target.resolution()
+			rollups = append(rollups, bucket)
+		}
+		points = points[n:]
+	}
+
+	if err := s.metrics.CreateBatch(rollups); err != nil {
+		return fmt.Errorf("failed to store container metric rollups: %w", err)
+	}
+	return nil
+}
+
+// aggregateMetrics merges time-ordered points of one container into buckets:
+// CPU and memory are averaged, the limit is the maximum and the cumulative
+// network counters keep their last value
+func aggregateMetrics(points []models.ContainerMetric, bucketOf func(time.Time) time.Time) []models.ContainerMetric {
+	var buckets []models.ContainerMetric
+	var count int
+	var cpuSum float64
+	var memSum int64
+
+	for _, point := range points {
+		key := bucketOf(point.Timestamp)
+		if len(buckets) == 0 || !buckets[len(buckets)-1].Timestamp.Equal(key) {
+			buckets = append(buckets, models.ContainerMetric{ContainerID: point.ContainerID, Timestamp: key})
+			count, cpuSum, memSum = 0, 0, 0
+		}
+
+		bucket := &buckets[len(buckets)-1]
+		count++
+		cpuSum += point.CPUPercent
+		memSum += point.MemoryUsage
+		bucket.CPUPercent = cpuSum / float64(count)
+		bucket.MemoryUsage = memSum / int64(count)
+		if point.MemoryLimit > bucket.MemoryLimit {
+			bucket.MemoryLimit = point.MemoryLimit
+		}
+		bucket.NetworkRx = point.NetworkRx
+		bucket.NetworkTx = point.NetworkTx
+	}
+	return buckets
+}
+
+// GetMetrics returns the series of a container on hostID, reached through
+// service, between from and to with one point per step. A zero step picks one
+// that yields about defaultMetricsPoints points. The finest stored resolution
+// that still covers from is used.
+func (s *ContainerMetricsService) GetMetrics(ctx context.Context, hostID uint, service *ContainerService, ref string, from, to time.Time, step time.Duration) (*models.ContainerMetricSeries, error) {
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidContainerRequest)
+	}
+	if step < 0 {
+		return nil, fmt.Errorf("%w: step must be positive", ErrInvalidContainerRequest)
+	}
+
+	containerID, name, err := s.resolveContainer(ctx, hostID, service, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	span := to.Sub(from)
+	if step == 0 {
+		step = (span / defaultMetricsPoints).Round(time.Second)
+	}
+	tier := s.tierFor(from, step)
+	if step < s.tierWidth(tier) {
+		step = s.tierWidth(tier)
+	}
+	if step < time.Second {
+		step = time.Second
+	}
+	if span/step > maxMetricsPoints {
+		return nil, fmt.Errorf("%w: step too small, at most %d points may be requested", ErrInvalidContainerRequest, maxMetricsPoints)
+	}
+
+	stored, err := s.metrics.FindRange(containerID, tier.resolution(), from, to)
+	if err != nil {
+		return nil, err
+	}
+	buckets := aggregateMetrics(stored, func(t time.Time) time.Time { return t.Truncate(step) })
+
+	series := &models.ContainerMetricSeries{
+		ContainerID: containerID,
+		Name:        name,
+		From:        from,
+		To:          to,
+		Step:        int(step / time.Second),
+		Resolution:  int(s.tierWidth(tier) / time.Second),
+		Points:      make([]models.ContainerMetricPoint, 0, len(buckets)),
+	}
+	for i, bucket := range buckets {
+		point := models.ContainerMetricPoint{
+			Timestamp:   bucket.Timestamp,
+			CPUPercent:  bucket.CPUPercent,
+			MemoryUsage: bucket.MemoryUsage,
+			MemoryLimit: bucket.MemoryLimit,
+			NetworkRx:   bucket.NetworkRx,
+			NetworkTx:   bucket.NetworkTx,
+		}
+		if i > 0 {
+			previous := buckets[i-1]
+			seconds := bucket.Timestamp.Sub(previous.Timestamp).Seconds()
+			point.NetworkRxRate = counterRate(previous.NetworkRx, bucket.NetworkRx, seconds)
+			point.NetworkTxRate = counterRate(previous.NetworkTx, bucket.NetworkTx, seconds)
+		}
+		series.Points = append(series.Points, point)
+	}
+	return series, nil
+}
+
+// tierFor picks the finest tier whose retention reaches back to from and whose
+// resolution is no coarser than step, falling back to the coarsest tier
+func (s *ContainerMetricsService) tierFor(from time.Time, step time.Duration) metricTier {
+	now := time.Now()
+	for _, tier := range s.tiers {
+		if tier.width > step {
+			break
+		}
+		if tier.retention <= 0 || !from.Before(now.Add(-tier.retention)) {
+			return tier
+		}
+	}
+	return s.tiers[len(s.tiers)-1]
+}
+
+// tierWidth is the tier's bucket width; raw samples are Interval apart
+func (s *ContainerMetricsService) tierWidth(tier metricTier) time.Duration {
+	if tier.width == 0 {
+		return s.config.Interval
+	}
+	return tier.width
+}
+
+// resolveContainer maps an ID, ID prefix or name to the full container ID,
+// asking the daemon first and falling back to the recorded inventory so removed
+// containers keep their history
+func (s *ContainerMetricsService) resolveContainer(ctx context.Context, hostID uint, service *ContainerService, ref string) (string, string, error) {
+	details, err := service.engine.InspectContainer(ctx, ref)
+	if err == nil {
+		return details.ID, strings.TrimPrefix(details.Name, "/"), nil
+	}
+	if !docker.IsNotFound(err) && !errors.Is(err, docker.ErrUnreachable) {
+		return "", "", err
+	}
+
+	container, findErr := s.inventory.FindByRef(hostID, ref)
+	if findErr != nil {
+		return "", "", fmt.Errorf("%w: %s", ErrUnknownContainer, ref)
+	}
+	return container.ContainerID, container.Name, nil
+}
+
+func counterRate(previous, current int64, seconds float64) *float64 {
+	if current < previous || seconds <= 0 {
+		return nil
+	}
+	rate := float64(current-previous) / seconds
+	return &rate
+}
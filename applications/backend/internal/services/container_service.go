@@ -1,120 +1,329 @@
 package services
 
 import (
-	"encoding/json"
-	"os/exec"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
 	"strconv"
 	"strings"
-	"fmt"
+	"sync"
+	"time"
 
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/docker"
 	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
 )
 
-type ContainerService struct{}
+const (
+	// statsConcurrency bounds parallel stats requests to the daemon
+	statsConcurrency = 8
+	statsTimeout     = 5 * time.Second
+	// maxContainerLogBytes caps a non-streaming log fetch
+	maxContainerLogBytes = 10 << 20
+)
+
+// ErrInvalidContainerRequest marks errors caused by bad request parameters
+var ErrInvalidContainerRequest = errors.New("invalid request")
+
+type ContainerService struct {
+	engine docker.Engine
+}
 
-func NewContainerService() (*ContainerService, error) {
-	return &ContainerService{}, nil
+func NewContainerService(engine docker.Engine) *ContainerService {
+	return &ContainerService{engine: engine}
 }
 
-type dockerContainer struct {
-	ID     string `json:"ID"`
-	Names  string `json:"Names"`
-	Status string `json:"Status"`
-	State  string `json:"State"`
-	Image  string `json:"Image"`
+// Health pings the daemon and reports its version
+func (s *ContainerService) Health(ctx context.Context) models.DockerHostHealth {
+	startTime := time.Now()
+	health := models.DockerHostHealth{CheckedAt: startTime.UTC()}
+
+	info, err := s.engine.Version(ctx)
+	health.LatencyMs = time.Since(startTime).Milliseconds()
+	if err != nil {
+		health.Status = models.ClusterStatusUnreachable
+		var apiErr *docker.APIError
+		if errors.As(err, &apiErr) {
+			health.Status = models.ClusterStatusError
+		}
+		health.Error = err.Error()
+		return health
+	}
+
+	health.Status = models.ClusterStatusHealthy
+	health.Version = info.Version
+	health.APIVersion = info.APIVersion
+	health.OS = info.Os
+	health.Arch = info.Arch
+	return health
 }
 
-func (s *ContainerService) GetAllContainers() ([]models.ContainerStats, error) {
-	cmd := exec.Command("docker", "ps", "-a", "--format", "{{json .}}")
-	output, err := cmd.Output()
+// GetAllContainers lists every container, fetching stats for the running ones
+// concurrently. A container whose stats fail is still listed, with StatsError set.
+func (s *ContainerService) GetAllContainers(ctx context.Context) ([]models.ContainerStats, error) {
+	containers, err := s.engine.ListContainers(ctx, true)
 	if err != nil {
 		return nil, err
 	}
 
-	lines := strings.Split(string(output), "\n")
-	var stats []models.ContainerStats
+	stats := make([]models.ContainerStats, len(containers))
+	sem := make(chan struct{}, statsConcurrency)
+	var wg sync.WaitGroup
 
-	for _, line := range lines {
-		if line == "" {
+	for i, container := range containers {
+		stats[i] = toContainerStats(container)
+		if container.State != "running" {
 			continue
 		}
 
-		var container dockerContainer
-		if err := json.Unmarshal([]byte(line), &container); err != nil {
-			continue
-		}
+		wg.Add(1)
+		go func(stat *models.ContainerStats) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
 
-		stat := models.ContainerStats{
-			ContainerID: container.ID,
-			Name:        strings.TrimPrefix(container.Names, "/"),
-			Status:      container.State,
-			Image:       container.Image,
-			CPUPercent:  0,
-			MemoryUsage: 0,
-			MemoryLimit: 0,
-		}
+			statsCtx, cancel := context.WithTimeout(ctx, statsTimeout)
+			defer cancel()
 
-		// Get stats for running containers
-		if container.State == "running" {
-			statsCmd := exec.Command("docker", "stats", container.ID, "--no-stream", "--format", "{{.CPUPerc}}|{{.MemUsage}}")
-			if statsOutput, err := statsCmd.Output(); err == nil {
-				parts := strings.Split(strings.TrimSpace(string(statsOutput)), "|")
-				if len(parts) >= 2 {
-					// Parse CPU percentage (e.g., "12.34%" -> 12.34)
-					cpuStr := strings.TrimSuffix(strings.TrimSpace(parts[0]), "%")
-					if cpu, err := strconv.ParseFloat(cpuStr, 64); err == nil {
-						stat.CPUPercent = cpu
-					}
-
-					// Parse Memory (e.g., "123.4MiB / 1.5GiB" -> 123400000 bytes)
-					memStr := strings.TrimSpace(parts[1])
-					memParts := strings.Split(memStr, " / ")
-					if len(memParts) >= 2 {
-						stat.MemoryUsage = parseMemory(memParts[0])
-						stat.MemoryLimit = parseMemory(memParts[1])
-					}
-				}
+			sample, err := s.engine.ContainerStats(statsCtx, stat.ContainerID)
+			if err != nil {
+				stat.StatsError = err.Error()
+				return
 			}
-		}
 
-		stats = append(stats, stat)
+			rx, tx := sample.NetworkBytes()
+			stat.CPUPercent = sample.CPUPercent()
+			stat.MemoryUsage = int64(sample.MemoryUsage())
+			stat.MemoryLimit = int64(sample.MemoryStats.Limit)
+			stat.NetworkRx = int64(rx)
+			stat.NetworkTx = int64(tx)
+		}(&stats[i])
 	}
+	wg.Wait()
 
 	return stats, nil
 }
 
-func (s *ContainerService) GetContainerLogs(containerID string, tail string) (string, error) {
-	cmd := exec.Command("docker", "logs", "--tail", tail, containerID)
-	output, err := cmd.Output()
+// toContainerStats is a container's list entry without stats
+func toContainerStats(container docker.Container) models.ContainerStats {
+	stats := models.ContainerStats{
+		ContainerID: container.ID,
+		Name:        containerName(container),
+		Status:      container.State,
+		Health:      container.Health(),
+		Image:       container.Image,
+		Labels:      container.Labels,
+	}
+	if code, ok := container.ExitCode(); ok {
+		stats.ExitCode = &code
+	}
+	return stats
+}
+
+// ContainerLogOptions select which logs are returned. Since and Until accept
+// RFC3339 timestamps, Unix timestamps or durations relative to now like "10m".
+type ContainerLogOptions struct {
+	Tail       string
+	Since      string
+	Until      string
+	Timestamps bool
+}
+
+// ContainerLogs holds a container's output split by stream, plus both streams
+// interleaved in the order they were written
+type ContainerLogs struct {
+	Logs   string `json:"logs"`
+	Stdout string `json:"stdout"`
+	Stderr string `json:"stderr"`
+}
+
+// LogLine is one line of container output and the stream it was written to
+type LogLine struct {
+	Stream string
+	Text   string
+}
+
+func (s *ContainerService) GetContainerLogs(ctx context.Context, containerID string, opts ContainerLogOptions) (*ContainerLogs, error) {
+	stream, tty, err := s.openLogs(ctx, containerID, opts, false)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	var logs, stdout, stderr strings.Builder
+	err = docker.ReadLogLines(io.LimitReader(stream, maxContainerLogBytes), tty, func(streamName, line string) {
+		logs.WriteString(line + "\n")
+		if streamName == docker.StreamStderr {
+			stderr.WriteString(line + "\n")
+		} else {
+			stdout.WriteString(line + "\n")
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read container logs: %w", err)
+	}
+
+	return &ContainerLogs{
+		Logs:   logs.String(),
+		Stdout: stdout.String(),
+		Stderr: stderr.String(),
+	}, nil
+}
+
+// StreamContainerLogs starts following a container's logs. Each stdout and stderr
+// line is sent on the returned channel, which is closed when the container stops
+// or ctx is cancelled (closing the upstream stream); the error channel then
+// yields the outcome.
+func (s *ContainerService) StreamContainerLogs(ctx context.Context, containerID string, opts ContainerLogOptions) (<-chan LogLine, <-chan error, error) {
+	stream, tty, err := s.openLogs(ctx, containerID, opts, true)
 	if err != nil {
-		return "", err
+		return nil, nil, err
 	}
-	return string(output), nil
+
+	lines := make(chan LogLine, 64)
+	result := make(chan error, 1)
+
+	go func() {
+		defer close(result)
+		defer close(lines)
+		defer stream.Close()
+
+		err := docker.ReadLogLines(stream, tty, func(streamName, line string) {
+			select {
+			case lines <- LogLine{Stream: streamName, Text: line}:
+			case <-ctx.Done():
+			}
+		})
+		if err != nil && ctx.Err() == nil {
+			result <- fmt.Errorf("container log stream failed: %w", err)
+		}
+	}()
+
+	return lines, result, nil
 }
 
-// parseMemory converts memory strings like "123.4MiB" or "1.5GiB" to bytes
-func parseMemory(memStr string) int64 {
-	memStr = strings.TrimSpace(memStr)
-	
-	// Extract number and unit
-	var value float64
-	var unit string
-	
-	if _, err := fmt.Sscanf(memStr, "%f%s", &value, &unit); err != nil {
-		return 0
-	}
-
-	// Convert to bytes
-	switch strings.ToUpper(unit) {
-	case "B":
-		return int64(value)
-	case "KIB", "KB":
-		return int64(value * 1024)
-	case "MIB", "MB":
-		return int64(value * 1024 * 1024)
-	case "GIB", "GB":
-		return int64(value * 1024 * 1024 * 1024)
+func (s *ContainerService) openLogs(ctx context.Context, containerID string, opts ContainerLogOptions, follow bool) (io.ReadCloser, bool, error) {
+	if opts.Tail != "" && opts.Tail != "all" {
+		if _, err := strconv.Atoi(opts.Tail); err != nil {
+			return nil, false, fmt.Errorf("%w: tail must be a number or \"all\"", ErrInvalidContainerRequest)
+		}
+	}
+
+	now := time.Now()
+	since, err := docker.ParseLogTime(opts.Since, now)
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: since: %v", ErrInvalidContainerRequest, err)
+	}
+	until, err := docker.ParseLogTime(opts.Until, now)
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: until: %v", ErrInvalidContainerRequest, err)
+	}
+
+	// TTY containers write a raw stream instead of a multiplexed one
+	details, err := s.engine.InspectContainer(ctx, containerID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	stream, err := s.engine.ContainerLogs(ctx, details.ID, docker.LogsOptions{
+		Stdout:     true,
+		Stderr:     true,
+		Follow:     follow,
+		Timestamps: opts.Timestamps,
+		Since:      since,
+		Until:      until,
+		Tail:       opts.Tail,
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return stream, details.Config.Tty, nil
+}
+
+func containerName(container docker.Container) string {
+	if len(container.Names) == 0 {
+		return container.ID
+	}
+	return strings.TrimPrefix(container.Names[0], "/")
+}
+
+const (
+	ContainerActionStart   = "start"
+	ContainerActionStop    = "stop"
+	ContainerActionRestart = "restart"
+	ContainerActionPause   = "pause"
+	ContainerActionUnpause = "unpause"
+	ContainerActionKill    = "kill"
+	ContainerActionRemove  = "remove"
+)
+
+// ErrConfirmationRequired is returned for destructive actions that were not
+// confirmed with the container's name or ID
+var ErrConfirmationRequired = errors.New("confirmation required: set confirm to the container name or ID")
+
+var signalPattern = regexp.MustCompile(`^[A-Z0-9+]+$`)
+
+// destructiveContainerActions cannot be undone and need explicit confirmation
+var destructiveContainerActions = map[string]bool{
+	ContainerActionKill:   true,
+	ContainerActionRemove: true,
+}
+
+// RunContainerAction performs a lifecycle action and reports the state before and after
+func (s *ContainerService) RunContainerAction(ctx context.Context, containerID string, action string, req *models.ContainerActionRequest) (*models.ContainerActionResult, error) {
+	details, err := s.engine.InspectContainer(ctx, containerID)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimPrefix(details.Name, "/")
+	if destructiveContainerActions[action] && req.Confirm != name && req.Confirm != details.ID {
+		return nil, ErrConfirmationRequired
+	}
+
+	result := &models.ContainerActionResult{
+		ContainerID: details.ID,
+		Name:        name,
+		Action:      action,
+		StateBefore: details.State.Status,
+	}
+
+	switch action {
+	case ContainerActionStart:
+		err = s.engine.StartContainer(ctx, details.ID)
+	case ContainerActionStop:
+		err = s.engine.StopContainer(ctx, details.ID, req.Timeout)
+	case ContainerActionRestart:
+		err = s.engine.RestartContainer(ctx, details.ID, req.Timeout)
+	case ContainerActionPause:
+		err = s.engine.PauseContainer(ctx, details.ID)
+	case ContainerActionUnpause:
+		err = s.engine.UnpauseContainer(ctx, details.ID)
+	case ContainerActionKill:
+		signal := strings.ToUpper(strings.TrimSpace(req.Signal))
+		if signal != "" && !signalPattern.MatchString(signal) {
+			return nil, fmt.Errorf("%w: invalid signal %q", ErrInvalidContainerRequest, req.Signal)
+		}
+		err = s.engine.KillContainer(ctx, details.ID, signal)
+	case ContainerActionRemove:
+		err = s.engine.RemoveContainer(ctx, details.ID, req.Force, req.RemoveVolumes)
 	default:
-		return 0
+		return nil, fmt.Errorf("%w: unknown action %q", ErrInvalidContainerRequest, action)
+	}
+	if err != nil {
+		return result, err
+	}
+
+	if action == ContainerActionRemove {
+		result.StateAfter = "removed"
+		return result, nil
+	}
+
+	after, err := s.engine.InspectContainer(ctx, details.ID)
+	if err != nil {
+		return result, nil
 	}
+	result.StateAfter = after.State.Status
+	return result, nil
 }
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/docker"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/docker/dockertest"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
+)
+
+func TestRunContainerAction(t *testing.T) {
+	tests := []struct {
+		name   string
+		state  string
+		action string
+		req    models.ContainerActionRequest
+		before string
+		after  string
+		err    func(error) bool
+	}{
+		{name: "stop", state: "running", action: ContainerActionStop, before: "running", after: "exited"},
+		{name: "start", state: "exited", action: ContainerActionStart, before: "exited", after: "running"},
+		{
+			name: "start running", state: "running", action: ContainerActionStart, before: "running",
+			err: func(err error) bool { return errors.Is(err, docker.ErrNotModified) },
+		},
+		{name: "restart", state: "exited", action: ContainerActionRestart, before: "exited", after: "running"},
+		{name: "pause", state: "running", action: ContainerActionPause, before: "running", after: "paused"},
+		{name: "unpause", state: "paused", action: ContainerActionUnpause, before: "paused", after: "running"},
+		{name: "pause stopped", state: "exited", action: ContainerActionPause, before: "exited", err: docker.IsConflict},
+		{
+			name: "kill confirmed by name", state: "running", action: ContainerActionKill,
+			req: models.ContainerActionRequest{Confirm: "web", Signal: "sigterm"}, before: "running", after: "exited",
+		},
+		{
+			name: "kill unconfirmed", state: "running", action: ContainerActionKill,
+			err: func(err error) bool { return errors.Is(err, ErrConfirmationRequired) },
+		},
+		{
+			name: "kill invalid signal", state: "running", action: ContainerActionKill,
+			req: models.ContainerActionRequest{Confirm: "web", Signal: "TERM; rm"},
+			err: func(err error) bool { return errors.Is(err, ErrInvalidContainerRequest) },
+		},
+		{
+			name: "remove running", state: "running", action: ContainerActionRemove,
+			req: models.ContainerActionRequest{Confirm: "web"}, before: "running", err: docker.IsConflict,
+		},
+		{
+			name: "force remove confirmed by ID", state: "running", action: ContainerActionRemove,
+			req: models.ContainerActionRequest{Confirm: "c0ffee", Force: true}, before: "running", after: "removed",
+		},
+		{
+			name: "unknown action", state: "running", action: "explode",
+			err: func(err error) bool { return errors.Is(err, ErrInvalidContainerRequest) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := dockertest.NewServer()
+			defer fake.Close()
+			fake.AddContainer(dockertest.Container{ID: "c0ffee", Name: "web", Image: "nginx", State: tt.state})
+			service := NewContainerService(fake.Client())
+
+			result, err := service.RunContainerAction(context.Background(), "web", tt.action, &tt.req)
+			if tt.err != nil {
+				if err == nil || !tt.err(err) {
+					t.Fatalf("RunContainerAction error = %v, want a matching error", err)
+				}
+				if tt.before != "" && (result == nil || result.StateBefore != tt.before) {
+					t.Fatalf("result = %+v, want state before %q", result, tt.before)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RunContainerAction: %v", err)
+			}
+			if result.ContainerID != "c0ffee" || result.Name != "web" {
+				t.Fatalf("result names %s (%s), want c0ffee (web)", result.ContainerID, result.Name)
+			}
+			if result.StateBefore != tt.before || result.StateAfter != tt.after {
+				t.Fatalf("state %q -> %q, want %q -> %q", result.StateBefore, result.StateAfter, tt.before, tt.after)
+			}
+		})
+	}
+}
+
+func TestRunContainerActionMissingContainer(t *testing.T) {
+	fake := dockertest.NewServer()
+	defer fake.Close()
+	service := NewContainerService(fake.Client())
+
+	_, err := service.RunContainerAction(context.Background(), "ghost", ContainerActionStop, &models.ContainerActionRequest{})
+	if !docker.IsNotFound(err) {
+		t.Fatalf("RunContainerAction error = %v, want not found", err)
+	}
+}
+
+func TestGetAllContainers(t *testing.T) {
+	fake := dockertest.NewServer()
+	defer fake.Close()
+	fake.AddContainer(dockertest.Container{
+		ID: "a1", Name: "api", Image: "api:1", Health: "healthy",
+		Stats: docker.Stats{MemoryStats: docker.MemoryStats{Usage: 2048, Limit: 4096}},
+	})
+	fake.AddContainer(dockertest.Container{ID: "b2", Name: "batch", Image: "batch:1", State: "exited", ExitCode: 3})
+	service := NewContainerService(fake.Client())
+
+	containers, err := service.GetAllContainers(context.Background())
+	if err != nil {
+		t.Fatalf("GetAllContainers: %v", err)
+	}
+	if len(containers) != 2 {
+		t.Fatalf("got %d containers, want 2", len(containers))
+	}
+
+	api, batch := containers[0], containers[1]
+	if api.Name != "api" || api.Health != "healthy" || api.MemoryLimit != 4096 || api.StatsError != "" {
+		t.Errorf("api = %+v", api)
+	}
+	if batch.Name != "batch" || batch.Status != "exited" || batch.ExitCode == nil || *batch.ExitCode != 3 {
+		t.Errorf("batch = %+v", batch)
+	}
+}
+
+func TestGetContainerLogs(t *testing.T) {
+	logs := []dockertest.LogEntry{
+		{Stream: docker.StreamStdout, Line: "listening on :8080"},
+		{Stream: docker.StreamStderr, Line: "warning: no config"},
+		{Stream: docker.StreamStdout, Line: "GET / 200"},
+	}
+	tests := []struct {
+		name   string
+		tty    bool
+		tail   string
+		logs   string
+		stdout string
+		stderr string
+	}{
+		{
+			name:   "multiplexed",
+			logs:   "listening on :8080\nwarning: no config\nGET / 200\n",
+			stdout: "listening on :8080\nGET / 200\n",
+			stderr: "warning: no config\n",
+		},
+		{
+			name:   "multiplexed tail",
+			tail:   "2",
+			logs:   "warning: no config\nGET / 200\n",
+			stdout: "GET / 200\n",
+			stderr: "warning: no config\n",
+		},
+		{
+			// A TTY merges both streams into one raw stream reported as stdout
+			name:   "tty",
+			tty:    true,
+			logs:   "listening on :8080\nwarning: no config\nGET / 200\n",
+			stdout: "listening on :8080\nwarning: no config\nGET / 200\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := dockertest.NewServer()
+			defer fake.Close()
+			fake.AddContainer(dockertest.Container{ID: "a1", Name: "api", Tty: tt.tty, Logs: logs})
+			service := NewContainerService(fake.Client())
+
+			got, err := service.GetContainerLogs(context.Background(), "api", ContainerLogOptions{Tail: tt.tail})
+			if err != nil {
+				t.Fatalf("GetContainerLogs: %v", err)
+			}
+			if got.Logs != tt.logs || got.Stdout != tt.stdout || got.Stderr != tt.stderr {
+				t.Fatalf("logs = %+v, want logs %q stdout %q stderr %q", got, tt.logs, tt.stdout, tt.stderr)
+			}
+		})
+	}
+
+	fake := dockertest.NewServer()
+	defer fake.Close()
+	service := NewContainerService(fake.Client())
+	if _, err := service.GetContainerLogs(context.Background(), "api", ContainerLogOptions{Tail: "ten"}); !errors.Is(err, ErrInvalidContainerRequest) {
+		t.Fatalf("tail=ten error = %v, want ErrInvalidContainerRequest", err)
+	}
+}
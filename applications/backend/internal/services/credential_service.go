@@ -0,0 +1,179 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/repositories"
+)
+
+var (
+	ErrCredentialRevoked     = errors.New("credential has been revoked")
+	ErrCredentialUnavailable = errors.New("credential unavailable")
+)
+
+type CredentialService struct {
+	repo  *repositories.CredentialRepository
+	vault *Vault
+}
+
+// NewCredentialService creates the service; vault may be nil, in which case
+// every operation that touches secrets returns ErrVaultUnavailable.
+func NewCredentialService(repo *repositories.CredentialRepository, vault *Vault) *CredentialService {
+	return &CredentialService{
+		repo:  repo,
+		vault: vault,
+	}
+}
+
+func (s *CredentialService) CreateCredential(orgID uint, userID uint, req *models.CreateCredentialRequest) (*models.Credential, error) {
+	if s.vault == nil {
+		return nil, ErrVaultUnavailable
+	}
+
+	name := strings.TrimSpace(req.Name)
+	secret := strings.TrimSpace(req.Secret)
+	if name == "" || secret == "" {
+		return nil, errors.New("name and secret cannot be empty")
+	}
+
+	exists, err := s.repo.ExistsActiveByName(userID, name)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, errors.New("credential with this name already exists")
+	}
+
+	provider := req.Provider
+	if provider == "" {
+		provider = "github"
+	}
+
+	credential := &models.Credential{
+		OrganizationID: orgID,
+		UserID:         userID,
+		Name:           name,
+		Provider:       provider,
+		Hint:           secretHint(secret),
+	}
+
+	err = s.repo.CreateSealed(credential, func(credential *models.Credential) error {
+		return s.seal(credential, secret)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return credential, nil
+}
+
+func (s *CredentialService) GetCredentials(userID uint) ([]models.Credential, error) {
+	return s.repo.FindByUserID(userID)
+}
+
+// RotateCredential replaces the stored secret while keeping the same ID, so
+// callers referencing the credential pick up the new value transparently.
+func (s *CredentialService) RotateCredential(userID, id uint, secret string) (*models.Credential, error) {
+	if s.vault == nil {
+		return nil, ErrVaultUnavailable
+	}
+
+	credential, err := s.repo.FindByIDForUser(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if credential.RevokedAt != nil {
+		return nil, ErrCredentialRevoked
+	}
+
+	secret = strings.TrimSpace(secret)
+	if secret == "" {
+		return nil, errors.New("secret cannot be empty")
+	}
+
+	if err := s.seal(credential, secret); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	credential.Hint = secretHint(secret)
+	credential.RotatedAt = &now
+
+	if err := s.repo.Update(credential); err != nil {
+		return nil, err
+	}
+
+	return credential, nil
+}
+
+// RevokeCredential marks the credential unusable and discards the ciphertext
+func (s *CredentialService) RevokeCredential(userID, id uint) error {
+	credential, err := s.repo.FindByIDForUser(id, userID)
+	if err != nil {
+		return err
+	}
+	if credential.RevokedAt != nil {
+		return nil
+	}
+
+	now := time.Now()
+	credential.RevokedAt = &now
+	credential.Ciphertext = []byte{}
+	credential.Nonce = []byte{}
+
+	return s.repo.Update(credential)
+}
+
+// ResolveSecret decrypts a credential owned by userID for use by another service
+func (s *CredentialService) ResolveSecret(userID, id uint) (string, error) {
+	if s.vault == nil {
+		return "", ErrVaultUnavailable
+	}
+
+	credential, err := s.repo.FindByIDForUser(id, userID)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrCredentialUnavailable, err)
+	}
+	if credential.RevokedAt != nil {
+		return "", fmt.Errorf("%w: %v", ErrCredentialUnavailable, ErrCredentialRevoked)
+	}
+
+	plaintext, err := s.vault.Open(credential.Nonce, credential.Ciphertext, credentialAAD(credential))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrCredentialUnavailable, err)
+	}
+
+	now := time.Now()
+	credential.LastUsedAt = &now
+	_ = s.repo.TouchLastUsed(credential)
+
+	return string(plaintext), nil
+}
+
+// seal encrypts secret into credential, bound to its ID, owner and provider so
+// a ciphertext copied onto another credential row does not open
+func (s *CredentialService) seal(credential *models.Credential, secret string) error {
+	nonce, ciphertext, err := s.vault.Seal([]byte(secret), credentialAAD(credential))
+	if err != nil {
+		return err
+	}
+	credential.Nonce = nonce
+	credential.Ciphertext = ciphertext
+	return nil
+}
+
+func credentialAAD(credential *models.Credential) []byte {
+	return []byte(fmt.Sprintf("clouddeck:credential:%d:user:%d:provider:%s",
+		credential.ID, credential.UserID, credential.Provider))
+}
+
+func secretHint(secret string) string {
+	if len(secret) <= 8 {
+		return "****"
+	}
+	return "…" + secret[len(secret)-4:]
+}
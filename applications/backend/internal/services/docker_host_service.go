@@ -0,0 +1,452 @@
+package services
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/docker"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/repositories"
+)
+
+const (
+	dockerHostHealthTimeout = 5 * time.Second
+	// fleetHostTimeout bounds how long one host may take in a fleet-wide list
+	fleetHostTimeout = 10 * time.Second
+	// localDockerHostName names the host of the server's own daemon
+	localDockerHostName = "local"
+)
+
+var (
+	ErrDockerHostNotFound    = errors.New("docker host not found")
+	ErrDockerHostUnavailable = errors.New("docker host unavailable")
+	ErrLocalDockerHost       = errors.New("the server's own Docker daemon cannot be removed")
+)
+
+// dockerHostSecrets is the sealed part of a DockerHost
+type dockerHostSecrets struct {
+	CACert        string `json:"caCert,omitempty"`
+	ClientCert    string `json:"clientCert,omitempty"`
+	ClientKey     string `json:"clientKey,omitempty"`
+	SSHPrivateKey string `json:"sshPrivateKey,omitempty"`
+	SSHPassphrase string `json:"sshPassphrase,omitempty"`
+}
+
+func (s dockerHostSecrets) empty() bool {
+	return s == dockerHostSecrets{}
+}
+
+type dockerHostClient struct {
+	client  *docker.Client
+	service *ContainerService
+}
+
+// DockerHostService manages registered Docker hosts and keeps a registry of
+// clients keyed by host ID. The server's own daemon at DOCKER_HOST is a host
+// of the default organization like any other; see EnsureLocalHost.
+type DockerHostService struct {
+	repo   *repositories.DockerHostRepository
+	vault  *Vault
+	policy targetPolicy
+
+	mu       sync.Mutex
+	clients  map[uint]dockerHostClient
+	local    *ContainerService
+	localErr error
+}
+
+// NewDockerHostService creates the service around the daemon at DOCKER_HOST;
+// vault may be nil, in which case only hosts without TLS or SSH credentials
+// can be added.
+func NewDockerHostService(repo *repositories.DockerHostRepository, vault *Vault) *DockerHostService {
+	s := &DockerHostService{
+		repo:    repo,
+		vault:   vault,
+		policy:  targetPolicyFromEnv(),
+		clients: make(map[uint]dockerHostClient),
+	}
+
+	client, err := docker.NewClientFromEnv()
+	if err != nil {
+		s.localErr = fmt.Errorf("%w: %v", ErrDockerHostUnavailable, err)
+	} else {
+		s.local = NewContainerService(client)
+	}
+	return s
+}
+
+// CreateDockerHost validates and stores a host for orgID. Nothing is dialed;
+// connection problems show up in the host's health.
+func (s *DockerHostService) CreateDockerHost(orgID uint, userID uint, req *models.CreateDockerHostRequest) (*models.DockerHost, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("name cannot be empty")
+	}
+	if name == localDockerHostName {
+		return nil, fmt.Errorf("%q is reserved for the server's own daemon", localDockerHostName)
+	}
+
+	repo := s.repo.ForOrganization(orgID)
+	exists, err := repo.ExistsByName(name)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, errors.New("docker host with this name already exists")
+	}
+
+	host := &models.DockerHost{
+		Name:        name,
+		URL:         strings.TrimSpace(req.URL),
+		TLS:         req.TLS || req.CACert != "" || req.ClientCert != "",
+		SSHHostKey:  strings.TrimSpace(req.SSHHostKey),
+		CreatedByID: userID,
+	}
+	secrets := dockerHostSecrets{
+		CACert:        req.CACert,
+		ClientCert:    req.ClientCert,
+		ClientKey:     req.ClientKey,
+		SSHPrivateKey: req.SSHPrivateKey,
+		SSHPassphrase: req.SSHPassphrase,
+	}
+
+	// Building the client checks the URL, certificates and keys
+	client, err := newDockerHostClient(host, secrets, s.policy)
+	if err != nil {
+		return nil, err
+	}
+	client.Close()
+
+	if !secrets.empty() {
+		if s.vault == nil {
+			return nil, ErrVaultUnavailable
+		}
+		plaintext, err := json.Marshal(secrets)
+		if err != nil {
+			return nil, err
+		}
+		if host.Nonce, host.Ciphertext, err = s.vault.Seal(plaintext, dockerHostAAD(orgID, name)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := repo.Create(host); err != nil {
+		return nil, err
+	}
+	return host, nil
+}
+
+func (s *DockerHostService) GetDockerHosts(orgID uint) ([]models.DockerHost, error) {
+	return s.repo.ForOrganization(orgID).FindAll()
+}
+
+func (s *DockerHostService) GetDockerHost(orgID uint, id uint) (*models.DockerHost, error) {
+	host, err := s.repo.ForOrganization(orgID).FindByID(id)
+	if err != nil {
+		return nil, ErrDockerHostNotFound
+	}
+	return host, nil
+}
+
+func (s *DockerHostService) DeleteDockerHost(orgID uint, id uint) error {
+	host, err := s.GetDockerHost(orgID, id)
+	if err != nil {
+		return err
+	}
+	if host.Local {
+		return ErrLocalDockerHost
+	}
+	if err := s.repo.ForOrganization(orgID).Delete(id); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	entry, ok := s.clients[id]
+	delete(s.clients, id)
+	s.mu.Unlock()
+
+	if ok {
+		entry.client.Close()
+	}
+	return nil
+}
+
+// Client returns the service for a registered host of orgID, building its client on first use
+func (s *DockerHostService) Client(orgID uint, id uint) (*ContainerService, error) {
+	host, err := s.GetDockerHost(orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.clientFor(host)
+}
+
+// LocalClient returns the server's own daemon when it is a host of orgID, for
+// the /api/containers, /api/images, ... routes that predate registered hosts
+func (s *DockerHostService) LocalClient(orgID uint) (uint, *ContainerService, error) {
+	host, err := s.repo.ForOrganization(orgID).FindLocal()
+	if err != nil {
+		return 0, nil, ErrDockerHostNotFound
+	}
+	service, err := s.clientFor(host)
+	return host.ID, service, err
+}
+
+func (s *DockerHostService) clientFor(host *models.DockerHost) (*ContainerService, error) {
+	if host.Local {
+		return s.local, s.localErr
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.clients[host.ID]; ok {
+		return entry.service, nil
+	}
+
+	var secrets dockerHostSecrets
+	if len(host.Ciphertext) > 0 {
+		if s.vault == nil {
+			return nil, fmt.Errorf("%w: %v", ErrDockerHostUnavailable, ErrVaultUnavailable)
+		}
+		plaintext, err := s.vault.Open(host.Nonce, host.Ciphertext, dockerHostAAD(host.OrganizationID, host.Name))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDockerHostUnavailable, err)
+		}
+		if err := json.Unmarshal(plaintext, &secrets); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDockerHostUnavailable, err)
+		}
+	}
+
+	client, err := newDockerHostClient(host, secrets, s.policy)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDockerHostUnavailable, err)
+	}
+
+	entry := dockerHostClient{client: client, service: NewContainerService(client)}
+	s.clients[host.ID] = entry
+	return entry.service, nil
+}
+
+// EnsureLocalHost records the server's own daemon as a host of orgID, the
+// default organization, unless it already is. Nothing is recorded when
+// DOCKER_HOST cannot be used.
+func (s *DockerHostService) EnsureLocalHost(orgID uint) (*models.DockerHost, error) {
+	if s.localErr != nil {
+		return nil, s.localErr
+	}
+	if host, err := s.repo.FindLocal(); err == nil {
+		return host, nil
+	}
+
+	host := &models.DockerHost{
+		OrganizationID: orgID,
+		Name:           localDockerHostName,
+		URL:            docker.HostFromEnv(),
+	}
+	if err := s.repo.CreateLocal(host); err != nil {
+		return nil, err
+	}
+	return host, nil
+}
+
+// GetDockerHostStatuses lists the hosts of orgID with their health, probing them concurrently
+func (s *DockerHostService) GetDockerHostStatuses(orgID uint) ([]models.DockerHostStatus, error) {
+	hosts, err := s.GetDockerHosts(orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]models.DockerHostStatus, len(hosts))
+	var wg sync.WaitGroup
+	for i := range hosts {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			statuses[i] = models.DockerHostStatus{
+				DockerHost: hosts[i],
+				Health:     s.DockerHostHealth(orgID, hosts[i].ID),
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	return statuses, nil
+}
+
+// DockerHostHealth probes one registered host
+func (s *DockerHostService) DockerHostHealth(orgID uint, id uint) models.DockerHostHealth {
+	return probeDockerHost(s.Client(orgID, id))
+}
+
+func probeDockerHost(service *ContainerService, err error) models.DockerHostHealth {
+	if err != nil {
+		return models.DockerHostHealth{
+			Status:    models.ClusterStatusError,
+			Error:     err.Error(),
+			CheckedAt: time.Now().UTC(),
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dockerHostHealthTimeout)
+	defer cancel()
+	return service.Health(ctx)
+}
+
+// dockerTarget is one daemon a fleet-wide operation runs against
+type dockerTarget struct {
+	id      uint
+	name    string
+	service *ContainerService
+	err     error
+}
+
+// targets returns the hosts of orgID, or of every organization when orgID is nil
+func (s *DockerHostService) targets(orgID *uint) ([]dockerTarget, error) {
+	var hosts []models.DockerHost
+	var err error
+	if orgID != nil {
+		hosts, err = s.GetDockerHosts(*orgID)
+	} else {
+		hosts, err = s.repo.FindAll()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	targets := make([]dockerTarget, 0, len(hosts))
+	for i := range hosts {
+		service, err := s.clientFor(&hosts[i])
+		targets = append(targets, dockerTarget{id: hosts[i].ID, name: hosts[i].Name, service: service, err: err})
+	}
+	return targets, nil
+}
+
+// FleetContainers lists the containers of every host orgID can see, querying
+// the hosts concurrently. A host that fails is reported in the result's Hosts
+// and does not fail the list.
+func (s *DockerHostService) FleetContainers(ctx context.Context, orgID uint) (*models.FleetContainerList, error) {
+	targets, err := s.targets(&orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]models.FleetHostResult, len(targets))
+	containers := make([][]models.ContainerStats, len(targets))
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		results[i] = models.FleetHostResult{HostID: target.id, Name: target.name}
+		if target.err != nil {
+			results[i].Error = target.err.Error()
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, target dockerTarget) {
+			defer wg.Done()
+			hostCtx, cancel := context.WithTimeout(ctx, fleetHostTimeout)
+			defer cancel()
+
+			stats, err := target.service.GetAllContainers(hostCtx)
+			if err != nil {
+				results[i].Error = err.Error()
+				return
+			}
+			for j := range stats {
+				stats[j].HostID = target.id
+				stats[j].Host = target.name
+			}
+			containers[i] = stats
+			results[i].Containers = len(stats)
+		}(i, target)
+	}
+	wg.Wait()
+
+	fleet := &models.FleetContainerList{
+		Containers: []models.ContainerStats{},
+		Hosts:      results,
+	}
+	for _, stats := range containers {
+		fleet.Containers = append(fleet.Containers, stats...)
+	}
+	return fleet, nil
+}
+
+// newDockerHostClient builds a client for a registered host. Unix sockets are
+// refused: registered hosts are supplied over the API and must not reach into
+// the server's own filesystem, nor, under policy, its network.
+func newDockerHostClient(host *models.DockerHost, secrets dockerHostSecrets, policy targetPolicy) (*docker.Client, error) {
+	hostURL, err := url.Parse(host.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid docker host URL: %v", err)
+	}
+	if err := policy.checkHost(hostURL.Hostname()); err != nil {
+		return nil, err
+	}
+
+	opts := docker.Options{Host: host.URL, Control: policy.control}
+	switch hostURL.Scheme {
+	case "tcp":
+		if secrets.SSHPrivateKey != "" {
+			return nil, errors.New("ssh credentials need an ssh:// URL")
+		}
+		if host.TLS {
+			if opts.TLS, err = dockerTLSConfig(secrets); err != nil {
+				return nil, err
+			}
+		}
+	case "ssh":
+		if host.TLS {
+			return nil, errors.New("TLS certificates need a tcp:// URL")
+		}
+		if secrets.SSHPrivateKey == "" {
+			return nil, errors.New("ssh docker hosts need sshPrivateKey")
+		}
+		opts.SSH = &docker.SSHAuth{
+			PrivateKey: []byte(secrets.SSHPrivateKey),
+			Passphrase: []byte(secrets.SSHPassphrase),
+			HostKey:    host.SSHHostKey,
+		}
+	default:
+		return nil, errors.New("docker host URL must be tcp://host:port or ssh://user@host")
+	}
+
+	return docker.NewClientWithOptions(opts)
+}
+
+// dockerTLSConfig trusts CACert (or the system roots when empty) and presents
+// the client certificate when one is given
+func dockerTLSConfig(secrets dockerHostSecrets) (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if secrets.CACert != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(secrets.CACert)) {
+			return nil, errors.New("caCert contains no PEM certificates")
+		}
+		config.RootCAs = pool
+	}
+
+	if (secrets.ClientCert == "") != (secrets.ClientKey == "") {
+		return nil, errors.New("clientCert and clientKey must be given together")
+	}
+	if secrets.ClientCert != "" {
+		certificate, err := tls.X509KeyPair([]byte(secrets.ClientCert), []byte(secrets.ClientKey))
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate: %v", err)
+		}
+		config.Certificates = []tls.Certificate{certificate}
+	}
+	return config, nil
+}
+
+func dockerHostAAD(orgID uint, name string) []byte {
+	return []byte(fmt.Sprintf("clouddeck:docker-host:org:%d:%s", orgID, name))
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
+)
+
+// builtinNetworks are created by the daemon and cannot be removed
+var builtinNetworks = map[string]bool{"bridge": true, "host": true, "none": true}
+
+// GetImages lists images with the containers (running or not) created from them
+func (s *ContainerService) GetImages(ctx context.Context) ([]models.DockerImage, error) {
+	images, err := s.engine.ListImages(ctx)
+	if err != nil {
+		return nil, err
+	}
+	containers, err := s.engine.ListContainers(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	users := make(map[string][]string)
+	for _, container := range containers {
+		users[container.ImageID] = append(users[container.ImageID], containerName(container))
+	}
+
+	result := make([]models.DockerImage, 0, len(images))
+	for _, image := range images {
+		tags := []string{}
+		for _, tag := range image.RepoTags {
+			if tag != "<none>:<none>" {
+				tags = append(tags, tag)
+			}
+		}
+		digests := []string{}
+		for _, digest := range image.RepoDigests {
+			if digest != "<none>@<none>" {
+				digests = append(digests, digest)
+			}
+		}
+		result = append(result, models.DockerImage{
+			ID:         image.ID,
+			Tags:       tags,
+			Digests:    digests,
+			Created:    time.Unix(image.Created, 0).UTC(),
+			Size:       image.Size,
+			Dangling:   image.Dangling(),
+			Containers: nonNil(users[image.ID]),
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Size > result[j].Size })
+	return result, nil
+}
+
+// GetVolumes lists volumes with their size and the containers mounting them
+func (s *ContainerService) GetVolumes(ctx context.Context) ([]models.DockerVolume, error) {
+	usage, err := s.engine.DiskUsage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	containers, err := s.engine.ListContainers(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	users := make(map[string][]string)
+	for _, container := range containers {
+		for _, mount := range container.Mounts {
+			if mount.Type == "volume" && mount.Name != "" {
+				users[mount.Name] = append(users[mount.Name], containerName(container))
+			}
+		}
+	}
+
+	result := make([]models.DockerVolume, 0, len(usage.Volumes))
+	for _, volume := range usage.Volumes {
+		size := int64(-1)
+		if volume.UsageData != nil {
+			size = volume.UsageData.Size
+		}
+		result = append(result, models.DockerVolume{
+			Name:       volume.Name,
+			Driver:     volume.Driver,
+			Mountpoint: volume.Mountpoint,
+			Scope:      volume.Scope,
+			Labels:     volume.Labels,
+			CreatedAt:  volume.CreatedAt,
+			Size:       size,
+			Dangling:   len(users[volume.Name]) == 0,
+			Containers: nonNil(users[volume.Name]),
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Size > result[j].Size })
+	return result, nil
+}
+
+// GetNetworks lists networks with the containers attached to them
+func (s *ContainerService) GetNetworks(ctx context.Context) ([]models.DockerNetwork, error) {
+	networks, err := s.engine.ListNetworks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	containers, err := s.engine.ListContainers(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	users := make(map[string][]string)
+	for _, container := range containers {
+		for _, endpoint := range container.NetworkSettings.Networks {
+			users[endpoint.NetworkID] = append(users[endpoint.NetworkID], containerName(container))
+		}
+	}
+
+	result := make([]models.DockerNetwork, 0, len(networks))
+	for _, network := range networks {
+		subnets := []string{}
+		for _, config := range network.IPAM.Config {
+			if config.Subnet != "" {
+				subnets = append(subnets, config.Subnet)
+			}
+		}
+		result = append(result, models.DockerNetwork{
+			ID:         network.ID,
+			Name:       network.Name,
+			Driver:     network.Driver,
+			Scope:      network.Scope,
+			Internal:   network.Internal,
+			Subnets:    subnets,
+			Created:    network.Created,
+			Builtin:    builtinNetworks[network.Name],
+			Unused:     len(users[network.ID]) == 0,
+			Containers: nonNil(users[network.ID]),
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+// PruneImages removes dangling images, or with all every image no container uses
+func (s *ContainerService) PruneImages(ctx context.Context, all bool) (*models.PruneResult, error) {
+	report, err := s.engine.PruneImages(ctx, all)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.PruneResult{Deleted: []string{}, SpaceReclaimed: report.SpaceReclaimed}
+	for _, item := range report.ImagesDeleted {
+		if item.Deleted != "" {
+			result.Deleted = append(result.Deleted, item.Deleted)
+		}
+	}
+	return result, nil
+}
+
+// PruneVolumes removes every local volume no container mounts
+func (s *ContainerService) PruneVolumes(ctx context.Context) (*models.PruneResult, error) {
+	report, err := s.engine.PruneVolumes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &models.PruneResult{Deleted: nonNil(report.VolumesDeleted), SpaceReclaimed: report.SpaceReclaimed}, nil
+}
+
+// PruneNetworks removes user-defined networks no container is attached to.
+// Networks hold no data, so SpaceReclaimed is always zero.
+func (s *ContainerService) PruneNetworks(ctx context.Context) (*models.PruneResult, error) {
+	report, err := s.engine.PruneNetworks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &models.PruneResult{Deleted: nonNil(report.NetworksDeleted)}, nil
+}
+
+// nonNil turns a nil slice into an empty one so it encodes as [] rather than null
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
@@ -15,25 +15,36 @@ import (
 type GitHubService struct {
 	taskRepo    *repositories.TaskRepository
 	projectRepo *repositories.ProjectRepository
+	credentials *CredentialService
 }
 
-func NewGitHubService(taskRepo *repositories.TaskRepository, projectRepo *repositories.ProjectRepository) *GitHubService {
+func NewGitHubService(taskRepo *repositories.TaskRepository, projectRepo *repositories.ProjectRepository, credentials *CredentialService) *GitHubService {
 	return &GitHubService{
 		taskRepo:    taskRepo,
 		projectRepo: projectRepo,
+		credentials: credentials,
 	}
 }
 
-func (s *GitHubService) createClient(token string) *github.Client {
+// createClient builds a GitHub client authenticated with a token from the credential vault
+func (s *GitHubService) createClient(userID, credentialID uint) (*github.Client, error) {
+	token, err := s.credentials.ResolveSecret(userID, credentialID)
+	if err != nil {
+		return nil, err
+	}
+
 	ctx := context.Background()
 	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
 	tc := oauth2.NewClient(ctx, ts)
-	return github.NewClient(tc)
+	return github.NewClient(tc), nil
 }
 
 // GetUserPRs fetches all PRs for a user across repos
-func (s *GitHubService) GetUserPRs(token string, username string) ([]map[string]interface{}, error) {
-	client := s.createClient(token)
+func (s *GitHubService) GetUserPRs(userID, credentialID uint, username string) ([]map[string]interface{}, error) {
+	client, err := s.createClient(userID, credentialID)
+	if err != nil {
+		return nil, err
+	}
 	ctx := context.Background()
 
 	// Search for user's PRs
@@ -70,8 +81,11 @@ func (s *GitHubService) GetUserPRs(token string, username string) ([]map[string]
 }
 
 // GetRepoIssues fetches issues from a specific repo
-func (s *GitHubService) GetRepoIssues(token string, owner string, repo string) ([]map[string]interface{}, error) {
-	client := s.createClient(token)
+func (s *GitHubService) GetRepoIssues(userID, credentialID uint, owner string, repo string) ([]map[string]interface{}, error) {
+	client, err := s.createClient(userID, credentialID)
+	if err != nil {
+		return nil, err
+	}
 	ctx := context.Background()
 
 	opts := &github.IssueListByRepoOptions{
@@ -107,8 +121,10 @@ func (s *GitHubService) GetRepoIssues(token string, owner string, repo string) (
 }
 
 // SyncPRsToTasks creates tasks from user's PRs
-func (s *GitHubService) SyncPRsToTasks(token string, username string, projectID uint) error {
-	prs, err := s.GetUserPRs(token, username)
+func (s *GitHubService) SyncPRsToTasks(orgID, userID, credentialID uint, username string, projectID uint) error {
+	taskRepo := s.taskRepo.ForOrganization(orgID)
+
+	prs, err := s.GetUserPRs(userID, credentialID, username)
 	if err != nil {
 		return err
 	}
@@ -116,7 +132,7 @@ func (s *GitHubService) SyncPRsToTasks(token string, username string, projectID
 	for _, pr := range prs {
 		// Check if task already exists
 		title := pr["title"].(string)
-		existingTasks, _ := s.taskRepo.FindByProjectID(projectID)
+		existingTasks, _ := taskRepo.FindByProjectID(projectID)
 		
 		exists := false
 		for _, task := range existingTasks {
@@ -137,7 +153,7 @@ func (s *GitHubService) SyncPRsToTasks(token string, username string, projectID
 				Labels:      pr["labels"].(string),
 			}
 
-			if err := s.taskRepo.Create(task); err != nil {
+			if err := taskRepo.Create(task); err != nil {
 				continue
 			}
 		}
@@ -147,15 +163,17 @@ func (s *GitHubService) SyncPRsToTasks(token string, username string, projectID
 }
 
 // SyncIssuesToTasks creates tasks from repo issues
-func (s *GitHubService) SyncIssuesToTasks(token string, owner string, repo string, projectID uint) error {
-	issues, err := s.GetRepoIssues(token, owner, repo)
+func (s *GitHubService) SyncIssuesToTasks(orgID, userID, credentialID uint, owner string, repo string, projectID uint) error {
+	taskRepo := s.taskRepo.ForOrganization(orgID)
+
+	issues, err := s.GetRepoIssues(userID, credentialID, owner, repo)
 	if err != nil {
 		return err
 	}
 
 	for _, issue := range issues {
 		title := issue["title"].(string)
-		existingTasks, _ := s.taskRepo.FindByProjectID(projectID)
+		existingTasks, _ := taskRepo.FindByProjectID(projectID)
 		
 		exists := false
 		for _, task := range existingTasks {
@@ -179,7 +197,7 @@ func (s *GitHubService) SyncIssuesToTasks(token string, owner string, repo strin
 				task.Assignee = assignee
 			}
 
-			if err := s.taskRepo.Create(task); err != nil {
+			if err := taskRepo.Create(task); err != nil {
 				continue
 			}
 		}
@@ -1,52 +1,126 @@
 package services
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
 	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
 	"gorm.io/gorm"
 )
 
+var ErrInvalidGitOpsApp = errors.New("invalid GitOps app")
+
 type GitOpsService struct {
-	db *gorm.DB
+	db       *gorm.DB
+	audit    *AuditService
+	clusters *ClusterService
+}
+
+func NewGitOpsService(db *gorm.DB, audit *AuditService, clusters *ClusterService) *GitOpsService {
+	return &GitOpsService{db: db, audit: audit, clusters: clusters}
 }
 
-func NewGitOpsService(db *gorm.DB) *GitOpsService {
-	return &GitOpsService{db: db}
+// scoped restricts queries to apps owned by an organization
+func (s *GitOpsService) scoped(orgID uint) *gorm.DB {
+	return s.db.Where("organization_id = ?", orgID)
 }
 
 // CreateApp creates a new GitOps application
-func (s *GitOpsService) CreateApp(app *models.GitOpsApp) error {
+func (s *GitOpsService) CreateApp(orgID uint, app *models.GitOpsApp) error {
+	app.OrganizationID = orgID
+	if err := validateGitOpsApp(app); err != nil {
+		return err
+	}
+	if _, err := s.targetCluster(app); err != nil {
+		return err
+	}
 	return s.db.Create(app).Error
 }
 
+// validateGitOpsApp checks the fields that end up on the git command line and
+// in paths on the server
+func validateGitOpsApp(app *models.GitOpsApp) error {
+	repo, err := url.Parse(app.RepoURL)
+	if err != nil || (repo.Scheme != "https" && repo.Scheme != "ssh") || repo.Host == "" {
+		return fmt.Errorf("%w: repo URL must be an https:// or ssh:// URL", ErrInvalidGitOpsApp)
+	}
+	if strings.HasPrefix(app.Branch, "-") {
+		return fmt.Errorf("%w: invalid branch %q", ErrInvalidGitOpsApp, app.Branch)
+	}
+	if _, err := manifestPath("/repo", app.Path); err != nil {
+		return err
+	}
+	return nil
+}
+
+// manifestPath joins path onto the checkout in dir, refusing paths that leave it
+func manifestPath(dir, path string) (string, error) {
+	joined := filepath.Join(dir, path)
+	if !withinDir(dir, joined) {
+		return "", fmt.Errorf("%w: path %q is outside the repository", ErrInvalidGitOpsApp, path)
+	}
+	return joined, nil
+}
+
+func withinDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// targetCluster returns the client for the cluster an app deploys to, which must
+// belong to the app's organization
+func (s *GitOpsService) targetCluster(app *models.GitOpsApp) (*KubernetesService, error) {
+	if s.clusters == nil {
+		return nil, ErrClusterUnavailable
+	}
+	clusterID := app.ClusterID
+	if clusterID == 0 {
+		id, err := s.clusters.LocalClusterID(app.OrganizationID)
+		if err != nil {
+			return nil, err
+		}
+		clusterID = id
+	}
+	return s.clusters.Client(app.OrganizationID, clusterID)
+}
+
 // GetAllApps retrieves all GitOps applications
-func (s *GitOpsService) GetAllApps() ([]models.GitOpsApp, error) {
+func (s *GitOpsService) GetAllApps(orgID uint) ([]models.GitOpsApp, error) {
 	var apps []models.GitOpsApp
-	err := s.db.Find(&apps).Error
+	err := s.scoped(orgID).Find(&apps).Error
 	return apps, err
 }
 
 // GetAppByID retrieves a GitOps app by ID
-func (s *GitOpsService) GetAppByID(id uint) (*models.GitOpsApp, error) {
+func (s *GitOpsService) GetAppByID(orgID uint, id uint) (*models.GitOpsApp, error) {
 	var app models.GitOpsApp
-	err := s.db.First(&app, id).Error
+	err := s.scoped(orgID).First(&app, id).Error
 	return &app, err
 }
 
-// SyncApp synchronizes the app with Git repository
-func (s *GitOpsService) SyncApp(appID uint) error {
-	app, err := s.GetAppByID(appID)
+// SyncApp synchronizes the app with Git repository on behalf of actor
+func (s *GitOpsService) SyncApp(orgID uint, appID uint, actor *models.Principal) error {
+	app, err := s.GetAppByID(orgID, appID)
 	if err != nil {
 		return err
 	}
 
 	// Clone and apply manifests
+	startTime := time.Now()
 	err = s.applyManifests(app)
+	s.recordApply(actor, app, startTime, err)
 	if err != nil {
 		app.SyncStatus = "OutOfSync"
 		s.db.Save(app)
@@ -62,8 +136,16 @@ func (s *GitOpsService) SyncApp(appID uint) error {
 	return nil
 }
 
-// applyManifests clones repo and applies K8s manifests
+// applyManifests clones repo and applies K8s manifests to the app's cluster
 func (s *GitOpsService) applyManifests(app *models.GitOpsApp) error {
+	if err := validateGitOpsApp(app); err != nil {
+		return err
+	}
+	cluster, err := s.targetCluster(app)
+	if err != nil {
+		return err
+	}
+
 	// Create temp directory
 	tmpDir, err := os.MkdirTemp("", "gitops-*")
 	if err != nil {
@@ -71,17 +153,38 @@ func (s *GitOpsService) applyManifests(app *models.GitOpsApp) error {
 	}
 	defer os.RemoveAll(tmpDir)
 
+	// kubectl talks to the app's cluster only, never the server's own kubeconfig
+	kubeconfig := filepath.Join(tmpDir, "kubeconfig")
+	if err := writeKubeconfig(cluster.config, kubeconfig); err != nil {
+		return fmt.Errorf("failed to write kubeconfig: %v", err)
+	}
+
 	// Clone repository
-	cmd := exec.Command("git", "clone", "--branch", app.Branch, "--depth", "1", app.RepoURL, tmpDir)
+	repoDir := filepath.Join(tmpDir, "repo")
+	cmd := exec.Command("git", "clone", "--branch", app.Branch, "--depth", "1", "--", app.RepoURL, repoDir)
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("git clone failed: %v, output: %s", err, output)
 	}
 
-	// Path to manifests
-	manifestPath := filepath.Join(tmpDir, app.Path)
+	// Path to manifests, which may not leave the checkout through symlinks either
+	manifests, err := manifestPath(repoDir, app.Path)
+	if err != nil {
+		return err
+	}
+	resolvedRepo, err := filepath.EvalSymlinks(repoDir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve checkout: %v", err)
+	}
+	resolved, err := filepath.EvalSymlinks(manifests)
+	if err != nil {
+		return fmt.Errorf("%w: path %q not found in repository", ErrInvalidGitOpsApp, app.Path)
+	}
+	if !withinDir(resolvedRepo, resolved) {
+		return fmt.Errorf("%w: path %q is outside the repository", ErrInvalidGitOpsApp, app.Path)
+	}
 
 	// Apply manifests using kubectl
-	cmd = exec.Command("kubectl", "apply", "-f", manifestPath, "-n", app.Namespace)
+	cmd = exec.Command("kubectl", "--kubeconfig", kubeconfig, "apply", "-f", resolved, "-n", app.Namespace)
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("kubectl apply failed: %v, output: %s", err, output)
 	}
@@ -89,7 +192,93 @@ func (s *GitOpsService) applyManifests(app *models.GitOpsApp) error {
 	return nil
 }
 
+// writeKubeconfig saves config as a kubeconfig file for kubectl
+func writeKubeconfig(config *rest.Config, path string) error {
+	cluster := &clientcmdapi.Cluster{
+		Server:                   config.Host,
+		TLSServerName:            config.ServerName,
+		InsecureSkipTLSVerify:    config.Insecure,
+		CertificateAuthority:     config.CAFile,
+		CertificateAuthorityData: config.CAData,
+	}
+	if config.Proxy != nil {
+		req, err := http.NewRequest(http.MethodGet, config.Host, nil)
+		if err != nil {
+			return err
+		}
+		proxy, err := config.Proxy(req)
+		if err != nil {
+			return err
+		}
+		if proxy != nil {
+			cluster.ProxyURL = proxy.String()
+		}
+	}
+
+	kubeconfig := clientcmdapi.NewConfig()
+	kubeconfig.Clusters["cluster"] = cluster
+	kubeconfig.AuthInfos["user"] = &clientcmdapi.AuthInfo{
+		Token:                 config.BearerToken,
+		TokenFile:             config.BearerTokenFile,
+		ClientCertificate:     config.CertFile,
+		ClientCertificateData: config.CertData,
+		ClientKey:             config.KeyFile,
+		ClientKeyData:         config.KeyData,
+		Username:              config.Username,
+		Password:              config.Password,
+		Impersonate:           config.Impersonate.UserName,
+		ImpersonateGroups:     config.Impersonate.Groups,
+		AuthProvider:          config.AuthProvider,
+		Exec:                  config.ExecProvider,
+	}
+	kubeconfig.Contexts["default"] = &clientcmdapi.Context{Cluster: "cluster", AuthInfo: "user"}
+	kubeconfig.CurrentContext = "default"
+	return clientcmd.WriteToFile(*kubeconfig, path)
+}
+
+// recordApply writes an audit entry for a manifest apply, whatever its outcome
+func (s *GitOpsService) recordApply(actor *models.Principal, app *models.GitOpsApp, startTime time.Time, applyErr error) {
+	if s.audit == nil {
+		return
+	}
+
+	entry := &models.AuditLog{
+		Timestamp:      startTime.UTC(),
+		OrganizationID: app.OrganizationID,
+		Action:         "gitops.apply_manifests",
+		EntityType:     "gitops_app",
+		EntityID:       strconv.FormatUint(uint64(app.ID), 10),
+		Details: map[string]interface{}{
+			"name":       app.Name,
+			"repo_url":   app.RepoURL,
+			"branch":     app.Branch,
+			"path":       app.Path,
+			"namespace":  app.Namespace,
+			"cluster_id": app.ClusterID,
+		},
+		Outcome:    models.AuditOutcomeSuccess,
+		DurationMs: time.Since(startTime).Milliseconds(),
+	}
+	if actor != nil {
+		entry.ActorID = actor.UserID
+		entry.ActorName = actor.Username
+	}
+	if applyErr != nil {
+		entry.Outcome = models.AuditOutcomeFailure
+		entry.Error = applyErr.Error()
+	}
+
+	s.audit.Record(entry)
+}
+
 // DeleteApp deletes a GitOps app
-func (s *GitOpsService) DeleteApp(id uint) error {
-	return s.db.Delete(&models.GitOpsApp{}, id).Error
+func (s *GitOpsService) DeleteApp(orgID uint, id uint) error {
+	result := s.scoped(orgID).Delete(&models.GitOpsApp{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
 }
@@ -0,0 +1,42 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
+)
+
+func TestValidateGitOpsApp(t *testing.T) {
+	tests := []struct {
+		name   string
+		repo   string
+		branch string
+		path   string
+		ok     bool
+	}{
+		{"https", "https://github.com/acme/deploy.git", "main", "k8s", true},
+		{"ssh", "ssh://git@github.com/acme/deploy.git", "main", "overlays/prod", true},
+		{"repository root", "https://github.com/acme/deploy.git", "main", "", true},
+		{"absolute path stays in the checkout", "https://github.com/acme/deploy.git", "main", "/k8s", true},
+		{"dotted path inside the checkout", "https://github.com/acme/deploy.git", "main", "k8s/../base", true},
+		{"plain http", "http://github.com/acme/deploy.git", "main", "k8s", false},
+		{"local file", "file:///etc", "main", "", false},
+		{"ext transport", "ext::sh -c touch% /tmp/pwned", "main", "", false},
+		{"option as URL", "--upload-pack=touch /tmp/pwned", "main", "", false},
+		{"option as branch", "https://github.com/acme/deploy.git", "--upload-pack=id", "", false},
+		{"path above the checkout", "https://github.com/acme/deploy.git", "main", "../../etc", false},
+		{"path climbing back out", "https://github.com/acme/deploy.git", "main", "k8s/../../etc", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateGitOpsApp(&models.GitOpsApp{RepoURL: tt.repo, Branch: tt.branch, Path: tt.path})
+			if tt.ok && err != nil {
+				t.Fatalf("validateGitOpsApp: %v", err)
+			}
+			if !tt.ok && !errors.Is(err, ErrInvalidGitOpsApp) {
+				t.Fatalf("validateGitOpsApp error = %v, want ErrInvalidGitOpsApp", err)
+			}
+		})
+	}
+}
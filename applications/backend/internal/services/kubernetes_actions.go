@@ -0,0 +1,306 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
+)
+
+// restartedAtAnnotation is the pod template annotation `kubectl rollout restart`
+// sets; changing it makes the deployment roll out new pods
+const restartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+
+// mirrorPodAnnotation marks the API server's copy of a static pod, which the
+// kubelet recreates however often it is deleted
+const mirrorPodAnnotation = "kubernetes.io/config.mirror"
+
+// dryRun returns the dryRun option for a write; with dryRun the API server runs
+// admission and validation and returns the result without persisting it
+func dryRun(enabled bool) []string {
+	if enabled {
+		return []string{metav1.DryRunAll}
+	}
+	return nil
+}
+
+// ScaleDeployment sets the replica count of a deployment
+func (s *KubernetesService) ScaleDeployment(ctx context.Context, namespace, name string, replicas int32, dry bool) (*models.KubernetesActionResult, error) {
+	deploy, err := s.clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{"replicas": replicas},
+	})
+	if err != nil {
+		return nil, err
+	}
+	updated, err := s.clientset.AppsV1().Deployments(namespace).Patch(ctx, name, types.MergePatchType, patch,
+		metav1.PatchOptions{DryRun: dryRun(dry)})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.KubernetesActionResult{
+		Kind:      "Deployment",
+		Namespace: namespace,
+		Name:      name,
+		Action:    "scale",
+		DryRun:    dry,
+		Before:    map[string]interface{}{"replicas": replicasOf(deploy)},
+		After:     map[string]interface{}{"replicas": replicasOf(updated)},
+		Timestamp: time.Now().UTC(),
+	}, nil
+}
+
+// RestartDeployment triggers a rolling restart the way `kubectl rollout restart`
+// does, by stamping the pod template with the current time
+func (s *KubernetesService) RestartDeployment(ctx context.Context, namespace, name string, dry bool) (*models.KubernetesActionResult, error) {
+	deploy, err := s.clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if deploy.Spec.Paused {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("deployment %s/%s is paused; resume it before restarting", namespace, name))
+	}
+
+	now := time.Now().UTC()
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]string{restartedAtAnnotation: now.Format(time.RFC3339)},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	updated, err := s.clientset.AppsV1().Deployments(namespace).Patch(ctx, name, types.StrategicMergePatchType, patch,
+		metav1.PatchOptions{DryRun: dryRun(dry)})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.KubernetesActionResult{
+		Kind:      "Deployment",
+		Namespace: namespace,
+		Name:      name,
+		Action:    "restart",
+		DryRun:    dry,
+		Before:    map[string]interface{}{"restartedAt": deploy.Spec.Template.Annotations[restartedAtAnnotation]},
+		After:     map[string]interface{}{"restartedAt": updated.Spec.Template.Annotations[restartedAtAnnotation]},
+		Timestamp: now,
+	}, nil
+}
+
+// DeletePod deletes a pod so its controller schedules a replacement. A nil
+// gracePeriod keeps the pod's own terminationGracePeriodSeconds.
+func (s *KubernetesService) DeletePod(ctx context.Context, namespace, name string, gracePeriod *int64, dry bool) (*models.KubernetesActionResult, error) {
+	pod, err := s.clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.clientset.CoreV1().Pods(namespace).Delete(ctx, name, metav1.DeleteOptions{
+		GracePeriodSeconds: gracePeriod,
+		DryRun:             dryRun(dry),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	before := map[string]interface{}{
+		"phase": pod.Status.Phase,
+		"node":  pod.Spec.NodeName,
+	}
+	if owner := metav1.GetControllerOf(pod); owner != nil {
+		before["owner"] = owner.Kind + "/" + owner.Name
+	}
+
+	return &models.KubernetesActionResult{
+		Kind:      "Pod",
+		Namespace: namespace,
+		Name:      name,
+		Action:    "delete",
+		DryRun:    dry,
+		Before:    before,
+		Timestamp: time.Now().UTC(),
+	}, nil
+}
+
+// CordonNode marks a node unschedulable, or schedulable again with
+// unschedulable false
+func (s *KubernetesService) CordonNode(ctx context.Context, name string, unschedulable bool, dry bool) (*models.KubernetesActionResult, error) {
+	node, err := s.clientset.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	action := "cordon"
+	if !unschedulable {
+		action = "uncordon"
+	}
+	result := &models.KubernetesActionResult{
+		Kind:      "Node",
+		Name:      name,
+		Action:    action,
+		DryRun:    dry,
+		Before:    map[string]interface{}{"unschedulable": node.Spec.Unschedulable},
+		After:     map[string]interface{}{"unschedulable": unschedulable},
+		Timestamp: time.Now().UTC(),
+	}
+	if node.Spec.Unschedulable == unschedulable {
+		return result, nil
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{"unschedulable": unschedulable},
+	})
+	if err != nil {
+		return nil, err
+	}
+	updated, err := s.clientset.CoreV1().Nodes().Patch(ctx, name, types.StrategicMergePatchType, patch,
+		metav1.PatchOptions{DryRun: dryRun(dry)})
+	if err != nil {
+		return nil, err
+	}
+	result.After["unschedulable"] = updated.Spec.Unschedulable
+	return result, nil
+}
+
+// DrainNode cordons a node and evicts its pods, like `kubectl drain` without
+// waiting for the pods to terminate. Evictions go through the eviction API so
+// PodDisruptionBudgets are honoured; a refused eviction is reported in Failed
+// and does not stop the drain. Draining an already drained node is a no-op.
+func (s *KubernetesService) DrainNode(ctx context.Context, name string, req *models.DrainNodeRequest, dry bool) (*models.DrainResult, error) {
+	// The node must exist before its pods are checked; an empty pod list
+	// would otherwise pass for a node that was never there
+	if _, err := s.clientset.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{}); err != nil {
+		return nil, err
+	}
+
+	podList, err := s.clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("spec.nodeName", name).String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods on node %s: %w", name, err)
+	}
+
+	ignoreDaemonSets := req.IgnoreDaemonSets == nil || *req.IgnoreDaemonSets
+	result := &models.DrainResult{
+		Node:    name,
+		DryRun:  dry,
+		Evicted: []models.DrainPod{},
+		Skipped: []models.DrainPod{},
+		Failed:  []models.DrainPod{},
+	}
+
+	// Check every pod before cordoning the node or evicting any, so a drain
+	// that cannot complete leaves the node and its workloads as they were
+	var evict []corev1.Pod
+	var blocked []string
+	for _, pod := range podList.Items {
+		ref := models.DrainPod{Namespace: pod.Namespace, Name: pod.Name}
+
+		if pod.DeletionTimestamp != nil {
+			ref.Reason = "already terminating"
+			result.Skipped = append(result.Skipped, ref)
+			continue
+		}
+		if _, mirror := pod.Annotations[mirrorPodAnnotation]; mirror {
+			ref.Reason = "static pod"
+			result.Skipped = append(result.Skipped, ref)
+			continue
+		}
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			evict = append(evict, pod)
+			continue
+		}
+
+		owner := metav1.GetControllerOf(&pod)
+		if owner != nil && owner.Kind == "DaemonSet" {
+			if !ignoreDaemonSets {
+				blocked = append(blocked, pod.Namespace+"/"+pod.Name+" is managed by a DaemonSet")
+				continue
+			}
+			ref.Reason = "managed by DaemonSet " + owner.Name
+			result.Skipped = append(result.Skipped, ref)
+			continue
+		}
+		if owner == nil && !req.Force {
+			blocked = append(blocked, pod.Namespace+"/"+pod.Name+" is not managed by a controller (use force)")
+			continue
+		}
+		if hasEmptyDir(&pod) && !req.DeleteEmptyDirData {
+			blocked = append(blocked, pod.Namespace+"/"+pod.Name+" uses emptyDir storage (use deleteEmptyDirData)")
+			continue
+		}
+		evict = append(evict, pod)
+	}
+	if len(blocked) > 0 {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("cannot drain node %s: %v", name, blocked))
+	}
+
+	if _, err := s.CordonNode(ctx, name, true, dry); err != nil {
+		return nil, err
+	}
+
+	for _, pod := range evict {
+		ref := models.DrainPod{Namespace: pod.Namespace, Name: pod.Name}
+		eviction := &policyv1.Eviction{
+			ObjectMeta: metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace},
+			DeleteOptions: &metav1.DeleteOptions{
+				GracePeriodSeconds: req.GracePeriodSeconds,
+				DryRun:             dryRun(dry),
+			},
+		}
+
+		err := s.clientset.PolicyV1().Evictions(pod.Namespace).Evict(ctx, eviction)
+		switch {
+		case err == nil:
+			result.Evicted = append(result.Evicted, ref)
+		case apierrors.IsNotFound(err):
+			ref.Reason = "already gone"
+			result.Skipped = append(result.Skipped, ref)
+		case apierrors.IsTooManyRequests(err):
+			ref.Reason = "blocked by PodDisruptionBudget: " + err.Error()
+			result.Failed = append(result.Failed, ref)
+		default:
+			ref.Reason = err.Error()
+			result.Failed = append(result.Failed, ref)
+		}
+	}
+
+	return result, nil
+}
+
+// replicasOf returns a deployment's desired replicas, which default to 1
+func replicasOf(deploy *appsv1.Deployment) int32 {
+	if deploy.Spec.Replicas == nil {
+		return 1
+	}
+	return *deploy.Spec.Replicas
+}
+
+func hasEmptyDir(pod *corev1.Pod) bool {
+	for _, volume := range pod.Spec.Volumes {
+		if volume.EmptyDir != nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,256 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
+)
+
+// newFakeKubernetes returns a KubernetesService backed by a fake clientset
+// holding objects
+func newFakeKubernetes(t *testing.T, objects ...runtime.Object) (*KubernetesService, *fake.Clientset) {
+	t.Helper()
+	clientset := fake.NewClientset(objects...)
+	service := &KubernetesService{clientset: clientset}
+	t.Cleanup(service.Close)
+	return service, clientset
+}
+
+// controlledBy makes kind/name the controller of an object
+func controlledBy(kind, name string) []metav1.OwnerReference {
+	controller := true
+	return []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: kind, Name: name, UID: types.UID("uid-" + name), Controller: &controller}}
+}
+
+func nodePod(namespace, name string, owners []metav1.OwnerReference) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, OwnerReferences: owners},
+		Spec:       corev1.PodSpec{NodeName: "worker-1", Containers: containers("app")},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
+
+// recordEvictions answers every eviction, refusing those of the pods in
+// guarded with 429 as a PodDisruptionBudget does, and returns the evictions
+// the API server received
+func recordEvictions(clientset *fake.Clientset, guarded ...string) *[]*policyv1.Eviction {
+	var evictions []*policyv1.Eviction
+	clientset.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		eviction := action.(k8stesting.CreateAction).GetObject().(*policyv1.Eviction)
+		evictions = append(evictions, eviction)
+		if slices.Contains(guarded, eviction.Name) {
+			return true, nil, apierrors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 0)
+		}
+		return true, nil, nil
+	})
+	return &evictions
+}
+
+func drainPodNames(pods []models.DrainPod) []string {
+	names := make([]string, 0, len(pods))
+	for _, pod := range pods {
+		names = append(names, pod.Name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func TestDrainNode(t *testing.T) {
+	mirror := nodePod("kube-system", "etcd-worker-1", nil)
+	mirror.Annotations = map[string]string{mirrorPodAnnotation: "hash"}
+	done := nodePod("default", "migrate-x1", controlledBy("Job", "migrate"))
+	done.Status.Phase = corev1.PodSucceeded
+
+	service, clientset := newFakeKubernetes(t,
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-1"}},
+		nodePod("default", "web-1", controlledBy("ReplicaSet", "web")),
+		nodePod("default", "api-1", controlledBy("ReplicaSet", "api")),
+		nodePod("kube-system", "fluentd-x", controlledBy("DaemonSet", "fluentd")),
+		mirror,
+		done,
+	)
+	evictions := recordEvictions(clientset, "api-1")
+
+	result, err := service.DrainNode(context.Background(), "worker-1", &models.DrainNodeRequest{}, false)
+	if err != nil {
+		t.Fatalf("DrainNode: %v", err)
+	}
+
+	if got := drainPodNames(result.Evicted); !slices.Equal(got, []string{"migrate-x1", "web-1"}) {
+		t.Fatalf("evicted %v, want migrate-x1 and web-1", got)
+	}
+	if got := drainPodNames(result.Skipped); !slices.Equal(got, []string{"etcd-worker-1", "fluentd-x"}) {
+		t.Fatalf("skipped %v, want the mirror and DaemonSet pods", got)
+	}
+	if len(result.Failed) != 1 || result.Failed[0].Name != "api-1" {
+		t.Fatalf("failed %+v, want api-1 blocked by its disruption budget", result.Failed)
+	}
+	for _, eviction := range *evictions {
+		if eviction.Name == "fluentd-x" || eviction.Name == "etcd-worker-1" {
+			t.Fatalf("evicted skipped pod %s", eviction.Name)
+		}
+	}
+
+	node, err := clientset.CoreV1().Nodes().Get(context.Background(), "worker-1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get node: %v", err)
+	}
+	if !node.Spec.Unschedulable {
+		t.Fatal("drained node was not cordoned")
+	}
+}
+
+func TestDrainNodeBlocked(t *testing.T) {
+	ignoreDaemonSets := false
+	emptyDir := nodePod("default", "cache-1", controlledBy("ReplicaSet", "cache"))
+	emptyDir.Spec.Volumes = []corev1.Volume{{Name: "scratch", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}}
+
+	tests := []struct {
+		name string
+		pod  *corev1.Pod
+		req  models.DrainNodeRequest
+	}{
+		{"unmanaged pod without force", nodePod("default", "debug", nil), models.DrainNodeRequest{}},
+		{"DaemonSet pod not ignored", nodePod("kube-system", "fluentd-x", controlledBy("DaemonSet", "fluentd")), models.DrainNodeRequest{IgnoreDaemonSets: &ignoreDaemonSets}},
+		{"emptyDir data", emptyDir, models.DrainNodeRequest{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, clientset := newFakeKubernetes(t, &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-1"}}, tt.pod)
+			evictions := recordEvictions(clientset)
+
+			if _, err := service.DrainNode(context.Background(), "worker-1", &tt.req, false); !apierrors.IsBadRequest(err) {
+				t.Fatalf("DrainNode error = %v, want BadRequest", err)
+			}
+			if len(*evictions) != 0 {
+				t.Fatalf("a blocked drain evicted %d pods", len(*evictions))
+			}
+			node, _ := clientset.CoreV1().Nodes().Get(context.Background(), "worker-1", metav1.GetOptions{})
+			if node.Spec.Unschedulable {
+				t.Fatal("a blocked drain cordoned the node")
+			}
+		})
+	}
+
+	service, _ := newFakeKubernetes(t)
+	if _, err := service.DrainNode(context.Background(), "ghost", &models.DrainNodeRequest{}, false); !apierrors.IsNotFound(err) {
+		t.Fatalf("drain of a missing node: error = %v, want NotFound", err)
+	}
+}
+
+// dryRunOf returns the dryRun option of a write action, and whether it is one
+func dryRunOf(action k8stesting.Action) ([]string, bool) {
+	switch action := action.(type) {
+	case k8stesting.PatchActionImpl:
+		return action.PatchOptions.DryRun, true
+	case k8stesting.DeleteActionImpl:
+		return action.DeleteOptions.DryRun, true
+	case k8stesting.CreateActionImpl:
+		if eviction, ok := action.Object.(*policyv1.Eviction); ok && eviction.DeleteOptions != nil {
+			return eviction.DeleteOptions.DryRun, true
+		}
+		return action.CreateOptions.DryRun, true
+	}
+	return nil, false
+}
+
+func TestActionsDryRun(t *testing.T) {
+	replicas := int32(2)
+	objects := func() []runtime.Object {
+		return []runtime.Object{
+			&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"},
+				Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+			},
+			&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-1"}},
+			nodePod("default", "web-1", controlledBy("ReplicaSet", "web")),
+		}
+	}
+
+	actions := []struct {
+		name string
+		run  func(s *KubernetesService, dry bool) error
+	}{
+		{"scale", func(s *KubernetesService, dry bool) error {
+			_, err := s.ScaleDeployment(context.Background(), "default", "web", 5, dry)
+			return err
+		}},
+		{"restart", func(s *KubernetesService, dry bool) error {
+			_, err := s.RestartDeployment(context.Background(), "default", "web", dry)
+			return err
+		}},
+		{"delete pod", func(s *KubernetesService, dry bool) error {
+			_, err := s.DeletePod(context.Background(), "default", "web-1", nil, dry)
+			return err
+		}},
+		{"cordon", func(s *KubernetesService, dry bool) error {
+			_, err := s.CordonNode(context.Background(), "worker-1", true, dry)
+			return err
+		}},
+		{"drain", func(s *KubernetesService, dry bool) error {
+			_, err := s.DrainNode(context.Background(), "worker-1", &models.DrainNodeRequest{}, dry)
+			return err
+		}},
+	}
+	for _, action := range actions {
+		for _, dry := range []bool{true, false} {
+			t.Run(fmt.Sprintf("%s dry run %v", action.name, dry), func(t *testing.T) {
+				service, clientset := newFakeKubernetes(t, objects()...)
+				recordEvictions(clientset)
+				if err := action.run(service, dry); err != nil {
+					t.Fatalf("%s: %v", action.name, err)
+				}
+
+				writes := 0
+				for _, recorded := range clientset.Actions() {
+					option, ok := dryRunOf(recorded)
+					if !ok {
+						continue
+					}
+					writes++
+					if slices.Equal(option, []string{metav1.DryRunAll}) != dry {
+						t.Fatalf("%s %s sent dryRun %v with dry run %v", recorded.GetVerb(), recorded.GetResource().Resource, option, dry)
+					}
+				}
+				if writes == 0 {
+					t.Fatalf("%s made no write", action.name)
+				}
+			})
+		}
+	}
+}
+
+func TestScaleDeploymentResult(t *testing.T) {
+	replicas := int32(2)
+	service, _ := newFakeKubernetes(t, &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+	})
+
+	result, err := service.ScaleDeployment(context.Background(), "default", "web", 5, false)
+	if err != nil {
+		t.Fatalf("ScaleDeployment: %v", err)
+	}
+	if result.Before["replicas"] != int32(2) || result.After["replicas"] != int32(5) {
+		t.Fatalf("scale result %v -> %v, want 2 -> 5", result.Before, result.After)
+	}
+	if _, err := service.ScaleDeployment(context.Background(), "default", "ghost", 1, false); !apierrors.IsNotFound(err) {
+		t.Fatalf("scale of a missing deployment: error = %v, want NotFound", err)
+	}
+}
@@ -0,0 +1,348 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// cacheSyncTimeout is how long a list request waits for a new cache to fill
+// before falling back to listing from the API server
+const cacheSyncTimeout = 10 * time.Second
+
+// cacheRetryInterval is how long after a failed sync the cache is rebuilt
+const cacheRetryInterval = time.Minute
+
+// watchBuffer is how many events a watcher may fall behind before it is dropped
+const watchBuffer = 256
+
+// Kinds of objects KubernetesWatchEvent reports
+const (
+	KindPod        = "pod"
+	KindDeployment = "deployment"
+	KindService    = "service"
+	KindNamespace  = "namespace"
+)
+
+// Types of KubernetesWatchEvent
+const (
+	WatchAdded   = "added"
+	WatchUpdated = "updated"
+	WatchDeleted = "deleted"
+)
+
+// KubernetesWatchEvent is a change to a cached object. Object is the PodInfo,
+// DeploymentInfo, ServiceInfo or NamespaceInfo the list endpoints return; for
+// deletions it is the last state seen.
+type KubernetesWatchEvent struct {
+	Type      string      `json:"type"`
+	Kind      string      `json:"kind"`
+	Namespace string      `json:"namespace,omitempty"`
+	Name      string      `json:"name"`
+	Object    interface{} `json:"object"`
+}
+
+// kubernetesCache keeps pods, deployments, services and namespaces of a cluster
+// in memory through shared informers, so lists are served without a round trip
+// to the API server and changes can be pushed to watchers.
+type kubernetesCache struct {
+	factory    informers.SharedInformerFactory
+	pods       corelisters.PodLister
+	deploys    appslisters.DeploymentLister
+	services   corelisters.ServiceLister
+	namespaces corelisters.NamespaceLister
+	started    time.Time
+	stop       chan struct{}
+	synced     chan struct{}
+	syncErr    error
+
+	mu       sync.Mutex
+	closed   bool
+	watchers map[*kubernetesWatcher]struct{}
+}
+
+type kubernetesWatcher struct {
+	namespace string
+	events    chan KubernetesWatchEvent
+}
+
+func newKubernetesCache(clientset kubernetes.Interface) *kubernetesCache {
+	// Managed fields are never served and make up a large part of each object
+	factory := informers.NewSharedInformerFactoryWithOptions(clientset, 0,
+		informers.WithTransform(func(obj interface{}) (interface{}, error) {
+			if accessor, err := meta.Accessor(obj); err == nil {
+				accessor.SetManagedFields(nil)
+			}
+			return obj, nil
+		}))
+
+	c := &kubernetesCache{
+		factory:  factory,
+		stop:     make(chan struct{}),
+		synced:   make(chan struct{}),
+		watchers: make(map[*kubernetesWatcher]struct{}),
+	}
+
+	podInformer := factory.Core().V1().Pods()
+	deployInformer := factory.Apps().V1().Deployments()
+	serviceInformer := factory.Core().V1().Services()
+	namespaceInformer := factory.Core().V1().Namespaces()
+	c.pods = podInformer.Lister()
+	c.deploys = deployInformer.Lister()
+	c.services = serviceInformer.Lister()
+	c.namespaces = namespaceInformer.Lister()
+
+	podInformer.Informer().AddEventHandler(c.handler(KindPod, func(obj interface{}) interface{} {
+		return toPodInfo(obj.(*corev1.Pod))
+	}))
+	deployInformer.Informer().AddEventHandler(c.handler(KindDeployment, func(obj interface{}) interface{} {
+		return toDeploymentInfo(obj.(*appsv1.Deployment))
+	}))
+	serviceInformer.Informer().AddEventHandler(c.handler(KindService, func(obj interface{}) interface{} {
+		return toServiceInfo(obj.(*corev1.Service))
+	}))
+	namespaceInformer.Informer().AddEventHandler(c.handler(KindNamespace, func(obj interface{}) interface{} {
+		return toNamespaceInfo(obj.(*corev1.Namespace))
+	}))
+
+	return c
+}
+
+// start runs the informers and records whether their first list succeeded
+func (c *kubernetesCache) start() {
+	c.started = time.Now()
+	c.factory.Start(c.stop)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), cacheRetryInterval)
+		defer cancel()
+		go func() {
+			select {
+			case <-c.stop:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+
+		for informerType, ok := range c.factory.WaitForCacheSync(ctx.Done()) {
+			if !ok {
+				c.syncErr = fmt.Errorf("informer cache for %s did not sync", informerType)
+				break
+			}
+		}
+		close(c.synced)
+	}()
+}
+
+// ready reports whether the cache can serve lists. Until cacheSyncTimeout
+// after start it waits for the first sync; later calls do not block.
+func (c *kubernetesCache) ready() bool {
+	wait := time.Until(c.started.Add(cacheSyncTimeout))
+	if wait < 0 {
+		wait = 0
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-c.synced:
+		return c.syncErr == nil
+	case <-timer.C:
+		return false
+	}
+}
+
+// failed reports whether the first sync gave up, e.g. because the credentials
+// may not watch every namespace
+func (c *kubernetesCache) failed() bool {
+	select {
+	case <-c.synced:
+		return c.syncErr != nil
+	default:
+		return false
+	}
+}
+
+func (c *kubernetesCache) close() {
+	close(c.stop)
+	c.factory.Shutdown()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	for watcher := range c.watchers {
+		close(watcher.events)
+		delete(c.watchers, watcher)
+	}
+}
+
+// watch registers a watcher for changes in namespace ("" for all). The channel
+// is closed when the watcher falls more than watchBuffer events behind or the
+// cache is closed; the client should then list again and re-watch.
+func (c *kubernetesCache) watch(namespace string) (<-chan KubernetesWatchEvent, func()) {
+	watcher := &kubernetesWatcher{
+		namespace: namespace,
+		events:    make(chan KubernetesWatchEvent, watchBuffer),
+	}
+
+	c.mu.Lock()
+	if c.closed {
+		// The cache was closed after the caller got hold of it
+		close(watcher.events)
+	} else {
+		c.watchers[watcher] = struct{}{}
+	}
+	c.mu.Unlock()
+
+	cancel := func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if _, ok := c.watchers[watcher]; ok {
+			close(watcher.events)
+			delete(c.watchers, watcher)
+		}
+	}
+	return watcher.events, cancel
+}
+
+func (c *kubernetesCache) publish(event KubernetesWatchEvent) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for watcher := range c.watchers {
+		// Namespaces are cluster-scoped and go to every watcher
+		if watcher.namespace != "" && event.Kind != KindNamespace && watcher.namespace != event.Namespace {
+			continue
+		}
+		select {
+		case watcher.events <- event:
+		default:
+			log.Printf("⚠️ Dropping Kubernetes watcher that fell %d events behind", watchBuffer)
+			close(watcher.events)
+			delete(c.watchers, watcher)
+		}
+	}
+}
+
+// handler turns informer notifications into watch events for kind; convert
+// maps the Kubernetes object to what the list endpoint returns
+func (c *kubernetesCache) handler(kind string, convert func(obj interface{}) interface{}) cache.ResourceEventHandler {
+	event := func(eventType string, obj interface{}) {
+		if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+			obj = tombstone.Obj
+		}
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			return
+		}
+		c.publish(KubernetesWatchEvent{
+			Type:      eventType,
+			Kind:      kind,
+			Namespace: accessor.GetNamespace(),
+			Name:      accessor.GetName(),
+			Object:    convert(obj),
+		})
+	}
+
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			event(WatchAdded, obj)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			// Periodic relists deliver unchanged objects as updates
+			oldAccessor, oldErr := meta.Accessor(oldObj)
+			newAccessor, newErr := meta.Accessor(newObj)
+			if oldErr == nil && newErr == nil && oldAccessor.GetResourceVersion() == newAccessor.GetResourceVersion() {
+				return
+			}
+			event(WatchUpdated, newObj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			event(WatchDeleted, obj)
+		},
+	}
+}
+
+func (c *kubernetesCache) listPods(namespace string, selector labels.Selector) ([]PodInfo, error) {
+	pods, err := c.pods.Pods(namespace).List(selector)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(pods, func(i, j int) bool {
+		return namespacedLess(pods[i].Namespace, pods[i].Name, pods[j].Namespace, pods[j].Name)
+	})
+
+	infos := make([]PodInfo, 0, len(pods))
+	for _, pod := range pods {
+		infos = append(infos, toPodInfo(pod))
+	}
+	return infos, nil
+}
+
+func (c *kubernetesCache) listDeployments(namespace string, selector labels.Selector) ([]DeploymentInfo, error) {
+	deploys, err := c.deploys.Deployments(namespace).List(selector)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(deploys, func(i, j int) bool {
+		return namespacedLess(deploys[i].Namespace, deploys[i].Name, deploys[j].Namespace, deploys[j].Name)
+	})
+
+	infos := make([]DeploymentInfo, 0, len(deploys))
+	for _, deploy := range deploys {
+		infos = append(infos, toDeploymentInfo(deploy))
+	}
+	return infos, nil
+}
+
+func (c *kubernetesCache) listServices(namespace string, selector labels.Selector) ([]ServiceInfo, error) {
+	svcs, err := c.services.Services(namespace).List(selector)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(svcs, func(i, j int) bool {
+		return namespacedLess(svcs[i].Namespace, svcs[i].Name, svcs[j].Namespace, svcs[j].Name)
+	})
+
+	infos := make([]ServiceInfo, 0, len(svcs))
+	for _, svc := range svcs {
+		infos = append(infos, toServiceInfo(svc))
+	}
+	return infos, nil
+}
+
+func (c *kubernetesCache) listNamespaces() ([]NamespaceInfo, error) {
+	namespaces, err := c.namespaces.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(namespaces, func(i, j int) bool {
+		return namespaces[i].Name < namespaces[j].Name
+	})
+
+	infos := make([]NamespaceInfo, 0, len(namespaces))
+	for _, ns := range namespaces {
+		infos = append(infos, toNamespaceInfo(ns))
+	}
+	return infos, nil
+}
+
+// namespacedLess orders objects by namespace, then name, as the API server does
+func namespacedLess(namespaceA, nameA, namespaceB, nameB string) bool {
+	if namespaceA != namespaceB {
+		return namespaceA < namespaceB
+	}
+	return nameA < nameB
+}
@@ -0,0 +1,237 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// waitForWatches waits until the informers watch every cached kind. The fake
+// clientset drops changes made between an informer's list and its watch.
+func waitForWatches(t *testing.T, clientset *fake.Clientset) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		watched := map[string]bool{}
+		for _, action := range clientset.Actions() {
+			if action.GetVerb() == "watch" {
+				watched[action.GetResource().Resource] = true
+			}
+		}
+		if watched["pods"] && watched["deployments"] && watched["services"] && watched["namespaces"] {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("informers did not start watching")
+}
+
+func nextEvent(t *testing.T, events <-chan KubernetesWatchEvent) KubernetesWatchEvent {
+	t.Helper()
+	select {
+	case event, ok := <-events:
+		if !ok {
+			t.Fatal("watch closed")
+		}
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("no watch event")
+	}
+	return KubernetesWatchEvent{}
+}
+
+func TestGetPodsFromCache(t *testing.T) {
+	service, clientset := newFakeKubernetes(t,
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web-1", Labels: map[string]string{"app": "web"}}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "api-1", Labels: map[string]string{"app": "api"}}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "jobs", Name: "web-2", Labels: map[string]string{"app": "web"}}},
+	)
+	pods, _, err := service.GetPods("all", ListOptions{})
+	if err != nil {
+		t.Fatalf("GetPods: %v", err)
+	}
+	if got := podNames(pods); !slices.Equal(got, []string{"api-1", "web-1", "web-2"}) {
+		t.Fatalf("pods = %v", got)
+	}
+
+	// Once the cache has synced, lists no longer reach the API server
+	clientset.ClearActions()
+	clientset.PrependReactor("list", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("unexpected list from the API server")
+	})
+
+	tests := []struct {
+		namespace string
+		selector  string
+		want      []string
+	}{
+		{"all", "app=web", []string{"web-1", "web-2"}},
+		{"default", "app=web", []string{"web-1"}},
+		{"default", "app!=web", []string{"api-1"}},
+		{"jobs", "", []string{"web-2"}},
+		{"default", "app in (db)", []string{}},
+	}
+	for _, tt := range tests {
+		pods, _, err := service.GetPods(tt.namespace, ListOptions{LabelSelector: tt.selector})
+		if err != nil {
+			t.Fatalf("GetPods(%s, %q): %v", tt.namespace, tt.selector, err)
+		}
+		if got := podNames(pods); !slices.Equal(got, tt.want) {
+			t.Fatalf("GetPods(%s, %q) = %v, want %v", tt.namespace, tt.selector, got, tt.want)
+		}
+	}
+	for _, action := range clientset.Actions() {
+		if action.GetVerb() == "list" {
+			t.Fatalf("cached list reached the API server: %v", action)
+		}
+	}
+
+	// Paging is not cached and goes to the API server
+	if _, _, err := service.GetPods("default", ListOptions{Limit: 10}); err == nil {
+		t.Fatal("paged list was served from the cache")
+	}
+}
+
+func TestWatch(t *testing.T) {
+	service, clientset := newFakeKubernetes(t)
+	events, cancel, err := service.Watch("default")
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	defer cancel()
+	waitForWatches(t, clientset)
+
+	ctx := context.Background()
+	pods := clientset.CoreV1().Pods
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web-1", ResourceVersion: "1"},
+		Spec:       corev1.PodSpec{Containers: containers("app")},
+		Status:     corev1.PodStatus{Phase: corev1.PodPending},
+	}
+
+	// Pods of other namespaces are not reported, namespaces always are
+	if _, err := pods("jobs").Create(ctx, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "jobs", Name: "batch-1"}}, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("create pod: %v", err)
+	}
+	if _, err := clientset.CoreV1().Namespaces().Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "staging"}}, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("create namespace: %v", err)
+	}
+	if event := nextEvent(t, events); event.Type != WatchAdded || event.Kind != KindNamespace || event.Name != "staging" {
+		t.Fatalf("event = %+v, want the staging namespace added", event)
+	}
+
+	if _, err := pods("default").Create(ctx, pod, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("create pod: %v", err)
+	}
+	event := nextEvent(t, events)
+	if event.Type != WatchAdded || event.Kind != KindPod || event.Namespace != "default" || event.Name != "web-1" {
+		t.Fatalf("event = %+v, want pod default/web-1 added", event)
+	}
+	if info, ok := event.Object.(PodInfo); !ok || info.Status != "Pending" {
+		t.Fatalf("added object = %#v, want a pending PodInfo", event.Object)
+	}
+
+	pod.ResourceVersion = "2"
+	pod.Status.Phase = corev1.PodRunning
+	if _, err := pods("default").UpdateStatus(ctx, pod, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("update pod: %v", err)
+	}
+	event = nextEvent(t, events)
+	if info, ok := event.Object.(PodInfo); event.Type != WatchUpdated || !ok || info.Status != "Running" {
+		t.Fatalf("event = %+v, want pod web-1 updated to Running", event)
+	}
+
+	if err := pods("default").Delete(ctx, "web-1", metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("delete pod: %v", err)
+	}
+	event = nextEvent(t, events)
+	if info, ok := event.Object.(PodInfo); event.Type != WatchDeleted || !ok || info.Status != "Running" {
+		t.Fatalf("event = %+v, want pod web-1 deleted in its last state", event)
+	}
+
+	replicas := int32(1)
+	if _, err := clientset.AppsV1().Deployments("default").Create(ctx, &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+	}, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("create deployment: %v", err)
+	}
+	if event := nextEvent(t, events); event.Type != WatchAdded || event.Kind != KindDeployment || event.Name != "web" {
+		t.Fatalf("event = %+v, want deployment web added", event)
+	}
+
+	select {
+	case event := <-events:
+		t.Fatalf("unexpected event %+v", event)
+	default:
+	}
+}
+
+func TestWatchEndsOnClose(t *testing.T) {
+	service, _ := newFakeKubernetes(t)
+	events, _, err := service.Watch("all")
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+
+	service.Close()
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Fatal("received an event after Close")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close did not end the watch")
+	}
+	if _, _, err := service.Watch("all"); !errors.Is(err, ErrWatchUnavailable) {
+		t.Fatalf("Watch after Close: error = %v, want ErrWatchUnavailable", err)
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	service, _ := newFakeKubernetes(t,
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "jobs"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web-1"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "jobs", Name: "batch-1"}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "jobs", Name: "queue"}},
+	)
+
+	snapshot, err := service.Snapshot("default")
+	if err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	if got := podNames(snapshot.Pods); !slices.Equal(got, []string{"web-1"}) {
+		t.Fatalf("snapshot pods = %v, want web-1", got)
+	}
+	if len(snapshot.Deployments) != 1 || snapshot.Deployments[0].Name != "web" {
+		t.Fatalf("snapshot deployments = %+v, want web", snapshot.Deployments)
+	}
+	if len(snapshot.Services) != 0 {
+		t.Fatalf("snapshot services = %+v, want none in default", snapshot.Services)
+	}
+	if len(snapshot.Namespaces) != 2 {
+		t.Fatalf("snapshot namespaces = %+v, want both", snapshot.Namespaces)
+	}
+
+	all, err := service.Snapshot("all")
+	if err != nil {
+		t.Fatalf("Snapshot(all): %v", err)
+	}
+	if got := podNames(all.Pods); !slices.Equal(got, []string{"web-1", "batch-1"}) {
+		t.Fatalf("snapshot of all namespaces pods = %v, want web-1 and batch-1", got)
+	}
+	if len(all.Services) != 1 || all.Services[0].Name != "queue" {
+		t.Fatalf("snapshot of all namespaces services = %+v, want queue", all.Services)
+	}
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+)
+
+// ErrUnknownResource is returned for a group, version and resource the API
+// server does not serve
+var ErrUnknownResource = errors.New("unknown resource")
+
+// APIResourceInfo is a resource type the cluster serves, as listed by discovery
+type APIResourceInfo struct {
+	Group      string   `json:"group"`
+	Version    string   `json:"version"`
+	Resource   string   `json:"resource"`
+	Kind       string   `json:"kind"`
+	Namespaced bool     `json:"namespaced"`
+	Verbs      []string `json:"verbs"`
+}
+
+// GetAPIResources lists the resource types the cluster serves, at each group's
+// preferred version. Groups whose discovery fails, such as an aggregated API
+// whose backend is down, are left out.
+func (s *KubernetesService) GetAPIResources() ([]APIResourceInfo, error) {
+	lists, err := s.clientset.Discovery().ServerPreferredResources()
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return nil, fmt.Errorf("failed to discover resources: %w", err)
+	}
+
+	resources := []APIResourceInfo{}
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, resource := range list.APIResources {
+			resources = append(resources, APIResourceInfo{
+				Group:      gv.Group,
+				Version:    gv.Version,
+				Resource:   resource.Name,
+				Kind:       resource.Kind,
+				Namespaced: resource.Namespaced,
+				Verbs:      resource.Verbs,
+			})
+		}
+	}
+
+	sort.Slice(resources, func(i, j int) bool {
+		if resources[i].Group != resources[j].Group {
+			return resources[i].Group < resources[j].Group
+		}
+		return resources[i].Resource < resources[j].Resource
+	})
+	return resources, nil
+}
+
+// ListDynamic lists objects of any resource type. namespace ("" or "all" for
+// every namespace) is ignored for cluster-scoped resources.
+func (s *KubernetesService) ListDynamic(ctx context.Context, gvr schema.GroupVersionResource, namespace string) ([]map[string]interface{}, error) {
+	client, err := s.dynamicResource(gvr, namespace, "list")
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := client.List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", gvr.Resource, err)
+	}
+
+	items := make([]map[string]interface{}, 0, len(list.Items))
+	for i := range list.Items {
+		items = append(items, redactObject(gvr, &list.Items[i]))
+	}
+	return items, nil
+}
+
+// GetDynamic fetches one object of any resource type
+func (s *KubernetesService) GetDynamic(ctx context.Context, gvr schema.GroupVersionResource, namespace, name string) (map[string]interface{}, error) {
+	client, err := s.dynamicResource(gvr, namespace, "get")
+	if err != nil {
+		return nil, err
+	}
+
+	obj, err := client.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return redactObject(gvr, obj), nil
+}
+
+// dynamicResource looks gvr up through discovery, checks it supports verb and
+// scopes the client to namespace if the resource is namespaced
+func (s *KubernetesService) dynamicResource(gvr schema.GroupVersionResource, namespace, verb string) (dynamic.ResourceInterface, error) {
+	list, err := s.clientset.Discovery().ServerResourcesForGroupVersion(gvr.GroupVersion().String())
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownResource, gvr)
+		}
+		return nil, fmt.Errorf("failed to discover %s: %w", gvr.GroupVersion(), err)
+	}
+
+	var resource *metav1.APIResource
+	for i := range list.APIResources {
+		// Subresources such as pods/log are listed as "pods/log"
+		if list.APIResources[i].Name == gvr.Resource {
+			resource = &list.APIResources[i]
+			break
+		}
+	}
+	if resource == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownResource, gvr)
+	}
+	if !slices.Contains(resource.Verbs, verb) {
+		return nil, apierrors.NewMethodNotSupported(gvr.GroupResource(), verb)
+	}
+
+	if !resource.Namespaced {
+		return s.dynamic.Resource(gvr), nil
+	}
+	if namespace == "all" {
+		namespace = ""
+	}
+	if namespace == "" && verb == "get" {
+		return nil, apierrors.NewBadRequest(gvr.Resource + " are namespaced; a namespace is required")
+	}
+	return s.dynamic.Resource(gvr).Namespace(namespace), nil
+}
+
+// redactObject returns obj without its managed fields and, for a Secret, without
+// its values or the last-applied annotation that may repeat them
+func redactObject(gvr schema.GroupVersionResource, obj *unstructured.Unstructured) map[string]interface{} {
+	obj.SetManagedFields(nil)
+	if gvr.Group == "" && gvr.Resource == "secrets" {
+		delete(obj.Object, "data")
+		delete(obj.Object, "stringData")
+		if annotations := obj.GetAnnotations(); annotations != nil {
+			delete(annotations, lastAppliedAnnotation)
+			obj.SetAnnotations(annotations)
+		}
+	}
+	return obj.Object
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+var (
+	secretsResource       = schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+	widgetsResource       = schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}
+	clusterWidgetResource = schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "clusterwidgets"}
+	gadgetsResource       = schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "gadgets"}
+)
+
+func widget(kind, namespace, name string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("example.com/v1")
+	obj.SetKind(kind)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	return obj
+}
+
+// newDynamicKubernetes returns a KubernetesService whose discovery serves core
+// secrets and a few example.com/v1 resources, backed by a fake dynamic client
+func newDynamicKubernetes(t *testing.T) *KubernetesService {
+	t.Helper()
+	service, clientset := newFakeKubernetes(t)
+	clientset.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{
+		{GroupVersion: "v1", APIResources: []metav1.APIResource{
+			{Name: "secrets", Kind: "Secret", Namespaced: true, Verbs: metav1.Verbs{"get", "list"}},
+			{Name: "pods/log", Kind: "Pod", Namespaced: true, Verbs: metav1.Verbs{"get"}},
+		}},
+		{GroupVersion: "example.com/v1", APIResources: []metav1.APIResource{
+			{Name: "widgets", Kind: "Widget", Namespaced: true, Verbs: metav1.Verbs{"get", "list"}},
+			{Name: "clusterwidgets", Kind: "ClusterWidget", Verbs: metav1.Verbs{"get", "list"}},
+			{Name: "gadgets", Kind: "Gadget", Namespaced: true, Verbs: metav1.Verbs{"get"}},
+		}},
+	}
+
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add core types to scheme: %v", err)
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default", Name: "db",
+			Annotations: map[string]string{
+				lastAppliedAnnotation: `{"data":{"password":"aHVudGVyMg=="}}`,
+				"team":                "storage",
+			},
+			ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "kubectl", Operation: metav1.ManagedFieldsOperationUpdate}},
+		},
+		Data:       map[string][]byte{"password": []byte("hunter2")},
+		StringData: map[string]string{"user": "admin"},
+	}
+	service.dynamic = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme,
+		map[schema.GroupVersionResource]string{
+			widgetsResource:       "WidgetList",
+			clusterWidgetResource: "ClusterWidgetList",
+			gadgetsResource:       "GadgetList",
+		},
+		secret,
+		widget("Widget", "team-a", "alpha"),
+		widget("Widget", "team-b", "beta"),
+		widget("ClusterWidget", "", "global"),
+	)
+	return service
+}
+
+func TestGetDynamicRedactsSecrets(t *testing.T) {
+	service := newDynamicKubernetes(t)
+
+	obj, err := service.GetDynamic(context.Background(), secretsResource, "default", "db")
+	if err != nil {
+		t.Fatalf("GetDynamic: %v", err)
+	}
+	for _, field := range []string{"data", "stringData"} {
+		if _, ok := obj[field]; ok {
+			t.Fatalf("secret returned with %s", field)
+		}
+	}
+	secret := unstructured.Unstructured{Object: obj}
+	if _, ok := secret.GetAnnotations()[lastAppliedAnnotation]; ok {
+		t.Fatal("secret returned with its last-applied configuration")
+	}
+	if secret.GetAnnotations()["team"] != "storage" {
+		t.Fatalf("annotations = %v, want the others kept", secret.GetAnnotations())
+	}
+	if len(secret.GetManagedFields()) != 0 {
+		t.Fatal("secret returned with managed fields")
+	}
+
+	items, err := service.ListDynamic(context.Background(), secretsResource, "all")
+	if err != nil {
+		t.Fatalf("ListDynamic: %v", err)
+	}
+	if len(items) != 1 || items[0]["data"] != nil {
+		t.Fatalf("listed secrets = %v, want db without data", items)
+	}
+}
+
+func TestListDynamic(t *testing.T) {
+	service := newDynamicKubernetes(t)
+
+	tests := []struct {
+		name      string
+		gvr       schema.GroupVersionResource
+		namespace string
+		want      int
+	}{
+		{"every namespace", widgetsResource, "all", 2},
+		{"one namespace", widgetsResource, "team-a", 1},
+		{"namespace ignored for cluster-scoped resources", clusterWidgetResource, "team-a", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, err := service.ListDynamic(context.Background(), tt.gvr, tt.namespace)
+			if err != nil {
+				t.Fatalf("ListDynamic: %v", err)
+			}
+			if len(items) != tt.want {
+				t.Fatalf("listed %d %s, want %d", len(items), tt.gvr.Resource, tt.want)
+			}
+		})
+	}
+}
+
+func TestDynamicResourceErrors(t *testing.T) {
+	service := newDynamicKubernetes(t)
+	ctx := context.Background()
+
+	unknown := []schema.GroupVersionResource{
+		{Group: "example.com", Version: "v2", Resource: "widgets"},
+		{Group: "example.com", Version: "v1", Resource: "sprockets"},
+		{Version: "v1", Resource: "log"},
+	}
+	for _, gvr := range unknown {
+		if _, err := service.ListDynamic(ctx, gvr, "all"); !errors.Is(err, ErrUnknownResource) {
+			t.Fatalf("ListDynamic(%s) error = %v, want ErrUnknownResource", gvr, err)
+		}
+	}
+
+	if _, err := service.ListDynamic(ctx, gadgetsResource, "all"); !apierrors.IsMethodNotSupported(err) {
+		t.Fatalf("list of a resource without the list verb: error = %v, want MethodNotSupported", err)
+	}
+	if _, err := service.GetDynamic(ctx, widgetsResource, "all", "alpha"); !apierrors.IsBadRequest(err) {
+		t.Fatalf("get of a namespaced resource without a namespace: error = %v, want BadRequest", err)
+	}
+	if _, err := service.GetDynamic(ctx, widgetsResource, "team-a", "ghost"); !apierrors.IsNotFound(err) {
+		t.Fatalf("get of a missing object: error = %v, want NotFound", err)
+	}
+	if _, err := service.GetDynamic(ctx, clusterWidgetResource, "", "global"); err != nil {
+		t.Fatalf("get of a cluster-scoped object: %v", err)
+	}
+}
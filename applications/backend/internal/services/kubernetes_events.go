@@ -0,0 +1,327 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// maxEvents caps how many events ListEvents returns
+const maxEvents = 1000
+
+// revisionAnnotation numbers a deployment's ReplicaSets by rollout
+const revisionAnnotation = "deployment.kubernetes.io/revision"
+
+// EventInfo is a Kubernetes event. Count is how often it recurred between
+// FirstSeen and LastSeen.
+type EventInfo struct {
+	Type      string          `json:"type"`
+	Reason    string          `json:"reason"`
+	Message   string          `json:"message"`
+	Object    EventObjectInfo `json:"object"`
+	Source    string          `json:"source,omitempty"`
+	Count     int32           `json:"count"`
+	FirstSeen time.Time       `json:"first_seen"`
+	LastSeen  time.Time       `json:"last_seen"`
+}
+
+// EventObjectInfo is the object an event is about
+type EventObjectInfo struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	UID       string `json:"uid,omitempty"`
+	FieldPath string `json:"field_path,omitempty"`
+}
+
+// EventFilter selects events for ListEvents; empty fields match everything
+type EventFilter struct {
+	Namespace string
+	Kind      string
+	Name      string
+	UID       string
+	Type      string
+	Since     *time.Time
+	Limit     int
+}
+
+// ListEvents returns events matching filter, newest first. Object and type are
+// filtered by the API server; Since applies to when an event was last seen.
+func (s *KubernetesService) ListEvents(ctx context.Context, filter EventFilter) ([]EventInfo, error) {
+	namespace := filter.Namespace
+	if namespace == "all" {
+		namespace = ""
+	}
+
+	selector := fields.Set{}
+	if filter.Kind != "" {
+		selector["involvedObject.kind"] = filter.Kind
+	}
+	if filter.Name != "" {
+		selector["involvedObject.name"] = filter.Name
+	}
+	if filter.UID != "" {
+		selector["involvedObject.uid"] = filter.UID
+	}
+	if filter.Type != "" {
+		selector["type"] = filter.Type
+	}
+
+	list, err := s.clientset.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{
+		FieldSelector: fields.SelectorFromSet(selector).String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events: %w", err)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 || limit > maxEvents {
+		limit = maxEvents
+	}
+	return toEventInfos(list.Items, filter.Since, limit, nil), nil
+}
+
+// objectEvents returns the events about the objects with the given UIDs in
+// namespace, newest first
+func (s *KubernetesService) objectEvents(ctx context.Context, namespace string, uids ...types.UID) ([]EventInfo, error) {
+	selector := ""
+	if len(uids) == 1 {
+		selector = fields.OneTermEqualSelector("involvedObject.uid", string(uids[0])).String()
+	}
+
+	list, err := s.clientset.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{FieldSelector: selector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events: %w", err)
+	}
+
+	wanted := make(map[types.UID]bool, len(uids))
+	for _, uid := range uids {
+		wanted[uid] = true
+	}
+	return toEventInfos(list.Items, nil, maxEvents, wanted), nil
+}
+
+// toEventInfos converts, filters and sorts events, newest first. A nil wanted
+// keeps events about any object.
+func toEventInfos(events []corev1.Event, since *time.Time, limit int, wanted map[types.UID]bool) []EventInfo {
+	infos := make([]EventInfo, 0, len(events))
+	for i := range events {
+		event := &events[i]
+		if wanted != nil && !wanted[event.InvolvedObject.UID] {
+			continue
+		}
+		info := toEventInfo(event)
+		if since != nil && info.LastSeen.Before(*since) {
+			continue
+		}
+		infos = append(infos, info)
+	}
+
+	sort.SliceStable(infos, func(i, j int) bool {
+		return infos[i].LastSeen.After(infos[j].LastSeen)
+	})
+	if len(infos) > limit {
+		infos = infos[:limit]
+	}
+	return infos
+}
+
+func toEventInfo(event *corev1.Event) EventInfo {
+	// Events recorded through events.k8s.io/v1 only set EventTime and Series
+	firstSeen := event.FirstTimestamp.Time
+	if firstSeen.IsZero() {
+		firstSeen = event.EventTime.Time
+	}
+	if firstSeen.IsZero() {
+		firstSeen = event.CreationTimestamp.Time
+	}
+	lastSeen := event.LastTimestamp.Time
+	if event.Series != nil && event.Series.LastObservedTime.After(lastSeen) {
+		lastSeen = event.Series.LastObservedTime.Time
+	}
+	if lastSeen.IsZero() {
+		lastSeen = firstSeen
+	}
+	if firstSeen.IsZero() || firstSeen.After(lastSeen) {
+		firstSeen = lastSeen
+	}
+
+	count := event.Count
+	if event.Series != nil && event.Series.Count > count {
+		count = event.Series.Count
+	}
+	if count == 0 {
+		count = 1
+	}
+
+	source := event.Source.Component
+	if source == "" {
+		source = event.ReportingController
+	}
+	if host := event.Source.Host; host != "" {
+		source += ", " + host
+	}
+
+	return EventInfo{
+		Type:    event.Type,
+		Reason:  event.Reason,
+		Message: event.Message,
+		Object: EventObjectInfo{
+			Kind:      event.InvolvedObject.Kind,
+			Namespace: event.InvolvedObject.Namespace,
+			Name:      event.InvolvedObject.Name,
+			UID:       string(event.InvolvedObject.UID),
+			FieldPath: event.InvolvedObject.FieldPath,
+		},
+		Source:    source,
+		Count:     count,
+		FirstSeen: firstSeen.UTC(),
+		LastSeen:  lastSeen.UTC(),
+	}
+}
+
+// DeploymentDetail is the deployment detail view: the list row, its rollout
+// state, its ReplicaSets and an event timeline covering the deployment, its
+// ReplicaSets and their pods
+type DeploymentDetail struct {
+	DeploymentInfo
+	UID         string                    `json:"uid"`
+	Labels      map[string]string         `json:"labels,omitempty"`
+	Annotations map[string]string         `json:"annotations,omitempty"`
+	Selector    string                    `json:"selector"`
+	Strategy    string                    `json:"strategy"`
+	Paused      bool                      `json:"paused"`
+	Conditions  []DeploymentConditionInfo `json:"conditions"`
+	ReplicaSets []ReplicaSetInfo          `json:"replica_sets"`
+	Events      []EventInfo               `json:"events"`
+	EventsError string                    `json:"events_error,omitempty"`
+}
+
+type DeploymentConditionInfo struct {
+	Type           string     `json:"type"`
+	Status         string     `json:"status"`
+	Reason         string     `json:"reason,omitempty"`
+	Message        string     `json:"message,omitempty"`
+	LastUpdateTime *time.Time `json:"last_update_time,omitempty"`
+}
+
+// ReplicaSetInfo is one rollout of a deployment
+type ReplicaSetInfo struct {
+	Name          string    `json:"name"`
+	Revision      int64     `json:"revision"`
+	Replicas      int32     `json:"replicas"`
+	ReadyReplicas int32     `json:"ready_replicas"`
+	Image         string    `json:"image"`
+	Age           string    `json:"age"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// GetDeployment returns the detail view of a deployment
+func (s *KubernetesService) GetDeployment(ctx context.Context, namespace, name string) (*DeploymentDetail, error) {
+	deploy, err := s.clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(deploy.Spec.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector on deployment %s/%s: %w", namespace, name, err)
+	}
+
+	detail := &DeploymentDetail{
+		DeploymentInfo: toDeploymentInfo(deploy),
+		UID:            string(deploy.UID),
+		Labels:         deploy.Labels,
+		Annotations:    deploy.Annotations,
+		Selector:       selector.String(),
+		Strategy:       string(deploy.Spec.Strategy.Type),
+		Paused:         deploy.Spec.Paused,
+		Conditions:     make([]DeploymentConditionInfo, 0, len(deploy.Status.Conditions)),
+		ReplicaSets:    []ReplicaSetInfo{},
+		Events:         []EventInfo{},
+	}
+	for _, condition := range deploy.Status.Conditions {
+		lastUpdate := condition.LastUpdateTime
+		detail.Conditions = append(detail.Conditions, DeploymentConditionInfo{
+			Type:           string(condition.Type),
+			Status:         string(condition.Status),
+			Reason:         condition.Reason,
+			Message:        condition.Message,
+			LastUpdateTime: timePtr(&lastUpdate),
+		})
+	}
+
+	uids := []types.UID{deploy.UID}
+	replicaSets, err := s.clientset.AppsV1().ReplicaSets(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list replica sets: %w", err)
+	}
+	owned := make(map[types.UID]bool)
+	for i := range replicaSets.Items {
+		rs := &replicaSets.Items[i]
+		if owner := metav1.GetControllerOf(rs); owner == nil || owner.UID != deploy.UID {
+			continue
+		}
+		owned[rs.UID] = true
+		uids = append(uids, rs.UID)
+		detail.ReplicaSets = append(detail.ReplicaSets, toReplicaSetInfo(rs))
+	}
+	sort.Slice(detail.ReplicaSets, func(i, j int) bool {
+		return detail.ReplicaSets[i].Revision > detail.ReplicaSets[j].Revision
+	})
+
+	if pods, err := s.podsOwnedBy(ctx, namespace, selector, owned); err == nil {
+		uids = append(uids, pods...)
+	}
+
+	// The timeline is an aid; a user who may not read events still gets the rest
+	if events, err := s.objectEvents(ctx, namespace, uids...); err != nil {
+		detail.EventsError = err.Error()
+	} else {
+		detail.Events = events
+	}
+	return detail, nil
+}
+
+// podsOwnedBy returns the UIDs of the pods matching selector whose controller
+// is one of owners
+func (s *KubernetesService) podsOwnedBy(ctx context.Context, namespace string, selector labels.Selector, owners map[types.UID]bool) ([]types.UID, error) {
+	pods, err := s.listPodObjects(ctx, namespace, selector)
+	if err != nil {
+		return nil, err
+	}
+
+	var uids []types.UID
+	for _, pod := range pods {
+		if owner := metav1.GetControllerOf(pod); owner != nil && owners[owner.UID] {
+			uids = append(uids, pod.UID)
+		}
+	}
+	return uids, nil
+}
+
+func toReplicaSetInfo(rs *appsv1.ReplicaSet) ReplicaSetInfo {
+	revision, _ := strconv.ParseInt(rs.Annotations[revisionAnnotation], 10, 64)
+	replicas := int32(0)
+	if rs.Spec.Replicas != nil {
+		replicas = *rs.Spec.Replicas
+	}
+	return ReplicaSetInfo{
+		Name:          rs.Name,
+		Revision:      revision,
+		Replicas:      replicas,
+		ReadyReplicas: rs.Status.ReadyReplicas,
+		Image:         containerImages(&rs.Spec.Template.Spec),
+		Age:           age(rs.CreationTimestamp),
+		CreatedAt:     rs.CreationTimestamp.UTC(),
+	}
+}
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func objectEvent(name, kind, object string, uid types.UID, lastSeen time.Time) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Namespace: "default", Name: name},
+		InvolvedObject: corev1.ObjectReference{Kind: kind, Namespace: "default", Name: object, UID: uid},
+		Type:           corev1.EventTypeNormal,
+		Reason:         name,
+		LastTimestamp:  metav1.NewTime(lastSeen),
+	}
+}
+
+func eventReasons(events []EventInfo) []string {
+	reasons := make([]string, 0, len(events))
+	for _, event := range events {
+		reasons = append(reasons, event.Reason)
+	}
+	return reasons
+}
+
+func TestListEvents(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	service, clientset := newFakeKubernetes(t,
+		objectEvent("Scheduled", "Pod", "web-1", "uid-web-1", now.Add(-3*time.Hour)),
+		objectEvent("Pulled", "Pod", "web-1", "uid-web-1", now.Add(-time.Minute)),
+		objectEvent("Started", "Pod", "web-1", "uid-web-1", now.Add(-30*time.Second)),
+		objectEvent("Created", "Pod", "web-1", "uid-web-1", now.Add(-2*time.Minute)),
+	)
+
+	events, err := service.ListEvents(context.Background(), EventFilter{Namespace: "all"})
+	if err != nil {
+		t.Fatalf("ListEvents: %v", err)
+	}
+	if got := eventReasons(events); !slices.Equal(got, []string{"Started", "Pulled", "Created", "Scheduled"}) {
+		t.Fatalf("events = %v, want newest first", got)
+	}
+	if events[0].Count != 1 || !events[0].FirstSeen.Equal(events[0].LastSeen) {
+		t.Fatalf("event without count or first timestamp = %+v, want one occurrence at its last timestamp", events[0])
+	}
+
+	since := now.Add(-time.Hour)
+	events, err = service.ListEvents(context.Background(), EventFilter{Namespace: "default", Since: &since, Limit: 2})
+	if err != nil {
+		t.Fatalf("ListEvents: %v", err)
+	}
+	if got := eventReasons(events); !slices.Equal(got, []string{"Started", "Pulled"}) {
+		t.Fatalf("events since an hour ago, limit 2 = %v, want Started and Pulled", got)
+	}
+
+	// Object and type are filtered by the API server
+	clientset.ClearActions()
+	if _, err := service.ListEvents(context.Background(), EventFilter{
+		Namespace: "default", Kind: "Pod", Name: "web-1", UID: "uid-web-1", Type: corev1.EventTypeWarning,
+	}); err != nil {
+		t.Fatalf("ListEvents: %v", err)
+	}
+	actions := clientset.Actions()
+	if len(actions) != 1 {
+		t.Fatalf("ListEvents made %d requests, want 1", len(actions))
+	}
+	list := actions[0].(k8stesting.ListActionImpl)
+	want := fields.Set{
+		"involvedObject.kind": "Pod",
+		"involvedObject.name": "web-1",
+		"involvedObject.uid":  "uid-web-1",
+		"type":                corev1.EventTypeWarning,
+	}
+	sent, err := fields.ParseSelector(list.ListOptions.FieldSelector)
+	if err != nil {
+		t.Fatalf("parse field selector %q: %v", list.ListOptions.FieldSelector, err)
+	}
+	if list.GetNamespace() != "default" || len(sent.Requirements()) != len(want) || !sent.Matches(want) {
+		t.Fatalf("listed events in %q with field selector %q", list.GetNamespace(), list.ListOptions.FieldSelector)
+	}
+}
+
+func TestToEventInfoSeries(t *testing.T) {
+	first := time.Now().Add(-time.Hour).Truncate(time.Second)
+	last := first.Add(30 * time.Minute)
+	info := toEventInfo(&corev1.Event{
+		EventTime:           metav1.NewMicroTime(first),
+		Series:              &corev1.EventSeries{Count: 12, LastObservedTime: metav1.NewMicroTime(last)},
+		ReportingController: "kubelet",
+		Source:              corev1.EventSource{Host: "worker-1"},
+	})
+	if !info.FirstSeen.Equal(first) || !info.LastSeen.Equal(last) || info.Count != 12 {
+		t.Fatalf("series event seen %d times from %v to %v, want 12 from %v to %v", info.Count, info.FirstSeen, info.LastSeen, first, last)
+	}
+	if info.Source != "kubelet, worker-1" {
+		t.Fatalf("source = %q, want kubelet, worker-1", info.Source)
+	}
+}
+
+func TestGetDeployment(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	replicas := int32(2)
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}
+	labels := map[string]string{"app": "web"}
+
+	replicaSet := func(name, revision string, owners []metav1.OwnerReference) *appsv1.ReplicaSet {
+		return &appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default", Name: name, UID: types.UID("uid-" + name), Labels: labels,
+				Annotations: map[string]string{revisionAnnotation: revision}, OwnerReferences: owners,
+			},
+			Spec: appsv1.ReplicaSetSpec{Replicas: &replicas, Selector: selector},
+		}
+	}
+	pod := func(name string, owners []metav1.OwnerReference) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default", Name: name, UID: types.UID("uid-" + name), Labels: labels, OwnerReferences: owners,
+		}}
+	}
+
+	service, _ := newFakeKubernetes(t,
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web", UID: "uid-web", Labels: labels},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas, Selector: selector, Strategy: appsv1.DeploymentStrategy{Type: appsv1.RollingUpdateDeploymentStrategyType}},
+			Status: appsv1.DeploymentStatus{Conditions: []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue, Reason: "MinimumReplicasAvailable"},
+			}},
+		},
+		replicaSet("web-6d4f", "1", controlledBy("Deployment", "web")),
+		replicaSet("web-7b9c", "2", controlledBy("Deployment", "web")),
+		// Matches the selector but belongs to another deployment
+		replicaSet("web-canary-5f8d", "1", controlledBy("Deployment", "web-canary")),
+		pod("web-7b9c-x1", controlledBy("ReplicaSet", "web-7b9c")),
+		pod("web-canary-5f8d-y1", controlledBy("ReplicaSet", "web-canary-5f8d")),
+		objectEvent("ScalingReplicaSet", "Deployment", "web", "uid-web", now.Add(-5*time.Minute)),
+		objectEvent("SuccessfulCreate", "ReplicaSet", "web-7b9c", "uid-web-7b9c", now.Add(-4*time.Minute)),
+		objectEvent("Started", "Pod", "web-7b9c-x1", "uid-web-7b9c-x1", now.Add(-3*time.Minute)),
+		objectEvent("BackOff", "Pod", "web-canary-5f8d-y1", "uid-web-canary-5f8d-y1", now.Add(-time.Minute)),
+		objectEvent("Killing", "Pod", "db-0", "uid-db-0", now),
+	)
+
+	detail, err := service.GetDeployment(context.Background(), "default", "web")
+	if err != nil {
+		t.Fatalf("GetDeployment: %v", err)
+	}
+	if detail.Selector != "app=web" || detail.Strategy != "RollingUpdate" || len(detail.Conditions) != 1 {
+		t.Fatalf("detail = %+v", detail)
+	}
+
+	var replicaSets []string
+	for _, rs := range detail.ReplicaSets {
+		replicaSets = append(replicaSets, rs.Name)
+	}
+	if !slices.Equal(replicaSets, []string{"web-7b9c", "web-6d4f"}) {
+		t.Fatalf("replica sets = %v, want the deployment's own, newest revision first", replicaSets)
+	}
+
+	if got := eventReasons(detail.Events); !slices.Equal(got, []string{"Started", "SuccessfulCreate", "ScalingReplicaSet"}) {
+		t.Fatalf("timeline = %v, want the events of the deployment, its ReplicaSet and its pod", got)
+	}
+	if detail.EventsError != "" {
+		t.Fatalf("events error = %q", detail.EventsError)
+	}
+
+	if _, err := service.GetDeployment(context.Background(), "default", "ghost"); !apierrors.IsNotFound(err) {
+		t.Fatalf("GetDeployment of a missing deployment: error = %v, want NotFound", err)
+	}
+}
+
+func TestGetDeploymentEventsForbidden(t *testing.T) {
+	replicas := int32(1)
+	service, clientset := newFakeKubernetes(t, &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web", UID: "uid-web"},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas, Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}},
+	})
+	clientset.PrependReactor("list", "events", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(corev1.Resource("events"), "", errors.New("cannot list events"))
+	})
+
+	detail, err := service.GetDeployment(context.Background(), "default", "web")
+	if err != nil {
+		t.Fatalf("GetDeployment: %v", err)
+	}
+	if detail.EventsError == "" || len(detail.Events) != 0 {
+		t.Fatalf("events = %v, error %q; want no events and the error", detail.Events, detail.EventsError)
+	}
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// ListOptions narrows and orders a list of pods, deployments or services.
+// Selectors use the Kubernetes syntax, e.g. "app=web,tier!=db" or
+// "status.phase=Running". Sort names a JSON field of the list row, prefixed
+// with "-" for descending order; without it lists are ordered by namespace,
+// then name.
+//
+// With Limit the list is read from the API server a page at a time and
+// Continue is the token ListMeta returned for the previous page. Pages come in
+// namespace and name order, so Sort cannot be combined with paging.
+type ListOptions struct {
+	LabelSelector string
+	FieldSelector string
+	Limit         int64
+	Continue      string
+	Sort          string
+}
+
+// ListMeta tells the client how to fetch the next page of a list. Continue is
+// empty on the last page; RemainingItemCount is an estimate and only set when
+// the API server can provide one.
+type ListMeta struct {
+	Continue           string
+	RemainingItemCount *int64
+}
+
+// listSorts are the sort keys of a list by JSON field name
+type listSorts[T any] map[string]func(a, b *T) int
+
+var podSorts = listSorts[PodInfo]{
+	"name":       func(a, b *PodInfo) int { return strings.Compare(a.Name, b.Name) },
+	"namespace":  func(a, b *PodInfo) int { return strings.Compare(a.Namespace, b.Namespace) },
+	"created_at": func(a, b *PodInfo) int { return a.CreatedAt.Compare(b.CreatedAt) },
+	"status":     func(a, b *PodInfo) int { return strings.Compare(a.Status, b.Status) },
+	"restarts":   func(a, b *PodInfo) int { return cmp.Compare(a.Restarts, b.Restarts) },
+	"node":       func(a, b *PodInfo) int { return strings.Compare(a.Node, b.Node) },
+}
+
+var deploymentSorts = listSorts[DeploymentInfo]{
+	"name":               func(a, b *DeploymentInfo) int { return strings.Compare(a.Name, b.Name) },
+	"namespace":          func(a, b *DeploymentInfo) int { return strings.Compare(a.Namespace, b.Namespace) },
+	"created_at":         func(a, b *DeploymentInfo) int { return a.CreatedAt.Compare(b.CreatedAt) },
+	"replicas":           func(a, b *DeploymentInfo) int { return cmp.Compare(a.Replicas, b.Replicas) },
+	"ready_replicas":     func(a, b *DeploymentInfo) int { return cmp.Compare(a.ReadyReplicas, b.ReadyReplicas) },
+	"available_replicas": func(a, b *DeploymentInfo) int { return cmp.Compare(a.AvailableReplicas, b.AvailableReplicas) },
+}
+
+var serviceSorts = listSorts[ServiceInfo]{
+	"name":       func(a, b *ServiceInfo) int { return strings.Compare(a.Name, b.Name) },
+	"namespace":  func(a, b *ServiceInfo) int { return strings.Compare(a.Namespace, b.Namespace) },
+	"created_at": func(a, b *ServiceInfo) int { return a.CreatedAt.Compare(b.CreatedAt) },
+	"type":       func(a, b *ServiceInfo) int { return strings.Compare(a.Type, b.Type) },
+}
+
+// validate checks the options before anything is listed and returns the
+// parsed label selector. Errors are BadRequest so they reach the client as 400.
+func (o ListOptions) validate() (labels.Selector, error) {
+	selector, err := labels.Parse(o.LabelSelector)
+	if err != nil {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("invalid label selector: %v", err))
+	}
+	if _, err := fields.ParseSelector(o.FieldSelector); err != nil {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("invalid field selector: %v", err))
+	}
+	if o.Limit < 0 {
+		return nil, apierrors.NewBadRequest("limit must not be negative")
+	}
+	if o.Sort != "" && (o.Limit > 0 || o.Continue != "") {
+		return nil, apierrors.NewBadRequest("sort cannot be combined with limit or continue")
+	}
+	return selector, nil
+}
+
+// cacheable reports whether the informer cache can answer the list. The cache
+// matches label selectors but has no field selectors or continue tokens.
+func (o ListOptions) cacheable() bool {
+	return o.FieldSelector == "" && o.Limit == 0 && o.Continue == ""
+}
+
+func (o ListOptions) listOptions() metav1.ListOptions {
+	return metav1.ListOptions{
+		LabelSelector: o.LabelSelector,
+		FieldSelector: o.FieldSelector,
+		Limit:         o.Limit,
+		Continue:      o.Continue,
+	}
+}
+
+func toListMeta(meta *metav1.ListMeta) ListMeta {
+	return ListMeta{
+		Continue:           meta.Continue,
+		RemainingItemCount: meta.RemainingItemCount,
+	}
+}
+
+// sortList orders items by the sort key, keeping the namespace and name order
+// among equal items
+func sortList[T any](items []T, sort string, sorts listSorts[T]) error {
+	if sort == "" {
+		return nil
+	}
+	field, descending := strings.CutPrefix(sort, "-")
+	compare, ok := sorts[field]
+	if !ok {
+		return apierrors.NewBadRequest(fmt.Sprintf("cannot sort by %q; sort by one of %s",
+			field, strings.Join(sortedKeys(sorts), ", ")))
+	}
+
+	slices.SortStableFunc(items, func(a, b T) int {
+		if descending {
+			return compare(&b, &a)
+		}
+		return compare(&a, &b)
+	})
+	return nil
+}
+
+// age is how long ago an object was created, as kubectl's AGE column shows it,
+// e.g. "45s", "3h12m" or "27d"
+func age(created metav1.Time) string {
+	if created.IsZero() {
+		return ""
+	}
+	return duration.HumanDuration(time.Since(created.Time))
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestListOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		opts ListOptions
+		ok   bool
+	}{
+		{"no options", ListOptions{}, true},
+		{"selectors", ListOptions{LabelSelector: "app=web,tier!=db", FieldSelector: "status.phase=Running"}, true},
+		{"sort", ListOptions{Sort: "-restarts"}, true},
+		{"paging", ListOptions{Limit: 50, Continue: "token"}, true},
+		{"invalid label selector", ListOptions{LabelSelector: "app in web"}, false},
+		{"invalid field selector", ListOptions{FieldSelector: "status.phase"}, false},
+		{"negative limit", ListOptions{Limit: -1}, false},
+		{"sort with limit", ListOptions{Sort: "name", Limit: 50}, false},
+		{"sort with continue", ListOptions{Sort: "name", Continue: "token"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.opts.validate()
+			if tt.ok && err != nil {
+				t.Fatalf("validate: %v", err)
+			}
+			if !tt.ok && !apierrors.IsBadRequest(err) {
+				t.Fatalf("validate error = %v, want BadRequest", err)
+			}
+		})
+	}
+}
+
+func listPod(namespace, name string, restarts int32, created time.Time) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, CreationTimestamp: metav1.NewTime(created)},
+		Spec:       corev1.PodSpec{Containers: containers("app")},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning, ContainerStatuses: []corev1.ContainerStatus{
+			{Name: "app", RestartCount: restarts, State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+		}},
+	}
+}
+
+func podNames(pods []PodInfo) []string {
+	names := make([]string, 0, len(pods))
+	for _, pod := range pods {
+		names = append(names, pod.Name)
+	}
+	return names
+}
+
+func TestGetPodsSort(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	service, _ := newFakeKubernetes(t,
+		listPod("default", "web-1", 3, now.Add(-time.Hour)),
+		listPod("default", "api-1", 0, now.Add(-time.Minute)),
+		listPod("kube-system", "dns-1", 3, now.Add(-24*time.Hour)),
+		listPod("default", "worker-1", 7, now.Add(-2*time.Hour)),
+	)
+
+	tests := []struct {
+		sort string
+		want []string
+	}{
+		{"", []string{"api-1", "web-1", "worker-1", "dns-1"}},
+		{"name", []string{"api-1", "dns-1", "web-1", "worker-1"}},
+		{"-name", []string{"worker-1", "web-1", "dns-1", "api-1"}},
+		// Pods restarting equally often keep their namespace and name order
+		{"restarts", []string{"api-1", "web-1", "dns-1", "worker-1"}},
+		{"-restarts", []string{"worker-1", "web-1", "dns-1", "api-1"}},
+		{"created_at", []string{"dns-1", "worker-1", "web-1", "api-1"}},
+		{"-created_at", []string{"api-1", "web-1", "worker-1", "dns-1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			pods, _, err := service.GetPods("all", ListOptions{Sort: tt.sort})
+			if err != nil {
+				t.Fatalf("GetPods: %v", err)
+			}
+			if got := podNames(pods); !slices.Equal(got, tt.want) {
+				t.Fatalf("pods sorted by %q = %v, want %v", tt.sort, got, tt.want)
+			}
+		})
+	}
+
+	for _, sort := range []string{"color", "-color", "--name"} {
+		if _, _, err := service.GetPods("all", ListOptions{Sort: sort}); !apierrors.IsBadRequest(err) {
+			t.Fatalf("GetPods sorted by %q: error = %v, want BadRequest", sort, err)
+		}
+	}
+}
+
+func TestGetDeploymentsSortDescending(t *testing.T) {
+	replicas := func(n int32) *int32 { return &n }
+	service, _ := newFakeKubernetes(t,
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "api"}, Spec: appsv1.DeploymentSpec{Replicas: replicas(2)}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"}, Spec: appsv1.DeploymentSpec{Replicas: replicas(5)}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "worker"}, Spec: appsv1.DeploymentSpec{Replicas: replicas(1)}},
+	)
+
+	deployments, _, err := service.GetDeployments("default", ListOptions{Sort: "-replicas"})
+	if err != nil {
+		t.Fatalf("GetDeployments: %v", err)
+	}
+	var names []string
+	for _, deploy := range deployments {
+		names = append(names, deploy.Name)
+	}
+	if want := []string{"web", "api", "worker"}; !slices.Equal(names, want) {
+		t.Fatalf("deployments sorted by -replicas = %v, want %v", names, want)
+	}
+}
+
+func TestGetPodsPaging(t *testing.T) {
+	now := time.Now()
+	remaining := int64(1)
+	pages := map[string]*corev1.PodList{
+		"": {
+			ListMeta: metav1.ListMeta{Continue: "page-2", RemainingItemCount: &remaining},
+			Items:    []corev1.Pod{*listPod("default", "api-1", 0, now), *listPod("default", "web-1", 0, now)},
+		},
+		"page-2": {
+			Items: []corev1.Pod{*listPod("default", "worker-1", 0, now)},
+		},
+	}
+
+	service, clientset := newFakeKubernetes(t)
+	var sent []metav1.ListOptions
+	clientset.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		// The informer cache lists pods too; answer only the paged requests
+		opts := action.(k8stesting.ListActionImpl).ListOptions
+		if opts.Limit != 2 {
+			return false, nil, nil
+		}
+		sent = append(sent, opts)
+		return true, pages[opts.Continue], nil
+	})
+
+	pods, meta, err := service.GetPods("default", ListOptions{Limit: 2})
+	if err != nil {
+		t.Fatalf("GetPods: %v", err)
+	}
+	if got := podNames(pods); !slices.Equal(got, []string{"api-1", "web-1"}) {
+		t.Fatalf("first page = %v", got)
+	}
+	if meta.Continue != "page-2" || meta.RemainingItemCount == nil || *meta.RemainingItemCount != 1 {
+		t.Fatalf("first page meta = %+v, want continue page-2 with 1 remaining", meta)
+	}
+
+	pods, meta, err = service.GetPods("default", ListOptions{Limit: 2, Continue: meta.Continue})
+	if err != nil {
+		t.Fatalf("GetPods: %v", err)
+	}
+	if got := podNames(pods); !slices.Equal(got, []string{"worker-1"}) {
+		t.Fatalf("second page = %v", got)
+	}
+	if meta.Continue != "" {
+		t.Fatalf("last page has continue token %q", meta.Continue)
+	}
+
+	if len(sent) != 2 || sent[0].Continue != "" || sent[1].Continue != "page-2" {
+		t.Fatalf("API server received %+v, want two pages of 2 following the token", sent)
+	}
+}
+
+func TestGetPodsFieldSelectorReachesAPI(t *testing.T) {
+	service, clientset := newFakeKubernetes(t, listPod("default", "web-1", 0, time.Now()))
+
+	if _, _, err := service.GetPods("default", ListOptions{LabelSelector: "app=web", FieldSelector: "status.phase=Running"}); err != nil {
+		t.Fatalf("GetPods: %v", err)
+	}
+	for _, action := range clientset.Actions() {
+		list, ok := action.(k8stesting.ListActionImpl)
+		if !ok || list.GetResource().Resource != "pods" {
+			continue
+		}
+		if list.ListOptions.FieldSelector == "status.phase=Running" && list.ListOptions.LabelSelector == "app=web" {
+			return
+		}
+	}
+	t.Fatal("the field selector did not reach the API server")
+}
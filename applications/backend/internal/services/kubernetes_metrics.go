@@ -0,0 +1,378 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/rest"
+)
+
+// ErrMetricsUnavailable is returned when the cluster does not serve the
+// metrics.k8s.io API, usually because metrics-server is not installed
+var ErrMetricsUnavailable = errors.New("metrics.k8s.io is not available; install metrics-server to see resource usage")
+
+// metricsGroupVersion is the resource metrics API served by metrics-server
+const (
+	metricsGroupVersion = "metrics.k8s.io/v1beta1"
+	metricsAPIPath      = "/apis/" + metricsGroupVersion
+)
+
+// Thresholds for usage flags. A container is hot at hotLimitRatio of its limit,
+// or above its request, and over-provisioned below overProvisionedRatio of a
+// request of at least the minimum, so tiny requests are not flagged.
+const (
+	hotLimitRatio            = 0.9
+	overProvisionedRatio     = 0.2
+	minOverProvisionedCPU    = 100               // millicores
+	minOverProvisionedMemory = 128 * 1024 * 1024 // bytes
+)
+
+// Flags on PodUsage and ContainerUsage
+const (
+	UsageFlagCPUNearLimit          = "cpu_near_limit"
+	UsageFlagMemoryNearLimit       = "memory_near_limit"
+	UsageFlagCPUOverRequest        = "cpu_over_request"
+	UsageFlagMemoryOverRequest     = "memory_over_request"
+	UsageFlagCPUOverProvisioned    = "cpu_over_provisioned"
+	UsageFlagMemoryOverProvisioned = "memory_over_provisioned"
+)
+
+// ResourceUsage compares current usage of CPU (in millicores) or memory (in
+// bytes) with the request and limit. Percentages are absent when there is no
+// request or limit to compare with.
+type ResourceUsage struct {
+	Usage            int64    `json:"usage"`
+	Request          int64    `json:"request,omitempty"`
+	Limit            int64    `json:"limit,omitempty"`
+	PercentOfRequest *float64 `json:"percent_of_request,omitempty"`
+	PercentOfLimit   *float64 `json:"percent_of_limit,omitempty"`
+}
+
+type ContainerUsage struct {
+	Name   string        `json:"name"`
+	CPU    ResourceUsage `json:"cpu"`
+	Memory ResourceUsage `json:"memory"`
+	Flags  []string      `json:"flags"`
+}
+
+// PodUsage is a pod's current resource usage as sampled by metrics-server over
+// Window. Flags holds the flags of every container.
+type PodUsage struct {
+	Name       string           `json:"name"`
+	Namespace  string           `json:"namespace"`
+	Node       string           `json:"node,omitempty"`
+	Timestamp  time.Time        `json:"timestamp"`
+	Window     string           `json:"window"`
+	CPU        ResourceUsage    `json:"cpu"`
+	Memory     ResourceUsage    `json:"memory"`
+	Containers []ContainerUsage `json:"containers"`
+	Flags      []string         `json:"flags"`
+}
+
+// NodeUsage is a node's current resource usage against what it can allocate
+type NodeUsage struct {
+	Name      string       `json:"name"`
+	Timestamp time.Time    `json:"timestamp"`
+	Window    string       `json:"window"`
+	CPU       NodeResource `json:"cpu"`
+	Memory    NodeResource `json:"memory"`
+}
+
+// NodeResource is usage of CPU (millicores) or memory (bytes) on a node
+type NodeResource struct {
+	Usage                int64    `json:"usage"`
+	Allocatable          int64    `json:"allocatable"`
+	PercentOfAllocatable *float64 `json:"percent_of_allocatable,omitempty"`
+}
+
+// metricsObject and friends mirror the parts of metrics.k8s.io/v1beta1 used here
+type metricsObject struct {
+	Metadata   metav1.ObjectMeta   `json:"metadata"`
+	Timestamp  metav1.Time         `json:"timestamp"`
+	Window     metav1.Duration     `json:"window"`
+	Usage      corev1.ResourceList `json:"usage"`
+	Containers []struct {
+		Name  string              `json:"name"`
+		Usage corev1.ResourceList `json:"usage"`
+	} `json:"containers"`
+}
+
+type metricsList struct {
+	Items []metricsObject `json:"items"`
+}
+
+// getMetrics reads resource ("pods" or "nodes") of the metrics API into out,
+// limited to namespace and to the object name when they are not empty. Both
+// come from the client and are checked to be single path segments, so they
+// cannot point the request elsewhere on the API server.
+func (s *KubernetesService) getMetrics(ctx context.Context, namespace, resource, name string, out interface{}) error {
+	for _, segment := range []string{namespace, name} {
+		if segment == "" {
+			continue
+		}
+		if msgs := rest.IsValidPathSegmentName(segment); len(msgs) != 0 {
+			return apierrors.NewBadRequest(fmt.Sprintf("invalid name %q: %s", segment, strings.Join(msgs, ", ")))
+		}
+	}
+
+	request := s.clientset.Discovery().RESTClient().Get().AbsPath(metricsAPIPath).Resource(resource)
+	if namespace != "" {
+		request = request.Namespace(namespace)
+	}
+	if name != "" {
+		request = request.Name(name)
+	}
+	body, err := request.Do(ctx).Raw()
+	switch {
+	case apierrors.IsServiceUnavailable(err):
+		// The APIService is registered but metrics-server is not running
+		return fmt.Errorf("%w: %v", ErrMetricsUnavailable, err)
+	case apierrors.IsNotFound(err):
+		// Either the object was not sampled or the whole API is missing
+		if _, discoveryErr := s.clientset.Discovery().ServerResourcesForGroupVersion(metricsGroupVersion); discoveryErr != nil {
+			return fmt.Errorf("%w: %v", ErrMetricsUnavailable, discoveryErr)
+		}
+		return err
+	case err != nil:
+		return err
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("unexpected metrics.k8s.io response: %w", err)
+	}
+	return nil
+}
+
+// GetPodUsage returns the usage of every pod in namespace ("" or "all" for all)
+// that metrics-server has sampled, busiest CPU first
+func (s *KubernetesService) GetPodUsage(ctx context.Context, namespace string) ([]PodUsage, error) {
+	if namespace == "all" {
+		namespace = ""
+	}
+
+	var metrics metricsList
+	if err := s.getMetrics(ctx, namespace, "pods", "", &metrics); err != nil {
+		return nil, err
+	}
+
+	pods, err := s.listPodObjects(ctx, namespace, labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]*corev1.Pod, len(pods))
+	for _, pod := range pods {
+		byName[pod.Namespace+"/"+pod.Name] = pod
+	}
+
+	usages := make([]PodUsage, 0, len(metrics.Items))
+	for i := range metrics.Items {
+		item := &metrics.Items[i]
+		usages = append(usages, toPodUsage(item, byName[item.Metadata.Namespace+"/"+item.Metadata.Name]))
+	}
+	sort.Slice(usages, func(i, j int) bool {
+		return usages[i].CPU.Usage > usages[j].CPU.Usage
+	})
+	return usages, nil
+}
+
+// GetPodUsageFor returns the usage of one pod
+func (s *KubernetesService) GetPodUsageFor(ctx context.Context, namespace, name string) (*PodUsage, error) {
+	var metrics metricsObject
+	if err := s.getMetrics(ctx, namespace, "pods", name, &metrics); err != nil {
+		return nil, err
+	}
+
+	// A pod deleted since it was sampled is reported by usage alone
+	pod, err := s.clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		pod, err = nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	usage := toPodUsage(&metrics, pod)
+	return &usage, nil
+}
+
+// GetNodeUsage returns the usage of every node metrics-server has sampled
+func (s *KubernetesService) GetNodeUsage(ctx context.Context) ([]NodeUsage, error) {
+	var metrics metricsList
+	if err := s.getMetrics(ctx, "", "nodes", "", &metrics); err != nil {
+		return nil, err
+	}
+
+	nodes, err := s.clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	allocatable := make(map[string]corev1.ResourceList, len(nodes.Items))
+	for _, node := range nodes.Items {
+		allocatable[node.Name] = node.Status.Allocatable
+	}
+
+	usages := make([]NodeUsage, 0, len(metrics.Items))
+	for i := range metrics.Items {
+		item := &metrics.Items[i]
+		usages = append(usages, toNodeUsage(item, allocatable[item.Metadata.Name]))
+	}
+	sort.Slice(usages, func(i, j int) bool {
+		return usages[i].Name < usages[j].Name
+	})
+	return usages, nil
+}
+
+// getNodeUsageFor returns the usage of one node against allocatable
+func (s *KubernetesService) getNodeUsageFor(ctx context.Context, name string, allocatable corev1.ResourceList) (*NodeUsage, error) {
+	var metrics metricsObject
+	if err := s.getMetrics(ctx, "", "nodes", name, &metrics); err != nil {
+		return nil, err
+	}
+	usage := toNodeUsage(&metrics, allocatable)
+	return &usage, nil
+}
+
+func toNodeUsage(metrics *metricsObject, allocatable corev1.ResourceList) NodeUsage {
+	return NodeUsage{
+		Name:      metrics.Metadata.Name,
+		Timestamp: metrics.Timestamp.UTC(),
+		Window:    metrics.Window.Duration.String(),
+		CPU:       nodeResource(metrics.Usage, allocatable, corev1.ResourceCPU),
+		Memory:    nodeResource(metrics.Usage, allocatable, corev1.ResourceMemory),
+	}
+}
+
+// toPodUsage combines a pod's metrics with its spec; pod is nil if the pod is
+// already gone, in which case only usage is known
+func toPodUsage(metrics *metricsObject, pod *corev1.Pod) PodUsage {
+	usage := PodUsage{
+		Name:       metrics.Metadata.Name,
+		Namespace:  metrics.Metadata.Namespace,
+		Timestamp:  metrics.Timestamp.UTC(),
+		Window:     metrics.Window.Duration.String(),
+		Containers: make([]ContainerUsage, 0, len(metrics.Containers)),
+		Flags:      []string{},
+	}
+	if pod != nil {
+		usage.Node = pod.Spec.NodeName
+	}
+
+	// The pod's limit is only known if every container has one
+	cpuLimited, memoryLimited := true, true
+	for _, container := range metrics.Containers {
+		var requests, limits corev1.ResourceList
+		if pod != nil {
+			if spec := findContainer(pod.Spec.Containers, container.Name); spec != nil {
+				requests, limits = spec.Resources.Requests, spec.Resources.Limits
+			} else if spec := findContainer(pod.Spec.InitContainers, container.Name); spec != nil {
+				requests, limits = spec.Resources.Requests, spec.Resources.Limits
+			}
+		}
+
+		containerUsage := ContainerUsage{
+			Name:   container.Name,
+			CPU:    resourceUsage(container.Usage, requests, limits, corev1.ResourceCPU),
+			Memory: resourceUsage(container.Usage, requests, limits, corev1.ResourceMemory),
+		}
+		containerUsage.Flags = usageFlags(containerUsage.CPU, containerUsage.Memory)
+		usage.Containers = append(usage.Containers, containerUsage)
+
+		usage.CPU.Usage += containerUsage.CPU.Usage
+		usage.CPU.Request += containerUsage.CPU.Request
+		usage.CPU.Limit += containerUsage.CPU.Limit
+		usage.Memory.Usage += containerUsage.Memory.Usage
+		usage.Memory.Request += containerUsage.Memory.Request
+		usage.Memory.Limit += containerUsage.Memory.Limit
+		cpuLimited = cpuLimited && containerUsage.CPU.Limit > 0
+		memoryLimited = memoryLimited && containerUsage.Memory.Limit > 0
+
+		for _, flag := range containerUsage.Flags {
+			if !slices.Contains(usage.Flags, flag) {
+				usage.Flags = append(usage.Flags, flag)
+			}
+		}
+	}
+	if !cpuLimited {
+		usage.CPU.Limit = 0
+	}
+	if !memoryLimited {
+		usage.Memory.Limit = 0
+	}
+	usage.CPU.PercentOfRequest, usage.CPU.PercentOfLimit = percent(usage.CPU.Usage, usage.CPU.Request), percent(usage.CPU.Usage, usage.CPU.Limit)
+	usage.Memory.PercentOfRequest, usage.Memory.PercentOfLimit = percent(usage.Memory.Usage, usage.Memory.Request), percent(usage.Memory.Usage, usage.Memory.Limit)
+	return usage
+}
+
+func resourceUsage(usage, requests, limits corev1.ResourceList, name corev1.ResourceName) ResourceUsage {
+	value := quantityValue(usage, name)
+	request := quantityValue(requests, name)
+	limit := quantityValue(limits, name)
+	return ResourceUsage{
+		Usage:            value,
+		Request:          request,
+		Limit:            limit,
+		PercentOfRequest: percent(value, request),
+		PercentOfLimit:   percent(value, limit),
+	}
+}
+
+func nodeResource(usage, allocatable corev1.ResourceList, name corev1.ResourceName) NodeResource {
+	value := quantityValue(usage, name)
+	total := quantityValue(allocatable, name)
+	return NodeResource{
+		Usage:                value,
+		Allocatable:          total,
+		PercentOfAllocatable: percent(value, total),
+	}
+}
+
+// usageFlags flags a container running hot or over-provisioned
+func usageFlags(cpu, memory ResourceUsage) []string {
+	flags := []string{}
+	if cpu.Limit > 0 && float64(cpu.Usage) >= hotLimitRatio*float64(cpu.Limit) {
+		flags = append(flags, UsageFlagCPUNearLimit)
+	} else if cpu.Request > 0 && cpu.Usage > cpu.Request {
+		flags = append(flags, UsageFlagCPUOverRequest)
+	} else if cpu.Request >= minOverProvisionedCPU && float64(cpu.Usage) < overProvisionedRatio*float64(cpu.Request) {
+		flags = append(flags, UsageFlagCPUOverProvisioned)
+	}
+
+	if memory.Limit > 0 && float64(memory.Usage) >= hotLimitRatio*float64(memory.Limit) {
+		flags = append(flags, UsageFlagMemoryNearLimit)
+	} else if memory.Request > 0 && memory.Usage > memory.Request {
+		flags = append(flags, UsageFlagMemoryOverRequest)
+	} else if memory.Request >= minOverProvisionedMemory && float64(memory.Usage) < overProvisionedRatio*float64(memory.Request) {
+		flags = append(flags, UsageFlagMemoryOverProvisioned)
+	}
+	return flags
+}
+
+// quantityValue returns CPU in millicores and anything else in base units
+func quantityValue(list corev1.ResourceList, name corev1.ResourceName) int64 {
+	quantity, ok := list[name]
+	if !ok {
+		return 0
+	}
+	if name == corev1.ResourceCPU {
+		return quantity.MilliValue()
+	}
+	return quantity.Value()
+}
+
+func percent(value, total int64) *float64 {
+	if total <= 0 {
+		return nil
+	}
+	p := math.Round(float64(value)/float64(total)*1000) / 10
+	return &p
+}
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+)
+
+// metricsClientset is a fake clientset whose discovery client, which the
+// metrics API is read through, talks to a test server
+type metricsClientset struct {
+	*fake.Clientset
+	discovery discovery.DiscoveryInterface
+}
+
+func (c metricsClientset) Discovery() discovery.DiscoveryInterface {
+	return c.discovery
+}
+
+// newMetricsKubernetes returns a KubernetesService holding objects whose
+// metrics API answers with the JSON in responses by path. Other paths are 404,
+// or status when it is set.
+func newMetricsKubernetes(t *testing.T, responses map[string]string, status int, objects ...runtime.Object) *KubernetesService {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if status != 0 {
+			w.WriteHeader(status)
+			return
+		}
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	clientset := fake.NewClientset(objects...)
+	service := &KubernetesService{clientset: metricsClientset{
+		Clientset: clientset,
+		discovery: discovery.NewDiscoveryClientForConfigOrDie(&rest.Config{Host: server.URL}),
+	}}
+	t.Cleanup(service.Close)
+	return service
+}
+
+const metricsResourceList = `{"kind":"APIResourceList","apiVersion":"v1","groupVersion":"metrics.k8s.io/v1beta1",
+	"resources":[{"name":"pods","namespaced":true,"kind":"PodMetrics","verbs":["get","list"]},
+	{"name":"nodes","namespaced":false,"kind":"NodeMetrics","verbs":["get","list"]}]}`
+
+const webPodMetrics = `{"metadata":{"name":"web-1","namespace":"default"},"timestamp":"2026-01-02T10:00:00Z","window":"30s",
+	"containers":[{"name":"app","usage":{"cpu":"480m","memory":"64Mi"}},{"name":"logger","usage":{"cpu":"10m","memory":"20Mi"}}]}`
+
+// apiPodMetrics is of a pod deleted since it was sampled
+const apiPodMetrics = `{"metadata":{"name":"api-1","namespace":"default"},"timestamp":"2026-01-02T10:00:00Z","window":"30s",
+	"containers":[{"name":"app","usage":{"cpu":"900m","memory":"100Mi"}}]}`
+
+func metricsPod() *corev1.Pod {
+	resources := func(requests, limits corev1.ResourceList) corev1.ResourceRequirements {
+		return corev1.ResourceRequirements{Requests: requests, Limits: limits}
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web-1"},
+		Spec: corev1.PodSpec{NodeName: "worker-1", Containers: []corev1.Container{
+			{Name: "app", Resources: resources(
+				corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("200m"), corev1.ResourceMemory: resource.MustParse("256Mi")},
+				corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m"), corev1.ResourceMemory: resource.MustParse("512Mi")},
+			)},
+			{Name: "logger", Resources: resources(
+				corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")}, nil,
+			)},
+		}},
+	}
+}
+
+func percentIs(p *float64, want float64) bool {
+	return p != nil && *p == want
+}
+
+func TestGetPodUsage(t *testing.T) {
+	service := newMetricsKubernetes(t, map[string]string{
+		"/apis/metrics.k8s.io/v1beta1/namespaces/default/pods": `{"items":[` + webPodMetrics + `,` + apiPodMetrics + `]}`,
+	}, 0, metricsPod())
+
+	usages, err := service.GetPodUsage(context.Background(), "default")
+	if err != nil {
+		t.Fatalf("GetPodUsage: %v", err)
+	}
+	if len(usages) != 2 || usages[0].Name != "api-1" || usages[1].Name != "web-1" {
+		t.Fatalf("usages = %+v, want api-1 then web-1, busiest first", usages)
+	}
+
+	deleted := usages[0]
+	if deleted.Node != "" || deleted.CPU.Usage != 900 || deleted.CPU.PercentOfRequest != nil || len(deleted.Flags) != 0 {
+		t.Fatalf("usage of a deleted pod = %+v, want usage alone", deleted)
+	}
+
+	web := usages[1]
+	if web.Node != "worker-1" || web.Window != "30s" {
+		t.Fatalf("web-1 on %q over %q, want worker-1 over 30s", web.Node, web.Window)
+	}
+	if web.CPU.Usage != 490 || web.CPU.Request != 300 || !percentIs(web.CPU.PercentOfRequest, 163.3) {
+		t.Fatalf("web-1 cpu = %+v, want 490m of 300m requested", web.CPU)
+	}
+	// logger has no limit, so neither has the pod
+	if web.CPU.Limit != 0 || web.CPU.PercentOfLimit != nil {
+		t.Fatalf("web-1 cpu limit = %d, want none", web.CPU.Limit)
+	}
+	if web.Memory.Usage != 84<<20 || web.Memory.Request != 256<<20 {
+		t.Fatalf("web-1 memory = %+v, want 84Mi of 256Mi requested", web.Memory)
+	}
+
+	app, logger := web.Containers[0], web.Containers[1]
+	if !percentIs(app.CPU.PercentOfLimit, 96) || !percentIs(app.CPU.PercentOfRequest, 240) || !percentIs(app.Memory.PercentOfRequest, 25) {
+		t.Fatalf("app usage = %+v", app)
+	}
+	if !slices.Equal(app.Flags, []string{UsageFlagCPUNearLimit}) {
+		t.Fatalf("app flags = %v, want cpu near limit", app.Flags)
+	}
+	if !slices.Equal(logger.Flags, []string{UsageFlagCPUOverProvisioned}) {
+		t.Fatalf("logger flags = %v, want cpu over-provisioned", logger.Flags)
+	}
+	if !slices.Equal(web.Flags, []string{UsageFlagCPUNearLimit, UsageFlagCPUOverProvisioned}) {
+		t.Fatalf("web-1 flags = %v, want those of both containers", web.Flags)
+	}
+}
+
+func TestGetPodUsageFor(t *testing.T) {
+	service := newMetricsKubernetes(t, map[string]string{
+		"/apis/metrics.k8s.io/v1beta1":                               metricsResourceList,
+		"/apis/metrics.k8s.io/v1beta1/namespaces/default/pods/web-1": webPodMetrics,
+	}, 0, metricsPod())
+
+	usage, err := service.GetPodUsageFor(context.Background(), "default", "web-1")
+	if err != nil {
+		t.Fatalf("GetPodUsageFor: %v", err)
+	}
+	if usage.Name != "web-1" || usage.CPU.Usage != 490 || len(usage.Containers) != 2 {
+		t.Fatalf("usage = %+v", usage)
+	}
+
+	// The API is served but has not sampled the pod
+	if _, err := service.GetPodUsageFor(context.Background(), "default", "web-2"); !apierrors.IsNotFound(err) || errors.Is(err, ErrMetricsUnavailable) {
+		t.Fatalf("usage of an unsampled pod: error = %v, want NotFound", err)
+	}
+	if _, err := service.GetPodUsageFor(context.Background(), "default", "../../../api/v1/secrets"); !apierrors.IsBadRequest(err) {
+		t.Fatalf("usage of a path: error = %v, want BadRequest", err)
+	}
+}
+
+func TestGetNodeUsage(t *testing.T) {
+	service := newMetricsKubernetes(t, map[string]string{
+		"/apis/metrics.k8s.io/v1beta1/nodes": `{"items":[
+			{"metadata":{"name":"worker-2"},"timestamp":"2026-01-02T10:00:00Z","window":"20s","usage":{"cpu":"500m","memory":"1Gi"}},
+			{"metadata":{"name":"worker-1"},"timestamp":"2026-01-02T10:00:00Z","window":"20s","usage":{"cpu":"1","memory":"2Gi"}}]}`,
+	}, 0, &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-1"},
+		Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{
+			corev1.ResourceCPU: resource.MustParse("4"), corev1.ResourceMemory: resource.MustParse("8Gi"),
+		}},
+	})
+
+	usages, err := service.GetNodeUsage(context.Background())
+	if err != nil {
+		t.Fatalf("GetNodeUsage: %v", err)
+	}
+	if len(usages) != 2 || usages[0].Name != "worker-1" || usages[1].Name != "worker-2" {
+		t.Fatalf("usages = %+v, want worker-1 and worker-2 by name", usages)
+	}
+	node := usages[0]
+	if node.CPU.Usage != 1000 || node.CPU.Allocatable != 4000 || !percentIs(node.CPU.PercentOfAllocatable, 25) {
+		t.Fatalf("worker-1 cpu = %+v, want 1 of 4 cores", node.CPU)
+	}
+	if !percentIs(node.Memory.PercentOfAllocatable, 25) {
+		t.Fatalf("worker-1 memory = %+v, want 2Gi of 8Gi", node.Memory)
+	}
+	// A node sampled but no longer listed has nothing to compare with
+	if usages[1].CPU.PercentOfAllocatable != nil {
+		t.Fatalf("worker-2 cpu = %+v, want usage alone", usages[1].CPU)
+	}
+}
+
+func TestMetricsUnavailable(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses map[string]string
+		status    int
+	}{
+		{"metrics-server not running", nil, http.StatusServiceUnavailable},
+		{"metrics API not installed", map[string]string{}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newMetricsKubernetes(t, tt.responses, tt.status, metricsPod())
+			if _, err := service.GetPodUsage(context.Background(), "all"); !errors.Is(err, ErrMetricsUnavailable) {
+				t.Fatalf("GetPodUsage error = %v, want ErrMetricsUnavailable", err)
+			}
+			if _, err := service.GetPodUsageFor(context.Background(), "default", "web-1"); !errors.Is(err, ErrMetricsUnavailable) {
+				t.Fatalf("GetPodUsageFor error = %v, want ErrMetricsUnavailable", err)
+			}
+			if _, err := service.GetNodeUsage(context.Background()); !errors.Is(err, ErrMetricsUnavailable) {
+				t.Fatalf("GetNodeUsage error = %v, want ErrMetricsUnavailable", err)
+			}
+		})
+	}
+}
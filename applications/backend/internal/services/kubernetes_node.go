@@ -0,0 +1,282 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Labels that carry a node's roles. kubeadm and most distributions set
+// node-role.kubernetes.io/<role>; older clusters use kubernetes.io/role=<role>.
+const (
+	nodeRoleLabelPrefix = "node-role.kubernetes.io/"
+	nodeRoleLabel       = "kubernetes.io/role"
+)
+
+// NodeInfo is a node's row in the node inventory. Status is "Ready",
+// "NotReady" or "Unknown", with ",SchedulingDisabled" appended for a cordoned
+// node as kubectl shows it. Pressure lists the conditions that currently
+// report a problem, e.g. MemoryPressure, so they stand out in the list.
+type NodeInfo struct {
+	Name             string              `json:"name"`
+	Status           string              `json:"status"`
+	Roles            []string            `json:"roles"`
+	Unschedulable    bool                `json:"unschedulable"`
+	Age              string              `json:"age"`
+	CreatedAt        time.Time           `json:"created_at"`
+	KubeletVersion   string              `json:"kubelet_version"`
+	ContainerRuntime string              `json:"container_runtime"`
+	OSImage          string              `json:"os_image"`
+	KernelVersion    string              `json:"kernel_version"`
+	OS               string              `json:"os"`
+	Architecture     string              `json:"architecture"`
+	InternalIP       string              `json:"internal_ip,omitempty"`
+	ExternalIP       string              `json:"external_ip,omitempty"`
+	CPU              NodeCapacity        `json:"cpu"`
+	Memory           NodeCapacity        `json:"memory"`
+	Pods             NodeCapacity        `json:"pods"`
+	Taints           []NodeTaintInfo     `json:"taints"`
+	Conditions       []NodeConditionInfo `json:"conditions"`
+	Pressure         []string            `json:"pressure"`
+}
+
+// NodeCapacity compares what a node offers with what is scheduled on it. CPU
+// is in millicores, memory in bytes and pods a count. Requested sums the
+// requests of the pods that still hold their resources, as the scheduler does;
+// for pods it is the number of such pods. Available is what remains for new
+// pods and is negative on an overcommitted node.
+type NodeCapacity struct {
+	Capacity         int64    `json:"capacity"`
+	Allocatable      int64    `json:"allocatable"`
+	Requested        int64    `json:"requested"`
+	PercentRequested *float64 `json:"percent_requested,omitempty"`
+	Available        int64    `json:"available"`
+}
+
+type NodeTaintInfo struct {
+	Key    string `json:"key"`
+	Value  string `json:"value,omitempty"`
+	Effect string `json:"effect"`
+}
+
+type NodeConditionInfo struct {
+	Type               string     `json:"type"`
+	Status             string     `json:"status"`
+	Reason             string     `json:"reason,omitempty"`
+	Message            string     `json:"message,omitempty"`
+	LastHeartbeatTime  *time.Time `json:"last_heartbeat_time,omitempty"`
+	LastTransitionTime *time.Time `json:"last_transition_time,omitempty"`
+}
+
+// NodeDetail is the node detail view: the inventory row plus the node's
+// labels, the pods scheduled on it and its current usage if metrics-server is
+// installed
+type NodeDetail struct {
+	NodeInfo
+	UID         string            `json:"uid"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	ProviderID  string            `json:"provider_id,omitempty"`
+	PodCIDRs    []string          `json:"pod_cidrs,omitempty"`
+	PodList     []PodInfo         `json:"pod_list"`
+	Usage       *NodeUsage        `json:"usage,omitempty"`
+}
+
+// ListNodes returns every node of the cluster by name
+func (s *KubernetesService) ListNodes(ctx context.Context) ([]NodeInfo, error) {
+	nodes, err := s.clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	podsByNode, err := s.podsByNode(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(nodes.Items, func(i, j int) bool {
+		return nodes.Items[i].Name < nodes.Items[j].Name
+	})
+	infos := make([]NodeInfo, 0, len(nodes.Items))
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		infos = append(infos, toNodeInfo(node, podsByNode[node.Name]))
+	}
+	return infos, nil
+}
+
+// GetNode returns the detail view of a node
+func (s *KubernetesService) GetNode(ctx context.Context, name string) (*NodeDetail, error) {
+	node, err := s.clientset.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	podsByNode, err := s.podsByNode(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pods := podsByNode[name]
+	sort.Slice(pods, func(i, j int) bool {
+		return namespacedLess(pods[i].Namespace, pods[i].Name, pods[j].Namespace, pods[j].Name)
+	})
+
+	detail := &NodeDetail{
+		NodeInfo:    toNodeInfo(node, pods),
+		UID:         string(node.UID),
+		Labels:      node.Labels,
+		Annotations: node.Annotations,
+		ProviderID:  node.Spec.ProviderID,
+		PodCIDRs:    node.Spec.PodCIDRs,
+		PodList:     make([]PodInfo, 0, len(pods)),
+	}
+	for _, pod := range pods {
+		detail.PodList = append(detail.PodList, toPodInfo(pod))
+	}
+
+	// Usage is shown when metrics-server has sampled the node
+	if usage, err := s.getNodeUsageFor(ctx, name, node.Status.Allocatable); err == nil {
+		detail.Usage = usage
+	}
+	return detail, nil
+}
+
+// podsByNode groups the pods that hold resources on a node by node name.
+// Pods that have finished no longer count against the node; unscheduled pods
+// are on no node yet.
+func (s *KubernetesService) podsByNode(ctx context.Context) (map[string][]*corev1.Pod, error) {
+	pods, err := s.listPodObjects(ctx, "", labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	byNode := make(map[string][]*corev1.Pod)
+	for _, pod := range pods {
+		if pod.Spec.NodeName == "" || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		byNode[pod.Spec.NodeName] = append(byNode[pod.Spec.NodeName], pod)
+	}
+	return byNode, nil
+}
+
+func toNodeInfo(node *corev1.Node, pods []*corev1.Pod) NodeInfo {
+	info := NodeInfo{
+		Name:             node.Name,
+		Status:           nodeStatus(node),
+		Roles:            nodeRoles(node),
+		Unschedulable:    node.Spec.Unschedulable,
+		Age:              age(node.CreationTimestamp),
+		CreatedAt:        node.CreationTimestamp.UTC(),
+		KubeletVersion:   node.Status.NodeInfo.KubeletVersion,
+		ContainerRuntime: node.Status.NodeInfo.ContainerRuntimeVersion,
+		OSImage:          node.Status.NodeInfo.OSImage,
+		KernelVersion:    node.Status.NodeInfo.KernelVersion,
+		OS:               node.Status.NodeInfo.OperatingSystem,
+		Architecture:     node.Status.NodeInfo.Architecture,
+		Taints:           make([]NodeTaintInfo, 0, len(node.Spec.Taints)),
+		Conditions:       make([]NodeConditionInfo, 0, len(node.Status.Conditions)),
+		Pressure:         []string{},
+	}
+	for _, address := range node.Status.Addresses {
+		switch address.Type {
+		case corev1.NodeInternalIP:
+			if info.InternalIP == "" {
+				info.InternalIP = address.Address
+			}
+		case corev1.NodeExternalIP:
+			if info.ExternalIP == "" {
+				info.ExternalIP = address.Address
+			}
+		}
+	}
+
+	var cpuRequested, memoryRequested int64
+	for _, pod := range pods {
+		cpuRequest := podRequest(pod, corev1.ResourceCPU)
+		memoryRequest := podRequest(pod, corev1.ResourceMemory)
+		cpuRequested += cpuRequest.MilliValue()
+		memoryRequested += memoryRequest.Value()
+	}
+	info.CPU = nodeCapacity(node, corev1.ResourceCPU, cpuRequested)
+	info.Memory = nodeCapacity(node, corev1.ResourceMemory, memoryRequested)
+	info.Pods = nodeCapacity(node, corev1.ResourcePods, int64(len(pods)))
+
+	for _, taint := range node.Spec.Taints {
+		info.Taints = append(info.Taints, NodeTaintInfo{
+			Key:    taint.Key,
+			Value:  taint.Value,
+			Effect: string(taint.Effect),
+		})
+	}
+	for _, condition := range node.Status.Conditions {
+		info.Conditions = append(info.Conditions, NodeConditionInfo{
+			Type:               string(condition.Type),
+			Status:             string(condition.Status),
+			Reason:             condition.Reason,
+			Message:            condition.Message,
+			LastHeartbeatTime:  timePtr(&condition.LastHeartbeatTime),
+			LastTransitionTime: timePtr(&condition.LastTransitionTime),
+		})
+		// Every condition but Ready reports a problem when it is true
+		if condition.Type != corev1.NodeReady && condition.Status == corev1.ConditionTrue {
+			info.Pressure = append(info.Pressure, string(condition.Type))
+		}
+	}
+	return info
+}
+
+// nodeStatus is the STATUS column of `kubectl get nodes`
+func nodeStatus(node *corev1.Node) string {
+	status := "Unknown"
+	for _, condition := range node.Status.Conditions {
+		if condition.Type != corev1.NodeReady {
+			continue
+		}
+		switch condition.Status {
+		case corev1.ConditionTrue:
+			status = "Ready"
+		case corev1.ConditionFalse:
+			status = "NotReady"
+		}
+	}
+	if node.Spec.Unschedulable {
+		status += ",SchedulingDisabled"
+	}
+	return status
+}
+
+func nodeRoles(node *corev1.Node) []string {
+	roles := []string{}
+	for key, value := range node.Labels {
+		switch {
+		case strings.HasPrefix(key, nodeRoleLabelPrefix):
+			if role := strings.TrimPrefix(key, nodeRoleLabelPrefix); role != "" {
+				roles = append(roles, role)
+			}
+		case key == nodeRoleLabel && value != "":
+			roles = append(roles, value)
+		}
+	}
+	// A node may carry the same role under both labels
+	sort.Strings(roles)
+	return slices.Compact(roles)
+}
+
+func nodeCapacity(node *corev1.Node, name corev1.ResourceName, requested int64) NodeCapacity {
+	allocatable := quantityValue(node.Status.Allocatable, name)
+	return NodeCapacity{
+		Capacity:         quantityValue(node.Status.Capacity, name),
+		Allocatable:      allocatable,
+		Requested:        requested,
+		PercentRequested: percent(requested, allocatable),
+		Available:        allocatable - requested,
+	}
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func inventoryNode(name string, ready corev1.ConditionStatus) *corev1.Node {
+	resources := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("4"),
+		corev1.ResourceMemory: resource.MustParse("8Gi"),
+		corev1.ResourcePods:   resource.MustParse("110"),
+	}
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: corev1.NodeStatus{
+			Capacity: resources,
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("3800m"),
+				corev1.ResourceMemory: resource.MustParse("7Gi"),
+				corev1.ResourcePods:   resource.MustParse("110"),
+			},
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: ready}},
+		},
+	}
+}
+
+func scheduledPod(name, node string, phase corev1.PodPhase, cpu, memory string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+		Spec: corev1.PodSpec{NodeName: node, Containers: []corev1.Container{{
+			Name: "app",
+			Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse(cpu), corev1.ResourceMemory: resource.MustParse(memory),
+			}},
+		}}},
+		Status: corev1.PodStatus{Phase: phase},
+	}
+}
+
+func nodeObjects() []*corev1.Node {
+	control := inventoryNode("control-1", corev1.ConditionTrue)
+	control.Labels = map[string]string{nodeRoleLabelPrefix + "control-plane": "", nodeRoleLabel: "control-plane"}
+	control.Spec.Taints = []corev1.Taint{{Key: nodeRoleLabelPrefix + "control-plane", Effect: corev1.TaintEffectNoSchedule}}
+
+	worker := inventoryNode("worker-1", corev1.ConditionTrue)
+	worker.Status.Addresses = []corev1.NodeAddress{
+		{Type: corev1.NodeHostName, Address: "worker-1"},
+		{Type: corev1.NodeInternalIP, Address: "10.0.0.11"},
+		{Type: corev1.NodeExternalIP, Address: "203.0.113.11"},
+	}
+	worker.Status.Conditions = append(worker.Status.Conditions,
+		corev1.NodeCondition{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionTrue, Reason: "KubeletHasInsufficientMemory"},
+		corev1.NodeCondition{Type: corev1.NodeDiskPressure, Status: corev1.ConditionFalse},
+	)
+
+	lost := inventoryNode("worker-2", corev1.ConditionUnknown)
+	lost.Spec.Unschedulable = true
+	lost.Spec.Taints = []corev1.Taint{{Key: "node.kubernetes.io/unreachable", Effect: corev1.TaintEffectNoExecute}}
+
+	return []*corev1.Node{worker, lost, control}
+}
+
+func TestListNodes(t *testing.T) {
+	nodes := nodeObjects()
+	service, _ := newFakeKubernetes(t,
+		nodes[0], nodes[1], nodes[2],
+		scheduledPod("web-1", "worker-1", corev1.PodRunning, "500m", "1Gi"),
+		scheduledPod("web-2", "worker-1", corev1.PodPending, "250m", "512Mi"),
+		// Finished and unscheduled pods hold no resources on the node
+		scheduledPod("migrate-1", "worker-1", corev1.PodSucceeded, "1", "1Gi"),
+		scheduledPod("crashed-1", "worker-1", corev1.PodFailed, "1", "1Gi"),
+		scheduledPod("queued-1", "", corev1.PodPending, "2", "2Gi"),
+		scheduledPod("api-1", "worker-2", corev1.PodRunning, "4", "1Gi"),
+	)
+
+	infos, err := service.ListNodes(context.Background())
+	if err != nil {
+		t.Fatalf("ListNodes: %v", err)
+	}
+	var names []string
+	for _, info := range infos {
+		names = append(names, info.Name)
+	}
+	if !slices.Equal(names, []string{"control-1", "worker-1", "worker-2"}) {
+		t.Fatalf("nodes = %v, want them by name", names)
+	}
+	control, worker, lost := infos[0], infos[1], infos[2]
+
+	if worker.Status != "Ready" || worker.InternalIP != "10.0.0.11" || worker.ExternalIP != "203.0.113.11" {
+		t.Fatalf("worker-1 = %+v", worker)
+	}
+	if worker.CPU.Capacity != 4000 || worker.CPU.Allocatable != 3800 || worker.CPU.Requested != 750 || worker.CPU.Available != 3050 {
+		t.Fatalf("worker-1 cpu = %+v, want 750m of 3800m requested", worker.CPU)
+	}
+	if !percentIs(worker.CPU.PercentRequested, 19.7) {
+		t.Fatalf("worker-1 cpu requested %v%%, want 19.7%%", worker.CPU.PercentRequested)
+	}
+	if worker.Memory.Requested != 1536<<20 || worker.Memory.Allocatable != 7<<30 {
+		t.Fatalf("worker-1 memory = %+v, want 1.5Gi of 7Gi requested", worker.Memory)
+	}
+	if worker.Pods.Requested != 2 || worker.Pods.Available != 108 {
+		t.Fatalf("worker-1 pods = %+v, want 2 of 110", worker.Pods)
+	}
+	if len(worker.Conditions) != 3 || !slices.Equal(worker.Pressure, []string{"MemoryPressure"}) {
+		t.Fatalf("worker-1 conditions %+v with pressure %v, want MemoryPressure alone", worker.Conditions, worker.Pressure)
+	}
+
+	// An overcommitted node has negative room left
+	if lost.Status != "Unknown,SchedulingDisabled" || !lost.Unschedulable || lost.CPU.Available != -200 {
+		t.Fatalf("worker-2 = %+v", lost)
+	}
+	if len(lost.Taints) != 1 || lost.Taints[0] != (NodeTaintInfo{Key: "node.kubernetes.io/unreachable", Effect: "NoExecute"}) {
+		t.Fatalf("worker-2 taints = %+v", lost.Taints)
+	}
+
+	if !slices.Equal(control.Roles, []string{"control-plane"}) || control.CPU.Requested != 0 || len(control.Pressure) != 0 {
+		t.Fatalf("control-1 = %+v", control)
+	}
+	if len(control.Taints) != 1 || control.Taints[0].Effect != "NoSchedule" {
+		t.Fatalf("control-1 taints = %+v", control.Taints)
+	}
+}
+
+func TestGetNode(t *testing.T) {
+	nodes := nodeObjects()
+	service := newMetricsKubernetes(t, map[string]string{
+		"/apis/metrics.k8s.io/v1beta1/nodes/worker-1": `{"metadata":{"name":"worker-1"},"timestamp":"2026-01-02T10:00:00Z","window":"20s","usage":{"cpu":"1900m","memory":"1Gi"}}`,
+	}, 0,
+		nodes[0], nodes[1],
+		scheduledPod("web-2", "worker-1", corev1.PodRunning, "250m", "512Mi"),
+		scheduledPod("web-1", "worker-1", corev1.PodRunning, "500m", "1Gi"),
+		scheduledPod("api-1", "worker-2", corev1.PodRunning, "1", "1Gi"),
+	)
+
+	detail, err := service.GetNode(context.Background(), "worker-1")
+	if err != nil {
+		t.Fatalf("GetNode: %v", err)
+	}
+	if got := podNames(detail.PodList); !slices.Equal(got, []string{"web-1", "web-2"}) {
+		t.Fatalf("pods on worker-1 = %v, want web-1 and web-2", got)
+	}
+	if detail.CPU.Requested != 750 {
+		t.Fatalf("worker-1 cpu requested = %d, want 750", detail.CPU.Requested)
+	}
+	if detail.Usage == nil || detail.Usage.CPU.Usage != 1900 || !percentIs(detail.Usage.CPU.PercentOfAllocatable, 50) {
+		t.Fatalf("worker-1 usage = %+v, want 1900m of 3800m", detail.Usage)
+	}
+
+	// A node metrics-server has not sampled is shown without usage
+	detail, err = service.GetNode(context.Background(), "worker-2")
+	if err != nil {
+		t.Fatalf("GetNode: %v", err)
+	}
+	if detail.Usage != nil || len(detail.PodList) != 1 {
+		t.Fatalf("worker-2 = %+v, want its pod and no usage", detail)
+	}
+
+	if _, err := service.GetNode(context.Background(), "ghost"); !apierrors.IsNotFound(err) {
+		t.Fatalf("GetNode of a missing node: error = %v, want NotFound", err)
+	}
+
+	unavailable := newMetricsKubernetes(t, nil, http.StatusServiceUnavailable, nodes[0])
+	if detail, err := unavailable.GetNode(context.Background(), "worker-1"); err != nil || detail.Usage != nil {
+		t.Fatalf("GetNode without metrics-server = %+v, %v; want the node without usage", detail, err)
+	}
+}
@@ -0,0 +1,404 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// PodDetail is everything the pod detail view shows: the list row plus each
+// container, the pod's conditions, what owns it, its events, newest first, and
+// its current resource usage if metrics-server is installed
+type PodDetail struct {
+	PodInfo
+	UID             string                  `json:"uid"`
+	Phase           string                  `json:"phase"`
+	Reason          string                  `json:"reason,omitempty"`
+	Message         string                  `json:"message,omitempty"`
+	QOSClass        string                  `json:"qos_class,omitempty"`
+	ServiceAccount  string                  `json:"service_account,omitempty"`
+	HostIP          string                  `json:"host_ip,omitempty"`
+	PodIPs          []string                `json:"pod_ips,omitempty"`
+	StartTime       *time.Time              `json:"start_time,omitempty"`
+	Labels          map[string]string       `json:"labels,omitempty"`
+	Annotations     map[string]string       `json:"annotations,omitempty"`
+	OwnerReferences []metav1.OwnerReference `json:"owner_references,omitempty"`
+	Conditions      []PodConditionInfo      `json:"conditions"`
+	InitContainers  []ContainerInfo         `json:"init_containers"`
+	Containers      []ContainerInfo         `json:"containers"`
+	Events          []EventInfo             `json:"events"`
+	EventsError     string                  `json:"events_error,omitempty"`
+	Usage           *PodUsage               `json:"usage,omitempty"`
+}
+
+type PodConditionInfo struct {
+	Type               string     `json:"type"`
+	Status             string     `json:"status"`
+	Reason             string     `json:"reason,omitempty"`
+	Message            string     `json:"message,omitempty"`
+	LastTransitionTime *time.Time `json:"last_transition_time,omitempty"`
+}
+
+// ContainerInfo is one container of a pod: its spec and its current state.
+// State is "waiting", "running", "terminated" or "" before the kubelet has
+// reported on it. Sidecar marks an init container that keeps running
+// alongside the app containers.
+type ContainerInfo struct {
+	Name            string                `json:"name"`
+	Image           string                `json:"image"`
+	ImageID         string                `json:"image_id,omitempty"`
+	Sidecar         bool                  `json:"sidecar,omitempty"`
+	State           string                `json:"state"`
+	Reason          string                `json:"reason,omitempty"`
+	Message         string                `json:"message,omitempty"`
+	ExitCode        *int32                `json:"exit_code,omitempty"`
+	StartedAt       *time.Time            `json:"started_at,omitempty"`
+	FinishedAt      *time.Time            `json:"finished_at,omitempty"`
+	Ready           bool                  `json:"ready"`
+	RestartCount    int32                 `json:"restart_count"`
+	LastTermination *ContainerTermination `json:"last_termination,omitempty"`
+	Requests        map[string]string     `json:"requests,omitempty"`
+	Limits          map[string]string     `json:"limits,omitempty"`
+}
+
+// ContainerTermination is how a container's previous run ended, e.g. OOMKilled
+type ContainerTermination struct {
+	Reason     string     `json:"reason,omitempty"`
+	ExitCode   int32      `json:"exit_code"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// GetPod returns the detail view of a pod, from the informer cache when it is
+// available
+func (s *KubernetesService) GetPod(ctx context.Context, namespace, name string) (*PodDetail, error) {
+	var pod *corev1.Pod
+	var err error
+	if cache := s.informerCache(); cache != nil {
+		pod, err = cache.pods.Pods(namespace).Get(name)
+	} else {
+		pod, err = s.clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	detail := toPodDetail(pod)
+	// Without access to events the pod is still shown, with EventsError set
+	if events, err := s.objectEvents(ctx, namespace, pod.UID); err != nil {
+		detail.EventsError = err.Error()
+	} else {
+		detail.Events = events
+	}
+	// Usage is shown when metrics-server has sampled the pod
+	if usage, err := s.GetPodUsageFor(ctx, namespace, name); err == nil {
+		detail.Usage = usage
+	}
+	return detail, nil
+}
+
+// listPodObjects lists the pods in namespace ("" for all) matching selector,
+// from the informer cache when it is available. The pods must not be modified.
+func (s *KubernetesService) listPodObjects(ctx context.Context, namespace string, selector labels.Selector) ([]*corev1.Pod, error) {
+	if cache := s.informerCache(); cache != nil {
+		return cache.pods.Pods(namespace).List(selector)
+	}
+
+	list, err := s.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	pods := make([]*corev1.Pod, 0, len(list.Items))
+	for i := range list.Items {
+		pods = append(pods, &list.Items[i])
+	}
+	return pods, nil
+}
+
+func toPodDetail(pod *corev1.Pod) *PodDetail {
+	detail := &PodDetail{
+		PodInfo:         toPodInfo(pod),
+		UID:             string(pod.UID),
+		Phase:           string(pod.Status.Phase),
+		Reason:          pod.Status.Reason,
+		Message:         pod.Status.Message,
+		QOSClass:        string(pod.Status.QOSClass),
+		ServiceAccount:  pod.Spec.ServiceAccountName,
+		HostIP:          pod.Status.HostIP,
+		StartTime:       timePtr(pod.Status.StartTime),
+		Labels:          pod.Labels,
+		Annotations:     pod.Annotations,
+		OwnerReferences: pod.OwnerReferences,
+		Conditions:      make([]PodConditionInfo, 0, len(pod.Status.Conditions)),
+		InitContainers:  make([]ContainerInfo, 0, len(pod.Spec.InitContainers)),
+		Containers:      make([]ContainerInfo, 0, len(pod.Spec.Containers)),
+		Events:          []EventInfo{},
+	}
+	for _, ip := range pod.Status.PodIPs {
+		detail.PodIPs = append(detail.PodIPs, ip.IP)
+	}
+
+	for _, condition := range pod.Status.Conditions {
+		lastTransition := condition.LastTransitionTime
+		detail.Conditions = append(detail.Conditions, PodConditionInfo{
+			Type:               string(condition.Type),
+			Status:             string(condition.Status),
+			Reason:             condition.Reason,
+			Message:            condition.Message,
+			LastTransitionTime: timePtr(&lastTransition),
+		})
+	}
+
+	for i := range pod.Spec.InitContainers {
+		container := &pod.Spec.InitContainers[i]
+		info := toContainerInfo(container, findContainerStatus(pod.Status.InitContainerStatuses, container.Name))
+		info.Sidecar = isSidecar(container)
+		detail.InitContainers = append(detail.InitContainers, info)
+	}
+	for i := range pod.Spec.Containers {
+		container := &pod.Spec.Containers[i]
+		detail.Containers = append(detail.Containers, toContainerInfo(container, findContainerStatus(pod.Status.ContainerStatuses, container.Name)))
+	}
+
+	return detail
+}
+
+func toContainerInfo(container *corev1.Container, status *corev1.ContainerStatus) ContainerInfo {
+	info := ContainerInfo{
+		Name:     container.Name,
+		Image:    container.Image,
+		Requests: resourceStrings(container.Resources.Requests),
+		Limits:   resourceStrings(container.Resources.Limits),
+	}
+	if status == nil {
+		return info
+	}
+
+	info.ImageID = status.ImageID
+	info.Ready = status.Ready
+	info.RestartCount = status.RestartCount
+
+	switch state := status.State; {
+	case state.Waiting != nil:
+		info.State = "waiting"
+		info.Reason = state.Waiting.Reason
+		info.Message = state.Waiting.Message
+	case state.Running != nil:
+		info.State = "running"
+		info.StartedAt = timePtr(&state.Running.StartedAt)
+	case state.Terminated != nil:
+		info.State = "terminated"
+		info.Reason = state.Terminated.Reason
+		info.Message = state.Terminated.Message
+		exitCode := state.Terminated.ExitCode
+		info.ExitCode = &exitCode
+		info.StartedAt = timePtr(&state.Terminated.StartedAt)
+		info.FinishedAt = timePtr(&state.Terminated.FinishedAt)
+	}
+
+	if last := status.LastTerminationState.Terminated; last != nil {
+		info.LastTermination = &ContainerTermination{
+			Reason:     last.Reason,
+			ExitCode:   last.ExitCode,
+			FinishedAt: timePtr(&last.FinishedAt),
+		}
+	}
+	return info
+}
+
+// podStatus is the STATUS column of `kubectl get pods`: the first reason a pod
+// is not running normally, e.g. "Init:0/2", "CrashLoopBackOff", "OOMKilled" or
+// "Terminating", and otherwise its phase
+func podStatus(pod *corev1.Pod) string {
+	reason := string(pod.Status.Phase)
+	if pod.Status.Reason != "" {
+		reason = pod.Status.Reason
+	}
+
+	// Sidecar init containers only have to have started
+	initializing := false
+	for i := range pod.Status.InitContainerStatuses {
+		container := &pod.Status.InitContainerStatuses[i]
+		spec := findContainer(pod.Spec.InitContainers, container.Name)
+		switch {
+		case container.State.Terminated != nil && container.State.Terminated.ExitCode == 0:
+			continue
+		case spec != nil && isSidecar(spec) && container.Started != nil && *container.Started:
+			continue
+		case container.State.Terminated != nil:
+			if container.State.Terminated.Reason != "" {
+				reason = "Init:" + container.State.Terminated.Reason
+			} else if container.State.Terminated.Signal != 0 {
+				reason = fmt.Sprintf("Init:Signal:%d", container.State.Terminated.Signal)
+			} else {
+				reason = fmt.Sprintf("Init:ExitCode:%d", container.State.Terminated.ExitCode)
+			}
+		case container.State.Waiting != nil && container.State.Waiting.Reason != "" && container.State.Waiting.Reason != "PodInitializing":
+			reason = "Init:" + container.State.Waiting.Reason
+		default:
+			reason = fmt.Sprintf("Init:%d/%d", i, len(pod.Spec.InitContainers))
+		}
+		initializing = true
+		break
+	}
+
+	if !initializing || podCondition(pod, corev1.PodInitialized) {
+		hasRunning := false
+		for i := len(pod.Status.ContainerStatuses) - 1; i >= 0; i-- {
+			container := &pod.Status.ContainerStatuses[i]
+			switch {
+			case container.State.Waiting != nil && container.State.Waiting.Reason != "":
+				reason = container.State.Waiting.Reason
+			case container.State.Terminated != nil && container.State.Terminated.Reason != "":
+				reason = container.State.Terminated.Reason
+			case container.State.Terminated != nil && container.State.Terminated.Signal != 0:
+				reason = fmt.Sprintf("Signal:%d", container.State.Terminated.Signal)
+			case container.State.Terminated != nil:
+				reason = fmt.Sprintf("ExitCode:%d", container.State.Terminated.ExitCode)
+			case container.Ready && container.State.Running != nil:
+				hasRunning = true
+			}
+		}
+
+		// A pod some of whose containers completed is still running
+		if reason == "Completed" && hasRunning {
+			if podCondition(pod, corev1.PodReady) {
+				reason = "Running"
+			} else {
+				reason = "NotReady"
+			}
+		}
+	}
+
+	if pod.DeletionTimestamp != nil {
+		if pod.Status.Reason == "NodeLost" {
+			reason = "Unknown"
+		} else {
+			reason = "Terminating"
+		}
+	}
+	return reason
+}
+
+// podRestarts sums the restarts of every container, including init containers
+func podRestarts(pod *corev1.Pod) int32 {
+	var restarts int32
+	for _, status := range pod.Status.InitContainerStatuses {
+		restarts += status.RestartCount
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		restarts += status.RestartCount
+	}
+	return restarts
+}
+
+// podReady is the READY column of `kubectl get pods`, e.g. "1/2"; running
+// sidecars count as containers
+func podReady(pod *corev1.Pod) string {
+	total, ready := len(pod.Spec.Containers), 0
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Ready {
+			ready++
+		}
+	}
+	for i := range pod.Spec.InitContainers {
+		if !isSidecar(&pod.Spec.InitContainers[i]) {
+			continue
+		}
+		total++
+		if status := findContainerStatus(pod.Status.InitContainerStatuses, pod.Spec.InitContainers[i].Name); status != nil && status.Ready {
+			ready++
+		}
+	}
+	return fmt.Sprintf("%d/%d", ready, total)
+}
+
+// podRequest is the pod's effective request for a resource, as the scheduler
+// computes it: the app containers and sidecars together, or the largest
+// regular init container if that is more. Zero means nothing was requested.
+func podRequest(pod *corev1.Pod, name corev1.ResourceName) resource.Quantity {
+	var total, sidecars, initMax resource.Quantity
+	for _, container := range pod.Spec.Containers {
+		if request, ok := container.Resources.Requests[name]; ok {
+			total.Add(request)
+		}
+	}
+	for i := range pod.Spec.InitContainers {
+		container := &pod.Spec.InitContainers[i]
+		request, ok := container.Resources.Requests[name]
+		if !ok {
+			continue
+		}
+		if isSidecar(container) {
+			sidecars.Add(request)
+			total.Add(request)
+			continue
+		}
+		// A regular init container runs next to the sidecars started before it
+		withSidecars := request.DeepCopy()
+		withSidecars.Add(sidecars)
+		if withSidecars.Cmp(initMax) > 0 {
+			initMax = withSidecars
+		}
+	}
+	if initMax.Cmp(total) > 0 {
+		return initMax
+	}
+	return total
+}
+
+func podCondition(pod *corev1.Pod, conditionType corev1.PodConditionType) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == conditionType {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// isSidecar reports whether an init container is a sidecar, which keeps running
+// for the pod's lifetime
+func isSidecar(container *corev1.Container) bool {
+	return container.RestartPolicy != nil && *container.RestartPolicy == corev1.ContainerRestartPolicyAlways
+}
+
+func findContainer(containers []corev1.Container, name string) *corev1.Container {
+	for i := range containers {
+		if containers[i].Name == name {
+			return &containers[i]
+		}
+	}
+	return nil
+}
+
+func findContainerStatus(statuses []corev1.ContainerStatus, name string) *corev1.ContainerStatus {
+	for i := range statuses {
+		if statuses[i].Name == name {
+			return &statuses[i]
+		}
+	}
+	return nil
+}
+
+func resourceStrings(list corev1.ResourceList) map[string]string {
+	if len(list) == 0 {
+		return nil
+	}
+	values := make(map[string]string, len(list))
+	for name, quantity := range list {
+		values[string(name)] = quantity.String()
+	}
+	return values
+}
+
+func timePtr(t *metav1.Time) *time.Time {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
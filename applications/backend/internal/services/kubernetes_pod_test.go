@@ -0,0 +1,329 @@
+package services
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var restartAlways = corev1.ContainerRestartPolicyAlways
+
+func running(name string, ready bool) corev1.ContainerStatus {
+	return corev1.ContainerStatus{Name: name, Ready: ready, State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}}
+}
+
+func waiting(name, reason string) corev1.ContainerStatus {
+	return corev1.ContainerStatus{Name: name, State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: reason}}}
+}
+
+func terminated(name, reason string, exitCode int32) corev1.ContainerStatus {
+	return corev1.ContainerStatus{Name: name, State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: reason, ExitCode: exitCode}}}
+}
+
+func containers(names ...string) []corev1.Container {
+	list := make([]corev1.Container, len(names))
+	for i, name := range names {
+		list[i] = corev1.Container{Name: name}
+	}
+	return list
+}
+
+func TestPodStatus(t *testing.T) {
+	now := metav1.Now()
+	started := true
+	sidecar := corev1.Container{Name: "proxy", RestartPolicy: &restartAlways}
+
+	tests := []struct {
+		name string
+		pod  corev1.Pod
+		want string
+	}{
+		{
+			name: "running",
+			pod: corev1.Pod{
+				Spec:   corev1.PodSpec{Containers: containers("app")},
+				Status: corev1.PodStatus{Phase: corev1.PodRunning, ContainerStatuses: []corev1.ContainerStatus{running("app", true)}},
+			},
+			want: "Running",
+		},
+		{
+			name: "pending without statuses",
+			pod:  corev1.Pod{Spec: corev1.PodSpec{Containers: containers("app")}, Status: corev1.PodStatus{Phase: corev1.PodPending}},
+			want: "Pending",
+		},
+		{
+			name: "crash loop",
+			pod: corev1.Pod{
+				Spec: corev1.PodSpec{Containers: containers("app")},
+				Status: corev1.PodStatus{Phase: corev1.PodRunning, ContainerStatuses: []corev1.ContainerStatus{
+					waiting("app", "CrashLoopBackOff"),
+				}},
+			},
+			want: "CrashLoopBackOff",
+		},
+		{
+			name: "first container's reason wins",
+			pod: corev1.Pod{
+				Spec: corev1.PodSpec{Containers: containers("app", "logger")},
+				Status: corev1.PodStatus{Phase: corev1.PodRunning, ContainerStatuses: []corev1.ContainerStatus{
+					waiting("app", "ImagePullBackOff"), terminated("logger", "OOMKilled", 137),
+				}},
+			},
+			want: "ImagePullBackOff",
+		},
+		{
+			name: "completed",
+			pod: corev1.Pod{
+				Spec:   corev1.PodSpec{Containers: containers("job")},
+				Status: corev1.PodStatus{Phase: corev1.PodSucceeded, ContainerStatuses: []corev1.ContainerStatus{terminated("job", "Completed", 0)}},
+			},
+			want: "Completed",
+		},
+		{
+			name: "completed container next to a ready one",
+			pod: corev1.Pod{
+				Spec: corev1.PodSpec{Containers: containers("migrate", "app")},
+				Status: corev1.PodStatus{
+					Phase:             corev1.PodRunning,
+					Conditions:        []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+					ContainerStatuses: []corev1.ContainerStatus{terminated("migrate", "Completed", 0), running("app", true)},
+				},
+			},
+			want: "Running",
+		},
+		{
+			name: "exit code without reason",
+			pod: corev1.Pod{
+				Spec:   corev1.PodSpec{Containers: containers("app")},
+				Status: corev1.PodStatus{Phase: corev1.PodFailed, ContainerStatuses: []corev1.ContainerStatus{terminated("app", "", 3)}},
+			},
+			want: "ExitCode:3",
+		},
+		{
+			name: "waiting on the second of two init containers",
+			pod: corev1.Pod{
+				Spec: corev1.PodSpec{InitContainers: containers("schema", "seed"), Containers: containers("app")},
+				Status: corev1.PodStatus{
+					Phase: corev1.PodPending,
+					InitContainerStatuses: []corev1.ContainerStatus{
+						terminated("schema", "Completed", 0), running("seed", false),
+					},
+					ContainerStatuses: []corev1.ContainerStatus{waiting("app", "PodInitializing")},
+				},
+			},
+			want: "Init:1/2",
+		},
+		{
+			name: "init container crash loop",
+			pod: corev1.Pod{
+				Spec: corev1.PodSpec{InitContainers: containers("schema"), Containers: containers("app")},
+				Status: corev1.PodStatus{
+					Phase:                 corev1.PodPending,
+					InitContainerStatuses: []corev1.ContainerStatus{waiting("schema", "CrashLoopBackOff")},
+				},
+			},
+			want: "Init:CrashLoopBackOff",
+		},
+		{
+			name: "init container failed",
+			pod: corev1.Pod{
+				Spec: corev1.PodSpec{InitContainers: containers("schema"), Containers: containers("app")},
+				Status: corev1.PodStatus{
+					Phase:                 corev1.PodPending,
+					InitContainerStatuses: []corev1.ContainerStatus{terminated("schema", "", 1)},
+				},
+			},
+			want: "Init:ExitCode:1",
+		},
+		{
+			name: "started sidecar does not hold up the pod",
+			pod: corev1.Pod{
+				Spec: corev1.PodSpec{InitContainers: []corev1.Container{sidecar}, Containers: containers("app")},
+				Status: corev1.PodStatus{
+					Phase:      corev1.PodRunning,
+					Conditions: []corev1.PodCondition{{Type: corev1.PodInitialized, Status: corev1.ConditionTrue}},
+					InitContainerStatuses: []corev1.ContainerStatus{
+						{Name: "proxy", Started: &started, Ready: true, State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+					},
+					ContainerStatuses: []corev1.ContainerStatus{running("app", true)},
+				},
+			},
+			want: "Running",
+		},
+		{
+			name: "terminating",
+			pod: corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &now},
+				Spec:       corev1.PodSpec{Containers: containers("app")},
+				Status:     corev1.PodStatus{Phase: corev1.PodRunning, ContainerStatuses: []corev1.ContainerStatus{running("app", true)}},
+			},
+			want: "Terminating",
+		},
+		{
+			name: "terminating on a lost node",
+			pod: corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &now},
+				Spec:       corev1.PodSpec{Containers: containers("app")},
+				Status:     corev1.PodStatus{Phase: corev1.PodRunning, Reason: "NodeLost"},
+			},
+			want: "Unknown",
+		},
+		{
+			name: "evicted",
+			pod: corev1.Pod{
+				Spec:   corev1.PodSpec{Containers: containers("app")},
+				Status: corev1.PodStatus{Phase: corev1.PodFailed, Reason: "Evicted"},
+			},
+			want: "Evicted",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := podStatus(&tt.pod); got != tt.want {
+				t.Fatalf("podStatus = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPodReady(t *testing.T) {
+	sidecar := corev1.Container{Name: "proxy", RestartPolicy: &restartAlways}
+
+	tests := []struct {
+		name string
+		pod  corev1.Pod
+		want string
+	}{
+		{
+			name: "all ready",
+			pod: corev1.Pod{
+				Spec:   corev1.PodSpec{Containers: containers("app", "logger")},
+				Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{running("app", true), running("logger", true)}},
+			},
+			want: "2/2",
+		},
+		{
+			name: "one crash looping",
+			pod: corev1.Pod{
+				Spec:   corev1.PodSpec{Containers: containers("app", "logger")},
+				Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{waiting("app", "CrashLoopBackOff"), running("logger", true)}},
+			},
+			want: "1/2",
+		},
+		{
+			name: "no statuses yet",
+			pod:  corev1.Pod{Spec: corev1.PodSpec{Containers: containers("app")}},
+			want: "0/1",
+		},
+		{
+			name: "regular init containers do not count",
+			pod: corev1.Pod{
+				Spec: corev1.PodSpec{InitContainers: containers("schema"), Containers: containers("app")},
+				Status: corev1.PodStatus{
+					InitContainerStatuses: []corev1.ContainerStatus{terminated("schema", "Completed", 0)},
+					ContainerStatuses:     []corev1.ContainerStatus{running("app", true)},
+				},
+			},
+			want: "1/1",
+		},
+		{
+			name: "sidecars count",
+			pod: corev1.Pod{
+				Spec: corev1.PodSpec{InitContainers: []corev1.Container{sidecar}, Containers: containers("app")},
+				Status: corev1.PodStatus{
+					InitContainerStatuses: []corev1.ContainerStatus{running("proxy", false)},
+					ContainerStatuses:     []corev1.ContainerStatus{running("app", true)},
+				},
+			},
+			want: "1/2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := podReady(&tt.pod); got != tt.want {
+				t.Fatalf("podReady = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPodRequest(t *testing.T) {
+	requests := func(cpu string) corev1.ResourceRequirements {
+		return corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)}}
+	}
+	container := func(name, cpu string, sidecar bool) corev1.Container {
+		c := corev1.Container{Name: name}
+		if cpu != "" {
+			c.Resources = requests(cpu)
+		}
+		if sidecar {
+			c.RestartPolicy = &restartAlways
+		}
+		return c
+	}
+
+	tests := []struct {
+		name           string
+		initContainers []corev1.Container
+		containers     []corev1.Container
+		want           string
+	}{
+		{
+			name:       "sum of app containers",
+			containers: []corev1.Container{container("app", "250m", false), container("logger", "100m", false)},
+			want:       "350m",
+		},
+		{
+			name:       "nothing requested",
+			containers: []corev1.Container{container("app", "", false)},
+			want:       "0",
+		},
+		{
+			name:           "smaller init container",
+			initContainers: []corev1.Container{container("schema", "100m", false)},
+			containers:     []corev1.Container{container("app", "250m", false)},
+			want:           "250m",
+		},
+		{
+			name:           "larger init container",
+			initContainers: []corev1.Container{container("schema", "1", false)},
+			containers:     []corev1.Container{container("app", "250m", false)},
+			want:           "1",
+		},
+		{
+			name:           "sidecars add to the app containers",
+			initContainers: []corev1.Container{container("proxy", "100m", true)},
+			containers:     []corev1.Container{container("app", "250m", false)},
+			want:           "350m",
+		},
+		{
+			name: "init container runs next to earlier sidecars",
+			initContainers: []corev1.Container{
+				container("proxy", "200m", true),
+				container("schema", "500m", false),
+			},
+			containers: []corev1.Container{container("app", "250m", false)},
+			want:       "700m",
+		},
+		{
+			name: "later sidecars do not run next to an init container",
+			initContainers: []corev1.Container{
+				container("schema", "500m", false),
+				container("proxy", "200m", true),
+			},
+			containers: []corev1.Container{container("app", "250m", false)},
+			want:       "500m",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{Spec: corev1.PodSpec{InitContainers: tt.initContainers, Containers: tt.containers}}
+			got := podRequest(pod, corev1.ResourceCPU)
+			if want := resource.MustParse(tt.want); got.Cmp(want) != 0 {
+				t.Fatalf("podRequest = %s, want %s", got.String(), want.String())
+			}
+		})
+	}
+}
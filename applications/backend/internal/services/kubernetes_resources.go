@@ -0,0 +1,736 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ErrUnknownResourceKind is returned for a kind without typed endpoints
+var ErrUnknownResourceKind = errors.New("unknown resource kind")
+
+// lastAppliedAnnotation holds the full object as last applied with kubectl,
+// which for a Secret includes its data
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+type StatefulSetInfo struct {
+	Name            string    `json:"name"`
+	Namespace       string    `json:"namespace"`
+	Replicas        int32     `json:"replicas"`
+	ReadyReplicas   int32     `json:"ready_replicas"`
+	CurrentReplicas int32     `json:"current_replicas"`
+	UpdatedReplicas int32     `json:"updated_replicas"`
+	ServiceName     string    `json:"service_name"`
+	Age             string    `json:"age"`
+	CreatedAt       time.Time `json:"created_at"`
+	Image           string    `json:"image"`
+}
+
+type DaemonSetInfo struct {
+	Name         string            `json:"name"`
+	Namespace    string            `json:"namespace"`
+	Desired      int32             `json:"desired"`
+	Current      int32             `json:"current"`
+	Ready        int32             `json:"ready"`
+	UpToDate     int32             `json:"up_to_date"`
+	Available    int32             `json:"available"`
+	NodeSelector map[string]string `json:"node_selector,omitempty"`
+	Age          string            `json:"age"`
+	CreatedAt    time.Time         `json:"created_at"`
+	Image        string            `json:"image"`
+}
+
+type JobInfo struct {
+	Name        string    `json:"name"`
+	Namespace   string    `json:"namespace"`
+	Status      string    `json:"status"`
+	Completions string    `json:"completions"`
+	Active      int32     `json:"active"`
+	Succeeded   int32     `json:"succeeded"`
+	Failed      int32     `json:"failed"`
+	Duration    string    `json:"duration,omitempty"`
+	Owner       string    `json:"owner,omitempty"`
+	Age         string    `json:"age"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type CronJobInfo struct {
+	Name               string    `json:"name"`
+	Namespace          string    `json:"namespace"`
+	Schedule           string    `json:"schedule"`
+	Suspend            bool      `json:"suspend"`
+	Active             int       `json:"active"`
+	LastSchedule       string    `json:"last_schedule,omitempty"`
+	LastSuccessfulTime string    `json:"last_successful_time,omitempty"`
+	Age                string    `json:"age"`
+	CreatedAt          time.Time `json:"created_at"`
+}
+
+type IngressInfo struct {
+	Name      string            `json:"name"`
+	Namespace string            `json:"namespace"`
+	Class     string            `json:"class,omitempty"`
+	Hosts     []string          `json:"hosts"`
+	Addresses []string          `json:"addresses"`
+	Rules     []IngressRuleInfo `json:"rules"`
+	TLSHosts  []string          `json:"tls_hosts,omitempty"`
+	Age       string            `json:"age"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// IngressRuleInfo is one host and path of an ingress and the backend it routes to
+type IngressRuleInfo struct {
+	Host    string `json:"host,omitempty"`
+	Path    string `json:"path"`
+	Backend string `json:"backend"`
+}
+
+type ConfigMapInfo struct {
+	Name      string    `json:"name"`
+	Namespace string    `json:"namespace"`
+	Keys      []string  `json:"keys"`
+	Age       string    `json:"age"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SecretInfo describes a secret without its values
+type SecretInfo struct {
+	Name      string    `json:"name"`
+	Namespace string    `json:"namespace"`
+	Type      string    `json:"type"`
+	Keys      []string  `json:"keys"`
+	Age       string    `json:"age"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type PVCInfo struct {
+	Name         string    `json:"name"`
+	Namespace    string    `json:"namespace"`
+	Status       string    `json:"status"`
+	Volume       string    `json:"volume"`
+	Capacity     string    `json:"capacity"`
+	AccessModes  []string  `json:"access_modes"`
+	StorageClass string    `json:"storage_class"`
+	Age          string    `json:"age"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type HPAInfo struct {
+	Name            string    `json:"name"`
+	Namespace       string    `json:"namespace"`
+	Reference       string    `json:"reference"`
+	MinReplicas     int32     `json:"min_replicas"`
+	MaxReplicas     int32     `json:"max_replicas"`
+	CurrentReplicas int32     `json:"current_replicas"`
+	DesiredReplicas int32     `json:"desired_replicas"`
+	Targets         []string  `json:"targets"`
+	Age             string    `json:"age"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// ResourceDetail is the detail view of one object: its metadata, the row the
+// list endpoint shows for it, and its spec and status as the API server
+// returns them. Secrets have no spec; their values are never returned.
+type ResourceDetail struct {
+	Kind            string                  `json:"kind"`
+	Name            string                  `json:"name"`
+	Namespace       string                  `json:"namespace,omitempty"`
+	UID             string                  `json:"uid"`
+	Labels          map[string]string       `json:"labels,omitempty"`
+	Annotations     map[string]string       `json:"annotations,omitempty"`
+	OwnerReferences []metav1.OwnerReference `json:"owner_references,omitempty"`
+	CreatedAt       time.Time               `json:"created_at"`
+	Summary         interface{}             `json:"summary"`
+	Spec            interface{}             `json:"spec,omitempty"`
+	Status          interface{}             `json:"status,omitempty"`
+}
+
+func newResourceDetail(kind string, meta *metav1.ObjectMeta, summary, spec, status interface{}) *ResourceDetail {
+	return &ResourceDetail{
+		Kind:            kind,
+		Name:            meta.Name,
+		Namespace:       meta.Namespace,
+		UID:             string(meta.UID),
+		Labels:          meta.Labels,
+		Annotations:     meta.Annotations,
+		OwnerReferences: meta.OwnerReferences,
+		CreatedAt:       meta.CreationTimestamp.UTC(),
+		Summary:         summary,
+		Spec:            spec,
+		Status:          status,
+	}
+}
+
+// typedResource lists and fetches one kind through the typed clientset
+type typedResource struct {
+	list func(ctx context.Context, clientset kubernetes.Interface, namespace string) (interface{}, error)
+	get  func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (*ResourceDetail, error)
+}
+
+// TypedResourceKinds are the kinds served by ListResources and GetResource, by
+// their plural resource name
+var TypedResourceKinds = []string{
+	"statefulsets", "daemonsets", "jobs", "cronjobs", "ingresses",
+	"configmaps", "secrets", "persistentvolumeclaims", "horizontalpodautoscalers",
+}
+
+var typedResources = map[string]typedResource{
+	"statefulsets":             {list: listStatefulSets, get: getStatefulSet},
+	"daemonsets":               {list: listDaemonSets, get: getDaemonSet},
+	"jobs":                     {list: listJobs, get: getJob},
+	"cronjobs":                 {list: listCronJobs, get: getCronJob},
+	"ingresses":                {list: listIngresses, get: getIngress},
+	"configmaps":               {list: listConfigMaps, get: getConfigMap},
+	"secrets":                  {list: listSecrets, get: getSecret},
+	"persistentvolumeclaims":   {list: listPVCs, get: getPVC},
+	"horizontalpodautoscalers": {list: listHPAs, get: getHPA},
+}
+
+// ListResources lists objects of kind, one of TypedResourceKinds, in namespace
+// ("" or "all" for every namespace)
+func (s *KubernetesService) ListResources(ctx context.Context, kind, namespace string) (interface{}, error) {
+	resource, ok := typedResources[kind]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownResourceKind, kind)
+	}
+	if namespace == "all" {
+		namespace = ""
+	}
+
+	items, err := resource.list(ctx, s.clientset, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", kind, err)
+	}
+	return items, nil
+}
+
+// GetResource fetches one object of kind, one of TypedResourceKinds
+func (s *KubernetesService) GetResource(ctx context.Context, kind, namespace, name string) (*ResourceDetail, error) {
+	resource, ok := typedResources[kind]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownResourceKind, kind)
+	}
+	return resource.get(ctx, s.clientset, namespace, name)
+}
+
+func listStatefulSets(ctx context.Context, clientset kubernetes.Interface, namespace string) (interface{}, error) {
+	list, err := clientset.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]StatefulSetInfo, 0, len(list.Items))
+	for i := range list.Items {
+		infos = append(infos, toStatefulSetInfo(&list.Items[i]))
+	}
+	return infos, nil
+}
+
+func getStatefulSet(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (*ResourceDetail, error) {
+	sts, err := clientset.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return newResourceDetail("StatefulSet", &sts.ObjectMeta, toStatefulSetInfo(sts), sts.Spec, sts.Status), nil
+}
+
+func toStatefulSetInfo(sts *appsv1.StatefulSet) StatefulSetInfo {
+	replicas := int32(1)
+	if sts.Spec.Replicas != nil {
+		replicas = *sts.Spec.Replicas
+	}
+	return StatefulSetInfo{
+		Name:            sts.Name,
+		Namespace:       sts.Namespace,
+		Replicas:        replicas,
+		ReadyReplicas:   sts.Status.ReadyReplicas,
+		CurrentReplicas: sts.Status.CurrentReplicas,
+		UpdatedReplicas: sts.Status.UpdatedReplicas,
+		ServiceName:     sts.Spec.ServiceName,
+		Age:             age(sts.CreationTimestamp),
+		CreatedAt:       sts.CreationTimestamp.UTC(),
+		Image:           containerImages(&sts.Spec.Template.Spec),
+	}
+}
+
+func listDaemonSets(ctx context.Context, clientset kubernetes.Interface, namespace string) (interface{}, error) {
+	list, err := clientset.AppsV1().DaemonSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]DaemonSetInfo, 0, len(list.Items))
+	for i := range list.Items {
+		infos = append(infos, toDaemonSetInfo(&list.Items[i]))
+	}
+	return infos, nil
+}
+
+func getDaemonSet(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (*ResourceDetail, error) {
+	ds, err := clientset.AppsV1().DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return newResourceDetail("DaemonSet", &ds.ObjectMeta, toDaemonSetInfo(ds), ds.Spec, ds.Status), nil
+}
+
+func toDaemonSetInfo(ds *appsv1.DaemonSet) DaemonSetInfo {
+	return DaemonSetInfo{
+		Name:         ds.Name,
+		Namespace:    ds.Namespace,
+		Desired:      ds.Status.DesiredNumberScheduled,
+		Current:      ds.Status.CurrentNumberScheduled,
+		Ready:        ds.Status.NumberReady,
+		UpToDate:     ds.Status.UpdatedNumberScheduled,
+		Available:    ds.Status.NumberAvailable,
+		NodeSelector: ds.Spec.Template.Spec.NodeSelector,
+		Age:          age(ds.CreationTimestamp),
+		CreatedAt:    ds.CreationTimestamp.UTC(),
+		Image:        containerImages(&ds.Spec.Template.Spec),
+	}
+}
+
+func listJobs(ctx context.Context, clientset kubernetes.Interface, namespace string) (interface{}, error) {
+	list, err := clientset.BatchV1().Jobs(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]JobInfo, 0, len(list.Items))
+	for i := range list.Items {
+		infos = append(infos, toJobInfo(&list.Items[i]))
+	}
+	return infos, nil
+}
+
+func getJob(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (*ResourceDetail, error) {
+	job, err := clientset.BatchV1().Jobs(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return newResourceDetail("Job", &job.ObjectMeta, toJobInfo(job), job.Spec, job.Status), nil
+}
+
+func toJobInfo(job *batchv1.Job) JobInfo {
+	completions := "-"
+	if job.Spec.Completions != nil {
+		completions = fmt.Sprintf("%d/%d", job.Status.Succeeded, *job.Spec.Completions)
+	}
+
+	status := "Running"
+	switch {
+	case jobCondition(job, batchv1.JobComplete):
+		status = "Complete"
+	case jobCondition(job, batchv1.JobFailed):
+		status = "Failed"
+	case job.Spec.Suspend != nil && *job.Spec.Suspend:
+		status = "Suspended"
+	}
+
+	duration := ""
+	if job.Status.StartTime != nil {
+		end := time.Now()
+		if job.Status.CompletionTime != nil {
+			end = job.Status.CompletionTime.Time
+		}
+		duration = end.Sub(job.Status.StartTime.Time).Round(time.Second).String()
+	}
+
+	owner := ""
+	if ref := metav1.GetControllerOf(job); ref != nil {
+		owner = ref.Kind + "/" + ref.Name
+	}
+
+	return JobInfo{
+		Name:        job.Name,
+		Namespace:   job.Namespace,
+		Status:      status,
+		Completions: completions,
+		Active:      job.Status.Active,
+		Succeeded:   job.Status.Succeeded,
+		Failed:      job.Status.Failed,
+		Duration:    duration,
+		Owner:       owner,
+		Age:         age(job.CreationTimestamp),
+		CreatedAt:   job.CreationTimestamp.UTC(),
+	}
+}
+
+func jobCondition(job *batchv1.Job, conditionType batchv1.JobConditionType) bool {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == conditionType && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+func listCronJobs(ctx context.Context, clientset kubernetes.Interface, namespace string) (interface{}, error) {
+	list, err := clientset.BatchV1().CronJobs(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]CronJobInfo, 0, len(list.Items))
+	for i := range list.Items {
+		infos = append(infos, toCronJobInfo(&list.Items[i]))
+	}
+	return infos, nil
+}
+
+func getCronJob(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (*ResourceDetail, error) {
+	cronJob, err := clientset.BatchV1().CronJobs(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return newResourceDetail("CronJob", &cronJob.ObjectMeta, toCronJobInfo(cronJob), cronJob.Spec, cronJob.Status), nil
+}
+
+func toCronJobInfo(cronJob *batchv1.CronJob) CronJobInfo {
+	info := CronJobInfo{
+		Name:      cronJob.Name,
+		Namespace: cronJob.Namespace,
+		Schedule:  cronJob.Spec.Schedule,
+		Suspend:   cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend,
+		Active:    len(cronJob.Status.Active),
+		Age:       age(cronJob.CreationTimestamp),
+		CreatedAt: cronJob.CreationTimestamp.UTC(),
+	}
+	if cronJob.Status.LastScheduleTime != nil {
+		info.LastSchedule = cronJob.Status.LastScheduleTime.String()
+	}
+	if cronJob.Status.LastSuccessfulTime != nil {
+		info.LastSuccessfulTime = cronJob.Status.LastSuccessfulTime.String()
+	}
+	return info
+}
+
+func listIngresses(ctx context.Context, clientset kubernetes.Interface, namespace string) (interface{}, error) {
+	list, err := clientset.NetworkingV1().Ingresses(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]IngressInfo, 0, len(list.Items))
+	for i := range list.Items {
+		infos = append(infos, toIngressInfo(&list.Items[i]))
+	}
+	return infos, nil
+}
+
+func getIngress(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (*ResourceDetail, error) {
+	ingress, err := clientset.NetworkingV1().Ingresses(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return newResourceDetail("Ingress", &ingress.ObjectMeta, toIngressInfo(ingress), ingress.Spec, ingress.Status), nil
+}
+
+func toIngressInfo(ingress *networkingv1.Ingress) IngressInfo {
+	info := IngressInfo{
+		Name:      ingress.Name,
+		Namespace: ingress.Namespace,
+		Hosts:     []string{},
+		Addresses: []string{},
+		Rules:     []IngressRuleInfo{},
+		Age:       age(ingress.CreationTimestamp),
+		CreatedAt: ingress.CreationTimestamp.UTC(),
+	}
+	if ingress.Spec.IngressClassName != nil {
+		info.Class = *ingress.Spec.IngressClassName
+	}
+
+	if ingress.Spec.DefaultBackend != nil {
+		info.Rules = append(info.Rules, IngressRuleInfo{Path: "*", Backend: ingressBackend(ingress.Spec.DefaultBackend)})
+	}
+	for _, rule := range ingress.Spec.Rules {
+		if rule.Host != "" {
+			info.Hosts = append(info.Hosts, rule.Host)
+		}
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			info.Rules = append(info.Rules, IngressRuleInfo{
+				Host:    rule.Host,
+				Path:    path.Path,
+				Backend: ingressBackend(&path.Backend),
+			})
+		}
+	}
+
+	for _, tls := range ingress.Spec.TLS {
+		info.TLSHosts = append(info.TLSHosts, tls.Hosts...)
+	}
+	for _, lb := range ingress.Status.LoadBalancer.Ingress {
+		if lb.IP != "" {
+			info.Addresses = append(info.Addresses, lb.IP)
+		} else if lb.Hostname != "" {
+			info.Addresses = append(info.Addresses, lb.Hostname)
+		}
+	}
+	return info
+}
+
+// ingressBackend renders a backend as service:port or Kind/name
+func ingressBackend(backend *networkingv1.IngressBackend) string {
+	if backend.Service != nil {
+		port := backend.Service.Port.Name
+		if port == "" {
+			port = fmt.Sprint(backend.Service.Port.Number)
+		}
+		return backend.Service.Name + ":" + port
+	}
+	if backend.Resource != nil {
+		return backend.Resource.Kind + "/" + backend.Resource.Name
+	}
+	return ""
+}
+
+func listConfigMaps(ctx context.Context, clientset kubernetes.Interface, namespace string) (interface{}, error) {
+	list, err := clientset.CoreV1().ConfigMaps(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]ConfigMapInfo, 0, len(list.Items))
+	for i := range list.Items {
+		infos = append(infos, toConfigMapInfo(&list.Items[i]))
+	}
+	return infos, nil
+}
+
+// getConfigMap returns the config map's values as its spec; binary values are
+// listed by key only
+func getConfigMap(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (*ResourceDetail, error) {
+	configMap, err := clientset.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	spec := map[string]interface{}{"data": configMap.Data}
+	if len(configMap.BinaryData) > 0 {
+		spec["binary_data_keys"] = sortedKeys(configMap.BinaryData)
+	}
+	return newResourceDetail("ConfigMap", &configMap.ObjectMeta, toConfigMapInfo(configMap), spec, nil), nil
+}
+
+func toConfigMapInfo(configMap *corev1.ConfigMap) ConfigMapInfo {
+	keys := sortedKeys(configMap.Data)
+	keys = append(keys, sortedKeys(configMap.BinaryData)...)
+	sort.Strings(keys)
+	return ConfigMapInfo{
+		Name:      configMap.Name,
+		Namespace: configMap.Namespace,
+		Keys:      keys,
+		Age:       age(configMap.CreationTimestamp),
+		CreatedAt: configMap.CreationTimestamp.UTC(),
+	}
+}
+
+// listSecrets returns each secret's type and key names; values are never returned
+func listSecrets(ctx context.Context, clientset kubernetes.Interface, namespace string) (interface{}, error) {
+	list, err := clientset.CoreV1().Secrets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]SecretInfo, 0, len(list.Items))
+	for i := range list.Items {
+		infos = append(infos, toSecretInfo(&list.Items[i]))
+	}
+	return infos, nil
+}
+
+// getSecret returns a secret's metadata and key names; its values and the
+// last-applied annotation, which may contain them, are dropped
+func getSecret(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (*ResourceDetail, error) {
+	secret, err := clientset.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	meta := secret.ObjectMeta.DeepCopy()
+	delete(meta.Annotations, lastAppliedAnnotation)
+	return newResourceDetail("Secret", meta, toSecretInfo(secret), nil, nil), nil
+}
+
+func toSecretInfo(secret *corev1.Secret) SecretInfo {
+	return SecretInfo{
+		Name:      secret.Name,
+		Namespace: secret.Namespace,
+		Type:      string(secret.Type),
+		Keys:      sortedKeys(secret.Data),
+		Age:       age(secret.CreationTimestamp),
+		CreatedAt: secret.CreationTimestamp.UTC(),
+	}
+}
+
+func listPVCs(ctx context.Context, clientset kubernetes.Interface, namespace string) (interface{}, error) {
+	list, err := clientset.CoreV1().PersistentVolumeClaims(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]PVCInfo, 0, len(list.Items))
+	for i := range list.Items {
+		infos = append(infos, toPVCInfo(&list.Items[i]))
+	}
+	return infos, nil
+}
+
+func getPVC(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (*ResourceDetail, error) {
+	pvc, err := clientset.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return newResourceDetail("PersistentVolumeClaim", &pvc.ObjectMeta, toPVCInfo(pvc), pvc.Spec, pvc.Status), nil
+}
+
+func toPVCInfo(pvc *corev1.PersistentVolumeClaim) PVCInfo {
+	info := PVCInfo{
+		Name:        pvc.Name,
+		Namespace:   pvc.Namespace,
+		Status:      string(pvc.Status.Phase),
+		Volume:      pvc.Spec.VolumeName,
+		AccessModes: []string{},
+		Age:         age(pvc.CreationTimestamp),
+		CreatedAt:   pvc.CreationTimestamp.UTC(),
+	}
+	if capacity, ok := pvc.Status.Capacity[corev1.ResourceStorage]; ok {
+		info.Capacity = capacity.String()
+	}
+	for _, mode := range pvc.Status.AccessModes {
+		info.AccessModes = append(info.AccessModes, string(mode))
+	}
+	if pvc.Spec.StorageClassName != nil {
+		info.StorageClass = *pvc.Spec.StorageClassName
+	}
+	return info
+}
+
+func listHPAs(ctx context.Context, clientset kubernetes.Interface, namespace string) (interface{}, error) {
+	list, err := clientset.AutoscalingV2().HorizontalPodAutoscalers(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]HPAInfo, 0, len(list.Items))
+	for i := range list.Items {
+		infos = append(infos, toHPAInfo(&list.Items[i]))
+	}
+	return infos, nil
+}
+
+func getHPA(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (*ResourceDetail, error) {
+	hpa, err := clientset.AutoscalingV2().HorizontalPodAutoscalers(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return newResourceDetail("HorizontalPodAutoscaler", &hpa.ObjectMeta, toHPAInfo(hpa), hpa.Spec, hpa.Status), nil
+}
+
+func toHPAInfo(hpa *autoscalingv2.HorizontalPodAutoscaler) HPAInfo {
+	minReplicas := int32(1)
+	if hpa.Spec.MinReplicas != nil {
+		minReplicas = *hpa.Spec.MinReplicas
+	}
+
+	targets := make([]string, 0, len(hpa.Spec.Metrics))
+	for i, metric := range hpa.Spec.Metrics {
+		var current *autoscalingv2.MetricStatus
+		if i < len(hpa.Status.CurrentMetrics) {
+			current = &hpa.Status.CurrentMetrics[i]
+		}
+		targets = append(targets, hpaTarget(metric, current))
+	}
+
+	return HPAInfo{
+		Name:            hpa.Name,
+		Namespace:       hpa.Namespace,
+		Reference:       hpa.Spec.ScaleTargetRef.Kind + "/" + hpa.Spec.ScaleTargetRef.Name,
+		MinReplicas:     minReplicas,
+		MaxReplicas:     hpa.Spec.MaxReplicas,
+		CurrentReplicas: hpa.Status.CurrentReplicas,
+		DesiredReplicas: hpa.Status.DesiredReplicas,
+		Targets:         targets,
+		Age:             age(hpa.CreationTimestamp),
+		CreatedAt:       hpa.CreationTimestamp.UTC(),
+	}
+}
+
+// hpaTarget renders a metric like kubectl's TARGETS column, "cpu: 45%/80%";
+// the current value is "<unknown>" until the HPA has read it
+func hpaTarget(metric autoscalingv2.MetricSpec, current *autoscalingv2.MetricStatus) string {
+	var name string
+	var target autoscalingv2.MetricTarget
+	var value *autoscalingv2.MetricValueStatus
+
+	switch metric.Type {
+	case autoscalingv2.ResourceMetricSourceType:
+		name, target = string(metric.Resource.Name), metric.Resource.Target
+		if current != nil && current.Resource != nil {
+			value = &current.Resource.Current
+		}
+	case autoscalingv2.ContainerResourceMetricSourceType:
+		name, target = metric.ContainerResource.Container+"/"+string(metric.ContainerResource.Name), metric.ContainerResource.Target
+		if current != nil && current.ContainerResource != nil {
+			value = &current.ContainerResource.Current
+		}
+	case autoscalingv2.PodsMetricSourceType:
+		name, target = metric.Pods.Metric.Name, metric.Pods.Target
+		if current != nil && current.Pods != nil {
+			value = &current.Pods.Current
+		}
+	case autoscalingv2.ObjectMetricSourceType:
+		name, target = metric.Object.Metric.Name, metric.Object.Target
+		if current != nil && current.Object != nil {
+			value = &current.Object.Current
+		}
+	case autoscalingv2.ExternalMetricSourceType:
+		name, target = metric.External.Metric.Name, metric.External.Target
+		if current != nil && current.External != nil {
+			value = &current.External.Current
+		}
+	default:
+		return string(metric.Type)
+	}
+
+	currentText, targetText := "<unknown>", "<unknown>"
+	switch {
+	case target.AverageUtilization != nil:
+		targetText = fmt.Sprintf("%d%%", *target.AverageUtilization)
+		if value != nil && value.AverageUtilization != nil {
+			currentText = fmt.Sprintf("%d%%", *value.AverageUtilization)
+		}
+	case target.AverageValue != nil:
+		targetText = target.AverageValue.String()
+		if value != nil && value.AverageValue != nil {
+			currentText = value.AverageValue.String()
+		}
+	case target.Value != nil:
+		targetText = target.Value.String()
+		if value != nil && value.Value != nil {
+			currentText = value.Value.String()
+		}
+	}
+	return name + ": " + currentText + "/" + targetText
+}
+
+// containerImages lists the images of a pod template's containers, comma-separated
+func containerImages(spec *corev1.PodSpec) string {
+	images := make([]string, 0, len(spec.Containers))
+	for _, container := range spec.Containers {
+		images = append(images, container.Image)
+	}
+	return strings.Join(images, ",")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -2,58 +2,86 @@ package services
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log"
 	"os"
 	"path/filepath"
+	"sync"
+	"time"
 
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
 )
 
+// KubernetesService talks to a single cluster. ClusterService keeps one per
+// registered cluster plus the default one built by NewKubernetesService.
 type KubernetesService struct {
-	clientset *kubernetes.Clientset
+	clientset kubernetes.Interface
+	dynamic   dynamic.Interface
+	config    *rest.Config
+
+	cacheMu sync.Mutex
+	cache   *kubernetesCache
+	closed  bool
 }
 
+// ErrWatchUnavailable is returned by Watch while the informer cache has not synced
+var ErrWatchUnavailable = errors.New("kubernetes watch unavailable: informer cache not synced")
+
 type PodInfo struct {
-	Name      string `json:"name"`
-	Namespace string `json:"namespace"`
-	Status    string `json:"status"`
-	Restarts  int32  `json:"restarts"`
-	Age       string `json:"age"`
-	Node      string `json:"node"`
-	IP        string `json:"ip"`
-	Image     string `json:"image"`
-	CPURequest string `json:"cpu_request"`
-	MemRequest string `json:"mem_request"`
+	Name       string    `json:"name"`
+	Namespace  string    `json:"namespace"`
+	Status     string    `json:"status"`
+	Ready      string    `json:"ready"`
+	Restarts   int32     `json:"restarts"`
+	Age        string    `json:"age"`
+	CreatedAt  time.Time `json:"created_at"`
+	Node       string    `json:"node"`
+	IP         string    `json:"ip"`
+	Image      string    `json:"image"`
+	CPURequest string    `json:"cpu_request"`
+	MemRequest string    `json:"mem_request"`
 }
 
 type DeploymentInfo struct {
-	Name          string `json:"name"`
-	Namespace     string `json:"namespace"`
-	Replicas      int32  `json:"replicas"`
-	ReadyReplicas int32  `json:"ready_replicas"`
-	UpdatedReplicas int32  `json:"updated_replicas"`
-	AvailableReplicas int32 `json:"available_replicas"`
-	Age            string `json:"age"`
-	Image          string `json:"image"`
+	Name              string    `json:"name"`
+	Namespace         string    `json:"namespace"`
+	Replicas          int32     `json:"replicas"`
+	ReadyReplicas     int32     `json:"ready_replicas"`
+	UpdatedReplicas   int32     `json:"updated_replicas"`
+	AvailableReplicas int32     `json:"available_replicas"`
+	Age               string    `json:"age"`
+	CreatedAt         time.Time `json:"created_at"`
+	Image             string    `json:"image"`
 }
 
 type ServiceInfo struct {
-	Name      string `json:"name"`
-	Namespace string `json:"namespace"`
-	Type      string `json:"type"`
-	ClusterIP string `json:"cluster_ip"`
-	Ports     []string `json:"ports"`
-	Age       string `json:"age"`
+	Name      string    `json:"name"`
+	Namespace string    `json:"namespace"`
+	Type      string    `json:"type"`
+	ClusterIP string    `json:"cluster_ip"`
+	Ports     []string  `json:"ports"`
+	Age       string    `json:"age"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type NamespaceInfo struct {
-	Name string `json:"name"`
-	Age  string `json:"age"`
-	Status string `json:"status"`
+	Name      string    `json:"name"`
+	Age       string    `json:"age"`
+	CreatedAt time.Time `json:"created_at"`
+	Status    string    `json:"status"`
 }
 
 func NewKubernetesService() (*KubernetesService, error) {
@@ -67,185 +95,389 @@ func NewKubernetesService() (*KubernetesService, error) {
 		kubeconfig := filepath.Join(os.Getenv("HOME"), ".kube", "config")
 		config, err = clientcmd.BuildConfigFromFlags("", kubeconfig)
 		if err != nil {
-			return nil, fmt.Errorf("failed to load kubeconfig: %v",err)
+			return nil, fmt.Errorf("failed to load kubeconfig: %v", err)
+		}
 	}
+
+	return newKubernetesServiceForConfig(config)
 }
 
+func newKubernetesServiceForConfig(config *rest.Config) (*KubernetesService, error) {
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create kubernetes clientset: %v", err)
 	}
 
-	return &KubernetesService{clientset: clientset}, nil
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes dynamic client: %v", err)
+	}
+
+	return &KubernetesService{clientset: clientset, dynamic: dynamicClient, config: config}, nil
 }
 
-func (s *KubernetesService) GetPods(namespace string) ([]PodInfo, error) {
-	ctx := context.Background()
-	
-	var pods *corev1.PodList
-	var err error
-	
-	if namespace == "" || namespace == "all" {
-		pods, err = s.clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
-	} else {
-		pods, err = s.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
-	}
-	
+// Health probes the API server's /version endpoint
+func (s *KubernetesService) Health(ctx context.Context) models.ClusterHealth {
+	startTime := time.Now()
+	health := models.ClusterHealth{CheckedAt: startTime.UTC()}
+
+	body, err := s.clientset.Discovery().RESTClient().Get().AbsPath("/version").Do(ctx).Raw()
+	health.LatencyMs = time.Since(startTime).Milliseconds()
 	if err != nil {
-		return nil, fmt.Errorf("failed to list pods: %v", err)
+		health.Status = models.ClusterStatusUnreachable
+		if apierrors.IsUnauthorized(err) || apierrors.IsForbidden(err) {
+			health.Status = models.ClusterStatusError
+		}
+		health.Error = err.Error()
+		return health
+	}
+
+	var info version.Info
+	if err := json.Unmarshal(body, &info); err != nil {
+		health.Status = models.ClusterStatusError
+		health.Error = fmt.Sprintf("unexpected /version response: %v", err)
+		return health
 	}
 
+	health.Status = models.ClusterStatusHealthy
+	health.Version = info.GitVersion
+	return health
+}
+
+// GetPods lists pods from the informer cache, or from the API server while the
+// cache is not available or opts need the API server; see ListOptions
+func (s *KubernetesService) GetPods(namespace string, opts ListOptions) ([]PodInfo, ListMeta, error) {
+	if namespace == "all" {
+		namespace = ""
+	}
+	selector, err := opts.validate()
+	if err != nil {
+		return nil, ListMeta{}, err
+	}
 
 	var podInfos []PodInfo
-	for _, pod := range pods.Items {
-		restarts := int32(0)
-		if len(pod.Status.ContainerStatuses) > 0 {
-			restarts = pod.Status.ContainerStatuses[0].RestartCount
+	var meta ListMeta
+	if cache := s.informerCache(); cache != nil && opts.cacheable() {
+		if podInfos, err = cache.listPods(namespace, selector); err != nil {
+			return nil, ListMeta{}, err
 		}
-
-		image := ""
-		if len(pod.Spec.Containers) > 0 {
-			image = pod.Spec.Containers[0].Image
+	} else {
+		pods, err := s.clientset.CoreV1().Pods(namespace).List(context.Background(), opts.listOptions())
+		if err != nil {
+			return nil, ListMeta{}, fmt.Errorf("failed to list pods: %w", err)
 		}
 
-		cpuRequest := "N/A"
-		memRequest := "N/A"
-		if len(pod.Spec.Containers) > 0 && pod.Spec.Containers[0].Resources.Requests != nil {
-			if cpu, ok := pod.Spec.Containers[0].Resources.Requests["cpu"]; ok {
-				cpuRequest = cpu.String()
-			}
-			if mem, ok := pod.Spec.Containers[0].Resources.Requests["memory"]; ok {
-				memRequest = mem.String()
-			}
+		podInfos = make([]PodInfo, 0, len(pods.Items))
+		for i := range pods.Items {
+			podInfos = append(podInfos, toPodInfo(&pods.Items[i]))
 		}
+		meta = toListMeta(&pods.ListMeta)
+	}
+
+	if err := sortList(podInfos, opts.Sort, podSorts); err != nil {
+		return nil, ListMeta{}, err
+	}
+	return podInfos, meta, nil
+}
 
-		podInfos = append(podInfos, PodInfo{
-			Name:       pod.Name,
-			Namespace:  pod.Namespace,
-			Status:     string(pod.Status.Phase),
-			Restarts:   restarts,
-			Age:        pod.CreationTimestamp.String(),
-			Node:       pod.Spec.NodeName,
-			IP:         pod.Status.PodIP,
-			Image:      image,
-			CPURequest: cpuRequest,
-			MemRequest: memRequest,
-		})
+// toPodInfo builds the pod list row. Status, Ready and Restarts match the
+// columns of `kubectl get pods`; Image lists every app container's image and the
+// requests are the pod's effective requests.
+func toPodInfo(pod *corev1.Pod) PodInfo {
+	cpuRequest := "N/A"
+	if request := podRequest(pod, corev1.ResourceCPU); !request.IsZero() {
+		cpuRequest = request.String()
+	}
+	memRequest := "N/A"
+	if request := podRequest(pod, corev1.ResourceMemory); !request.IsZero() {
+		memRequest = request.String()
 	}
 
-	return podInfos, nil
+	return PodInfo{
+		Name:       pod.Name,
+		Namespace:  pod.Namespace,
+		Status:     podStatus(pod),
+		Ready:      podReady(pod),
+		Restarts:   podRestarts(pod),
+		Age:        age(pod.CreationTimestamp),
+		CreatedAt:  pod.CreationTimestamp.UTC(),
+		Node:       pod.Spec.NodeName,
+		IP:         pod.Status.PodIP,
+		Image:      containerImages(&pod.Spec),
+		CPURequest: cpuRequest,
+		MemRequest: memRequest,
+	}
 }
 
-// GetDeployments retrieves all deployments
-func (s *KubernetesService) GetDeployments(namespace string) ([]DeploymentInfo, error) {
-	ctx := context.Background()
-	
-	var deployments []DeploymentInfo
-	
-	if namespace == "" || namespace == "all" {
+// GetDeployments lists deployments like GetPods lists pods
+func (s *KubernetesService) GetDeployments(namespace string, opts ListOptions) ([]DeploymentInfo, ListMeta, error) {
+	if namespace == "all" {
 		namespace = ""
 	}
-	
-	deployList, err := s.clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	selector, err := opts.validate()
 	if err != nil {
-		return nil, fmt.Errorf("failed to list deployments: %v", err)
+		return nil, ListMeta{}, err
 	}
 
-	for _, deploy := range deployList.Items {
-		image := ""
-		if len(deploy.Spec.Template.Spec.Containers) > 0 {
-			image = deploy.Spec.Template.Spec.Containers[0].Image
+	var deployments []DeploymentInfo
+	var meta ListMeta
+	if cache := s.informerCache(); cache != nil && opts.cacheable() {
+		if deployments, err = cache.listDeployments(namespace, selector); err != nil {
+			return nil, ListMeta{}, err
+		}
+	} else {
+		deployList, err := s.clientset.AppsV1().Deployments(namespace).List(context.Background(), opts.listOptions())
+		if err != nil {
+			return nil, ListMeta{}, fmt.Errorf("failed to list deployments: %w", err)
+		}
+
+		deployments = make([]DeploymentInfo, 0, len(deployList.Items))
+		for i := range deployList.Items {
+			deployments = append(deployments, toDeploymentInfo(&deployList.Items[i]))
 		}
+		meta = toListMeta(&deployList.ListMeta)
+	}
+
+	if err := sortList(deployments, opts.Sort, deploymentSorts); err != nil {
+		return nil, ListMeta{}, err
+	}
+	return deployments, meta, nil
+}
 
-		deployments = append(deployments, DeploymentInfo{
-			Name:              deploy.Name,
-			Namespace:         deploy.Namespace,
-			Replicas:          *deploy.Spec.Replicas,
-			ReadyReplicas:     deploy.Status.ReadyReplicas,
-			UpdatedReplicas:   deploy.Status.UpdatedReplicas,
-			AvailableReplicas: deploy.Status.AvailableReplicas,
-			Age:               deploy.CreationTimestamp.String(),
-			Image:             image,
-		})
+func toDeploymentInfo(deploy *appsv1.Deployment) DeploymentInfo {
+	image := ""
+	if len(deploy.Spec.Template.Spec.Containers) > 0 {
+		image = deploy.Spec.Template.Spec.Containers[0].Image
 	}
 
-	return deployments, nil
+	return DeploymentInfo{
+		Name:              deploy.Name,
+		Namespace:         deploy.Namespace,
+		Replicas:          replicasOf(deploy),
+		ReadyReplicas:     deploy.Status.ReadyReplicas,
+		UpdatedReplicas:   deploy.Status.UpdatedReplicas,
+		AvailableReplicas: deploy.Status.AvailableReplicas,
+		Age:               age(deploy.CreationTimestamp),
+		CreatedAt:         deploy.CreationTimestamp.UTC(),
+		Image:             image,
+	}
 }
 
-// GetServices retrieves all services
-func (s *KubernetesService) GetServices(namespace string) ([]ServiceInfo, error) {
-	ctx := context.Background()
-	
-	if namespace == "" || namespace == "all" {
+// GetServices lists services like GetPods lists pods
+func (s *KubernetesService) GetServices(namespace string, opts ListOptions) ([]ServiceInfo, ListMeta, error) {
+	if namespace == "all" {
 		namespace = ""
 	}
-	
-	serviceList, err := s.clientset.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{})
+	selector, err := opts.validate()
 	if err != nil {
-		return nil, fmt.Errorf("failed to list services: %v", err)
+		return nil, ListMeta{}, err
 	}
 
 	var services []ServiceInfo
-	for _, svc := range serviceList.Items {
-		ports := []string{}
-		for _, port := range svc.Spec.Ports {
-			ports = append(ports, fmt.Sprintf("%d:%d/%s", port.Port, port.NodePort, port.Protocol))
+	var meta ListMeta
+	if cache := s.informerCache(); cache != nil && opts.cacheable() {
+		if services, err = cache.listServices(namespace, selector); err != nil {
+			return nil, ListMeta{}, err
 		}
+	} else {
+		serviceList, err := s.clientset.CoreV1().Services(namespace).List(context.Background(), opts.listOptions())
+		if err != nil {
+			return nil, ListMeta{}, fmt.Errorf("failed to list services: %w", err)
+		}
+
+		services = make([]ServiceInfo, 0, len(serviceList.Items))
+		for i := range serviceList.Items {
+			services = append(services, toServiceInfo(&serviceList.Items[i]))
+		}
+		meta = toListMeta(&serviceList.ListMeta)
+	}
 
-		services = append(services, ServiceInfo{
-			Name:      svc.Name,
-			Namespace: svc.Namespace,
-			Type:      string(svc.Spec.Type),
-			ClusterIP: svc.Spec.ClusterIP,
-			Ports:     ports,
-			Age:       svc.CreationTimestamp.String(),
-		})
+	if err := sortList(services, opts.Sort, serviceSorts); err != nil {
+		return nil, ListMeta{}, err
+	}
+	return services, meta, nil
+}
+
+func toServiceInfo(svc *corev1.Service) ServiceInfo {
+	ports := []string{}
+	for _, port := range svc.Spec.Ports {
+		ports = append(ports, fmt.Sprintf("%d:%d/%s", port.Port, port.NodePort, port.Protocol))
 	}
 
-	return services, nil
+	return ServiceInfo{
+		Name:      svc.Name,
+		Namespace: svc.Namespace,
+		Type:      string(svc.Spec.Type),
+		ClusterIP: svc.Spec.ClusterIP,
+		Ports:     ports,
+		Age:       age(svc.CreationTimestamp),
+		CreatedAt: svc.CreationTimestamp.UTC(),
+	}
 }
 
 // GetNamespaces retrieves all namespaces
 func (s *KubernetesService) GetNamespaces() ([]NamespaceInfo, error) {
-	ctx := context.Background()
-	
-	nsList, err := s.clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if cache := s.informerCache(); cache != nil {
+		return cache.listNamespaces()
+	}
+
+	nsList, err := s.clientset.CoreV1().Namespaces().List(context.Background(), metav1.ListOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list namespaces: %v", err)
 	}
 
-	var namespaces []NamespaceInfo
-	for _, ns := range nsList.Items {
-		namespaces = append(namespaces, NamespaceInfo{
-			Name:   ns.Name,
-			Status: string(ns.Status.Phase),
-			Age:    ns.CreationTimestamp.String(),
-		})
+	namespaces := make([]NamespaceInfo, 0, len(nsList.Items))
+	for i := range nsList.Items {
+		namespaces = append(namespaces, toNamespaceInfo(&nsList.Items[i]))
 	}
-
 	return namespaces, nil
 }
 
-// GetPodLogs retrieves logs from a specific pod
-func (s *KubernetesService) GetPodLogs(namespace, podName string, lines int64) (string, error) {
-	ctx := context.Background()
-	
-	podLogOpts := corev1.PodLogOptions{
-		TailLines: &lines,
+func toNamespaceInfo(ns *corev1.Namespace) NamespaceInfo {
+	return NamespaceInfo{
+		Name:      ns.Name,
+		Status:    string(ns.Status.Phase),
+		Age:       age(ns.CreationTimestamp),
+		CreatedAt: ns.CreationTimestamp.UTC(),
+	}
+}
+
+// informerCache returns the cluster's informer cache, starting it on first use,
+// or nil while it cannot serve lists. A cache that failed to sync is rebuilt
+// after cacheRetryInterval. After Close it is always nil, so a request racing
+// with the removal of the cluster does not start informers nobody stops.
+func (s *KubernetesService) informerCache() *kubernetesCache {
+	s.cacheMu.Lock()
+	if s.closed {
+		s.cacheMu.Unlock()
+		return nil
+	}
+	if s.cache != nil && s.cache.failed() && time.Since(s.cache.started) >= cacheRetryInterval {
+		log.Printf("⚠️ Kubernetes informer cache failed (%v), retrying", s.cache.syncErr)
+		s.cache.close()
+		s.cache = nil
+	}
+	if s.cache == nil {
+		s.cache = newKubernetesCache(s.clientset)
+		s.cache.start()
+	}
+	cache := s.cache
+	s.cacheMu.Unlock()
+
+	if !cache.ready() {
+		return nil
+	}
+	return cache
+}
+
+// Watch streams changes to the cached pods, deployments, services and
+// namespaces; namespace limits the namespaced kinds to one namespace. Stop the
+// watch with the returned function.
+func (s *KubernetesService) Watch(namespace string) (<-chan KubernetesWatchEvent, func(), error) {
+	if namespace == "all" {
+		namespace = ""
+	}
+	cache := s.informerCache()
+	if cache == nil {
+		return nil, nil, ErrWatchUnavailable
+	}
+
+	events, cancel := cache.watch(namespace)
+	return events, cancel, nil
+}
+
+// KubernetesSnapshot is the state a watch starts from
+type KubernetesSnapshot struct {
+	Pods        []PodInfo        `json:"pods"`
+	Deployments []DeploymentInfo `json:"deployments"`
+	Services    []ServiceInfo    `json:"services"`
+	Namespaces  []NamespaceInfo  `json:"namespaces"`
+}
+
+// Snapshot lists everything Watch reports on, for namespace
+func (s *KubernetesService) Snapshot(namespace string) (*KubernetesSnapshot, error) {
+	var snapshot KubernetesSnapshot
+	var err error
+
+	if snapshot.Pods, _, err = s.GetPods(namespace, ListOptions{}); err != nil {
+		return nil, err
+	}
+	if snapshot.Deployments, _, err = s.GetDeployments(namespace, ListOptions{}); err != nil {
+		return nil, err
+	}
+	if snapshot.Services, _, err = s.GetServices(namespace, ListOptions{}); err != nil {
+		return nil, err
+	}
+	if snapshot.Namespaces, err = s.GetNamespaces(); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// Close stops the informer cache and ends every watch. Lists are served from
+// the API server afterwards.
+func (s *KubernetesService) Close() {
+	s.cacheMu.Lock()
+	defer s.cacheMu.Unlock()
+	s.closed = true
+	if s.cache != nil {
+		s.cache.close()
+		s.cache = nil
+	}
+}
+
+// maxPodLogBytes caps a non-streaming log fetch; use StreamPodLogs for more
+const maxPodLogBytes = 10 << 20
+
+// PodLogOptions selects which logs GetPodLogs and StreamPodLogs return
+type PodLogOptions struct {
+	Container    string
+	Previous     bool
+	TailLines    *int64
+	SinceSeconds *int64
+	SinceTime    *time.Time
+	Timestamps   bool
+}
+
+func (o PodLogOptions) toKubernetes(follow bool) *corev1.PodLogOptions {
+	opts := &corev1.PodLogOptions{
+		Container:    o.Container,
+		Previous:     o.Previous,
+		TailLines:    o.TailLines,
+		SinceSeconds: o.SinceSeconds,
+		Timestamps:   o.Timestamps,
+		Follow:       follow,
 	}
-	
-	req := s.clientset.CoreV1().Pods(namespace).GetLogs(podName, &podLogOpts)
+	if o.SinceTime != nil {
+		sinceTime := metav1.NewTime(*o.SinceTime)
+		opts.SinceTime = &sinceTime
+	}
+	return opts
+}
+
+// GetPodLogs retrieves logs from a specific pod, up to maxPodLogBytes
+func (s *KubernetesService) GetPodLogs(ctx context.Context, namespace, podName string, opts PodLogOptions) (string, error) {
+	req := s.clientset.CoreV1().Pods(namespace).GetLogs(podName, opts.toKubernetes(false))
 	podLogs, err := req.Stream(ctx)
 	if err != nil {
 		return "", fmt.Errorf("failed to get pod logs: %v", err)
 	}
 	defer podLogs.Close()
 
-	buf := make([]byte, 2000)
-	numBytes, err := podLogs.Read(buf)
+	logs, err := io.ReadAll(io.LimitReader(podLogs, maxPodLogBytes))
 	if err != nil {
 		return "", fmt.Errorf("failed to read pod logs: %v", err)
 	}
-	
-	return string(buf[:numBytes]), nil
-}
\ No newline at end of file
+
+	return string(logs), nil
+}
+
+// StreamPodLogs follows a pod's logs until ctx is cancelled or the container
+// exits. The caller must close the returned stream.
+func (s *KubernetesService) StreamPodLogs(ctx context.Context, namespace, podName string, opts PodLogOptions) (io.ReadCloser, error) {
+	stream, err := s.clientset.CoreV1().Pods(namespace).GetLogs(podName, opts.toKubernetes(true)).Stream(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stream pod logs: %w", err)
+	}
+	return stream, nil
+}
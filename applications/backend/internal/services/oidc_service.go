@@ -0,0 +1,360 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/repositories"
+)
+
+var ErrOIDCDisabled = errors.New("OIDC login is not configured")
+
+// OIDCConfig describes the identity provider and how its group claims map onto
+// CloudDeck roles and organizations
+type OIDCConfig struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	GroupsClaim  string
+	// RoleMappings maps an IdP group to a CloudDeck role; the highest match wins
+	RoleMappings map[string]string
+	// OrgMappings maps an IdP group to an organization slug; the first match wins
+	OrgMappings map[string]string
+	DefaultRole string
+	DefaultOrg  string
+	// LinkByEmail signs a first-time IdP user into the one local account with
+	// the same email, if the provider marks the email verified, instead of
+	// creating a new user. Only enable it for a provider trusted to verify
+	// every address, since it hands that account to whoever holds the email.
+	LinkByEmail bool
+}
+
+// OIDCConfigFromEnv reads the OIDC_* variables. It returns nil when
+// OIDC_ISSUER_URL is unset, which disables single sign-on.
+//
+//	OIDC_ROLE_MAPPING=clouddeck-admins=admin,clouddeck-devs=developer
+//	OIDC_ORG_MAPPING=team-acme=acme
+//	OIDC_LINK_BY_EMAIL=true
+func OIDCConfigFromEnv() (*OIDCConfig, error) {
+	issuer := os.Getenv("OIDC_ISSUER_URL")
+	if issuer == "" {
+		return nil, nil
+	}
+
+	config := &OIDCConfig{
+		IssuerURL:    issuer,
+		ClientID:     os.Getenv("OIDC_CLIENT_ID"),
+		ClientSecret: os.Getenv("OIDC_CLIENT_SECRET"),
+		RedirectURL:  os.Getenv("OIDC_REDIRECT_URL"),
+		GroupsClaim:  getEnvOrDefault("OIDC_GROUPS_CLAIM", "groups"),
+		DefaultRole:  getEnvOrDefault("OIDC_DEFAULT_ROLE", models.RoleNone),
+		DefaultOrg:   getEnvOrDefault("OIDC_DEFAULT_ORG", models.DefaultOrganizationSlug),
+	}
+	if config.ClientID == "" || config.RedirectURL == "" {
+		return nil, errors.New("OIDC_CLIENT_ID and OIDC_REDIRECT_URL are required when OIDC_ISSUER_URL is set")
+	}
+
+	var err error
+	if config.RoleMappings, err = parseGroupMapping(os.Getenv("OIDC_ROLE_MAPPING")); err != nil {
+		return nil, fmt.Errorf("invalid OIDC_ROLE_MAPPING: %w", err)
+	}
+	for group, role := range config.RoleMappings {
+		if !models.ValidRole(role) {
+			return nil, fmt.Errorf("invalid OIDC_ROLE_MAPPING: unknown role %q for group %q", role, group)
+		}
+	}
+	if !models.ValidRole(config.DefaultRole) {
+		return nil, fmt.Errorf("invalid OIDC_DEFAULT_ROLE %q", config.DefaultRole)
+	}
+	if config.OrgMappings, err = parseGroupMapping(os.Getenv("OIDC_ORG_MAPPING")); err != nil {
+		return nil, fmt.Errorf("invalid OIDC_ORG_MAPPING: %w", err)
+	}
+	if value := os.Getenv("OIDC_LINK_BY_EMAIL"); value != "" {
+		if config.LinkByEmail, err = strconv.ParseBool(value); err != nil {
+			return nil, fmt.Errorf("invalid OIDC_LINK_BY_EMAIL %q", value)
+		}
+	}
+
+	return config, nil
+}
+
+// OIDCService implements the authorization code flow (with PKCE) against an
+// OIDC provider and provisions CloudDeck users from the verified ID token
+type OIDCService struct {
+	config      *OIDCConfig
+	userRepo    *repositories.UserRepository
+	orgRepo     *repositories.OrganizationRepository
+	authService *AuthService
+
+	mu       sync.Mutex
+	provider *oidc.Provider
+}
+
+func NewOIDCService(config *OIDCConfig, userRepo *repositories.UserRepository, orgRepo *repositories.OrganizationRepository, authService *AuthService) *OIDCService {
+	return &OIDCService{
+		config:      config,
+		userRepo:    userRepo,
+		orgRepo:     orgRepo,
+		authService: authService,
+	}
+}
+
+func (s *OIDCService) Enabled() bool {
+	return s != nil && s.config != nil
+}
+
+// AuthCodeURL returns the provider URL the browser is sent to
+func (s *OIDCService) AuthCodeURL(ctx context.Context, state, nonce, codeVerifier string) (string, error) {
+	oauthConfig, _, err := s.clients(ctx)
+	if err != nil {
+		return "", err
+	}
+	return oauthConfig.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(codeVerifier)), nil
+}
+
+// Exchange redeems an authorization code, verifies the ID token and signs the
+// matching CloudDeck user in, creating them on first login
+func (s *OIDCService) Exchange(ctx context.Context, code, nonce, codeVerifier string) (*models.TokenPair, error) {
+	oauthConfig, idTokenVerifier, err := s.clients(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := oauthConfig.Exchange(ctx, code, oauth2.VerifierOption(codeVerifier))
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, errors.New("provider response did not include an id_token")
+	}
+
+	idToken, err := idTokenVerifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id_token: %w", err)
+	}
+	if idToken.Nonce != nonce {
+		return nil, errors.New("invalid id_token: nonce mismatch")
+	}
+
+	var claims map[string]interface{}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("invalid id_token claims: %w", err)
+	}
+
+	user, err := s.provisionUser(idToken.Subject, claims)
+	if err != nil {
+		return nil, err
+	}
+	if !user.Active {
+		return nil, ErrInvalidCredentials
+	}
+
+	return s.authService.CompleteLogin(user)
+}
+
+// provisionUser finds or creates the user for an IdP subject and syncs their
+// role and organization from the group claims on every login
+func (s *OIDCService) provisionUser(subject string, claims map[string]interface{}) (*models.User, error) {
+	groups := claimStrings(claims[s.config.GroupsClaim])
+	role := s.mapRole(groups)
+
+	org, err := s.mapOrganization(groups)
+	if err != nil {
+		return nil, err
+	}
+
+	externalID := s.config.IssuerURL + "|" + subject
+	email, _ := claims["email"].(string)
+	name, _ := claims["name"].(string)
+
+	user, err := s.userRepo.FindByExternalID(models.AuthProviderOIDC, externalID)
+	if err != nil {
+		if user, err = s.linkableAccount(claims); err != nil {
+			return nil, err
+		}
+		if user != nil {
+			log.Printf("🔗 Linked OIDC identity %q to local user %q", externalID, user.Username)
+			user.AuthProvider = models.AuthProviderOIDC
+			user.ExternalID = externalID
+		}
+	}
+	if user != nil {
+		user.OrganizationID = org.ID
+		user.Role = role
+		user.Email = email
+		user.Name = name
+		return user, nil
+	}
+
+	username, err := s.availableUsername(subject, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	user = &models.User{
+		Username:     username,
+		Email:        email,
+		Name:         name,
+		AuthProvider: models.AuthProviderOIDC,
+		ExternalID:   externalID,
+		Role:         role,
+		Active:       true,
+	}
+	if err := s.userRepo.ForOrganization(org.ID).Create(user); err != nil {
+		return nil, err
+	}
+
+	log.Printf("✅ Provisioned OIDC user %q in organization %q as %s", username, org.Slug, role)
+	return user, nil
+}
+
+// linkableAccount returns the local account a first-time IdP user is linked to
+// when LinkByEmail is set, or nil to provision a new user. The email must be
+// verified by the provider and belong to exactly one local account.
+func (s *OIDCService) linkableAccount(claims map[string]interface{}) (*models.User, error) {
+	email, _ := claims["email"].(string)
+	verified, _ := claims["email_verified"].(bool)
+	if !s.config.LinkByEmail || email == "" || !verified {
+		return nil, nil
+	}
+
+	users, err := s.userRepo.FindByEmail(models.AuthProviderLocal, email)
+	if err != nil {
+		return nil, err
+	}
+	if len(users) != 1 {
+		return nil, nil
+	}
+	return &users[0], nil
+}
+
+// availableUsername prefers preferred_username, then email; a name already held
+// by a local account gets a suffix derived from the subject instead of being linked
+func (s *OIDCService) availableUsername(subject string, claims map[string]interface{}) (string, error) {
+	username, _ := claims["preferred_username"].(string)
+	if username == "" {
+		username, _ = claims["email"].(string)
+	}
+	if username == "" {
+		username = subject
+	}
+
+	taken, err := s.userRepo.ExistsByUsername(username)
+	if err != nil || !taken {
+		return username, err
+	}
+
+	sum := sha256.Sum256([]byte(subject))
+	return username + "-" + hex.EncodeToString(sum[:])[:6], nil
+}
+
+func (s *OIDCService) mapRole(groups []string) string {
+	role := s.config.DefaultRole
+	for _, group := range groups {
+		if mapped, ok := s.config.RoleMappings[group]; ok {
+			role = models.HigherRole(role, mapped)
+		}
+	}
+	return role
+}
+
+func (s *OIDCService) mapOrganization(groups []string) (*models.Organization, error) {
+	slug := s.config.DefaultOrg
+	for _, group := range groups {
+		if mapped, ok := s.config.OrgMappings[group]; ok {
+			slug = mapped
+			break
+		}
+	}
+
+	org, err := s.orgRepo.FindBySlug(slug)
+	if err != nil {
+		return nil, fmt.Errorf("organization %q for OIDC login: %w", slug, err)
+	}
+	return org, nil
+}
+
+// clients discovers the provider on first use so the server can start before the IdP is reachable
+func (s *OIDCService) clients(ctx context.Context) (*oauth2.Config, *oidc.IDTokenVerifier, error) {
+	if !s.Enabled() {
+		return nil, nil, ErrOIDCDisabled
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.provider == nil {
+		provider, err := oidc.NewProvider(ctx, s.config.IssuerURL)
+		if err != nil {
+			return nil, nil, fmt.Errorf("OIDC discovery failed: %w", err)
+		}
+		s.provider = provider
+	}
+
+	oauthConfig := &oauth2.Config{
+		ClientID:     s.config.ClientID,
+		ClientSecret: s.config.ClientSecret,
+		RedirectURL:  s.config.RedirectURL,
+		Endpoint:     s.provider.Endpoint(),
+		Scopes:       []string{oidc.ScopeOpenID, "profile", "email", "groups"},
+	}
+	verifier := s.provider.Verifier(&oidc.Config{ClientID: s.config.ClientID})
+	return oauthConfig, verifier, nil
+}
+
+// parseGroupMapping parses "group=value,group=value"
+func parseGroupMapping(value string) (map[string]string, error) {
+	mapping := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		group, target, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(group) == "" || strings.TrimSpace(target) == "" {
+			return nil, fmt.Errorf("expected group=value, got %q", pair)
+		}
+		mapping[strings.TrimSpace(group)] = strings.TrimSpace(target)
+	}
+	return mapping, nil
+}
+
+// claimStrings accepts a claim given either as a list or a single string
+func claimStrings(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
+
+func getEnvOrDefault(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
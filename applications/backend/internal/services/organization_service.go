@@ -0,0 +1,73 @@
+package services
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/repositories"
+)
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+type OrganizationService struct {
+	repo        *repositories.OrganizationRepository
+	authService *AuthService
+}
+
+func NewOrganizationService(repo *repositories.OrganizationRepository, authService *AuthService) *OrganizationService {
+	return &OrganizationService{
+		repo:        repo,
+		authService: authService,
+	}
+}
+
+// CreateOrganization creates a tenant together with its first admin account
+func (s *OrganizationService) CreateOrganization(req *models.CreateOrganizationRequest) (*models.Organization, error) {
+	slug := strings.ToLower(strings.TrimSpace(req.Slug))
+	if !slugPattern.MatchString(slug) {
+		return nil, errors.New("slug may only contain lowercase letters, digits and dashes")
+	}
+
+	exists, err := s.repo.ExistsBySlug(slug)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, errors.New("organization with this slug already exists")
+	}
+
+	admin, err := s.authService.newLocalUser(&models.CreateUserRequest{
+		Username: req.AdminUsername,
+		Password: req.AdminPassword,
+		Role:     models.RoleAdmin,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	org := &models.Organization{
+		Name: strings.TrimSpace(req.Name),
+		Slug: slug,
+	}
+	if err := s.repo.CreateWithAdmin(org, admin); err != nil {
+		return nil, err
+	}
+
+	return org, nil
+}
+
+func (s *OrganizationService) GetAllOrganizations() ([]models.Organization, error) {
+	return s.repo.FindAll()
+}
+
+func (s *OrganizationService) GetOrganizationByID(id uint) (*models.Organization, error) {
+	return s.repo.FindByID(id)
+}
+
+// IsDefaultOrganization reports whether orgID is the instance operators' organization
+func (s *OrganizationService) IsDefaultOrganization(orgID uint) bool {
+	org, err := s.repo.FindByID(orgID)
+	return err == nil && org.Slug == models.DefaultOrganizationSlug
+}
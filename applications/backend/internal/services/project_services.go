@@ -16,12 +16,14 @@ func NewProjectService(repo *repositories.ProjectRepository) *ProjectService {
 	return &ProjectService{repo: repo}
 }
 
-func (s *ProjectService) CreateProject(req *models.ProjectCreateInput) (*models.Project, error) {
+func (s *ProjectService) CreateProject(orgID uint, req *models.ProjectCreateInput) (*models.Project, error) {
 	if strings.TrimSpace(req.Name) == "" {
 		return nil, errors.New("name cannot be empty")
 	}
 
-	exists, err := s.repo.ExistsByName(req.Name)
+	repo := s.repo.ForOrganization(orgID)
+
+	exists, err := repo.ExistsByName(req.Name)
 	if err != nil {
 		return nil, err
 	}
@@ -44,28 +46,41 @@ func (s *ProjectService) CreateProject(req *models.ProjectCreateInput) (*models.
 		project.Color = "#1976d2"
 	}
 
-	if err := s.repo.Create(project); err != nil {
+	if err := repo.Create(project); err != nil {
 		return nil, err
 	}
 
 	return project, nil
 }
 
-func (s *ProjectService) GetAllProjects() ([]models.Project, error) {
-	return s.repo.FindAll()
+func (s *ProjectService) GetAllProjects(orgID uint) ([]models.Project, error) {
+	return s.repo.ForOrganization(orgID).FindAll()
+}
+
+func (s *ProjectService) GetProjectsByIDs(orgID uint, ids []uint) ([]models.Project, error) {
+	return s.repo.ForOrganization(orgID).FindByIDs(ids)
 }
 
-func (s *ProjectService) GetProjectByID(id uint) (*models.Project, error) {
-	return s.repo.FindByID(id)
+func (s *ProjectService) GetProjectByID(orgID uint, id uint) (*models.Project, error) {
+	return s.repo.ForOrganization(orgID).FindByID(id)
 }
 
-func (s *ProjectService) UpdateProject(id uint, req *models.ProjectUpdateInput) (*models.Project, error) {
-	project, err := s.repo.FindByID(id)
+func (s *ProjectService) UpdateProject(orgID uint, id uint, req *models.ProjectUpdateInput) (*models.Project, error) {
+	repo := s.repo.ForOrganization(orgID)
+
+	project, err := repo.FindByID(id)
 	if err != nil {
 		return nil, err
 	}
 
-	if req.Name != "" {
+	if req.Name != "" && strings.TrimSpace(req.Name) != project.Name {
+		exists, err := repo.ExistsByName(strings.TrimSpace(req.Name))
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, errors.New("project with this name already exists")
+		}
 		project.Name = strings.TrimSpace(req.Name)
 	}
 	if req.Description != "" {
@@ -81,17 +96,17 @@ func (s *ProjectService) UpdateProject(id uint, req *models.ProjectUpdateInput)
 		project.Color = req.Color
 	}
 
-	if err := s.repo.Update(project); err != nil {
+	if err := repo.Update(project); err != nil {
 		return nil, err
 	}
 
 	return project, nil
 }
 
-func (s *ProjectService) DeleteProject(id uint) error {
-	return s.repo.Delete(id)
+func (s *ProjectService) DeleteProject(orgID uint, id uint) error {
+	return s.repo.ForOrganization(orgID).Delete(id)
 }
 
-func (s *ProjectService) GetStats() (map[string]interface{}, error) {
-	return s.repo.GetStats()
+func (s *ProjectService) GetStats(orgID uint) (map[string]interface{}, error) {
+	return s.repo.ForOrganization(orgID).GetStats()
 }
@@ -0,0 +1,514 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/docker"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
+)
+
+// Labels Docker Compose puts on the containers it creates
+const (
+	composeProjectLabel     = "com.docker.compose.project"
+	composeServiceLabel     = "com.docker.compose.service"
+	composeWorkingDirLabel  = "com.docker.compose.project.working_dir"
+	composeConfigFilesLabel = "com.docker.compose.project.config_files"
+	composeDependsOnLabel   = "com.docker.compose.depends_on"
+	composeOneoffLabel      = "com.docker.compose.oneoff"
+)
+
+const (
+	StackActionRestart  = "restart"
+	StackActionRecreate = "recreate"
+)
+
+// ErrStackNotFound and ErrStackServiceNotFound are returned for a compose
+// project or service without containers on the host
+var (
+	ErrStackNotFound        = errors.New("stack not found")
+	ErrStackServiceNotFound = errors.New("stack service not found")
+)
+
+// GetStacks groups the host's containers into compose projects by their
+// labels. Containers started with `docker compose run` are left out.
+func (s *ContainerService) GetStacks(ctx context.Context) ([]models.Stack, error) {
+	containers, err := s.GetAllContainers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byProject := make(map[string][]models.ContainerStats)
+	for _, container := range containers {
+		if isStackMember(container.Labels) {
+			project := container.Labels[composeProjectLabel]
+			byProject[project] = append(byProject[project], container)
+		}
+	}
+
+	stacks := make([]models.Stack, 0, len(byProject))
+	for project, members := range byProject {
+		stacks = append(stacks, buildStack(project, members))
+	}
+	sort.Slice(stacks, func(i, j int) bool { return stacks[i].Name < stacks[j].Name })
+	return stacks, nil
+}
+
+func (s *ContainerService) GetStack(ctx context.Context, name string) (*models.Stack, error) {
+	stacks, err := s.GetStacks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range stacks {
+		if stacks[i].Name == name {
+			return &stacks[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrStackNotFound, name)
+}
+
+// isStackMember reports whether a container belongs to a compose project's
+// services, as opposed to a one-off `docker compose run` container
+func isStackMember(labels map[string]string) bool {
+	return labels[composeProjectLabel] != "" && labels[composeServiceLabel] != "" && labels[composeOneoffLabel] != "True"
+}
+
+// stackForAction is GetStack without the containers' stats, which an action
+// does not need; it lists only the project's containers
+func (s *ContainerService) stackForAction(ctx context.Context, name string) (*models.Stack, error) {
+	containers, err := s.engine.ListContainers(ctx, true, composeProjectLabel+"="+name)
+	if err != nil {
+		return nil, err
+	}
+
+	var members []models.ContainerStats
+	for _, container := range containers {
+		if container.Labels[composeProjectLabel] == name && isStackMember(container.Labels) {
+			members = append(members, toContainerStats(container))
+		}
+	}
+	if len(members) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrStackNotFound, name)
+	}
+
+	stack := buildStack(name, members)
+	return &stack, nil
+}
+
+func buildStack(project string, members []models.ContainerStats) models.Stack {
+	stack := models.Stack{Name: project}
+
+	byService := make(map[string]*models.StackService)
+	for _, container := range members {
+		labels := container.Labels
+		if stack.WorkingDir == "" {
+			stack.WorkingDir = labels[composeWorkingDirLabel]
+		}
+		if stack.ConfigFiles == nil && labels[composeConfigFilesLabel] != "" {
+			stack.ConfigFiles = strings.Split(labels[composeConfigFilesLabel], ",")
+		}
+
+		name := labels[composeServiceLabel]
+		service, ok := byService[name]
+		if !ok {
+			service = &models.StackService{
+				Name:      name,
+				Image:     container.Image,
+				DependsOn: parseDependsOn(labels[composeDependsOnLabel]),
+			}
+			byService[name] = service
+		}
+		service.Replicas++
+		if container.Status == "running" {
+			service.Running++
+		}
+		service.CPUPercent += container.CPUPercent
+		service.MemoryUsage += container.MemoryUsage
+		service.Containers = append(service.Containers, container)
+	}
+
+	for _, name := range dependencyOrder(byService) {
+		service := byService[name]
+		sort.Slice(service.Containers, func(i, j int) bool { return service.Containers[i].Name < service.Containers[j].Name })
+		service.Health = serviceHealth(service)
+		stack.Services = append(stack.Services, *service)
+	}
+	stack.Health = stackHealth(stack.Services)
+	return stack
+}
+
+// parseDependsOn reads the depends_on label, e.g. "db:service_healthy:false,cache:service_started:true"
+func parseDependsOn(label string) []string {
+	var dependencies []string
+	for _, entry := range strings.Split(label, ",") {
+		if name, _, _ := strings.Cut(strings.TrimSpace(entry), ":"); name != "" {
+			dependencies = append(dependencies, name)
+		}
+	}
+	return dependencies
+}
+
+// dependencyOrder sorts services so each comes after the services it depends
+// on, by name otherwise. Services in a dependency cycle are appended by name.
+func dependencyOrder(services map[string]*models.StackService) []string {
+	names := make([]string, 0, len(services))
+	for name := range services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	placed := make(map[string]bool, len(names))
+	order := make([]string, 0, len(names))
+	for len(order) < len(names) {
+		progressed := false
+		for _, name := range names {
+			if placed[name] {
+				continue
+			}
+			ready := true
+			for _, dependency := range services[name].DependsOn {
+				if _, known := services[dependency]; known && !placed[dependency] && dependency != name {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				placed[name] = true
+				order = append(order, name)
+				progressed = true
+			}
+		}
+		if !progressed {
+			for _, name := range names {
+				if !placed[name] {
+					placed[name] = true
+					order = append(order, name)
+				}
+			}
+		}
+	}
+	return order
+}
+
+func serviceHealth(service *models.StackService) string {
+	completed, unhealthy, starting := 0, 0, 0
+	for _, container := range service.Containers {
+		if container.ExitCode != nil && *container.ExitCode == 0 {
+			completed++
+		}
+		switch container.Health {
+		case "unhealthy":
+			unhealthy++
+		case "starting":
+			starting++
+		}
+	}
+
+	switch {
+	case completed == service.Replicas:
+		return models.StackHealthCompleted
+	case service.Running == 0:
+		return models.StackHealthStopped
+	case unhealthy > 0:
+		return models.StackHealthUnhealthy
+	case service.Running < service.Replicas:
+		return models.StackHealthDegraded
+	case starting > 0:
+		return models.StackHealthStarting
+	}
+	return models.StackHealthHealthy
+}
+
+// stackHealth is the worst health among the services that are expected to run;
+// a stack whose services have all stopped is "stopped" rather than "degraded"
+func stackHealth(services []models.StackService) string {
+	rank := map[string]int{
+		models.StackHealthHealthy:   0,
+		models.StackHealthStarting:  1,
+		models.StackHealthDegraded:  2,
+		models.StackHealthUnhealthy: 3,
+	}
+
+	health := models.StackHealthHealthy
+	active, stopped := 0, 0
+	for _, service := range services {
+		switch service.Health {
+		case models.StackHealthCompleted:
+			continue
+		case models.StackHealthStopped:
+			stopped++
+			continue
+		}
+		active++
+		if rank[service.Health] > rank[health] {
+			health = service.Health
+		}
+	}
+
+	switch {
+	case active == 0 && stopped > 0:
+		return models.StackHealthStopped
+	case stopped > 0 && rank[health] < rank[models.StackHealthDegraded]:
+		return models.StackHealthDegraded
+	}
+	return health
+}
+
+// RunStackAction restarts or recreates every container of a stack, or of one
+// of its services when service is set, in dependency order. It stops at the
+// first container that fails and reports what was done up to that point.
+func (s *ContainerService) RunStackAction(ctx context.Context, stackName string, serviceName string, action string, req *models.StackActionRequest) (*models.StackActionResult, error) {
+	if action != StackActionRestart && action != StackActionRecreate {
+		return nil, fmt.Errorf("%w: unknown action %q", ErrInvalidContainerRequest, action)
+	}
+
+	stack, err := s.stackForAction(ctx, stackName)
+	if err != nil {
+		return nil, err
+	}
+
+	services := stack.Services
+	if serviceName != "" {
+		services = nil
+		for _, service := range stack.Services {
+			if service.Name == serviceName {
+				services = append(services, service)
+			}
+		}
+		if len(services) == 0 {
+			return nil, fmt.Errorf("%w: %s/%s", ErrStackServiceNotFound, stackName, serviceName)
+		}
+	}
+
+	result := &models.StackActionResult{
+		Stack:      stack.Name,
+		Service:    serviceName,
+		Action:     action,
+		Containers: []models.StackContainerResult{},
+	}
+	pulled := make(map[string]bool)
+
+	for _, service := range services {
+		if action == StackActionRecreate && req.Pull && pullableImage(service.Image) && !pulled[service.Image] {
+			if err := s.engine.PullImage(ctx, service.Image); err != nil {
+				return result, err
+			}
+			pulled[service.Image] = true
+		}
+
+		for _, container := range service.Containers {
+			if err := ctx.Err(); err != nil {
+				return result, err
+			}
+
+			entry := models.StackContainerResult{
+				Service:     service.Name,
+				Name:        container.Name,
+				ContainerID: container.ContainerID,
+				StateBefore: container.Status,
+			}
+			// Finish (or roll back) a container even if the client goes away
+			// so it is never left half-replaced
+			opCtx := context.WithoutCancel(ctx)
+			if action == StackActionRestart {
+				err = s.engine.RestartContainer(opCtx, container.ContainerID, req.Timeout)
+			} else {
+				entry.NewContainerID, err = s.recreateContainer(opCtx, container.ContainerID, req.Timeout)
+			}
+
+			stateID := container.ContainerID
+			if entry.NewContainerID != "" {
+				stateID = entry.NewContainerID
+			}
+			if details, inspectErr := s.engine.InspectContainer(opCtx, stateID); inspectErr == nil {
+				entry.StateAfter = details.State.Status
+			}
+			if err != nil {
+				entry.Error = err.Error()
+				result.Containers = append(result.Containers, entry)
+				return result, fmt.Errorf("failed to %s %s: %w", action, container.Name, err)
+			}
+			result.Containers = append(result.Containers, entry)
+		}
+	}
+	return result, nil
+}
+
+// pullableImage reports whether image names a repository that can be pulled.
+// Once the tag a container was created from moves to another image, the
+// container list shows the image's ID instead, which cannot be pulled.
+func pullableImage(image string) bool {
+	if image == "" || strings.HasPrefix(image, "sha256:") {
+		return false
+	}
+	if len(image) != 64 {
+		return true
+	}
+	for _, r := range image {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return true
+		}
+	}
+	return false
+}
+
+// recreateContainer replaces a container with a new one created from the same
+// settings and the image its tag now points to, the way `docker compose up
+// --force-recreate` does. The old container is renamed out of the way and
+// only removed once the new one has started; on failure it is put back.
+// Anonymous volumes are carried over so their data survives.
+func (s *ContainerService) recreateContainer(ctx context.Context, id string, timeout *int) (string, error) {
+	spec, err := s.engine.InspectContainerSpec(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	name := strings.TrimPrefix(spec.Name, "/")
+
+	config := make(map[string]json.RawMessage, len(spec.Config))
+	for key, value := range spec.Config {
+		config[key] = value
+	}
+	// The daemon defaults the hostname to the short ID; let the new container get its own
+	var hostname string
+	if json.Unmarshal(config["Hostname"], &hostname) == nil && hostname == shortContainerID(spec.ID) {
+		delete(config, "Hostname")
+	}
+
+	hostConfig, err := preserveAnonymousVolumes(spec)
+	if err != nil {
+		return "", err
+	}
+
+	var networkMode string
+	json.Unmarshal(hostConfig["NetworkMode"], &networkMode)
+	primary := make(map[string]docker.EndpointConfig)
+	secondary := make(map[string]docker.EndpointConfig)
+	for network, endpoint := range spec.NetworkSettings.Networks {
+		// Drop the old container's ID from its aliases
+		aliases := endpoint.Aliases[:0:0]
+		for _, alias := range endpoint.Aliases {
+			if alias != shortContainerID(spec.ID) {
+				aliases = append(aliases, alias)
+			}
+		}
+		endpoint.Aliases = aliases
+
+		if network == networkMode || (len(primary) == 0 && networkMode == "default" && network == "bridge") {
+			primary[network] = endpoint
+		} else {
+			secondary[network] = endpoint
+		}
+	}
+
+	wasRunning := spec.State.Running
+	if wasRunning {
+		if err := s.engine.StopContainer(ctx, spec.ID, timeout); err != nil && !errors.Is(err, docker.ErrNotModified) {
+			return "", err
+		}
+	}
+	oldName := shortContainerID(spec.ID) + "_" + name
+	if err := s.engine.RenameContainer(ctx, spec.ID, oldName); err != nil {
+		if wasRunning {
+			s.engine.StartContainer(ctx, spec.ID)
+		}
+		return "", err
+	}
+
+	restore := func(newID string, cause error) (string, error) {
+		if newID != "" {
+			s.engine.RemoveContainer(ctx, newID, true, false)
+		}
+		if err := s.engine.RenameContainer(ctx, spec.ID, name); err != nil {
+			return "", fmt.Errorf("%w (and restoring the old container as %s failed: %v)", cause, oldName, err)
+		}
+		if wasRunning {
+			s.engine.StartContainer(ctx, spec.ID)
+		}
+		return "", cause
+	}
+
+	newID, err := s.engine.CreateContainer(ctx, docker.CreateContainerOptions{
+		Name:       name,
+		Config:     config,
+		HostConfig: hostConfig,
+		Networks:   primary,
+	})
+	if err != nil {
+		return restore("", err)
+	}
+	for network, endpoint := range secondary {
+		if err := s.engine.ConnectNetwork(ctx, network, newID, endpoint); err != nil {
+			return restore(newID, err)
+		}
+	}
+	if err := s.engine.StartContainer(ctx, newID); err != nil && !errors.Is(err, docker.ErrNotModified) {
+		return restore(newID, err)
+	}
+
+	if err := s.engine.RemoveContainer(ctx, spec.ID, true, false); err != nil {
+		return newID, fmt.Errorf("new container started but removing the old one (%s) failed: %w", oldName, err)
+	}
+	return newID, nil
+}
+
+// preserveAnonymousVolumes returns the container's HostConfig with its
+// anonymous volumes (those not declared in Binds or Mounts, e.g. from an
+// image's VOLUME) added to Binds, so a recreated container reuses them
+func preserveAnonymousVolumes(spec *docker.ContainerSpec) (map[string]json.RawMessage, error) {
+	hostConfig := make(map[string]json.RawMessage, len(spec.HostConfig))
+	for key, value := range spec.HostConfig {
+		hostConfig[key] = value
+	}
+
+	var binds []string
+	var mounts []struct {
+		Target string `json:"Target"`
+	}
+	if raw, ok := hostConfig["Binds"]; ok {
+		json.Unmarshal(raw, &binds)
+	}
+	if raw, ok := hostConfig["Mounts"]; ok {
+		json.Unmarshal(raw, &mounts)
+	}
+
+	declared := make(map[string]bool)
+	for _, bind := range binds {
+		parts := strings.Split(bind, ":")
+		if len(parts) >= 2 {
+			declared[parts[1]] = true
+		}
+	}
+	for _, mount := range mounts {
+		declared[mount.Target] = true
+	}
+
+	added := false
+	for _, mount := range spec.Mounts {
+		if mount.Type != "volume" || mount.Name == "" || declared[mount.Destination] {
+			continue
+		}
+		binds = append(binds, mount.Name+":"+mount.Destination)
+		added = true
+	}
+	if !added {
+		return hostConfig, nil
+	}
+
+	raw, err := json.Marshal(binds)
+	if err != nil {
+		return nil, err
+	}
+	hostConfig["Binds"] = raw
+	return hostConfig, nil
+}
+
+func shortContainerID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// allowPrivateTargetsEnv lets operators register Docker hosts and clusters on
+// loopback, link-local and private addresses, e.g. when every daemon sits on
+// the same private network as the server
+const allowPrivateTargetsEnv = "CLOUDDECK_ALLOW_PRIVATE_TARGETS"
+
+const targetLookupTimeout = 3 * time.Second
+
+var ErrTargetNotAllowed = errors.New("address is not allowed")
+
+// targetPolicy decides which addresses the server may connect to on behalf of
+// a registered Docker host or cluster. Those URLs come in over the API, so by
+// default they may not point back at the server or into its network. The
+// server's own daemon and cluster are not registered and are exempt.
+type targetPolicy struct {
+	allowPrivate bool
+}
+
+func targetPolicyFromEnv() targetPolicy {
+	allow, _ := strconv.ParseBool(os.Getenv(allowPrivateTargetsEnv))
+	return targetPolicy{allowPrivate: allow}
+}
+
+func (p targetPolicy) checkIP(ip net.IP) error {
+	if p.allowPrivate {
+		return nil
+	}
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() {
+		return fmt.Errorf("%w: %s is a loopback, link-local or private address; set %s to allow it",
+			ErrTargetNotAllowed, ip, allowPrivateTargetsEnv)
+	}
+	return nil
+}
+
+// checkHost vets a host name or IP address when it is registered. Names are
+// resolved where possible, but only the check made when dialing (see control)
+// holds against names that resolve differently later.
+func (p targetPolicy) checkHost(host string) error {
+	if p.allowPrivate {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return p.checkIP(ip)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), targetLookupTimeout)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil
+	}
+	for _, addr := range addrs {
+		if err := p.checkIP(addr.IP); err != nil {
+			return fmt.Errorf("%s: %w", host, err)
+		}
+	}
+	return nil
+}
+
+// control is a net.Dialer Control function applying the policy to the address
+// actually connected to
+func (p targetPolicy) control(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return fmt.Errorf("%w: %s", ErrTargetNotAllowed, address)
+	}
+	return p.checkIP(ip)
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/docker/dockertest"
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
+)
+
+func TestTargetPolicyCheckIP(t *testing.T) {
+	tests := []struct {
+		ip      string
+		allowed bool
+	}{
+		{"203.0.113.10", true},
+		{"2001:db8::1", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.0.0.5", false},
+		{"172.16.3.4", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"fd00::1", false},
+		{"0.0.0.0", false},
+		{"::ffff:127.0.0.1", false},
+	}
+	for _, tt := range tests {
+		err := targetPolicy{}.checkIP(net.ParseIP(tt.ip))
+		if tt.allowed && err != nil {
+			t.Errorf("checkIP(%s): %v", tt.ip, err)
+		}
+		if !tt.allowed && !errors.Is(err, ErrTargetNotAllowed) {
+			t.Errorf("checkIP(%s) error = %v, want ErrTargetNotAllowed", tt.ip, err)
+		}
+		if err := (targetPolicy{allowPrivate: true}).checkIP(net.ParseIP(tt.ip)); err != nil {
+			t.Errorf("checkIP(%s) with private targets allowed: %v", tt.ip, err)
+		}
+	}
+}
+
+func TestDockerHostClientTargetPolicy(t *testing.T) {
+	fake := dockertest.NewServer()
+	defer fake.Close()
+
+	host := &models.DockerHost{URL: "tcp://" + fake.Listener.Addr().String()}
+	if _, err := newDockerHostClient(host, dockerHostSecrets{}, targetPolicy{}); !errors.Is(err, ErrTargetNotAllowed) {
+		t.Fatalf("newDockerHostClient for a loopback host: error = %v, want ErrTargetNotAllowed", err)
+	}
+	sshHost := &models.DockerHost{URL: "ssh://deploy@127.0.0.1"}
+	if _, err := newDockerHostClient(sshHost, dockerHostSecrets{SSHPrivateKey: "key"}, targetPolicy{}); !errors.Is(err, ErrTargetNotAllowed) {
+		t.Fatalf("newDockerHostClient for a loopback ssh host: error = %v, want ErrTargetNotAllowed", err)
+	}
+
+	client, err := newDockerHostClient(host, dockerHostSecrets{}, targetPolicy{allowPrivate: true})
+	if err != nil {
+		t.Fatalf("newDockerHostClient: %v", err)
+	}
+	defer client.Close()
+	if err := client.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping with private targets allowed: %v", err)
+	}
+}
+
+func TestTargetPolicyControl(t *testing.T) {
+	// A name that passed registration but resolves to a private address when
+	// dialed is still refused
+	policy := targetPolicy{}
+	if err := policy.control("tcp4", "127.0.0.1:2376", nil); !errors.Is(err, ErrTargetNotAllowed) {
+		t.Fatalf("control(127.0.0.1) error = %v, want ErrTargetNotAllowed", err)
+	}
+	if err := policy.control("tcp4", "203.0.113.10:2376", nil); err != nil {
+		t.Fatalf("control(203.0.113.10): %v", err)
+	}
+}
@@ -20,13 +20,13 @@ func NewTaskService(repo *repositories.TaskRepository, projectRepo *repositories
 	}
 }
 
-func (s *TaskService) CreateTask(req *models.CreateTaskRequest) (*models.Task, error) {
+func (s *TaskService) CreateTask(orgID uint, req *models.CreateTaskRequest) (*models.Task, error) {
 	if strings.TrimSpace(req.Title) == "" {
 		return nil, errors.New("title cannot be empty")
 	}
 
 	// Verify project exists
-	_, err := s.projectRepo.FindByID(req.ProjectID)
+	_, err := s.projectRepo.ForOrganization(orgID).FindByID(req.ProjectID)
 	if err != nil {
 		return nil, errors.New("project not found")
 	}
@@ -50,27 +50,33 @@ func (s *TaskService) CreateTask(req *models.CreateTaskRequest) (*models.Task, e
 		task.Priority = "medium"
 	}
 
-	if err := s.repo.Create(task); err != nil {
+	if err := s.repo.ForOrganization(orgID).Create(task); err != nil {
 		return nil, err
 	}
 
 	return task, nil
 }
 
-func (s *TaskService) GetAllTasks() ([]models.Task, error) {
-	return s.repo.FindAll()
+func (s *TaskService) GetAllTasks(orgID uint) ([]models.Task, error) {
+	return s.repo.ForOrganization(orgID).FindAll()
 }
 
-func (s *TaskService) GetTasksByProjectID(projectID uint) ([]models.Task, error) {
-	return s.repo.FindByProjectID(projectID)
+func (s *TaskService) GetTasksByProjectID(orgID uint, projectID uint) ([]models.Task, error) {
+	return s.repo.ForOrganization(orgID).FindByProjectID(projectID)
 }
 
-func (s *TaskService) GetTaskByID(id uint) (*models.Task, error) {
-	return s.repo.FindByID(id)
+func (s *TaskService) GetTasksByProjectIDs(orgID uint, projectIDs []uint) ([]models.Task, error) {
+	return s.repo.ForOrganization(orgID).FindByProjectIDs(projectIDs)
 }
 
-func (s *TaskService) UpdateTask(id uint, req *models.UpdateTaskRequest) (*models.Task, error) {
-	task, err := s.repo.FindByID(id)
+func (s *TaskService) GetTaskByID(orgID uint, id uint) (*models.Task, error) {
+	return s.repo.ForOrganization(orgID).FindByID(id)
+}
+
+func (s *TaskService) UpdateTask(orgID uint, id uint, req *models.UpdateTaskRequest) (*models.Task, error) {
+	repo := s.repo.ForOrganization(orgID)
+
+	task, err := repo.FindByID(id)
 	if err != nil {
 		return nil, err
 	}
@@ -98,17 +104,17 @@ func (s *TaskService) UpdateTask(id uint, req *models.UpdateTaskRequest) (*model
 	}
 	task.EstimatedHours = req.EstimatedHours
 
-	if err := s.repo.Update(task); err != nil {
+	if err := repo.Update(task); err != nil {
 		return nil, err
 	}
 
 	return task, nil
 }
 
-func (s *TaskService) DeleteTask(id uint) error {
-	return s.repo.Delete(id)
+func (s *TaskService) DeleteTask(orgID uint, id uint) error {
+	return s.repo.ForOrganization(orgID).Delete(id)
 }
 
-func (s *TaskService) GetStats() (map[string]interface{}, error) {
-	return s.repo.GetStats()
+func (s *TaskService) GetStats(orgID uint) (map[string]interface{}, error) {
+	return s.repo.ForOrganization(orgID).GetStats()
 }
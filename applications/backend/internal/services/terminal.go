@@ -0,0 +1,254 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+	utilexec "k8s.io/client-go/util/exec"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/docker"
+)
+
+var (
+	// ErrInvalidExecRequest marks exec requests with a bad command or container
+	ErrInvalidExecRequest = errors.New("invalid exec request")
+	// ErrExecTargetNotRunning is returned when the pod to exec into is not running
+	ErrExecTargetNotRunning = errors.New("exec target is not running")
+)
+
+// TerminalSize is a terminal's dimensions in character cells
+type TerminalSize struct {
+	Width  uint16
+	Height uint16
+}
+
+// TerminalStreams connects an exec session to its client. Stdin may be nil for
+// a non-interactive command. With Tty set, all output arrives on Stdout.
+// Resize delivers size changes until it is closed.
+type TerminalStreams struct {
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+	Resize <-chan TerminalSize
+	Tty    bool
+}
+
+// TerminalSession is an exec session that has been validated against its target
+// and is ready to attach. Run blocks until the command exits or ctx is cancelled
+// and returns the command's exit code.
+type TerminalSession interface {
+	Run(ctx context.Context, streams TerminalStreams) (int, error)
+}
+
+// OpenExec prepares command to run in a running container. Errors from the
+// daemon (unknown or stopped container) surface here, before anything is
+// attached. The exec itself is only created by Run, once the client is
+// connected, so a failed WebSocket upgrade leaves nothing behind in the daemon.
+func (s *ContainerService) OpenExec(ctx context.Context, containerID string, command []string, tty bool) (TerminalSession, error) {
+	if len(command) == 0 {
+		return nil, fmt.Errorf("%w: command is required", ErrInvalidExecRequest)
+	}
+
+	details, err := s.engine.InspectContainer(ctx, containerID)
+	if err != nil {
+		return nil, err
+	}
+	if !details.State.Running || details.State.Paused {
+		return nil, fmt.Errorf("%w: container %s is %s", ErrExecTargetNotRunning, containerID, details.State.Status)
+	}
+
+	return &containerExec{
+		engine:      s.engine,
+		containerID: details.ID,
+		config: docker.ExecConfig{
+			AttachStdin:  true,
+			AttachStdout: true,
+			AttachStderr: true,
+			Tty:          tty,
+			Cmd:          command,
+		},
+	}, nil
+}
+
+type containerExec struct {
+	engine      docker.Engine
+	containerID string
+	config      docker.ExecConfig
+}
+
+func (e *containerExec) Run(ctx context.Context, streams TerminalStreams) (int, error) {
+	execID, err := e.engine.CreateExec(ctx, e.containerID, e.config)
+	if err != nil {
+		return -1, err
+	}
+	conn, err := e.engine.StartExec(ctx, execID, e.config.Tty)
+	if err != nil {
+		return -1, err
+	}
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	if streams.Resize != nil && e.config.Tty {
+		go func() {
+			for size := range streams.Resize {
+				if ctx.Err() != nil {
+					return
+				}
+				e.engine.ResizeExec(ctx, execID, size.Height, size.Width)
+			}
+		}()
+	}
+	if streams.Stdin != nil {
+		go func() {
+			io.Copy(conn, streams.Stdin)
+			conn.CloseWrite()
+		}()
+	}
+
+	stderr := streams.Stderr
+	if stderr == nil {
+		stderr = streams.Stdout
+	}
+	if e.config.Tty {
+		_, err = io.Copy(streams.Stdout, conn)
+	} else {
+		err = docker.Demultiplex(conn, streams.Stdout, stderr)
+	}
+	if ctx.Err() != nil {
+		return -1, ctx.Err()
+	}
+	if err != nil && !errors.Is(err, net.ErrClosed) {
+		return -1, fmt.Errorf("exec stream failed: %w", err)
+	}
+
+	// The daemon may report the exec as running for a moment after the stream ends
+	for attempt := 0; attempt < 10; attempt++ {
+		inspect, err := e.engine.InspectExec(ctx, execID)
+		if err != nil {
+			return -1, err
+		}
+		if !inspect.Running {
+			return inspect.ExitCode, nil
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	return -1, errors.New("exec session ended but the command is still running")
+}
+
+// defaultContainerAnnotation names the container kubectl exec picks by default
+const defaultContainerAnnotation = "kubectl.kubernetes.io/default-container"
+
+// OpenExec prepares command to run in a container of a running pod. An empty
+// container selects the pod's default container, as kubectl does.
+func (s *KubernetesService) OpenExec(ctx context.Context, namespace, podName, container string, command []string, tty bool) (TerminalSession, error) {
+	if len(command) == 0 {
+		return nil, fmt.Errorf("%w: command is required", ErrInvalidExecRequest)
+	}
+
+	pod, err := s.clientset.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if pod.Status.Phase != corev1.PodRunning {
+		return nil, fmt.Errorf("%w: pod %s is %s", ErrExecTargetNotRunning, podName, pod.Status.Phase)
+	}
+
+	if container == "" {
+		container = pod.Annotations[defaultContainerAnnotation]
+	}
+	if container == "" && len(pod.Spec.Containers) > 0 {
+		container = pod.Spec.Containers[0].Name
+	}
+	found := false
+	for _, c := range pod.Spec.Containers {
+		if c.Name == container {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("%w: pod %s has no container %q", ErrInvalidExecRequest, podName, container)
+	}
+
+	req := s.clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(podName).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: container,
+			Command:   command,
+			Stdin:     true,
+			Stdout:    true,
+			Stderr:    !tty,
+			TTY:       tty,
+		}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(s.config, "POST", req.URL())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create exec session: %w", err)
+	}
+	return &podExec{executor: executor, tty: tty}, nil
+}
+
+type podExec struct {
+	executor remotecommand.Executor
+	tty      bool
+}
+
+func (e *podExec) Run(ctx context.Context, streams TerminalStreams) (int, error) {
+	opts := remotecommand.StreamOptions{
+		Stdin:  streams.Stdin,
+		Stdout: streams.Stdout,
+		Tty:    e.tty,
+	}
+	if !e.tty {
+		opts.Stderr = streams.Stderr
+		if opts.Stderr == nil {
+			opts.Stderr = streams.Stdout
+		}
+	}
+	if streams.Resize != nil && e.tty {
+		opts.TerminalSizeQueue = &terminalSizeQueue{ctx: ctx, sizes: streams.Resize}
+	}
+
+	err := e.executor.StreamWithContext(ctx, opts)
+	var exitErr utilexec.ExitError
+	if errors.As(err, &exitErr) && exitErr.Exited() {
+		return exitErr.ExitStatus(), nil
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return -1, ctx.Err()
+		}
+		return -1, fmt.Errorf("exec stream failed: %w", err)
+	}
+	return 0, nil
+}
+
+// terminalSizeQueue adapts a TerminalSize channel to remotecommand.TerminalSizeQueue
+type terminalSizeQueue struct {
+	ctx   context.Context
+	sizes <-chan TerminalSize
+}
+
+func (q *terminalSizeQueue) Next() *remotecommand.TerminalSize {
+	select {
+	case size, ok := <-q.sizes:
+		if !ok {
+			return nil
+		}
+		return &remotecommand.TerminalSize{Width: size.Width, Height: size.Height}
+	case <-q.ctx.Done():
+		return nil
+	}
+}
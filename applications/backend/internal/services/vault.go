@@ -0,0 +1,81 @@
+package services
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+)
+
+var ErrVaultUnavailable = errors.New("credential vault is not configured (set CLOUDDECK_VAULT_KEY)")
+
+// Vault encrypts secrets with AES-256-GCM using a key supplied through the environment.
+// The additional data binds each ciphertext to its owner so rows cannot be swapped.
+type Vault struct {
+	aead cipher.AEAD
+}
+
+// NewVaultFromEnv builds a Vault from CLOUDDECK_VAULT_KEY, a 32-byte key encoded as
+// base64 or hex. It returns ErrVaultUnavailable when the variable is unset.
+func NewVaultFromEnv() (*Vault, error) {
+	encoded := os.Getenv("CLOUDDECK_VAULT_KEY")
+	if encoded == "" {
+		return nil, ErrVaultUnavailable
+	}
+
+	key, err := decodeVaultKey(encoded)
+	if err != nil {
+		return nil, err
+	}
+	return NewVault(key)
+}
+
+func NewVault(key []byte) (*Vault, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("vault key must be 32 bytes, got %d", len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+
+	return &Vault{aead: aead}, nil
+}
+
+// Seal encrypts plaintext and returns the random nonce alongside the ciphertext
+func (v *Vault) Seal(plaintext []byte, additionalData []byte) (nonce, ciphertext []byte, err error) {
+	nonce = make([]byte, v.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return nonce, v.aead.Seal(nil, nonce, plaintext, additionalData), nil
+}
+
+// Open decrypts a ciphertext produced by Seal with the same additional data
+func (v *Vault) Open(nonce, ciphertext []byte, additionalData []byte) ([]byte, error) {
+	plaintext, err := v.aead.Open(nil, nonce, ciphertext, additionalData)
+	if err != nil {
+		return nil, errors.New("failed to decrypt secret")
+	}
+	return plaintext, nil
+}
+
+func decodeVaultKey(encoded string) ([]byte, error) {
+	if key, err := base64.StdEncoding.DecodeString(encoded); err == nil && len(key) == 32 {
+		return key, nil
+	}
+	if key, err := hex.DecodeString(encoded); err == nil && len(key) == 32 {
+		return key, nil
+	}
+	return nil, errors.New("CLOUDDECK_VAULT_KEY must be 32 bytes encoded as base64 or hex")
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/SoumyaRaikwar/clouddeck-backend/internal/models"
+)
+
+func testVault(t *testing.T) *Vault {
+	t.Helper()
+	vault, err := NewVault(bytes.Repeat([]byte{7}, 32))
+	if err != nil {
+		t.Fatalf("NewVault: %v", err)
+	}
+	return vault
+}
+
+func TestVaultOpenRequiresSameAdditionalData(t *testing.T) {
+	vault := testVault(t)
+	secret := []byte("ghp_0123456789abcdef")
+	aad := []byte("clouddeck:credential:1:user:1:provider:github")
+
+	nonce, ciphertext, err := vault.Seal(secret, aad)
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+
+	tamper := func(b []byte) []byte {
+		b = bytes.Clone(b)
+		b[0] ^= 1
+		return b
+	}
+	tests := []struct {
+		name       string
+		nonce      []byte
+		ciphertext []byte
+		aad        []byte
+		ok         bool
+	}{
+		{"same additional data", nonce, ciphertext, aad, true},
+		{"other credential", nonce, ciphertext, []byte("clouddeck:credential:2:user:1:provider:github"), false},
+		{"other user", nonce, ciphertext, []byte("clouddeck:credential:1:user:2:provider:github"), false},
+		{"no additional data", nonce, ciphertext, nil, false},
+		{"tampered ciphertext", nonce, tamper(ciphertext), aad, false},
+		{"tampered nonce", tamper(nonce), ciphertext, aad, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plaintext, err := vault.Open(tt.nonce, tt.ciphertext, tt.aad)
+			if tt.ok {
+				if err != nil || !bytes.Equal(plaintext, secret) {
+					t.Fatalf("Open = (%q, %v), want the secret", plaintext, err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Open succeeded with the wrong inputs: %q", plaintext)
+			}
+		})
+	}
+}
+
+func TestVaultRejectsOtherKey(t *testing.T) {
+	nonce, ciphertext, err := testVault(t).Seal([]byte("secret"), nil)
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	other, err := NewVault(bytes.Repeat([]byte{8}, 32))
+	if err != nil {
+		t.Fatalf("NewVault: %v", err)
+	}
+	if _, err := other.Open(nonce, ciphertext, nil); err == nil {
+		t.Fatal("Open succeeded with another key")
+	}
+}
+
+func TestNewVaultKeyLength(t *testing.T) {
+	for _, size := range []int{0, 16, 31, 33} {
+		if _, err := NewVault(make([]byte, size)); err == nil {
+			t.Errorf("NewVault accepted a %d-byte key", size)
+		}
+	}
+}
+
+// A sealed credential only opens for the row, owner and provider it was sealed for
+func TestCredentialAADBindsRow(t *testing.T) {
+	vault := testVault(t)
+	service := &CredentialService{vault: vault}
+	credential := &models.Credential{ID: 1, UserID: 1, Provider: "github"}
+	if err := service.seal(credential, "ghp_secret"); err != nil {
+		t.Fatalf("seal: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		other models.Credential
+	}{
+		{"other ID", models.Credential{ID: 2, UserID: 1, Provider: "github"}},
+		{"other user", models.Credential{ID: 1, UserID: 2, Provider: "github"}},
+		{"other provider", models.Credential{ID: 1, UserID: 1, Provider: "gitlab"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := vault.Open(credential.Nonce, credential.Ciphertext, credentialAAD(&tt.other)); err == nil {
+				t.Fatal("ciphertext opened for another credential")
+			}
+		})
+	}
+	if _, err := vault.Open(credential.Nonce, credential.Ciphertext, credentialAAD(credential)); err != nil {
+		t.Fatalf("ciphertext does not open for its own credential: %v", err)
+	}
+}
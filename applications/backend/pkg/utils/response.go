@@ -21,8 +21,16 @@ func SuccessResponse(c *gin.Context, statusCode int, message string, data interf
 	})
 }
 
+// ErrorContextKey holds the error of a failed response so middleware can log it
+const ErrorContextKey = "responseError"
+
 // ErrorResponse sends an error response
 func ErrorResponse(c *gin.Context, statusCode int, message string, err string) {
+	if err != "" {
+		c.Set(ErrorContextKey, message+": "+err)
+	} else {
+		c.Set(ErrorContextKey, message)
+	}
 	c.JSON(statusCode, APIResponse{
 		Success: false,
 		Message: message,
@@ -1,5 +1,5 @@
 import React from 'react';
-import { BrowserRouter as Router, Routes, Route } from 'react-router-dom';
+import { BrowserRouter as Router, Routes, Route, Navigate, Outlet } from 'react-router-dom';
 import { ThemeProvider, createTheme, CssBaseline } from '@mui/material';
 import Navbar from './components/Navbar';
 import Dashboard from './pages/Dashboard';
@@ -10,6 +10,8 @@ import GitHub from './pages/GitHub';
 import Kubernetes from './pages/Kubernetes';
 import CICD from './pages/CICD';
 import GitOps from './pages/GitOps'; 
+import Login from './pages/Login';
+import { captureTokensFromFragment, isLoggedIn } from './services/auth';
 
 const theme = createTheme({
   palette: {
@@ -23,21 +25,38 @@ const theme = createTheme({
   },
 });
 
+captureTokensFromFragment();
+
+// RequireAuth sends visitors without a token to the login page
+const RequireAuth: React.FC = () => {
+  if (!isLoggedIn()) {
+    return <Navigate to="/login" replace />;
+  }
+  return (
+    <>
+      <Navbar />
+      <Outlet />
+    </>
+  );
+};
+
 function App() {
   return (
     <ThemeProvider theme={theme}>
       <CssBaseline />
       <Router>
-        <Navbar />
         <Routes>
-          <Route path="/" element={<Dashboard />} />
-          <Route path="/projects" element={<Projects />} />
-          <Route path="/tasks" element={<Tasks />} />
-          <Route path="/containers" element={<Containers />} />
-          <Route path="/github" element={<GitHub />} />
-          <Route path="/kubernetes" element={<Kubernetes />} />
-          <Route path="/cicd" element={<CICD />} />
-          <Route path="/gitops" element={<GitOps />} /> 
+          <Route path="/login" element={<Login />} />
+          <Route element={<RequireAuth />}>
+            <Route path="/" element={<Dashboard />} />
+            <Route path="/projects" element={<Projects />} />
+            <Route path="/tasks" element={<Tasks />} />
+            <Route path="/containers" element={<Containers />} />
+            <Route path="/github" element={<GitHub />} />
+            <Route path="/kubernetes" element={<Kubernetes />} />
+            <Route path="/cicd" element={<CICD />} />
+            <Route path="/gitops" element={<GitOps />} />
+          </Route>
         </Routes>
       </Router>
     </ThemeProvider>
@@ -0,0 +1,102 @@
+import React, { useEffect, useState } from 'react';
+import { Box, Button, FormControl, InputLabel, MenuItem, Select, TextField } from '@mui/material';
+import { createCredential, getCredentials } from '../services/api';
+import { Credential } from '../types/credential';
+
+// The selected credential is remembered across pages; the token itself stays
+// in the server's credential vault
+export const GITHUB_CREDENTIAL_KEY = 'github_credential_id';
+
+interface GitHubCredentialPickerProps {
+  value: number;
+  onChange: (credentialId: number) => void;
+  onError: (message: string) => void;
+}
+
+const GitHubCredentialPicker: React.FC<GitHubCredentialPickerProps> = ({ value, onChange, onError }) => {
+  const [credentials, setCredentials] = useState<Credential[]>([]);
+  const [name, setName] = useState('');
+  const [secret, setSecret] = useState('');
+  const [saving, setSaving] = useState(false);
+
+  useEffect(() => {
+    // Tokens used to be kept in the browser; they now live in the vault
+    localStorage.removeItem('github_token');
+    fetchCredentials();
+    // eslint-disable-next-line react-hooks/exhaustive-deps
+  }, []);
+
+  const select = (credentialId: number) => {
+    localStorage.setItem(GITHUB_CREDENTIAL_KEY, String(credentialId));
+    onChange(credentialId);
+  };
+
+  const fetchCredentials = async () => {
+    try {
+      const data = await getCredentials();
+      setCredentials(data.filter((c) => c.provider === 'github' && !c.revokedAt));
+    } catch (err: any) {
+      onError(err.message || 'Failed to fetch credentials');
+    }
+  };
+
+  const handleSave = async () => {
+    if (!name || !secret) {
+      onError('Please enter a name and a GitHub token');
+      return;
+    }
+
+    setSaving(true);
+    try {
+      const credential = await createCredential({ name, provider: 'github', secret });
+      setCredentials([credential, ...credentials]);
+      select(credential.id);
+      setName('');
+      setSecret('');
+    } catch (err: any) {
+      onError(err.response?.data?.error || err.message || 'Failed to save token');
+    } finally {
+      setSaving(false);
+    }
+  };
+
+  return (
+    <Box sx={{ display: 'flex', gap: 2, flexWrap: 'wrap', flex: 2 }}>
+      <FormControl size="small" sx={{ flex: 1, minWidth: '200px' }}>
+        <InputLabel>GitHub Token</InputLabel>
+        <Select
+          value={credentials.some((c) => c.id === value) ? value : ''}
+          label="GitHub Token"
+          onChange={(e) => select(Number(e.target.value))}
+        >
+          {credentials.map((credential) => (
+            <MenuItem key={credential.id} value={credential.id}>
+              {credential.name} ({credential.hint})
+            </MenuItem>
+          ))}
+        </Select>
+      </FormControl>
+      <TextField
+        label="New token name"
+        value={name}
+        onChange={(e) => setName(e.target.value)}
+        sx={{ flex: 1, minWidth: '150px' }}
+        size="small"
+      />
+      <TextField
+        label="New token"
+        type="password"
+        value={secret}
+        onChange={(e) => setSecret(e.target.value)}
+        placeholder="ghp_xxxxxxxxxxxxx"
+        sx={{ flex: 1, minWidth: '150px' }}
+        size="small"
+      />
+      <Button variant="outlined" onClick={handleSave} disabled={saving}>
+        Save Token
+      </Button>
+    </Box>
+  );
+};
+
+export default GitHubCredentialPicker;
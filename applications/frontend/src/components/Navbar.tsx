@@ -10,7 +10,9 @@ import {
   CloudQueue as K8sIcon,
   Timeline as CICDIcon,
   Rocket as GitOpsIcon, 
+  Logout as LogoutIcon,
 } from '@mui/icons-material';
+import { logout } from '../services/api';
 
 const Navbar: React.FC = () => {
   const navigate = useNavigate();
@@ -62,6 +64,18 @@ const Navbar: React.FC = () => {
           <Typography variant="caption" sx={{ color: 'rgba(255, 255, 255, 0.7)' }}>
             Kubernetes Project Management
           </Typography>
+
+          <Button
+            color="inherit"
+            startIcon={<LogoutIcon />}
+            onClick={() => {
+              logout();
+              navigate('/login');
+            }}
+            sx={{ ml: 2 }}
+          >
+            Logout
+          </Button>
         </Toolbar>
       </Container>
     </AppBar>
@@ -32,9 +32,10 @@ import {
 } from '@mui/icons-material';
 import { getWorkflowRuns, getPipelineStats } from '../services/api';
 import { WorkflowRun, PipelineStats } from '../types/cicd';
+import GitHubCredentialPicker, { GITHUB_CREDENTIAL_KEY } from '../components/GitHubCredentialPicker';
 
 const CICD: React.FC = () => {
-  const [token, setToken] = useState(localStorage.getItem('github_token') || '');
+  const [credentialId, setCredentialId] = useState(Number(localStorage.getItem(GITHUB_CREDENTIAL_KEY)) || 0);
   const [owner, setOwner] = useState(localStorage.getItem('cicd_owner') || 'jaegertracing');
   const [repo, setRepo] = useState(localStorage.getItem('cicd_repo') || 'jaeger');
   
@@ -45,15 +46,15 @@ const CICD: React.FC = () => {
   const [success, setSuccess] = useState('');
 
   useEffect(() => {
-    if (token && owner && repo) {
+    if (credentialId && owner && repo) {
       fetchData();
     }
     // eslint-disable-next-line react-hooks/exhaustive-deps
   }, []);
 
   const fetchData = async () => {
-    if (!token || !owner || !repo) {
-      setError('Please enter GitHub token, owner, and repository');
+    if (!credentialId || !owner || !repo) {
+      setError('Please select a GitHub token and enter owner and repository');
       return;
     }
 
@@ -62,14 +63,13 @@ const CICD: React.FC = () => {
     
     try {
       // Save to localStorage
-      localStorage.setItem('github_token', token);
       localStorage.setItem('cicd_owner', owner);
       localStorage.setItem('cicd_repo', repo);
 
       // Fetch workflow runs and stats
       const [runsData, statsData] = await Promise.all([
-        getWorkflowRuns(token, owner, repo, 20),
-        getPipelineStats(token, owner, repo),
+        getWorkflowRuns(credentialId, owner, repo, 20),
+        getPipelineStats(credentialId, owner, repo),
       ]);
 
       setRuns(runsData);
@@ -137,15 +137,7 @@ const CICD: React.FC = () => {
             Repository Configuration
           </Typography>
           <Box sx={{ display: 'flex', gap: 2, mb: 2, flexWrap: 'wrap' }}>
-            <TextField
-              label="GitHub Token"
-              type="password"
-              value={token}
-              onChange={(e) => setToken(e.target.value)}
-              placeholder="ghp_xxxxxxxxxxxxx"
-              sx={{ flex: 1, minWidth: '200px' }}
-              size="small"
-            />
+            <GitHubCredentialPicker value={credentialId} onChange={setCredentialId} onError={setError} />
             <TextField
               label="Owner"
               value={owner}
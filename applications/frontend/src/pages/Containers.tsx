@@ -58,7 +58,7 @@ const Containers: React.FC = () => {
     setLoadingLogs(true);
 
     try {
-      const logsData = await getContainerLogs(container.containerId);
+      const logsData = await getContainerLogs(container.hostId, container.containerId);
       setLogs(logsData);
     } catch (err: any) {
       setLogs(`Error fetching logs: ${err.message}`);
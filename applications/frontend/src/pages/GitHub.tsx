@@ -31,10 +31,11 @@ import {
 import { getGitHubPRs, getGitHubIssues, syncPRsToTasks, syncIssuesToTasks, getProjects } from '../services/api';
 import { GitHubPR, GitHubIssue } from '../types/github';
 import { Project } from '../types/project';
+import GitHubCredentialPicker, { GITHUB_CREDENTIAL_KEY } from '../components/GitHubCredentialPicker';
 
 const GitHub: React.FC = () => {
   const [tab, setTab] = useState(0);
-  const [token, setToken] = useState(localStorage.getItem('github_token') || '');
+  const [credentialId, setCredentialId] = useState(Number(localStorage.getItem(GITHUB_CREDENTIAL_KEY)) || 0);
   const [username, setUsername] = useState(localStorage.getItem('github_username') || '');
   const [owner, setOwner] = useState('');
   const [repo, setRepo] = useState('');
@@ -65,18 +66,17 @@ const GitHub: React.FC = () => {
   };
 
   const handleFetchPRs = async () => {
-    if (!token || !username) {
-      setError('Please enter both GitHub token and username');
+    if (!credentialId || !username) {
+      setError('Please select a GitHub token and enter a username');
       return;
     }
 
     setLoading(true);
     setError('');
     try {
-      localStorage.setItem('github_token', token);
       localStorage.setItem('github_username', username);
       
-      const data = await getGitHubPRs(token, username);
+      const data = await getGitHubPRs(credentialId, username);
       setPRs(data);
       setSuccess(`Fetched ${data.length} PRs!`);
     } catch (err: any) {
@@ -87,15 +87,15 @@ const GitHub: React.FC = () => {
   };
 
   const handleFetchIssues = async () => {
-    if (!token || !owner || !repo) {
-      setError('Please enter token, owner, and repo');
+    if (!credentialId || !owner || !repo) {
+      setError('Please select a token and enter owner and repo');
       return;
     }
 
     setLoading(true);
     setError('');
     try {
-      const data = await getGitHubIssues(token, owner, repo);
+      const data = await getGitHubIssues(credentialId, owner, repo);
       setIssues(data);
       setSuccess(`Fetched ${data.length} issues from ${owner}/${repo}!`);
     } catch (err: any) {
@@ -113,7 +113,7 @@ const GitHub: React.FC = () => {
 
     setLoading(true);
     try {
-      await syncPRsToTasks({ token, username, project_id: selectedProject });
+      await syncPRsToTasks({ credential_id: credentialId, username, project_id: selectedProject });
       setSuccess('PRs synced to tasks successfully!');
       setSyncDialog(false);
     } catch (err: any) {
@@ -131,7 +131,7 @@ const GitHub: React.FC = () => {
 
     setLoading(true);
     try {
-      await syncIssuesToTasks({ token, owner, repo, project_id: selectedProject });
+      await syncIssuesToTasks({ credential_id: credentialId, owner, repo, project_id: selectedProject });
       setSuccess('Issues synced to tasks successfully!');
       setSyncDialog(false);
     } catch (err: any) {
@@ -177,15 +177,7 @@ const GitHub: React.FC = () => {
             🔑 GitHub Settings
           </Typography>
           <Box sx={{ display: 'flex', gap: 2, mb: 2, flexWrap: 'wrap' }}>
-            <TextField
-              label="GitHub Token"
-              type="password"
-              value={token}
-              onChange={(e) => setToken(e.target.value)}
-              placeholder="ghp_xxxxxxxxxxxxx"
-              sx={{ flex: 1, minWidth: '200px' }}
-              size="small"
-            />
+            <GitHubCredentialPicker value={credentialId} onChange={setCredentialId} onError={setError} />
             <TextField
               label="GitHub Username"
               value={username}
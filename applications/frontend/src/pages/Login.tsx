@@ -0,0 +1,73 @@
+import React, { useState } from 'react';
+import { useNavigate } from 'react-router-dom';
+import { Container, Card, CardContent, Typography, TextField, Button, Alert, Box } from '@mui/material';
+import { login } from '../services/api';
+
+const Login: React.FC = () => {
+  const navigate = useNavigate();
+  const [username, setUsername] = useState('');
+  const [password, setPassword] = useState('');
+  const [error, setError] = useState('');
+  const [loading, setLoading] = useState(false);
+
+  const handleSubmit = async (event: React.FormEvent) => {
+    event.preventDefault();
+    setLoading(true);
+    setError('');
+
+    try {
+      await login({ username, password });
+      navigate('/', { replace: true });
+    } catch (err: any) {
+      setError(err.response?.data?.error || err.message || 'Login failed');
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  return (
+    <Container maxWidth="xs" sx={{ py: 8 }}>
+      <Card>
+        <CardContent>
+          <Typography variant="h5" gutterBottom>
+            🚢 Sign in to CloudOps Hub
+          </Typography>
+
+          {error && (
+            <Alert severity="error" sx={{ mb: 2 }}>
+              {error}
+            </Alert>
+          )}
+
+          <Box component="form" onSubmit={handleSubmit}>
+            <TextField
+              label="Username"
+              value={username}
+              onChange={(e) => setUsername(e.target.value)}
+              fullWidth
+              margin="normal"
+              autoComplete="username"
+              autoFocus
+              required
+            />
+            <TextField
+              label="Password"
+              type="password"
+              value={password}
+              onChange={(e) => setPassword(e.target.value)}
+              fullWidth
+              margin="normal"
+              autoComplete="current-password"
+              required
+            />
+            <Button type="submit" variant="contained" fullWidth sx={{ mt: 2 }} disabled={loading}>
+              {loading ? 'Signing in...' : 'Sign in'}
+            </Button>
+          </Box>
+        </CardContent>
+      </Card>
+    </Container>
+  );
+};
+
+export default Login;
@@ -3,11 +3,14 @@ import { API_BASE_URL } from '../config/api';
 import { Item, CreateItemRequest, UpdateItemRequest, ApiResponse } from '../types/item';
 import { Project, CreateProjectRequest } from '../types/project';
 import { Task, CreateTaskRequest } from '../types/task';
-import { Container, ContainerLogs } from '../types/container';
+import { Container, ContainerLogs, FleetContainerList } from '../types/container';
 import { GitHubPR, GitHubIssue, SyncPRsRequest, SyncIssuesRequest } from '../types/github';
 import { Pod, Deployment, Service, Namespace } from '../types/kubernetes';
 import { WorkflowRun, PipelineStats, Workflow } from '../types/cicd';
 import { GitOpsApp, CreateGitOpsAppRequest } from '../types/gitops';
+import { LoginRequest, TokenPair } from '../types/auth';
+import { Credential, CreateCredentialRequest } from '../types/credential';
+import { getAccessToken, getRefreshToken, setTokens, clearTokens } from './auth';
 
 
 const apiClient = axios.create({
@@ -18,6 +21,64 @@ const apiClient = axios.create({
   },
 });
 
+// Every request carries the access token in the Authorization header
+apiClient.interceptors.request.use((config) => {
+  const token = getAccessToken();
+  if (token) {
+    config.headers.Authorization = `Bearer ${token}`;
+  }
+  return config;
+});
+
+// An expired access token is refreshed once and the request retried; when
+// that fails too the user has to log in again
+let refreshing: Promise<string> | null = null;
+
+const refreshAccessToken = async (): Promise<string> => {
+  const refreshToken = getRefreshToken();
+  if (!refreshToken) {
+    throw new Error('Not logged in');
+  }
+  const response = await axios.post<ApiResponse<TokenPair>>(`${API_BASE_URL}/auth/refresh`, { refreshToken });
+  setTokens(response.data.data!);
+  return response.data.data!.accessToken;
+};
+
+apiClient.interceptors.response.use(
+  (response) => response,
+  async (error) => {
+    const request = error.config;
+    if (error.response?.status !== 401 || !request || request._retried) {
+      return Promise.reject(error);
+    }
+
+    try {
+      refreshing = refreshing || refreshAccessToken();
+      const token = await refreshing;
+      request._retried = true;
+      request.headers.Authorization = `Bearer ${token}`;
+      return apiClient(request);
+    } catch {
+      clearTokens();
+      window.location.assign('/login');
+      return Promise.reject(error);
+    } finally {
+      refreshing = null;
+    }
+  }
+);
+
+// ==================== Authentication ====================
+export const login = async (data: LoginRequest): Promise<TokenPair> => {
+  const response = await axios.post<ApiResponse<TokenPair>>(`${API_BASE_URL}/auth/login`, data);
+  setTokens(response.data.data!);
+  return response.data.data!;
+};
+
+export const logout = (): void => {
+  clearTokens();
+};
+
 // ==================== Health Check ====================
 export const healthCheck = async (): Promise<any> => {
   const response = await axios.get('http://localhost:8080/health');
@@ -111,25 +172,38 @@ export const getTaskStats = async (): Promise<any> => {
 };
 
 export const getContainers = async (): Promise<Container[]> => {
-  const response = await apiClient.get<ApiResponse<Container[]>>('/containers');
-  return response.data.data || [];
+  const response = await apiClient.get<ApiResponse<FleetContainerList>>('/docker-hosts/containers');
+  return response.data.data?.containers || [];
 };
 
-export const getContainerLogs = async (containerId: string, tail: number = 100): Promise<string> => {
-  const response = await apiClient.get<ApiResponse<ContainerLogs>>(`/containers/${containerId}/logs?tail=${tail}`);
+export const getContainerLogs = async (hostId: number, containerId: string, tail: number = 100): Promise<string> => {
+  const response = await apiClient.get<ApiResponse<ContainerLogs>>(
+    `/docker-hosts/${hostId}/containers/${containerId}/logs?tail=${tail}`
+  );
   return response.data.data?.logs || '';
 };
 
-export const getGitHubPRs = async (token: string, username: string): Promise<GitHubPR[]> => {
+// ==================== Credentials ====================
+export const getCredentials = async (): Promise<Credential[]> => {
+  const response = await apiClient.get<ApiResponse<Credential[]>>('/credentials');
+  return response.data.data || [];
+};
+
+export const createCredential = async (data: CreateCredentialRequest): Promise<Credential> => {
+  const response = await apiClient.post<ApiResponse<Credential>>('/credentials', data);
+  return response.data.data!;
+};
+
+export const getGitHubPRs = async (credentialId: number, username: string): Promise<GitHubPR[]> => {
   const response = await apiClient.get<ApiResponse<GitHubPR[]>>(
-    `/github/prs?token=${token}&username=${username}`
+    `/github/prs?credentialId=${credentialId}&username=${username}`
   );
   return response.data.data || [];
 };
 
-export const getGitHubIssues = async (token: string, owner: string, repo: string): Promise<GitHubIssue[]> => {
+export const getGitHubIssues = async (credentialId: number, owner: string, repo: string): Promise<GitHubIssue[]> => {
   const response = await apiClient.get<ApiResponse<GitHubIssue[]>>(
-    `/github/issues?token=${token}&owner=${owner}&repo=${repo}`
+    `/github/issues?credentialId=${credentialId}&owner=${owner}&repo=${repo}`
   );
   return response.data.data || [];
 };
@@ -143,53 +217,70 @@ export const syncIssuesToTasks = async (data: SyncIssuesRequest): Promise<void>
 };
 
 //  Kubernetes 
+// Kubernetes calls go to a cluster of the organization: the server's own
+// cluster if the organization has it, otherwise the first registered one.
+let k8sBasePath: string | undefined;
+
+const getK8sBase = async (): Promise<string> => {
+  if (!k8sBasePath) {
+    const response = await apiClient.get<ApiResponse<{ id: number; local: boolean }[]>>('/clusters');
+    const clusters = response.data.data || [];
+    const cluster = clusters.find((c) => c.local) || clusters[0];
+    if (!cluster) {
+      throw new Error('No Kubernetes cluster is registered for this organization');
+    }
+    k8sBasePath = `/clusters/${cluster.id}/kubernetes`;
+  }
+  return k8sBasePath;
+};
+
 export const getK8sPods = async (namespace: string = 'all'): Promise<Pod[]> => {
-  const response = await apiClient.get<ApiResponse<Pod[]>>(`/kubernetes/pods?namespace=${namespace}`);
+  const response = await apiClient.get<ApiResponse<Pod[]>>(`${await getK8sBase()}/pods?namespace=${namespace}`);
   return response.data.data || [];
 };
 
 export const getK8sDeployments = async (namespace: string = 'all'): Promise<Deployment[]> => {
-  const response = await apiClient.get<ApiResponse<Deployment[]>>(`/kubernetes/deployments?namespace=${namespace}`);
+  const response = await apiClient.get<ApiResponse<Deployment[]>>(`${await getK8sBase()}/deployments?namespace=${namespace}`);
   return response.data.data || [];
 };
 
 export const getK8sServices = async (namespace: string = 'all'): Promise<Service[]> => {
-  const response = await apiClient.get<ApiResponse<Service[]>>(`/kubernetes/services?namespace=${namespace}`);
+  const response = await apiClient.get<ApiResponse<Service[]>>(`${await getK8sBase()}/services?namespace=${namespace}`);
   return response.data.data || [];
 };
 
 export const getK8sNamespaces = async (): Promise<Namespace[]> => {
-  const response = await apiClient.get<ApiResponse<Namespace[]>>('/kubernetes/namespaces');
+  const response = await apiClient.get<ApiResponse<Namespace[]>>(`${await getK8sBase()}/namespaces`);
   return response.data.data || [];
 };
 
 export const getK8sPodLogs = async (namespace: string, podName: string, lines: number = 100): Promise<string> => {
   const response = await apiClient.get<ApiResponse<{ logs: string }>>(
-    `/kubernetes/pods/${namespace}/${podName}/logs?lines=${lines}`
+    `${await getK8sBase()}/pods/${namespace}/${podName}/logs?lines=${lines}`
   );
   return response.data.data?.logs || '';
 };
 
 // ==================== CI/CD Pipeline (NEW) ====================
 export const getWorkflowRuns = async (
-  token: string,
+  credentialId: number,
   owner: string,
   repo: string,
   limit: number = 20
 ): Promise<WorkflowRun[]> => {
   const response = await apiClient.get<ApiResponse<WorkflowRun[]>>(
-    `/cicd/runs?token=${token}&owner=${owner}&repo=${repo}&limit=${limit}`
+    `/cicd/runs?credentialId=${credentialId}&owner=${owner}&repo=${repo}&limit=${limit}`
   );
   return response.data.data || [];
 };
 
 export const getPipelineStats = async (
-  token: string,
+  credentialId: number,
   owner: string,
   repo: string
 ): Promise<PipelineStats> => {
   const response = await apiClient.get<ApiResponse<PipelineStats>>(
-    `/cicd/stats?token=${token}&owner=${owner}&repo=${repo}`
+    `/cicd/stats?credentialId=${credentialId}&owner=${owner}&repo=${repo}`
   );
   return response.data.data || {
     total_runs: 0,
@@ -226,12 +317,12 @@ export const deleteGitOpsApp = async (id: number): Promise<void> => {
 
 
 export const getWorkflows = async (
-  token: string,
+  credentialId: number,
   owner: string,
   repo: string
 ): Promise<Workflow[]> => {
   const response = await apiClient.get<ApiResponse<Workflow[]>>(
-    `/cicd/workflows?token=${token}&owner=${owner}&repo=${repo}`
+    `/cicd/workflows?credentialId=${credentialId}&owner=${owner}&repo=${repo}`
   );
   return response.data.data || [];
 };
\ No newline at end of file
@@ -0,0 +1,36 @@
+import { TokenPair } from '../types/auth';
+
+// The API requires a bearer token on every /api call. Tokens from a password
+// or single sign-on login are kept in localStorage until logout.
+const ACCESS_TOKEN_KEY = 'clouddeck_access_token';
+const REFRESH_TOKEN_KEY = 'clouddeck_refresh_token';
+
+export const getAccessToken = (): string | null => localStorage.getItem(ACCESS_TOKEN_KEY);
+
+export const getRefreshToken = (): string | null => localStorage.getItem(REFRESH_TOKEN_KEY);
+
+export const isLoggedIn = (): boolean => getAccessToken() !== null;
+
+export const setTokens = (tokens: TokenPair): void => {
+  localStorage.setItem(ACCESS_TOKEN_KEY, tokens.accessToken);
+  localStorage.setItem(REFRESH_TOKEN_KEY, tokens.refreshToken);
+};
+
+export const clearTokens = (): void => {
+  localStorage.removeItem(ACCESS_TOKEN_KEY);
+  localStorage.removeItem(REFRESH_TOKEN_KEY);
+};
+
+// After single sign-on the backend redirects here with the tokens in the URL
+// fragment; store them and drop the fragment from the address bar.
+export const captureTokensFromFragment = (): void => {
+  const params = new URLSearchParams(window.location.hash.slice(1));
+  const accessToken = params.get('accessToken');
+  const refreshToken = params.get('refreshToken');
+  if (!accessToken || !refreshToken) {
+    return;
+  }
+
+  setTokens({ accessToken, refreshToken, tokenType: params.get('tokenType') || 'Bearer' });
+  window.history.replaceState(null, '', window.location.pathname + window.location.search);
+};
@@ -0,0 +1,11 @@
+export interface LoginRequest {
+  username: string;
+  password: string;
+}
+
+export interface TokenPair {
+  accessToken: string;
+  refreshToken: string;
+  tokenType: string;
+  expiresAt?: string;
+}
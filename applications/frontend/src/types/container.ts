@@ -1,4 +1,6 @@
 export interface Container {
+  hostId: number;
+  host: string;
   containerId: string;
   name: string;
   status: string;
@@ -11,3 +13,15 @@ export interface Container {
 export interface ContainerLogs {
   logs: string;
 }
+
+export interface FleetHostResult {
+  hostId: number;
+  name: string;
+  containers: number;
+  error?: string;
+}
+
+export interface FleetContainerList {
+  containers: Container[];
+  hosts: FleetHostResult[];
+}
@@ -0,0 +1,16 @@
+export interface Credential {
+  id: number;
+  name: string;
+  provider: string;
+  hint: string;
+  lastUsedAt?: string;
+  rotatedAt?: string;
+  revokedAt?: string;
+  createdAt: string;
+}
+
+export interface CreateCredentialRequest {
+  name: string;
+  provider?: string;
+  secret: string;
+}
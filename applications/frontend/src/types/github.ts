@@ -21,13 +21,13 @@ export interface GitHubIssue {
 }
 
 export interface SyncPRsRequest {
-  token: string;
+  credential_id: number;
   username: string;
   project_id: number;
 }
 
 export interface SyncIssuesRequest {
-  token: string;
+  credential_id: number;
   owner: string;
   repo: string;
   project_id: number;